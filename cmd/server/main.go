@@ -10,15 +10,17 @@ import (
 
 	"rbac7/internal/rbac/config"
 	"rbac7/internal/rbac/handler"
+	"rbac7/internal/rbac/notify"
+	"rbac7/internal/rbac/policy"
 	"rbac7/internal/rbac/repository"
 	"rbac7/internal/rbac/router"
 	"rbac7/internal/rbac/service"
 	"rbac7/internal/rbac/util"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	// Note: Go 1.21+ uses "log/slog", but for compatibility check standard lib
 )
 
@@ -50,6 +52,37 @@ func main() {
 	// 3. Init Layers
 	db := client.Database(cfg.DBName)
 	repo := repository.NewMongoRepository(db, cfg.UserRolesCollection, cfg.ResourceRolesCollection)
+	repo.RouteResourceTypes(cfg.ResourceTypeCollections)
+	repo.NamespacedResources = cfg.NamespacedResources
+	repo.MultiRole = cfg.MultiRole
+	repo.PendingOwners = cfg.PendingOwners
+	repo.PendingOwnersActive = cfg.PendingOwnersActive
+	repo.MultipleOwners = cfg.AllowMultipleOwners
+	repo.Standalone = cfg.MongoStandalone
+	repo.TxnMaxRetries = cfg.MongoTxnMaxRetries
+	repo.TxnTimeout = cfg.MongoTxnTimeout
+	repo.SoftDeleteGracePeriod = cfg.SoftDeleteGracePeriod
+	repo.ExpiredRoleRetention = cfg.ExpiredRoleRetention
+	repo.RejectExistingRole = cfg.RejectExistingRole
+	if cfg.MongoReadPreference != "" {
+		mode, _ := readpref.ModeFromString(cfg.MongoReadPreference) // checked by config.Validate
+		rp, err := readpref.New(mode)
+		if err != nil {
+			logger.Error("Invalid MongoDB read preference", "error", err)
+			os.Exit(1)
+		}
+		repo.ReadPreference = rp
+		logger.Info("Permission checks and listing reads use read preference", "mode", rp.Mode().String())
+	}
+	if cfg.MultiRole {
+		logger.Info("MULTI_ROLE set: users may hold several roles; drop the single-role unique indexes when switching modes")
+	}
+	if cfg.AllowMultipleOwners {
+		logger.Info("ALLOW_MULTIPLE_OWNERS set: namespaces may have co-owners; drop unique_system_owner_v2 when switching modes")
+	}
+	if cfg.MongoStandalone {
+		logger.Warn("MONGO_STANDALONE set: role writes and their history are not transactional")
+	}
 
 	// Ensure Indexes
 	if err := repo.EnsureIndexes(context.Background()); err != nil {
@@ -61,30 +94,53 @@ func main() {
 	}
 
 	svc := service.NewService(repo, repo) // repo implements both RBACRepository and HistoryRepository
+	if cfg.PolicyDir != "" {
+		svc.Policy.SetLoader(policy.NewDirLoader(cfg.PolicyDir))
+		if err := svc.Policy.Reload(); err != nil {
+			logger.Error("Failed to load policies", "dir", cfg.PolicyDir, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("Policies loaded from directory; POST /api/v1/policy/reload applies edits", "dir", cfg.PolicyDir)
+	}
+	svc.Policy.SetStrictPermissions(cfg.StrictPermissions)
+	if cfg.RBACDebugDenials {
+		logger.Warn("RBAC_DEBUG_DENIALS set: permission errors expose policy details to callers")
+		svc.Policy.SetDebugDenials(true)
+	}
+	svc.PendingOwners = cfg.PendingOwners
+	svc.AllowMultipleOwners = cfg.AllowMultipleOwners
+	svc.NamespacedResources = cfg.NamespacedResources
+	svc.WidgetCheckConcurrency = cfg.WidgetCheckConcurrency
+	svc.MaxUnpagedRoles = cfg.MaxUnpagedRoles
+	if len(cfg.SuperadminUserIDs) > 0 {
+		logger.Warn("Superadmin permission bypass enabled", "user_ids", cfg.SuperadminUserIDs)
+		svc.Policy.SetSuperadmins(cfg.SuperadminUserIDs)
+	}
+	var notifier *notify.WebhookNotifier
+	if cfg.NotifyWebhookURL != "" {
+		notifier = notify.NewWebhookNotifier(cfg.NotifyWebhookURL, cfg.NotifyTimeout, cfg.NotifyQueueSize)
+		svc.Notifier = notifier
+	}
 	h := handler.NewSystemHandler(svc)
+	h.MaxChildResourceIDs = cfg.MaxChildResourceIDs
+	h.MaxPageSize = cfg.MaxPageSize
+	h.FoldResourceIDCase = cfg.FoldResourceIDCase
 
 	// 4. Init Echo & Routes
 	e := echo.New()
-	e.Use(middleware.Recover())
-	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
-		LogStatus: true,
-		LogURI:    true,
-		LogMethod: true,
-		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-			logger.Info("request",
-				"method", v.Method,
-				"uri", v.URI,
-				"status", v.Status,
-			)
-			return nil
-		},
+	// Access log is outermost so recovered panics are logged with their 500 status
+	e.Use(handler.AccessLogMiddleware(handler.AccessLogConfig{
+		Logger:         logger,
+		ReadSampleRate: cfg.AccessLogReadSampleRate,
+	}))
+	e.Use(handler.RecoverMiddleware(logger))
+	e.Use(handler.CORSMiddleware(handler.CORSConfig{
+		AllowOrigins: cfg.CORSAllowOrigins,
+		AllowMethods: cfg.CORSAllowMethods,
+		AllowHeaders: cfg.CORSAllowHeaders,
 	}))
 
-	// Load API configs for RBAC middleware
-	policyLoader := svc.Policy.GetLoader()
-	apiConfigs := policyLoader.LoadAPIConfigs(svc.Policy.GetEntityPolicies())
-
-	router.RegisterRoutes(e, h, svc.Policy, repo, apiConfigs)
+	router.RegisterRoutes(e, h, svc.Policy, repo)
 
 	// 5. Start Server with Graceful Shutdown
 	srv := &http.Server{
@@ -118,6 +174,13 @@ func main() {
 		logger.Error("Server Shutdown Failed", "error", err)
 	}
 
+	// Flush pending grant notifications
+	if notifier != nil {
+		if err := notifier.Close(ctx); err != nil {
+			logger.Warn("Failed to flush grant notifications", "error", err)
+		}
+	}
+
 	// Disconnect DB
 	if err := client.Disconnect(ctx); err != nil {
 		logger.Error("Failed to disconnect DB", "error", err)
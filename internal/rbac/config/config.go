@@ -1,10 +1,17 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 type Config struct {
@@ -13,8 +20,102 @@ type Config struct {
 	DBName                  string
 	UserRolesCollection     string
 	ResourceRolesCollection string
-	ReadTimeout             time.Duration
-	WriteTimeout            time.Duration
+	// ResourceTypeCollections routes roles of a resource type to a dedicated collection.
+	// Resource types not listed stay in ResourceRolesCollection.
+	ResourceTypeCollections map[string]string
+	// NamespacedResources includes namespace in the resource unique index (strict namespace mode)
+	NamespacedResources bool
+	// MultiRole includes role in the unique indexes so a user may hold several roles at once;
+	// permission checks then pass if any held role grants the permission
+	MultiRole bool
+	// PendingOwners creates the target of an ownership transfer who holds no member role there as a
+	// pending owner, activated on their first GET /user_roles/me; PendingOwnersActive lets pending
+	// owners pass permission checks before that
+	PendingOwners       bool
+	PendingOwnersActive bool
+	// AllowMultipleOwners lets a system namespace have several co-owners (requires dropping the
+	// unique_system_owner_v2 index when switching)
+	AllowMultipleOwners bool
+	// MongoReadPreference routes permission checks and listing reads, e.g. "secondaryPreferred"
+	// (empty keeps the primary). Secondary reads may briefly miss just-granted roles.
+	MongoReadPreference string
+	// MongoStandalone disables multi-document transactions for a MongoDB without a replica set
+	MongoStandalone bool
+	// MongoTxnMaxRetries caps retries of a transaction after transient errors; MongoTxnTimeout bounds
+	// a transaction including retries. Exhausting the retries fails the request with 429 and
+	// Retry-After, exhausting the timeout with 503.
+	MongoTxnMaxRetries int
+	MongoTxnTimeout    time.Duration
+	// SoftDeleteGracePeriod rejects re-adding a removed user for this long after removal (0 disables it)
+	SoftDeleteGracePeriod time.Duration
+	// ExpiredRoleRetention lets a TTL index remove temporary grants this long after they expire (0 keeps them)
+	ExpiredRoleRetention time.Duration
+	// RejectExistingRole answers assigning a role the user already holds with 409 already_has_role
+	// instead of a no-op 200, for idempotency-strict clients
+	RejectExistingRole bool
+	// FoldResourceIDCase lower-cases resource IDs so IDs differing only in case share roles
+	FoldResourceIDCase bool
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	// CORS policy for browser clients (e.g. Swagger UI)
+	CORSAllowOrigins []string
+	CORSAllowMethods []string
+	CORSAllowHeaders []string
+	// AccessLogReadSampleRate is the fraction of successful GET requests written to the access log
+	AccessLogReadSampleRate float64
+	// MaxChildResourceIDs caps child_resource_ids per request (0 disables the cap)
+	MaxChildResourceIDs int
+	// MaxPageSize clamps the size query parameter of paginated endpoints (at most 1000)
+	MaxPageSize int
+	// WidgetCheckConcurrency bounds how many child widgets one dashboard request checks at once
+	WidgetCheckConcurrency int
+	// MaxUnpagedRoles caps the roles returned by unpaginated listings and by one sync page (0 disables the cap)
+	MaxUnpagedRoles int
+	// NotifyWebhookURL receives grant notifications for notify=true assignments (empty disables them)
+	NotifyWebhookURL string
+	NotifyQueueSize  int
+	NotifyTimeout    time.Duration
+	// StrictPermissions fails checks of a permission no role grants instead of denying them
+	StrictPermissions bool
+	// RBACDebugDenials adds the matched operation and unmet requirement to RBAC middleware errors
+	// (leaks policy details; keep off in production)
+	RBACDebugDenials bool
+	// SuperadminUserIDs bypass operation permission checks (break-glass; empty disables the bypass)
+	SuperadminUserIDs []string
+	// PolicyDir loads policy files from this directory instead of the embedded ones, so edits apply
+	// with POST /policy/reload (empty uses the embedded policies)
+	PolicyDir string
+
+	// envErrors lists typed env vars that could not be parsed (their defaults were used)
+	envErrors []string
+}
+
+// typedEnv maps typed env vars to their parser, so unparsable values are reported instead of
+// silently falling back to the default
+var typedEnv = map[string]func(string) error{
+	"RESOURCE_INDEX_INCLUDE_NAMESPACE": func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"MULTI_ROLE":                       func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"PENDING_OWNERS":                   func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"PENDING_OWNERS_ACTIVE":            func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"ALLOW_MULTIPLE_OWNERS":            func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"RESOURCE_ID_CASE_FOLD":            func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"REJECT_EXISTING_ROLE":             func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"MONGO_STANDALONE":                 func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"STRICT_PERMISSIONS":               func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"RBAC_DEBUG_DENIALS":               func(v string) error { _, err := strconv.ParseBool(v); return err },
+	"SERVER_READ_TIMEOUT":              func(v string) error { _, err := parseDuration(v); return err },
+	"SERVER_WRITE_TIMEOUT":             func(v string) error { _, err := parseDuration(v); return err },
+	"NOTIFY_TIMEOUT":                   func(v string) error { _, err := parseDuration(v); return err },
+	"SOFT_DELETE_GRACE_PERIOD":         func(v string) error { _, err := parseDuration(v); return err },
+	"EXPIRED_ROLE_RETENTION":           func(v string) error { _, err := parseDuration(v); return err },
+	"MONGO_TXN_TIMEOUT":                func(v string) error { _, err := parseDuration(v); return err },
+	"MONGO_TXN_MAX_RETRIES":            func(v string) error { _, err := strconv.Atoi(v); return err },
+	"ACCESS_LOG_READ_SAMPLE_RATE":      func(v string) error { _, err := strconv.ParseFloat(v, 64); return err },
+	"MAX_CHILD_RESOURCE_IDS":           func(v string) error { _, err := strconv.Atoi(v); return err },
+	"MAX_PAGE_SIZE":                    func(v string) error { _, err := strconv.Atoi(v); return err },
+	"WIDGET_CHECK_CONCURRENCY":         func(v string) error { _, err := strconv.Atoi(v); return err },
+	"MAX_UNPAGED_ROLES":                func(v string) error { _, err := strconv.Atoi(v); return err },
+	"NOTIFY_QUEUE_SIZE":                func(v string) error { _, err := strconv.Atoi(v); return err },
 }
 
 func LoadConfig() (*Config, error) {
@@ -42,9 +143,44 @@ func LoadConfig() (*Config, error) {
 		DBName:                  getEnv("DB_NAME", "rbac_db"),
 		UserRolesCollection:     getEnv("COLLECTION_USER_ROLES", "user_roles"),
 		ResourceRolesCollection: getEnv("COLLECTION_RESOURCE_ROLES", "user_resource_roles"),
+		ResourceTypeCollections: getEnvMap("COLLECTION_RESOURCE_ROLES_BY_TYPE"),
+		NamespacedResources:     getEnvBool("RESOURCE_INDEX_INCLUDE_NAMESPACE", false),
+		MultiRole:               getEnvBool("MULTI_ROLE", false),
+		PendingOwners:           getEnvBool("PENDING_OWNERS", false),
+		PendingOwnersActive:     getEnvBool("PENDING_OWNERS_ACTIVE", false),
+		AllowMultipleOwners:     getEnvBool("ALLOW_MULTIPLE_OWNERS", false),
+		MongoReadPreference:     getEnv("MONGO_READ_PREFERENCE", ""),
+		MongoStandalone:         getEnvBool("MONGO_STANDALONE", false),
+		MongoTxnMaxRetries:      getEnvInt("MONGO_TXN_MAX_RETRIES", 3),
+		MongoTxnTimeout:         getEnvDuration("MONGO_TXN_TIMEOUT", 10*time.Second),
+		SoftDeleteGracePeriod:   getEnvDuration("SOFT_DELETE_GRACE_PERIOD", 0),
+		ExpiredRoleRetention:    getEnvDuration("EXPIRED_ROLE_RETENTION", 0),
+		RejectExistingRole:      getEnvBool("REJECT_EXISTING_ROLE", false),
+		FoldResourceIDCase:      getEnvBool("RESOURCE_ID_CASE_FOLD", false),
 		ReadTimeout:             readTimeout,
 		WriteTimeout:            writeTimeout,
+		CORSAllowOrigins:        getEnvList("CORS_ALLOW_ORIGINS", []string{"http://localhost:8080"}),
+		CORSAllowMethods:        getEnvList("CORS_ALLOW_METHODS", []string{"GET", "PUT", "POST", "DELETE", "OPTIONS"}),
+		CORSAllowHeaders:        getEnvList("CORS_ALLOW_HEADERS", []string{"Origin", "Content-Type", "Accept", "authentication", "x-user-id"}),
+		AccessLogReadSampleRate: getEnvFloat("ACCESS_LOG_READ_SAMPLE_RATE", 1.0),
+		MaxChildResourceIDs:     getEnvInt("MAX_CHILD_RESOURCE_IDS", 500),
+		MaxPageSize:             getEnvInt("MAX_PAGE_SIZE", 1000),
+		WidgetCheckConcurrency:  getEnvInt("WIDGET_CHECK_CONCURRENCY", 8),
+		MaxUnpagedRoles:         getEnvInt("MAX_UNPAGED_ROLES", 10000),
+		NotifyWebhookURL:        getEnv("NOTIFY_WEBHOOK_URL", ""),
+		NotifyQueueSize:         getEnvInt("NOTIFY_QUEUE_SIZE", 1000),
+		NotifyTimeout:           getEnvDuration("NOTIFY_TIMEOUT", 5*time.Second),
+		StrictPermissions:       getEnvBool("STRICT_PERMISSIONS", false),
+		RBACDebugDenials:        getEnvBool("RBAC_DEBUG_DENIALS", false),
+		SuperadminUserIDs:       getEnvList("SUPERADMIN_USER_IDS", nil),
+		PolicyDir:               getEnv("POLICY_DIR", ""),
+	}
+	for key, parse := range typedEnv {
+		if v := os.Getenv(key); v != "" && parse(v) != nil {
+			cfg.envErrors = append(cfg.envErrors, fmt.Sprintf("%s=%q is not a valid value", key, v))
+		}
 	}
+	sort.Strings(cfg.envErrors)
 
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -53,9 +189,87 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// Validate checks the whole configuration and reports every problem at once, so a misconfigured
+// deployment fails at startup with one clear message instead of later at connect or request time.
 func (c *Config) Validate() error {
+	problems := append([]string{}, c.envErrors...)
+
 	if c.MongoURI == "" {
-		return fmt.Errorf("MONGO_URI is required")
+		problems = append(problems, "MONGO_URI is required")
+	} else if err := options.Client().ApplyURI(c.MongoURI).Validate(); err != nil {
+		problems = append(problems, fmt.Sprintf("MONGO_URI is malformed: %v", err))
+	}
+	if c.MongoReadPreference != "" {
+		if _, err := readpref.ModeFromString(c.MongoReadPreference); err != nil {
+			problems = append(problems, fmt.Sprintf("MONGO_READ_PREFERENCE=%q must be one of primary, primaryPreferred, secondary, secondaryPreferred, nearest", c.MongoReadPreference))
+		}
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT=%q must be a number between 1 and 65535", c.Port))
+	}
+	if strings.TrimSpace(c.DBName) == "" {
+		problems = append(problems, "DB_NAME must not be empty")
+	}
+	if strings.TrimSpace(c.UserRolesCollection) == "" {
+		problems = append(problems, "COLLECTION_USER_ROLES must not be empty")
+	}
+	if strings.TrimSpace(c.ResourceRolesCollection) == "" {
+		problems = append(problems, "COLLECTION_RESOURCE_ROLES must not be empty")
+	} else if c.ResourceRolesCollection == c.UserRolesCollection {
+		problems = append(problems, "COLLECTION_RESOURCE_ROLES must differ from COLLECTION_USER_ROLES")
+	}
+	for resourceType, name := range c.ResourceTypeCollections {
+		if name == c.UserRolesCollection {
+			problems = append(problems, fmt.Sprintf("COLLECTION_RESOURCE_ROLES_BY_TYPE routes %s to the system roles collection", resourceType))
+		}
+	}
+	if c.MongoTxnMaxRetries < 0 {
+		problems = append(problems, "MONGO_TXN_MAX_RETRIES must not be negative")
+	}
+	if c.MongoTxnTimeout <= 0 {
+		problems = append(problems, "MONGO_TXN_TIMEOUT must be positive")
+	}
+	if c.SoftDeleteGracePeriod < 0 {
+		problems = append(problems, "SOFT_DELETE_GRACE_PERIOD must not be negative")
+	}
+	if c.ExpiredRoleRetention < 0 {
+		problems = append(problems, "EXPIRED_ROLE_RETENTION must not be negative")
+	}
+	if c.ReadTimeout <= 0 {
+		problems = append(problems, "SERVER_READ_TIMEOUT must be positive")
+	}
+	if c.WriteTimeout <= 0 {
+		problems = append(problems, "SERVER_WRITE_TIMEOUT must be positive")
+	}
+	if c.AccessLogReadSampleRate < 0 || c.AccessLogReadSampleRate > 1 {
+		problems = append(problems, "ACCESS_LOG_READ_SAMPLE_RATE must be between 0 and 1")
+	}
+	if c.MaxChildResourceIDs < 0 {
+		problems = append(problems, "MAX_CHILD_RESOURCE_IDS must not be negative")
+	}
+	if c.MaxPageSize < 1 || c.MaxPageSize > 1000 {
+		problems = append(problems, "MAX_PAGE_SIZE must be between 1 and 1000")
+	}
+	if c.WidgetCheckConcurrency < 1 {
+		problems = append(problems, "WIDGET_CHECK_CONCURRENCY must be at least 1")
+	}
+	if c.MaxUnpagedRoles < 0 {
+		problems = append(problems, "MAX_UNPAGED_ROLES must not be negative")
+	}
+	if c.NotifyWebhookURL != "" {
+		if u, err := url.Parse(c.NotifyWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("NOTIFY_WEBHOOK_URL=%q must be an http(s) URL", c.NotifyWebhookURL))
+		}
+		if c.NotifyQueueSize <= 0 {
+			problems = append(problems, "NOTIFY_QUEUE_SIZE must be positive")
+		}
+		if c.NotifyTimeout <= 0 {
+			problems = append(problems, "NOTIFY_TIMEOUT must be positive")
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
 	}
 	return nil
 }
@@ -67,19 +281,93 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func getEnvDuration(key string, fallback time.Duration) time.Duration {
+// getEnvList parses a comma separated list, e.g. "https://a.example.com,https://b.example.com"
+func getEnvList(key string, fallback []string) []string {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return fallback
+	}
+	var result []string
+	for _, item := range strings.Split(valStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvMap parses "key=value,key=value" pairs, e.g. "dashboard=dashboard_roles,library_widget=library_widget_roles".
+// Malformed pairs are skipped.
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return result
+	}
+	for _, pair := range strings.Split(valStr, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return fallback
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func getEnvInt(key string, fallback int) int {
 	valStr := os.Getenv(key)
 	if valStr == "" {
 		return fallback
 	}
 	val, err := strconv.Atoi(valStr)
 	if err != nil {
-		// Try parsing as duration string? e.g. "10s"
-		d, err := time.ParseDuration(valStr)
-		if err == nil {
-			return d
-		}
 		return fallback
 	}
-	return time.Duration(val) * time.Second
+	return val
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return fallback
+	}
+	val, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return fallback
+	}
+	val, err := parseDuration(valStr)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// parseDuration accepts whole seconds ("10") or a Go duration string ("10s", "500ms")
+func parseDuration(valStr string) (time.Duration, error) {
+	if val, err := strconv.Atoi(valStr); err == nil {
+		return time.Duration(val) * time.Second, nil
+	}
+	return time.ParseDuration(valStr)
 }
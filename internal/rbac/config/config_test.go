@@ -0,0 +1,99 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		MongoURI:                "mongodb://localhost:27017",
+		Port:                    "8080",
+		DBName:                  "rbac_db",
+		UserRolesCollection:     "user_roles",
+		ResourceRolesCollection: "user_resource_roles",
+		MongoTxnMaxRetries:      3,
+		MongoTxnTimeout:         10 * time.Second,
+		ReadTimeout:             10 * time.Second,
+		WriteTimeout:            10 * time.Second,
+		AccessLogReadSampleRate: 1,
+		MaxChildResourceIDs:     500,
+		MaxPageSize:             1000,
+		MaxUnpagedRoles:         10000,
+		NotifyQueueSize:         1000,
+		NotifyTimeout:           5 * time.Second,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("missing required fields are all reported", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MongoURI = ""
+		cfg.DBName = ""
+		cfg.UserRolesCollection = ""
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "MONGO_URI is required")
+		assert.Contains(t, err.Error(), "DB_NAME must not be empty")
+		assert.Contains(t, err.Error(), "COLLECTION_USER_ROLES must not be empty")
+	})
+
+	t.Run("malformed values are reported", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MongoURI = "localhost:27017"
+		cfg.Port = "http"
+		cfg.ResourceRolesCollection = cfg.UserRolesCollection
+		cfg.AccessLogReadSampleRate = 1.5
+		cfg.NotifyWebhookURL = "hooks.example.com/rbac"
+		cfg.MongoReadPreference = "replica"
+		cfg.MongoTxnMaxRetries = -1
+		cfg.MaxPageSize = 5000
+		cfg.MaxUnpagedRoles = -1
+		cfg.ExpiredRoleRetention = -time.Hour
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "MONGO_URI is malformed")
+		assert.Contains(t, err.Error(), `PORT="http" must be a number`)
+		assert.Contains(t, err.Error(), "COLLECTION_RESOURCE_ROLES must differ")
+		assert.Contains(t, err.Error(), "ACCESS_LOG_READ_SAMPLE_RATE must be between 0 and 1")
+		assert.Contains(t, err.Error(), "NOTIFY_WEBHOOK_URL")
+		assert.Contains(t, err.Error(), `MONGO_READ_PREFERENCE="replica"`)
+		assert.Contains(t, err.Error(), "MONGO_TXN_MAX_RETRIES must not be negative")
+		assert.Contains(t, err.Error(), "MAX_PAGE_SIZE must be between 1 and 1000")
+		assert.Contains(t, err.Error(), "MAX_UNPAGED_ROLES must not be negative")
+		assert.Contains(t, err.Error(), "EXPIRED_ROLE_RETENTION must not be negative")
+	})
+
+	t.Run("unparsable typed env vars fail LoadConfig", func(t *testing.T) {
+		t.Setenv("MAX_CHILD_RESOURCE_IDS", "lots")
+		t.Setenv("SERVER_READ_TIMEOUT", "soon")
+		t.Setenv("PORT", "99999")
+
+		_, err := LoadConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `MAX_CHILD_RESOURCE_IDS="lots" is not a valid value`)
+		assert.Contains(t, err.Error(), `SERVER_READ_TIMEOUT="soon" is not a valid value`)
+		assert.Contains(t, err.Error(), `PORT="99999"`)
+	})
+
+	t.Run("durations accept seconds and duration strings", func(t *testing.T) {
+		t.Setenv("SERVER_READ_TIMEOUT", "15")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "500ms")
+
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Second, cfg.ReadTimeout)
+		assert.Equal(t, 500*time.Millisecond, cfg.WriteTimeout)
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AccessLogConfig configures the structured access log
+type AccessLogConfig struct {
+	Logger *slog.Logger
+	// ReadSampleRate is the fraction (0..1) of successful GET requests that are logged.
+	// Writes and failed requests are always logged.
+	ReadSampleRate float64
+}
+
+// AccessLogMiddleware emits one JSON log line per request with request ID, caller, route, status and latency.
+// The request ID is the correlation ID set by RequestIDMiddleware (or sent by the client).
+func AccessLogMiddleware(cfg AccessLogConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			latency := time.Since(start)
+
+			req := c.Request()
+			status := c.Response().Status
+			if req.Method == http.MethodGet && status < http.StatusBadRequest && !sampled(cfg.ReadSampleRate) {
+				return nil
+			}
+
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = req.Header.Get(echo.HeaderXRequestID)
+			}
+
+			cfg.Logger.Info("request",
+				"request_id", requestID,
+				"caller", req.Header.Get("x-user-id"),
+				"method", req.Method,
+				"route", c.Path(),
+				"uri", req.RequestURI,
+				"status", status,
+				"latency_ms", latency.Milliseconds(),
+			)
+			return nil
+		}
+	}
+}
+
+// sampled reports whether a request should be logged for the given sample rate
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return rand.Float64() < rate
+}
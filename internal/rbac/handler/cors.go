@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// CORSConfig defines which browser origins may call the API
+type CORSConfig struct {
+	AllowOrigins []string
+	AllowMethods []string
+	AllowHeaders []string
+}
+
+// CORSMiddleware builds the CORS middleware from config.
+// An empty AllowOrigins denies every cross-origin request instead of falling back to Echo's "*" default.
+func CORSMiddleware(cfg CORSConfig) echo.MiddlewareFunc {
+	corsConfig := middleware.CORSConfig{
+		AllowOrigins: cfg.AllowOrigins,
+		AllowMethods: cfg.AllowMethods,
+		AllowHeaders: cfg.AllowHeaders,
+	}
+	if len(cfg.AllowOrigins) == 0 {
+		corsConfig.AllowOriginFunc = func(origin string) (bool, error) {
+			return false, nil
+		}
+	}
+	return middleware.CORSWithConfig(corsConfig)
+}
@@ -4,36 +4,68 @@ import (
 	"errors"
 	"net/http"
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
 	"rbac7/internal/rbac/service"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// Helper to map errors to HTTP status and body
-func httpError(err error) (int, interface{}) {
-	if errors.Is(err, service.ErrUnauthorized) {
-		return http.StatusUnauthorized, model.ErrorResponse{
-			Error: model.ErrorDetail{Code: "unauthorized", Message: err.Error()},
-		}
-	}
-	if errors.Is(err, service.ErrForbidden) {
-		return http.StatusForbidden, model.ErrorResponse{
-			Error: model.ErrorDetail{Code: "forbidden", Message: err.Error()},
-		}
-	}
-	if errors.Is(err, service.ErrConflict) {
-		return http.StatusConflict, model.ErrorResponse{
-			Error: model.ErrorDetail{Code: "conflict", Message: err.Error()},
-		}
-	}
-	if errors.Is(err, service.ErrInvalidNamespace) || errors.Is(err, service.ErrBadRequest) {
-		return http.StatusBadRequest, model.ErrorResponse{
-			Error: model.ErrorDetail{Code: "bad_request", Message: err.Error()},
+// errorStatuses maps service and repository errors to their HTTP status and error code.
+// Errors are matched with errors.Is in order, so wrapped errors map like their sentinel.
+// New error types get their status here; anything unlisted is a 500.
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{service.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{service.ErrConflict, http.StatusConflict, "conflict"},
+	{service.ErrNamespaceConflict, http.StatusConflict, "conflict"},
+	{repository.ErrRecentlyRemoved, http.StatusConflict, "conflict"},
+	{repository.ErrAlreadyHasRole, http.StatusConflict, "already_has_role"},
+	{service.ErrInvalidNamespace, http.StatusBadRequest, "bad_request"},
+	{service.ErrBadRequest, http.StatusBadRequest, "bad_request"},
+	{service.ErrResourceNotFound, http.StatusNotFound, "not_found"},
+	{service.ErrUserNotResolved, http.StatusNotFound, "not_found"},
+	{repository.ErrOwnerNotFound, http.StatusNotFound, "not_found"},
+	// Reaches the handler only where a missing document is the answer (e.g. nothing to restore)
+	{mongo.ErrNoDocuments, http.StatusNotFound, "not_found"},
+	// Contention or a failover: the write was rolled back and is safe to retry.
+	// Contention answers 429 so clients back off for ContentionRetryAfter first.
+	{repository.ErrTransactionContention, http.StatusTooManyRequests, "too_many_requests"},
+	{repository.ErrTransactionTimeout, http.StatusServiceUnavailable, "service_unavailable"},
+}
+
+// StatusForError returns the HTTP status for err, or 500 when it wraps no known error
+func StatusForError(err error) int {
+	status, _ := errorStatus(err)
+	return status
+}
+
+func errorStatus(err error) (int, string) {
+	for _, entry := range errorStatuses {
+		if errors.Is(err, entry.err) {
+			return entry.status, entry.code
 		}
 	}
+	return http.StatusInternalServerError, "internal_error"
+}
 
-	// Fallback
-	return http.StatusInternalServerError, model.ErrorResponse{
-		Error: model.ErrorDetail{Code: "internal_error", Message: "Internal Server Error"},
+// Helper to map errors to HTTP status and body
+func httpError(err error) (int, interface{}) {
+	status, code := errorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		// Fallback: do not leak internal error details
+		message = "Internal Server Error"
+	}
+	detail := model.ErrorDetail{Code: code, Message: message}
+	var ownerExists *service.OwnerExistsError
+	if errors.As(err, &ownerExists) {
+		detail.CurrentOwner = ownerExists.CurrentOwner
 	}
+	return status, model.ErrorResponse{Error: detail}
 }
 
 // validationError converts validation errors to HTTP response.
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"rbac7/internal/rbac/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PostEraseUser handles POST /admin/users/:id/erase (GDPR erasure)
+func (h *SystemHandler) PostEraseUser(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.EraseUserReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.EraseUser(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostReassignOwner handles POST /user_roles/reassign_owner (offboarding: move all owned resources)
+func (h *SystemHandler) PostReassignOwner(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.ReassignOwnerReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.ReassignOwner(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// DeletePurgeUser handles DELETE /users/:user_id/purge (hard delete of a user's roles)
+func (h *SystemHandler) DeletePurgeUser(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.PurgeUserReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.PurgeUser(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetAccessSnapshot handles GET /admin/users/:id/access_snapshot (support diagnostics)
+func (h *SystemHandler) GetAccessSnapshot(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.AccessSnapshotReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetAccessSnapshot(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostPolicyReload handles POST /policy/reload (apply edited policy files without a restart)
+func (h *SystemHandler) PostPolicyReload(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	result, err := h.Service.ReloadPolicy(c.Request().Context(), callerID)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -1,19 +1,125 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"rbac7/internal/rbac/model"
 	"rbac7/internal/rbac/service"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
+// DefaultMaxChildResourceIDs is the default cap on child_resource_ids per request
+const DefaultMaxChildResourceIDs = 500
+
+// HeaderResultTruncated is set to "true" when an unpaginated listing hit the service's role cap
+const HeaderResultTruncated = "X-Result-Truncated"
+
 type SystemHandler struct {
 	Service service.RBACService
+	// MaxChildResourceIDs caps child_resource_ids per request to bound $in queries (0 disables the cap)
+	MaxChildResourceIDs int
+	// MaxPageSize clamps the size query parameter of paginated endpoints
+	MaxPageSize int
+	// FoldResourceIDCase lower-cases resource IDs of requests so IDs differing only in case share roles
+	FoldResourceIDCase bool
 }
 
 func NewSystemHandler(s service.RBACService) *SystemHandler {
-	return &SystemHandler{Service: s}
+	return &SystemHandler{Service: s, MaxChildResourceIDs: DefaultMaxChildResourceIDs, MaxPageSize: model.MaxPageSize}
+}
+
+// checkChildResourceIDs rejects requests carrying more child_resource_ids than allowed
+func (h *SystemHandler) checkChildResourceIDs(ids []string) error {
+	if h.MaxChildResourceIDs > 0 && len(ids) > h.MaxChildResourceIDs {
+		return &model.ErrorDetail{
+			Code:    "bad_request",
+			Message: fmt.Sprintf("child_resource_ids exceeds the maximum of %d", h.MaxChildResourceIDs),
+		}
+	}
+	return nil
+}
+
+// validate case-folds the request's resource IDs when configured, then normalizes and validates it
+func (h *SystemHandler) validate(req interface{ Validate() error }) error {
+	if folder, ok := req.(model.ResourceIDFolder); ok && h.FoldResourceIDCase {
+		folder.FoldResourceIDCase()
+	}
+	return req.Validate()
+}
+
+// bindStrict decodes the JSON body into v, rejecting unknown fields and wrong-typed values
+// with a precise bad_request instead of echo's generic bind error
+func bindStrict(c echo.Context, v interface{}) error {
+	dec := json.NewDecoder(c.Request().Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		if dec.More() {
+			return &model.ErrorDetail{Code: "bad_request", Message: "Invalid body: unexpected data after JSON object"}
+		}
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		field := strings.SplitN(typeErr.Field, ".", 2)[0]
+		return &model.ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("%s must be %s", field, jsonTypeName(v, field))}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return &model.ErrorDetail{Code: "bad_request", Message: "Invalid body: " + strings.TrimPrefix(err.Error(), "json: ")}
+	case errors.Is(err, io.EOF):
+		return &model.ErrorDetail{Code: "bad_request", Message: "Invalid body: empty"}
+	default:
+		return &model.ErrorDetail{Code: "bad_request", Message: "Invalid body"}
+	}
+}
+
+// jsonTypeName describes the JSON type expected for the struct field tagged name
+func jsonTypeName(v interface{}, name string) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "a valid value"
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] != name {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.String:
+			return "a string"
+		case reflect.Bool:
+			return "a boolean"
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+			return "a number"
+		case reflect.Slice:
+			if f.Type.Elem().Kind() == reflect.String {
+				return "an array of strings"
+			}
+			return "an array"
+		}
+	}
+	return "a valid value"
+}
+
+// success writes the standard assign/transfer response, echoing the normalized request when ?echo=true
+func (h *SystemHandler) success(c echo.Context, req model.RequestEcho) error {
+	resp := model.SuccessResp{Status: "success"}
+	if want, _ := strconv.ParseBool(c.QueryParam("echo")); want {
+		resp.Request = &req
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 func (h *SystemHandler) extractCallerID(c echo.Context) (string, error) {
@@ -38,16 +144,19 @@ func (h *SystemHandler) GetUserRolesMe(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
 	// Forward parameters to service
-	roles, err := h.Service.GetUserRolesMe(c.Request().Context(), callerID, req)
+	roles, truncated, err := h.Service.GetUserRolesMe(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
+	if truncated {
+		c.Response().Header().Set(HeaderResultTruncated, "true")
+	}
 
 	return c.JSON(http.StatusOK, roles)
 }
@@ -66,18 +175,78 @@ func (h *SystemHandler) GetUserRoles(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	roles, err := h.Service.GetUserRoles(c.Request().Context(), callerID, req)
+	roles, truncated, err := h.Service.GetUserRoles(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
+	if truncated {
+		c.Response().Header().Set(HeaderResultTruncated, "true")
+	}
 	return c.JSON(http.StatusOK, roles)
 }
 
+// PostCheckMembers handles POST /user_roles/members/check
+// Returns which of the given users are already members (e.g. before sending invitations)
+func (h *SystemHandler) PostCheckMembers(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.CheckMembersReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.CheckMembers(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostRestoreUserRole handles POST /user_roles/restore
+// Undoes the soft delete of a system or resource role; 404 when there is none to restore
+func (h *SystemHandler) PostRestoreUserRole(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.RestoreUserRoleReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	if err := h.Service.RestoreUserRole(c.Request().Context(), callerID, req); err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResp{Status: "success"})
+}
+
 func (h *SystemHandler) PostPermissionsCheck(c echo.Context) error {
 	callerID, err := h.extractCallerID(c)
 	if err != nil {
@@ -92,7 +261,7 @@ func (h *SystemHandler) PostPermissionsCheck(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
@@ -105,6 +274,63 @@ func (h *SystemHandler) PostPermissionsCheck(c echo.Context) error {
 	return c.JSON(http.StatusOK, model.CheckPermissionResponse{Allowed: allowed})
 }
 
+// PostPermissionsCheckBatch handles POST /permissions/check/batch
+// Checks many permissions of the caller in one round trip (e.g. every widget of a dashboard)
+func (h *SystemHandler) PostPermissionsCheckBatch(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.CheckPermissionsBatchReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.CheckPermissionsBatch(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetPermissionRoles handles GET /permissions/:permission/roles
+func (h *SystemHandler) GetPermissionRoles(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.GetPermissionRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetPermissionRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
 // GetUserRoleHistory handles GET /user_roles/logs
 func (h *SystemHandler) GetUserRoleHistory(c echo.Context) error {
 	callerID, err := h.extractCallerID(c)
@@ -119,8 +345,11 @@ func (h *SystemHandler) GetUserRoleHistory(c echo.Context) error {
 			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
 		})
 	}
+	if req.Page, req.Size, err = ParsePagination(c, h.MaxPageSize); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
@@ -130,5 +359,41 @@ func (h *SystemHandler) GetUserRoleHistory(c echo.Context) error {
 		return c.JSON(code, body)
 	}
 
+	if req.Format == model.HistoryFormatECS {
+		events := make([]*model.ECSEvent, len(result.Data))
+		for i, record := range result.Data {
+			events[i] = model.NewECSEvent(record)
+		}
+		return c.JSON(http.StatusOK, model.GetUserRoleHistoryECSResp{Data: events, Pagination: result.Pagination})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetUserRolesSync handles GET /user_roles/sync (incremental sync)
+func (h *SystemHandler) GetUserRolesSync(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.SyncUserRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.SyncUserRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"rbac7/internal/rbac/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetNamespaceResourceRoles handles GET /namespaces/:namespace/resource_roles
+func (h *SystemHandler) GetNamespaceResourceRoles(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.NamespaceResourceRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetNamespaceResourceRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PutNamespaceResourceRoles handles PUT /namespaces/:namespace/resource_roles
+func (h *SystemHandler) PutNamespaceResourceRoles(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.PutNamespaceResourceRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	err = h.Service.PutNamespaceResourceRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// DeleteNamespaceResourceRoles handles DELETE /namespaces/:namespace/resource_roles (reset to default)
+func (h *SystemHandler) DeleteNamespaceResourceRoles(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.NamespaceResourceRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	err = h.Service.DeleteNamespaceResourceRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// PostRenameNamespace handles POST /namespaces/rename
+func (h *SystemHandler) PostRenameNamespace(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.RenameNamespaceReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.RenameNamespace(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetNamespaceSnapshot handles GET /namespaces/:namespace/snapshot
+func (h *SystemHandler) GetNamespaceSnapshot(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.NamespaceSnapshotReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.SnapshotNamespace(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostRestoreNamespace handles POST /namespaces/:namespace/restore
+func (h *SystemHandler) PostRestoreNamespace(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.RestoreNamespaceReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.RestoreNamespace(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -22,17 +22,17 @@ func (h *SystemHandler) PostResourceOwner(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.AssignResourceOwner(c.Request().Context(), callerID, req)
+	role, err := h.Service.AssignResourceOwner(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return h.success(c, role.Echo())
 }
 
 // PutResourceOwner handles PUT /user_roles/resources/owner (Transfer Owner)
@@ -50,17 +50,17 @@ func (h *SystemHandler) PutResourceOwner(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.TransferResourceOwner(c.Request().Context(), callerID, req)
+	role, err := h.Service.TransferResourceOwner(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return h.success(c, role.Echo())
 }
 
 // PostResourceUserRoles handles POST /resource_roles (Assign Member)
@@ -78,17 +78,23 @@ func (h *SystemHandler) PostResourceUserRoles(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.AssignResourceUserRole(c.Request().Context(), callerID, req)
+	req.UserID, err = h.Service.ResolveUserID(c.Request().Context(), req.UserID, req.ExternalID, req.Email)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	role, err := h.Service.AssignResourceUserRole(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return h.success(c, role.Echo())
 }
 
 // DeleteResourceUserRoles handles DELETE /resource_roles (Remove Member)
@@ -107,17 +113,17 @@ func (h *SystemHandler) DeleteResourceUserRoles(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.DeleteResourceUserRole(c.Request().Context(), callerID, req)
+	deleted, err := h.Service.DeleteResourceUserRole(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return c.JSON(http.StatusOK, model.DeleteResp{Status: "success", DeletedCount: deleted})
 }
 
 // PostResourceUserRolesBatch handles POST /user_roles/resources/batch (Batch Assign Members)
@@ -135,10 +141,15 @@ func (h *SystemHandler) PostResourceUserRolesBatch(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
+	if err := h.Service.ResolveAssignments(c.Request().Context(), req.Assignments); err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
 	result, err := h.Service.AssignResourceUserRoles(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
@@ -157,23 +168,25 @@ func (h *SystemHandler) PutDeleteResource(c echo.Context) error {
 	}
 
 	var req model.SoftDeleteResourceReq
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
-		})
+	if err := bindStrict(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.checkChildResourceIDs(req.ChildResourceIDs); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.SoftDeleteResource(c.Request().Context(), callerID, &req)
+	deleted, err := h.Service.SoftDeleteResource(c.Request().Context(), callerID, &req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return c.JSON(http.StatusOK, model.DeleteResp{Status: "success", DeletedCount: deleted})
 }
 
 // GetDashboardResource handles POST /resources/dashboards
@@ -192,7 +205,11 @@ func (h *SystemHandler) GetDashboardResource(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	if err := h.checkChildResourceIDs(req.ChildResourceIDs); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
@@ -204,3 +221,137 @@ func (h *SystemHandler) GetDashboardResource(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// GetAccessibleResourceSummary handles GET /resources/accessible/summary
+// Returns how many resources of each type the caller can access
+func (h *SystemHandler) GetAccessibleResourceSummary(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	result, err := h.Service.GetAccessibleResourceSummary(c.Request().Context(), callerID)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostMyResourceRoles handles POST /user_roles/resources/my_roles
+// Returns the caller's role on each requested resource (null when none)
+func (h *SystemHandler) PostMyResourceRoles(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.GetMyResourceRolesReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetMyResourceRoles(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PostResourceOwners handles POST /resources/owners
+// Returns the owner user_id of each requested resource (null when ownerless)
+func (h *SystemHandler) PostResourceOwners(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.GetResourceOwnersReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid body"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetResourceOwners(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetResourceOwner handles GET /resources/owner
+// Returns the resource's owner role, or null when it has none
+func (h *SystemHandler) GetResourceOwner(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.GetResourceOwnerReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	owner, err := h.Service.GetResourceOwner(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, owner)
+}
+
+// GetCapableUsers handles GET /resources/capable_users
+// Returns the members whose role on the resource grants the given permission
+func (h *SystemHandler) GetCapableUsers(c echo.Context) error {
+	callerID, err := h.extractCallerID(c)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	var req model.GetCapableUsersReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "bad_request", Message: "Invalid parameters"},
+		})
+	}
+
+	if err := h.validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, validationError(err))
+	}
+
+	result, err := h.Service.GetCapableUsers(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
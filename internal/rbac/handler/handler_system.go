@@ -24,17 +24,17 @@ func (h *SystemHandler) PostSystemOwner(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.AssignSystemOwner(c.Request().Context(), callerID, req)
+	role, err := h.Service.AssignSystemOwner(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return h.success(c, role.Echo())
 }
 
 // PutSystemOwner handles PUT /user_roles/owner (Transfer)
@@ -54,18 +54,18 @@ func (h *SystemHandler) PutSystemOwner(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
 	// 3. Call Service
-	err = h.Service.TransferSystemOwner(c.Request().Context(), callerID, req)
+	role, err := h.Service.TransferSystemOwner(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return h.success(c, role.Echo())
 }
 
 // PostUserRoles handles POST /user_roles (System Scope)
@@ -83,17 +83,23 @@ func (h *SystemHandler) PostUserRoles(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.AssignSystemUserRole(c.Request().Context(), callerID, req)
+	req.UserID, err = h.Service.ResolveUserID(c.Request().Context(), req.UserID, req.ExternalID, req.Email)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	role, err := h.Service.AssignSystemUserRole(c.Request().Context(), callerID, req)
+	if err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
+	return h.success(c, role.Echo())
 }
 
 // PostUserRolesBatch handles POST /user_roles/batch (System Scope)
@@ -111,10 +117,15 @@ func (h *SystemHandler) PostUserRolesBatch(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
+	if err := h.Service.ResolveAssignments(c.Request().Context(), req.Assignments); err != nil {
+		code, body := httpError(err)
+		return c.JSON(code, body)
+	}
+
 	result, err := h.Service.AssignSystemUserRoles(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
@@ -140,15 +151,15 @@ func (h *SystemHandler) DeleteUserRoles(c echo.Context) error {
 		})
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := h.validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, validationError(err))
 	}
 
-	err = h.Service.DeleteSystemUserRole(c.Request().Context(), callerID, req)
+	deleted, err := h.Service.DeleteSystemUserRole(c.Request().Context(), callerID, req)
 	if err != nil {
 		code, body := httpError(err)
 		return c.JSON(code, body)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	return c.JSON(http.StatusOK, model.DeleteResp{Status: "success", DeletedCount: deleted})
 }
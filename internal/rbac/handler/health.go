@@ -1,13 +1,38 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"time"
+
+	"rbac7/internal/rbac/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
+// readinessTimeout bounds the repository self-test so a hung database fails the probe instead of stalling it
+const readinessTimeout = 3 * time.Second
+
 func HealthCheck(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{
 		"status": "up",
 	})
 }
+
+// ReadinessCheck reports ready only when the repository can serve role queries
+func ReadinessCheck(repo repository.RBACRepository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+		defer cancel()
+
+		if err := repo.RepositoryHealth(ctx); err != nil {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{
+				"status": "not_ready",
+				"error":  err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]string{
+			"status": "ready",
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,3 +21,20 @@ func RequestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// ContentionRetryAfter is the Retry-After, in seconds, sent with 429 responses
+const ContentionRetryAfter = "1"
+
+// RetryAfterMiddleware adds Retry-After to 429 responses, so clients hitting write contention back off
+// before retrying. Handlers map errors to their status in one place, so the header is set here.
+func RetryAfterMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Before(func() {
+			if res.Status == http.StatusTooManyRequests && res.Header().Get(echo.HeaderRetryAfter) == "" {
+				res.Header().Set(echo.HeaderRetryAfter, ContentionRetryAfter)
+			}
+		})
+		return next(c)
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ParsePagination reads the 1-based page and size query parameters of a paginated endpoint.
+// Absent values default to page 1 and model.DefaultPageSize; non-numeric, zero or negative values
+// are rejected; size is clamped to maxSize.
+func ParsePagination(c echo.Context, maxSize int) (page, size int, err error) {
+	if maxSize <= 0 || maxSize > model.MaxPageSize {
+		maxSize = model.MaxPageSize
+	}
+
+	page, err = parsePositiveQuery(c, "page", 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err = parsePositiveQuery(c, "size", model.DefaultPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	if size > maxSize {
+		size = maxSize
+	}
+	return page, size, nil
+}
+
+// parsePositiveQuery parses an optional positive integer query parameter
+func parsePositiveQuery(c echo.Context, name string, def int) (int, error) {
+	raw := strings.TrimSpace(c.QueryParam(name))
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &model.ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("%s must be an integer", name)}
+	}
+	if n < 1 {
+		return 0, &model.ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("%s must be at least 1", name)}
+	}
+	return n, nil
+}
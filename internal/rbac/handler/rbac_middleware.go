@@ -6,11 +6,13 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"rbac7/internal/rbac/model"
 	"rbac7/internal/rbac/policy"
 	"rbac7/internal/rbac/repository"
+	"rbac7/internal/rbac/service"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,15 +21,17 @@ import (
 type RBACMiddleware struct {
 	policyEngine *policy.Engine
 	repo         repository.RBACRepository
-	apiConfigs   map[string][]*policy.APIConfig // key: "METHOD:PATH"
+	// FoldResourceIDCase lower-cases resource IDs like SystemHandler.FoldResourceIDCase, so the
+	// permission check and the handler address the same resource
+	FoldResourceIDCase bool
 }
 
-// NewRBACMiddleware creates a new RBAC middleware instance
-func NewRBACMiddleware(engine *policy.Engine, repo repository.RBACRepository, apiConfigs map[string][]*policy.APIConfig) *RBACMiddleware {
+// NewRBACMiddleware creates a new RBAC middleware instance. Routes are matched against the engine's
+// current API configs, so a policy reload applies without re-registering routes.
+func NewRBACMiddleware(engine *policy.Engine, repo repository.RBACRepository) *RBACMiddleware {
 	return &RBACMiddleware{
 		policyEngine: engine,
 		repo:         repo,
-		apiConfigs:   apiConfigs,
 	}
 }
 
@@ -39,21 +43,21 @@ func (m *RBACMiddleware) Middleware() echo.MiddlewareFunc {
 			key := c.Request().Method + ":" + c.Path()
 
 			// 2. Find matching API configs
-			configs, exists := m.apiConfigs[key]
+			configs, exists := m.policyEngine.APIConfigs()[key]
 			log.Printf("Audit:RBACMiddleware. key=%s, configs=%v, exists=%v", key, configs, exists)
 
 			if !exists {
-				return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "bad_request", Message: "No matching RBAC configuration for this request"},
-				})
+				return m.deny(c, http.StatusBadRequest,
+					model.ErrorDetail{Code: "bad_request", Message: "No matching RBAC configuration for this request"},
+					&model.RBACDebug{Key: key})
 			}
 
 			// 3. Extract caller ID
 			callerID := c.Request().Header.Get("x-user-id")
 			if callerID == "" {
-				return c.JSON(http.StatusUnauthorized, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "unauthorized", Message: "x-user-id header is required"},
-				})
+				return m.deny(c, http.StatusUnauthorized,
+					model.ErrorDetail{Code: "unauthorized", Message: "x-user-id header is required"},
+					&model.RBACDebug{Key: key})
 			}
 
 			// 4. Parse request body for POST/PUT/DELETE (need to read and restore)
@@ -72,9 +76,9 @@ func (m *RBACMiddleware) Middleware() echo.MiddlewareFunc {
 			log.Printf("Audit:RBACMiddleware. config=%v", config)
 			if config == nil {
 				// No matching condition - return error (invalid request)
-				return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "bad_request", Message: "No matching RBAC configuration for this request"},
-				})
+				return m.deny(c, http.StatusBadRequest,
+					model.ErrorDetail{Code: "bad_request", Message: "No matching RBAC configuration for this request"},
+					&model.RBACDebug{Key: key, Candidates: candidateOperations(configs)})
 			}
 
 			// 6. Skip if no permission required
@@ -90,36 +94,44 @@ func (m *RBACMiddleware) Middleware() echo.MiddlewareFunc {
 			// 7.5 Validate required parameters before permission check
 			// Prevent permission check with empty values that could match wrong records
 			if config.Policy.NamespaceRequired && opReq.Namespace == "" {
-				return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "bad_request", Message: "namespace is required for this operation"},
-				})
+				return m.deny(c, http.StatusBadRequest,
+					model.ErrorDetail{Code: "bad_request", Message: "namespace is required for this operation"},
+					operationDebug(key, config, "namespace"))
 			}
 
 			if config.Policy.ResourceIDRequired && opReq.ResourceID == "" {
-				return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "bad_request", Message: "resource_id is required for this operation"},
-				})
+				return m.deny(c, http.StatusBadRequest,
+					model.ErrorDetail{Code: "bad_request", Message: "resource_id is required for this operation"},
+					operationDebug(key, config, "resource_id"))
 			}
 
 			if config.Policy.ParentResourceRequired && opReq.ParentResourceID == "" {
-				return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "bad_request", Message: "parent_resource_id is required for this operation"},
-				})
+				return m.deny(c, http.StatusBadRequest,
+					model.ErrorDetail{Code: "bad_request", Message: "parent_resource_id is required for this operation"},
+					operationDebug(key, config, "parent_resource_id"))
+			}
+
+			// 7.6 Break-glass: superadmins skip the permission check; the bypass is flagged in history
+			if m.policyEngine.IsSuperadmin(callerID) {
+				log.Printf("WARNING Audit:RBACMiddleware. SUPERADMIN BYPASS caller=%s, entity=%s, operation=%s, namespace=%s, resource=%s:%s",
+					callerID, opReq.Entity, opReq.Operation, opReq.Namespace, opReq.ResourceType, opReq.ResourceID)
+				c.SetRequest(c.Request().WithContext(service.WithSuperadminBypass(c.Request().Context())))
+				return next(c)
 			}
 
 			// 8. Check permission
 			allowed, err := m.policyEngine.CheckOperationPermission(c.Request().Context(), m.repo, &opReq)
 			log.Printf("Audit:RBACMiddleware. allowed=%v, err=%v", allowed, err)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "internal_error", Message: err.Error()},
-				})
+				return m.deny(c, http.StatusInternalServerError,
+					model.ErrorDetail{Code: "internal_error", Message: err.Error()},
+					operationDebug(key, config, ""))
 			}
 
 			if !allowed {
-				return c.JSON(http.StatusForbidden, model.ErrorResponse{
-					Error: model.ErrorDetail{Code: "forbidden", Message: "You do not have permission to perform this action"},
-				})
+				return m.deny(c, http.StatusForbidden,
+					model.ErrorDetail{Code: "forbidden", Message: "You do not have permission to perform this action"},
+					operationDebug(key, config, ""))
 			}
 
 			// 9. Permission granted, continue to handler
@@ -128,6 +140,36 @@ func (m *RBACMiddleware) Middleware() echo.MiddlewareFunc {
 	}
 }
 
+// deny writes a middleware error response, adding debug only when the policy engine has debug denials on
+func (m *RBACMiddleware) deny(c echo.Context, status int, detail model.ErrorDetail, debug *model.RBACDebug) error {
+	if m.policyEngine.DebugDenials() {
+		detail.Debug = debug
+	}
+	return c.JSON(status, model.ErrorResponse{Error: detail})
+}
+
+// operationDebug describes the matched config and the missing param, if any
+func operationDebug(key string, config *policy.APIConfig, missingParam string) *model.RBACDebug {
+	return &model.RBACDebug{
+		Key:          key,
+		Entity:       config.Entity,
+		Operation:    config.Operation,
+		Permission:   config.Policy.Permission,
+		CheckScope:   string(config.Policy.CheckScope),
+		MissingParam: missingParam,
+	}
+}
+
+// candidateOperations lists the configs registered for a key as entity.operation
+func candidateOperations(configs []*policy.APIConfig) []string {
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		names = append(names, config.Entity+"."+config.Operation)
+	}
+	slices.Sort(names)
+	return names
+}
+
 // findMatchingConfig finds the API config that matches the request conditions
 func (m *RBACMiddleware) findMatchingConfig(c echo.Context, configs []*policy.APIConfig, bodyData map[string]interface{}) *policy.APIConfig {
 	for _, config := range configs {
@@ -143,6 +185,10 @@ func (m *RBACMiddleware) findMatchingConfig(c echo.Context, configs []*policy.AP
 			if actualValue == "" {
 				actualValue = m.extractValue(c, "body."+condKey, bodyData)
 			}
+			if condKey == "resource_type" {
+				// Match configs the way the service will see the type (same as model validation)
+				actualValue = normalizeResourceType(actualValue)
+			}
 			if actualValue != condValue {
 				allMatch = false
 				break
@@ -158,6 +204,21 @@ func (m *RBACMiddleware) findMatchingConfig(c echo.Context, configs []*policy.AP
 	return nil
 }
 
+// normalizeResourceType lowercases resource_type, matching model validation, so the middleware
+// and the service agree on the type for any casing the client sends
+func normalizeResourceType(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// normalizeResourceID trims a resource ID and case-folds it when FoldResourceIDCase is on
+func (m *RBACMiddleware) normalizeResourceID(value string) string {
+	value = model.NormalizeResourceID(value)
+	if m.FoldResourceIDCase {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
 // buildOperationRequest builds the OperationRequest from config and request params
 func (m *RBACMiddleware) buildOperationRequest(c echo.Context, config *policy.APIConfig, callerID string, bodyData map[string]interface{}) policy.OperationRequest {
 	opReq := policy.OperationRequest{
@@ -175,11 +236,12 @@ func (m *RBACMiddleware) buildOperationRequest(c echo.Context, config *policy.AP
 				// Normalize namespace to uppercase (same as model validation)
 				opReq.Namespace = strings.ToUpper(strings.TrimSpace(value))
 			case "resource_id":
-				opReq.ResourceID = value
+				// Same normalization as model validation (optional case folding)
+				opReq.ResourceID = m.normalizeResourceID(value)
 			case "resource_type":
-				opReq.ResourceType = value
+				opReq.ResourceType = normalizeResourceType(value)
 			case "parent_resource_id":
-				opReq.ParentResourceID = value
+				opReq.ParentResourceID = m.normalizeResourceID(value)
 			case "role":
 				opReq.Role = value
 			case "scope":
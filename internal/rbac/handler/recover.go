@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RecoverMiddleware turns panics into the standard error envelope.
+// The panic value and stack are logged with the request ID but never returned to the client.
+// Register it outside the RBAC middleware so panics in permission checks are covered too.
+func RecoverMiddleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+				if requestID == "" {
+					requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+				}
+				logger.Error("panic recovered",
+					"request_id", requestID,
+					"method", c.Request().Method,
+					"route", c.Path(),
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+
+				if c.Response().Committed {
+					return
+				}
+				err = c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+					Error: model.ErrorDetail{Code: "internal_error", Message: "Internal Server Error", RequestID: requestID},
+				})
+			}()
+			return next(c)
+		}
+	}
+}
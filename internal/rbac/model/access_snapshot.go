@@ -0,0 +1,44 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// AccessSnapshotReq identifies the user whose access is inspected (path param)
+type AccessSnapshotReq struct {
+	UserID string `param:"id" validate:"required,min=1,max=50"`
+}
+
+func (r *AccessSnapshotReq) Validate() error {
+	r.UserID = strings.TrimSpace(r.UserID)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// AccessGrant is one of the user's active roles with the permissions it confers
+type AccessGrant struct {
+	Role             string     `json:"role"`
+	UserType         string     `json:"user_type"`
+	Scope            string     `json:"scope"`
+	Namespace        string     `json:"namespace,omitempty"`
+	ResourceID       string     `json:"resource_id,omitempty"`
+	ResourceType     string     `json:"resource_type,omitempty"`
+	ParentResourceID string     `json:"parent_resource_id,omitempty"`
+	Permissions      []string   `json:"permissions"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	Expiring         bool       `json:"expiring"` // expires within the snapshot's expiring window
+}
+
+// AccessSnapshotResp is a support view of everything a user can currently do.
+// The policy model has no deny rules, so access is exactly the union of the grants.
+type AccessSnapshotResp struct {
+	UserID        string         `json:"user_id"`
+	Grants        []*AccessGrant `json:"grants"`
+	ExpiringCount int            `json:"expiring_count"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// AccessibleResourceSummaryResp counts the resources the caller holds any role on
+type AccessibleResourceSummaryResp struct {
+	Counts map[string]int64 `json:"counts"` // key: resource_type
+	Total  int64            `json:"total"`
+}
@@ -1,30 +1,74 @@
 package model
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type AssignResourceUserRoleReq struct {
-	UserID           string `json:"user_id" validate:"required,min=1,max=50"`
+	UserID           string `json:"user_id" validate:"omitempty,max=50"`
+	ExternalID       string `json:"external_id" validate:"omitempty,max=100"` // Optional alternative to user_id, resolved by the user resolver
+	Email            string `json:"email" validate:"omitempty,max=254,email"` // Optional alternative to user_id; user_id wins when both are set
 	Role             string `json:"role" validate:"required,min=1,max=50"`
 	ResourceID       string `json:"resource_id" validate:"required,min=1,max=50"`
 	ResourceType     string `json:"resource_type" validate:"required,min=1,max=50"`
 	ParentResourceID string `json:"parent_resource_id" validate:"omitempty,max=50"`
 	UserType         string `json:"user_type" validate:"omitempty,max=50"` // Optional
+	Namespace        string `json:"namespace" validate:"omitempty,max=50"` // Optional, selects the namespace's assignable roles
+	Notify           bool   `json:"notify"`                                // Optional, enqueue a grant notification after success
+	// Optional temporary grant: access ends at ExpiresAt; Reason is kept on the role and in history
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty" validate:"omitempty,min=1,max=31536000"` // Alternative to expires_at, relative to now
+	Reason     string     `json:"reason,omitempty" validate:"omitempty,max=200"`
 }
 
 func (r *AssignResourceUserRoleReq) Validate() error {
 	r.UserID = strings.TrimSpace(r.UserID)
+	r.ExternalID = strings.TrimSpace(r.ExternalID)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
 	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
 	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.Reason = strings.TrimSpace(r.Reason)
 
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
 	}
+	if r.UserID == "" && r.ExternalID == "" && r.Email == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "user_id, external_id or email is required"}
+	}
+
+	if err := resolveExpiry(&r.ExpiresAt, r.TTLSeconds); err != nil {
+		return err
+	}
 
 	if r.ResourceType == ResourceTypeDashboardWidget && r.ParentResourceID == "" {
 		return &ErrorDetail{Code: "bad_request", Message: "parent_resource_id is required for dashboard_widget"}
 	}
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *AssignResourceUserRoleReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
+
+// resolveExpiry turns ttl_seconds into expires_at and rejects temporary grants that would already be expired
+func resolveExpiry(expiresAt **time.Time, ttlSeconds int64) error {
+	now := time.Now()
+	if ttlSeconds > 0 {
+		if *expiresAt != nil {
+			return &ErrorDetail{Code: "bad_request", Message: "expires_at and ttl_seconds cannot be combined"}
+		}
+		at := now.Add(time.Duration(ttlSeconds) * time.Second)
+		*expiresAt = &at
+	}
+	if *expiresAt != nil && !(*expiresAt).After(now) {
+		return &ErrorDetail{Code: "bad_request", Message: "expires_at must be in the future"}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package model
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // AllowedResourceRoles defines which roles can be assigned for resource scope
 var AllowedResourceRoles = map[string]bool{
@@ -9,14 +12,21 @@ var AllowedResourceRoles = map[string]bool{
 	RoleResourceViewer: true,
 }
 
+// AssignResourceUserRolesReq grants Role to every user in UserIDs, or a role per user when Assignments is set
 type AssignResourceUserRolesReq struct {
-	UserIDs          []string `json:"user_ids" validate:"required,min=1,max=50,dive,required"`
-	Role             string   `json:"role" validate:"required,min=1,max=50"`
-	ResourceID       string   `json:"resource_id" validate:"required,min=1,max=50"`
-	ResourceType     string   `json:"resource_type" validate:"required,min=1,max=50"`
-	ParentResourceID string   `json:"parent_resource_id" validate:"omitempty,max=50"`
-	Namespace        string   `json:"namespace" validate:"omitempty,max=50"` // Required for library_widget
-	UserType         string   `json:"user_type" validate:"omitempty,max=50"` // Optional
+	UserIDs          []string         `json:"user_ids" validate:"required_without=Assignments,omitempty,min=1,max=50,dive,required"`
+	Role             string           `json:"role" validate:"required_without=Assignments,omitempty,max=50"`
+	Assignments      []RoleAssignment `json:"assignments,omitempty" validate:"omitempty,max=50,dive"` // Optional, mixed roles; replaces user_ids and role
+	ResourceID       string           `json:"resource_id" validate:"required,min=1,max=50"`
+	ResourceType     string           `json:"resource_type" validate:"required,min=1,max=50"`
+	ParentResourceID string           `json:"parent_resource_id" validate:"omitempty,max=50"`
+	Namespace        string           `json:"namespace" validate:"omitempty,max=50"` // Required for library_widget
+	UserType         string           `json:"user_type" validate:"omitempty,max=50"` // Optional
+	Notify           bool             `json:"notify"`                                // Optional, enqueue a grant notification after success
+	// Optional temporary grant applied to every user: access ends at ExpiresAt; Reason is kept on each role and in history
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty" validate:"omitempty,min=1,max=31536000"` // Alternative to expires_at, relative to now
+	Reason     string     `json:"reason,omitempty" validate:"omitempty,max=200"`
 }
 
 func (r *AssignResourceUserRolesReq) Validate() error {
@@ -24,40 +34,43 @@ func (r *AssignResourceUserRolesReq) Validate() error {
 		r.UserIDs[i] = strings.TrimSpace(id)
 	}
 	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	normalizeAssignments(r.Assignments)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
+	r.Reason = strings.TrimSpace(r.Reason)
 
 	// 1. Basic Struct Validation (required, min/max)
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
 	}
 
-	// 2. Business Logic Validation
-	if len(r.UserIDs) == 0 {
-		return &ErrorDetail{Code: "bad_request", Message: "user_ids cannot be empty"}
-	}
-
-	if r.Role == RoleResourceOwner {
-		return &ErrorDetail{Code: "bad_request", Message: "cannot assign resource owner role via this API"}
+	if err := resolveExpiry(&r.ExpiresAt, r.TTLSeconds); err != nil {
+		return err
 	}
 
-	// Special handling for library_widget
-	if r.ResourceType == ResourceTypeLibraryWidget {
-		if r.Namespace == "" {
-			return &ErrorDetail{Code: "bad_request", Message: "namespace is required for library_widget"}
+	// 2. Business Logic Validation
+	// Roles in a mixed batch are checked per pair by the service so one bad pair does not reject the rest
+	if len(r.Assignments) > 0 {
+		if len(r.UserIDs) > 0 || r.Role != "" {
+			return &ErrorDetail{Code: "bad_request", Message: "assignments cannot be combined with user_ids or role"}
 		}
-		if r.Role != RoleResourceViewer {
-			return &ErrorDetail{Code: "bad_request", Message: "only viewer role is allowed for library_widget"}
+		if err := assignmentUsersError(r.Assignments); err != nil {
+			return err
+		}
+	} else {
+		if len(r.UserIDs) == 0 {
+			return &ErrorDetail{Code: "bad_request", Message: "user_ids cannot be empty"}
+		}
+		if reason := r.RoleError(r.Role); reason != "" {
+			return &ErrorDetail{Code: "bad_request", Message: reason}
 		}
-		return nil
 	}
 
-	// Allowed roles check for other resource types
-	if !AllowedResourceRoles[r.Role] {
-		return &ErrorDetail{Code: "bad_request", Message: "invalid role: must be one of [admin, editor, viewer]"}
+	if r.ResourceType == ResourceTypeLibraryWidget && r.Namespace == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "namespace is required for library_widget"}
 	}
 
 	if r.ResourceType == ResourceTypeDashboardWidget && r.ParentResourceID == "" {
@@ -66,3 +79,33 @@ func (r *AssignResourceUserRolesReq) Validate() error {
 
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *AssignResourceUserRolesReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
+
+// RoleError returns why role cannot be granted by this request, or "" if it can
+func (r *AssignResourceUserRolesReq) RoleError(role string) string {
+	if role == RoleResourceOwner {
+		return "cannot assign resource owner role via this API"
+	}
+	// Special handling for library_widget
+	if r.ResourceType == ResourceTypeLibraryWidget {
+		if role != RoleResourceViewer {
+			return "only viewer role is allowed for library_widget"
+		}
+		return ""
+	}
+	// Allowed roles check for other resource types
+	if !AllowedResourceRoles[role] {
+		return "invalid role: must be one of [admin, editor, viewer]"
+	}
+	return ""
+}
+
+// RoleAssignments returns the requested grants as user/role pairs
+func (r *AssignResourceUserRolesReq) RoleAssignments() []RoleAssignment {
+	return expandAssignments(r.UserIDs, r.Role, r.Assignments)
+}
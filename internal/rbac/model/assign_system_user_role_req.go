@@ -1,23 +1,42 @@
 package model
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type AssignSystemUserRoleReq struct {
-	UserID    string `json:"user_id" validate:"required,min=1,max=50"`
-	Role      string `json:"role" validate:"required,min=1,max=50"`
-	Namespace string `json:"namespace" validate:"required,min=1,max=50"`
-	UserType  string `json:"user_type" validate:"omitempty,max=50"` // Optional, defaults to member
+	UserID     string `json:"user_id" validate:"omitempty,max=50"`
+	ExternalID string `json:"external_id" validate:"omitempty,max=100"` // Optional alternative to user_id, resolved by the user resolver
+	Email      string `json:"email" validate:"omitempty,max=254,email"` // Optional alternative to user_id; user_id wins when both are set
+	Role       string `json:"role" validate:"required,min=1,max=50"`
+	Namespace  string `json:"namespace" validate:"required,min=1,max=50"`
+	UserType   string `json:"user_type" validate:"omitempty,max=50"` // Optional, defaults to member
+	Notify     bool   `json:"notify"`                                // Optional, enqueue a grant notification after success
+	// Optional temporary grant: access ends at ExpiresAt (or TTLSeconds from now); Reason is kept on the role and in history
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty" validate:"omitempty,min=1,max=31536000"`
+	Reason     string     `json:"reason,omitempty" validate:"omitempty,max=200"`
 }
 
 func (r *AssignSystemUserRoleReq) Validate() error {
 	r.UserID = strings.TrimSpace(r.UserID)
+	r.ExternalID = strings.TrimSpace(r.ExternalID)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
 	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
+	r.Reason = strings.TrimSpace(r.Reason)
 
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
 	}
+	if r.UserID == "" && r.ExternalID == "" && r.Email == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "user_id, external_id or email is required"}
+	}
+	if err := resolveExpiry(&r.ExpiresAt, r.TTLSeconds); err != nil {
+		return err
+	}
 
 	// Business Logic Validation
 	if r.Role == RoleSystemOwner {
@@ -26,7 +45,7 @@ func (r *AssignSystemUserRoleReq) Validate() error {
 
 	// Allowed roles check
 	if !AllowedSystemRoles[r.Role] {
-		return &ErrorDetail{Code: "bad_request", Message: "invalid role: must be one of [admin, viewer, dev_user]"}
+		return &ErrorDetail{Code: "bad_request", Message: "invalid role: must be one of [admin, viewer, dev_user, auditor]"}
 	}
 
 	return nil
@@ -2,11 +2,14 @@ package model
 
 import "strings"
 
+// AssignSystemUserRolesReq grants Role to every user in UserIDs, or a role per user when Assignments is set
 type AssignSystemUserRolesReq struct {
-	UserIDs   []string `json:"user_ids" validate:"required,min=1,max=50,dive,required"`
-	Role      string   `json:"role" validate:"required,min=1,max=50"`
-	Namespace string   `json:"namespace" validate:"required,min=1,max=50"`
-	UserType  string   `json:"user_type" validate:"omitempty,max=50"` // Optional, defaults to member
+	UserIDs     []string         `json:"user_ids" validate:"required_without=Assignments,omitempty,min=1,max=50,dive,required"`
+	Role        string           `json:"role" validate:"required_without=Assignments,omitempty,max=50"`
+	Assignments []RoleAssignment `json:"assignments,omitempty" validate:"omitempty,max=50,dive"` // Optional, mixed roles; replaces user_ids and role
+	Namespace   string           `json:"namespace" validate:"required,min=1,max=50"`
+	UserType    string           `json:"user_type" validate:"omitempty,max=50"` // Optional, defaults to member
+	Notify      bool             `json:"notify"`                                // Optional, enqueue a grant notification after success
 }
 
 func (r *AssignSystemUserRolesReq) Validate() error {
@@ -14,6 +17,7 @@ func (r *AssignSystemUserRolesReq) Validate() error {
 		r.UserIDs[i] = strings.TrimSpace(id)
 	}
 	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+	normalizeAssignments(r.Assignments)
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
 
@@ -23,18 +27,37 @@ func (r *AssignSystemUserRolesReq) Validate() error {
 	}
 
 	// 2. Business Logic Validation
+	// Roles in a mixed batch are checked per pair by the service so one bad pair does not reject the rest
+	if len(r.Assignments) > 0 {
+		if len(r.UserIDs) > 0 || r.Role != "" {
+			return &ErrorDetail{Code: "bad_request", Message: "assignments cannot be combined with user_ids or role"}
+		}
+		return assignmentUsersError(r.Assignments)
+	}
+
 	if len(r.UserIDs) == 0 {
 		return &ErrorDetail{Code: "bad_request", Message: "user_ids cannot be empty"}
 	}
 
-	if r.Role == RoleSystemOwner {
-		return &ErrorDetail{Code: "bad_request", Message: "cannot assign system owner role via this API"}
+	if reason := r.RoleError(r.Role); reason != "" {
+		return &ErrorDetail{Code: "bad_request", Message: reason}
 	}
 
-	// Allowed roles check
-	if !AllowedSystemRoles[r.Role] {
-		return &ErrorDetail{Code: "bad_request", Message: "invalid role: must be one of [admin, viewer, dev_user]"}
+	return nil
+}
+
+// RoleError returns why role cannot be granted by this request, or "" if it can
+func (r *AssignSystemUserRolesReq) RoleError(role string) string {
+	if role == RoleSystemOwner {
+		return "cannot assign system owner role via this API"
+	}
+	if !AllowedSystemRoles[role] {
+		return "invalid role: must be one of [admin, viewer, dev_user, auditor]"
 	}
+	return ""
+}
 
-	return nil
+// RoleAssignments returns the requested grants as user/role pairs
+func (r *AssignSystemUserRolesReq) RoleAssignments() []RoleAssignment {
+	return expandAssignments(r.UserIDs, r.Role, r.Assignments)
 }
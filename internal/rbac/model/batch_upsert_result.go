@@ -5,6 +5,7 @@ type BatchUpsertResult struct {
 	SuccessCount int              `json:"success_count"`
 	FailedCount  int              `json:"failed_count"`
 	FailedUsers  []FailedUserInfo `json:"failed_users,omitempty"`
+	Assigned     []RoleAssignment `json:"assigned,omitempty"` // Granted pairs, reported for assignments batches
 }
 
 // FailedUserInfo contains information about a failed user operation
@@ -0,0 +1,48 @@
+package model
+
+import "strings"
+
+// GetCapableUsersReq asks which members can perform permission on a resource (query params)
+type GetCapableUsersReq struct {
+	ResourceID       string `query:"resource_id" validate:"required,min=1,max=50"`
+	ResourceType     string `query:"resource_type" validate:"required,oneof=dashboard dashboard_widget library_widget"`
+	ParentResourceID string `query:"parent_resource_id" validate:"omitempty,max=50"`
+	Namespace        string `query:"namespace" validate:"omitempty,max=50"`
+	Permission       string `query:"permission" validate:"required,min=1,max=100"`
+}
+
+func (r *GetCapableUsersReq) Validate() error {
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.Permission = strings.TrimSpace(r.Permission)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetCapableUsersReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
+
+// CapableUser is a member holding a role that grants the requested permission
+type CapableUser struct {
+	UserID   string `json:"user_id"`
+	UserType string `json:"user_type"`
+	Role     string `json:"role"`
+}
+
+// GetCapableUsersResp lists members with a direct role on the resource that grants the permission.
+// Roles lists the granting roles; both lists are empty when no role grants the permission.
+// Truncated is set when Users was cut to the service's unpaginated role cap.
+type GetCapableUsersResp struct {
+	Permission string         `json:"permission"`
+	Roles      []string       `json:"roles"`
+	Users      []*CapableUser `json:"users"`
+	Truncated  bool           `json:"truncated"`
+}
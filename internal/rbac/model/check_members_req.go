@@ -0,0 +1,68 @@
+package model
+
+import "strings"
+
+// CheckMembersReq asks which of user_ids are active members of a namespace or resource
+type CheckMembersReq struct {
+	Scope            string   `json:"scope" validate:"required,oneof=system resource"`
+	Namespace        string   `json:"namespace" validate:"omitempty,max=50"`
+	ResourceID       string   `json:"resource_id" validate:"omitempty,max=50"`
+	ResourceType     string   `json:"resource_type" validate:"omitempty,max=50"`
+	ParentResourceID string   `json:"parent_resource_id" validate:"omitempty,max=50"`
+	UserIDs          []string `json:"user_ids" validate:"required,min=1,max=100,dive,max=50"`
+}
+
+func (r *CheckMembersReq) Validate() error {
+	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
+
+	// Normalize and remove duplicates from UserIDs
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(r.UserIDs))
+	for _, id := range r.UserIDs {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	r.UserIDs = unique
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+
+	if r.Scope == ScopeSystem {
+		if r.Namespace == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "namespace required for system scope"}
+		}
+		if r.ResourceID != "" || r.ResourceType != "" {
+			return &ErrorDetail{Code: "bad_request", Message: "invalid parameters for system scope"}
+		}
+	} else {
+		if r.ResourceID == "" || r.ResourceType == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "resource_id and resource_type required for resource scope"}
+		}
+		if r.ResourceType == ResourceTypeLibraryWidget && r.Namespace == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "namespace required for resource library_widget"}
+		}
+		if r.ResourceType == ResourceTypeDashboardWidget && r.ParentResourceID == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "parent_resource_id required for resource dashboard_widget"}
+		}
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *CheckMembersReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
+
+// CheckMembersResp lists the requested user IDs that are active members, in request order
+type CheckMembersResp struct {
+	Members []string `json:"members"`
+}
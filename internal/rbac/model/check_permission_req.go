@@ -15,9 +15,9 @@ func (r *CheckPermissionReq) Validate() error {
 	r.Permission = strings.TrimSpace(r.Permission)
 	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
 
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
@@ -33,3 +33,9 @@ func (r *CheckPermissionReq) Validate() error {
 	}
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *CheckPermissionReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxPermissionChecks caps the checks of one batch, matching the default child_resource_ids cap so a
+// dashboard's widgets fit in one request
+const MaxPermissionChecks = 500
+
+// PermissionCheck is one check of a batch; the caller-supplied ID keys its result
+type PermissionCheck struct {
+	ID string `json:"id"`
+	CheckPermissionReq
+}
+
+// CheckPermissionsBatchReq checks several permissions of the caller in one request
+type CheckPermissionsBatchReq struct {
+	Checks []*PermissionCheck `json:"checks" validate:"required,min=1"`
+}
+
+func (r *CheckPermissionsBatchReq) Validate() error {
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	if len(r.Checks) > MaxPermissionChecks {
+		return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("checks exceeds the maximum of %d", MaxPermissionChecks)}
+	}
+
+	seen := make(map[string]bool, len(r.Checks))
+	for i, check := range r.Checks {
+		if check == nil {
+			return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("checks[%d] is empty", i)}
+		}
+		check.ID = strings.TrimSpace(check.ID)
+		if check.ID == "" || len(check.ID) > 100 {
+			return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("checks[%d]: id must be 1 to 100 characters", i)}
+		}
+		if err := check.CheckPermissionReq.Validate(); err != nil {
+			return checkError(i, err)
+		}
+		if seen[check.ID] {
+			return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("checks[%d]: duplicate id %q", i, check.ID)}
+		}
+		seen[check.ID] = true
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *CheckPermissionsBatchReq) FoldResourceIDCase() {
+	for _, check := range r.Checks {
+		if check != nil {
+			check.CheckPermissionReq.FoldResourceIDCase()
+		}
+	}
+}
+
+// checkError prefixes a check's validation error with its position
+func checkError(i int, err error) error {
+	var detail *ErrorDetail
+	if errors.As(err, &detail) {
+		return &ErrorDetail{Code: detail.Code, Message: fmt.Sprintf("checks[%d]: %s", i, detail.Message)}
+	}
+	return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("checks[%d]: %v", i, err)}
+}
+
+// CheckPermissionsBatchResp maps each check ID to whether the caller has the permission
+type CheckPermissionsBatchResp struct {
+	Results map[string]bool `json:"results"`
+}
@@ -7,6 +7,7 @@ const (
 	RoleSystemAdmin     = "admin"
 	RoleSystemDev       = "dev_user"
 	RoleSystemViewer    = "viewer"
+	RoleSystemAuditor   = "auditor"
 
 	RoleResourceOwner  = "owner"
 	RoleResourceAdmin  = "admin"
@@ -16,9 +17,10 @@ const (
 
 // AllowedSystemRoles defines which roles can be assigned for system scope
 var AllowedSystemRoles = map[string]bool{
-	RoleSystemAdmin:  true,
-	RoleSystemViewer: true,
-	RoleSystemDev:    true,
+	RoleSystemAdmin:   true,
+	RoleSystemViewer:  true,
+	RoleSystemDev:     true,
+	RoleSystemAuditor: true,
 }
 
 // Scopes
@@ -37,6 +39,16 @@ const (
 	PermPlatformSystemRemoveMember  = "platform.system.remove_member"
 	PermPlatformSystemGetMember     = "platform.system.get_member" // Used for GetUserRoles (List)
 	PermPlatformSystemTransferOwner = "platform.system.transfer_owner"
+	PermPlatformSystemReadAudit     = "platform.system.read_audit"    // Used for GetUserRoleHistory (Logs)
+	PermPlatformUserErase           = "platform.user.erase"           // Used for EraseUser (GDPR), moderator only
+	PermPlatformUserPurge           = "platform.user.purge"           // Used for PurgeUser, moderator only
+	PermPlatformUserReassignOwner   = "platform.user.reassign_owner"  // Used for ReassignOwner (offboarding), moderator only
+	PermPlatformUserReadAccess      = "platform.user.read_access"     // Used for GetAccessSnapshot (support), moderator only
+	PermPlatformNamespaceRename     = "platform.namespace.rename"     // Used for RenameNamespace, moderator only
+	PermPlatformNamespaceSnapshot   = "platform.namespace.snapshot"   // Used for SnapshotNamespace, moderator only
+	PermPlatformNamespaceRestore    = "platform.namespace.restore"    // Used for RestoreNamespace, moderator only
+	PermPlatformResourceReadOwners  = "platform.resource.read_owners" // Used for GetResourceOwners (billing), moderator only
+	PermPlatformPolicyReload        = "platform.policy.reload"        // Used for ReloadPolicy, moderator only
 	PermSystemResourceCreate        = "system.resource.create"
 	PermSystemResourceRead          = "system.resource.read"
 	PermSystemResourceDelete        = "system.resource.delete"
@@ -68,6 +80,8 @@ const (
 const (
 	UserTypeMember = "member"
 	UserTypeOrg    = "org"
+	// UserTypePending marks an owner created by a transfer before the user first signed in
+	UserTypePending = "pending"
 )
 
 // Resource Types
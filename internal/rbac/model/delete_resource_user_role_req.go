@@ -13,9 +13,9 @@ type DeleteResourceUserRoleReq struct {
 
 func (r *DeleteResourceUserRoleReq) Validate() error {
 	r.UserID = strings.TrimSpace(r.UserID)
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
 
@@ -32,3 +32,9 @@ func (r *DeleteResourceUserRoleReq) Validate() error {
 	}
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *DeleteResourceUserRoleReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// EraseUserReq identifies the user whose data is erased (path param)
+type EraseUserReq struct {
+	UserID string `param:"id" validate:"required,min=1,max=50"`
+}
+
+func (r *EraseUserReq) Validate() error {
+	r.UserID = strings.TrimSpace(r.UserID)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// EraseUserResult counts the documents touched by an erasure
+type EraseUserResult struct {
+	Tombstone         string `json:"tombstone"`
+	RolesDeleted      int64  `json:"roles_deleted"`      // the user's own role documents (hard delete)
+	RolesAnonymized   int64  `json:"roles_anonymized"`   // other roles the user created/updated/deleted
+	HistoryAnonymized int64  `json:"history_anonymized"` // history entries naming the user
+}
+
+// UserErasure is the append-only record of an erasure. It never stores the erased user_id.
+type UserErasure struct {
+	ID                string    `bson:"_id,omitempty" json:"id"`
+	Tombstone         string    `bson:"tombstone" json:"tombstone"`
+	CallerID          string    `bson:"caller_id" json:"caller_id"`
+	RolesDeleted      int64     `bson:"roles_deleted" json:"roles_deleted"`
+	RolesAnonymized   int64     `bson:"roles_anonymized" json:"roles_anonymized"`
+	HistoryAnonymized int64     `bson:"history_anonymized" json:"history_anonymized"`
+	CreatedAt         time.Time `bson:"created_at" json:"created_at"`
+}
@@ -7,19 +7,21 @@ type GetDashboardResourceReq struct {
 	ResourceID       string   `json:"resource_id" validate:"required,min=1,max=50"`
 	ResourceType     string   `json:"resource_type" validate:"required,min=1,max=50"`
 	ChildResourceIDs []string `json:"child_resource_ids"`
+	Namespace        string   `json:"namespace" validate:"omitempty,max=50"` // Scopes the widget checks when resources are namespaced
 }
 
 // Validate normalizes and validates the request
 func (r *GetDashboardResourceReq) Validate() error {
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.TrimSpace(r.ResourceType)
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 
 	// TrimSpace and remove duplicates from ChildResourceIDs
 	if len(r.ChildResourceIDs) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]string, 0, len(r.ChildResourceIDs))
 		for _, id := range r.ChildResourceIDs {
-			trimmed := strings.TrimSpace(id)
+			trimmed := NormalizeResourceID(id)
 			if trimmed != "" && !seen[trimmed] {
 				seen[trimmed] = true
 				unique = append(unique, trimmed)
@@ -35,6 +37,12 @@ func (r *GetDashboardResourceReq) Validate() error {
 	return nil
 }
 
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetDashboardResourceReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	foldIDs(r.ChildResourceIDs)
+}
+
 // GetDashboardResourceResp represents the response for get dashboard resource
 type GetDashboardResourceResp struct {
 	UserRoles           []*UserRoleDTO `json:"user_roles"`
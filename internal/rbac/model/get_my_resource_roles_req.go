@@ -0,0 +1,42 @@
+package model
+
+import "strings"
+
+// GetMyResourceRolesReq asks for the caller's role on each of many resources of one type
+type GetMyResourceRolesReq struct {
+	ResourceType string   `json:"resource_type" validate:"required,min=1,max=50"`
+	ResourceIDs  []string `json:"resource_ids" validate:"required,min=1,max=100,dive,max=50"`
+	Namespace    string   `json:"namespace" validate:"omitempty,max=50"` // Scopes the resources when resources are namespaced
+}
+
+func (r *GetMyResourceRolesReq) Validate() error {
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+
+	// Normalize and remove duplicates from ResourceIDs
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(r.ResourceIDs))
+	for _, id := range r.ResourceIDs {
+		id = NormalizeResourceID(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	r.ResourceIDs = unique
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetMyResourceRolesReq) FoldResourceIDCase() {
+	foldIDs(r.ResourceIDs)
+}
+
+// GetMyResourceRolesResp maps every requested resource_id to the caller's role, or null when the caller has none
+type GetMyResourceRolesResp struct {
+	Roles map[string]*string `json:"roles"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "strings"
+
+// GetResourceOwnerReq asks for the current owner of one resource (query params). Only dashboards have owners.
+type GetResourceOwnerReq struct {
+	ResourceID   string `query:"resource_id" validate:"required,min=1,max=50"`
+	ResourceType string `query:"resource_type" validate:"required,oneof=dashboard"`
+	Namespace    string `query:"namespace" validate:"omitempty,max=50"` // Scopes the resource when resources are namespaced
+}
+
+func (r *GetResourceOwnerReq) Validate() error {
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetResourceOwnerReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+}
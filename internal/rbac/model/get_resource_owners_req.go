@@ -0,0 +1,42 @@
+package model
+
+import "strings"
+
+// GetResourceOwnersReq asks for the owner of each of many resources of one type (billing attribution)
+type GetResourceOwnersReq struct {
+	ResourceType string   `json:"resource_type" validate:"required,min=1,max=50"`
+	ResourceIDs  []string `json:"resource_ids" validate:"required,min=1,max=1000,dive,max=50"`
+	Namespace    string   `json:"namespace" validate:"omitempty,max=50"` // Scopes the resources when resources are namespaced
+}
+
+func (r *GetResourceOwnersReq) Validate() error {
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+
+	// Normalize and remove duplicates from ResourceIDs
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(r.ResourceIDs))
+	for _, id := range r.ResourceIDs {
+		id = NormalizeResourceID(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	r.ResourceIDs = unique
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetResourceOwnersReq) FoldResourceIDCase() {
+	foldIDs(r.ResourceIDs)
+}
+
+// GetResourceOwnersResp maps every requested resource_id to its owner's user_id, or null when it has none
+type GetResourceOwnersResp struct {
+	Owners map[string]*string `json:"owners"`
+}
@@ -18,31 +18,39 @@ type GetUserRoleHistoryReq struct {
 	ResourceType     string `query:"resource_type" validate:"omitempty,max=50"`      // dashboard, dashboard_widget, library_widget
 	ParentResourceID string `query:"parent_resource_id" validate:"omitempty,max=50"` // Required for dashboard_widget
 
+	// Target User Filter (affected user, matches user_id / user_ids / new_owner_id)
+	TargetUserID string `query:"target_user_id" validate:"omitempty,max=50"`
+
 	// Time Filter
 	StartTime *time.Time `query:"start_time"`
 	EndTime   *time.Time `query:"end_time"`
 
-	// Pagination
-	Page int `query:"page" validate:"omitempty,min=1"`
-	Size int `query:"size" validate:"omitempty,min=1,max=1000"`
+	// Output format: json (default, native records) or ecs (Elastic Common Schema events)
+	Format string `query:"format" validate:"omitempty,oneof=json ecs"`
+
+	// Pagination (parsed by handler.ParsePagination, not bound)
+	Page int `validate:"omitempty,min=1"`
+	Size int `validate:"omitempty,min=1,max=1000"`
 }
 
 func (r *GetUserRoleHistoryReq) Validate() error {
 	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
+	r.TargetUserID = strings.TrimSpace(r.TargetUserID)
+	r.Format = strings.ToLower(strings.TrimSpace(r.Format))
 
 	// Set default pagination
 	if r.Page <= 0 {
 		r.Page = 1
 	}
 	if r.Size <= 0 {
-		r.Size = 100 // Default size
+		r.Size = DefaultPageSize
 	}
-	if r.Size > 1000 {
-		r.Size = 1000 // Max size
+	if r.Size > MaxPageSize {
+		r.Size = MaxPageSize
 	}
 
 	if err := GetValidator().Struct(r); err != nil {
@@ -70,10 +78,14 @@ func (r *GetUserRoleHistoryReq) Validate() error {
 	return nil
 }
 
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetUserRoleHistoryReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
+
 // GetUserRoleHistoryResp 分頁回應
 type GetUserRoleHistoryResp struct {
-	Data       []*UserRoleHistory `json:"data"`
-	Page       int                `json:"page"`
-	Size       int                `json:"size"`
-	TotalCount int64              `json:"total_count"`
+	Data []*UserRoleHistory `json:"data"`
+	Pagination
 }
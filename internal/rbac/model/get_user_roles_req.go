@@ -10,16 +10,30 @@ type GetUserRolesReq struct {
 	ResourceID       string `query:"resource_id" validate:"omitempty,max=50"`
 	ResourceType     string `query:"resource_type" validate:"omitempty,max=50"`
 	ParentResourceID string `query:"parent_resource_id" validate:"omitempty,max=50"`
+	// Expand=permissions adds the permissions each member's role grants
+	Expand string `query:"expand" validate:"omitempty,oneof=permissions"`
+	// IncludeAudit adds created_at/updated_at and created_by/updated_by to each member
+	IncludeAudit bool `query:"include_audit"`
+	// IncludeInherited adds the namespace's system owners and admins to a resource member list
+	IncludeInherited bool `query:"include_inherited"`
 }
 
+// InheritedSystemRoles are the system roles listed as inherited resource members: their
+// system.resource permissions give them implicit access to every resource of the namespace
+var InheritedSystemRoles = []string{RoleSystemOwner, RoleSystemAdmin}
+
+// ExpandPermissions is the GetUserRolesReq.Expand value that adds each role's permissions
+const ExpandPermissions = "permissions"
+
 func (r *GetUserRolesReq) Validate() error {
 	r.UserID = strings.TrimSpace(r.UserID)
 	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
 	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
+	r.Expand = strings.ToLower(strings.TrimSpace(r.Expand))
 
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
@@ -43,5 +57,14 @@ func (r *GetUserRolesReq) Validate() error {
 			return &ErrorDetail{Code: "bad_request", Message: "parent_resource_id required for resource dashboard_widget"}
 		}
 	}
+	if r.IncludeInherited && (r.Scope != ScopeResource || r.Namespace == "") {
+		return &ErrorDetail{Code: "bad_request", Message: "include_inherited requires resource scope and a namespace"}
+	}
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *GetUserRolesReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// GrantEventRoleGranted is the event type emitted after a successful role assignment
+const GrantEventRoleGranted = "role_granted"
+
+// GrantEvent notifies a grantee of a new or changed role (sent when the request sets notify=true)
+type GrantEvent struct {
+	Type             string    `json:"type"`
+	UserID           string    `json:"user_id"`
+	UserType         string    `json:"user_type"`
+	Role             string    `json:"role"`
+	Scope            string    `json:"scope"`
+	Namespace        string    `json:"namespace,omitempty"`
+	ResourceID       string    `json:"resource_id,omitempty"`
+	ResourceType     string    `json:"resource_type,omitempty"`
+	ParentResourceID string    `json:"parent_resource_id,omitempty"`
+	GrantedBy        string    `json:"granted_by"`
+	GrantedAt        time.Time `json:"granted_at"`
+}
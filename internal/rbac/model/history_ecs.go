@@ -0,0 +1,138 @@
+package model
+
+import "time"
+
+// HistoryFormatECS is the GetUserRoleHistoryReq.Format that returns Elastic Common Schema events
+const HistoryFormatECS = "ecs"
+
+// ECSVersion is the Elastic Common Schema version the exported events follow
+const ECSVersion = "8.11.0"
+
+// ECSEvent is a history record in Elastic Common Schema for SIEM ingestion. The caller is the
+// actor (user), the affected user the target (user.target); RBAC details without an ECS field sit
+// under the custom rbac namespace.
+type ECSEvent struct {
+	Timestamp time.Time     `json:"@timestamp"`
+	ECS       ECSMeta       `json:"ecs"`
+	Event     ECSEventMeta  `json:"event"`
+	User      ECSUser       `json:"user"`
+	Related   ECSRelated    `json:"related"`
+	RBAC      ECSRBACFields `json:"rbac"`
+}
+
+type ECSMeta struct {
+	Version string `json:"version"`
+}
+
+type ECSEventMeta struct {
+	ID       string   `json:"id,omitempty"`
+	Kind     string   `json:"kind"`
+	Category []string `json:"category"`
+	Type     []string `json:"type"`
+	Action   string   `json:"action"`
+	Outcome  string   `json:"outcome"`
+	Provider string   `json:"provider"`
+	Dataset  string   `json:"dataset"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+type ECSUser struct {
+	ID     string         `json:"id"`
+	Target *ECSTargetUser `json:"target,omitempty"`
+}
+
+type ECSTargetUser struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type ECSRelated struct {
+	User []string `json:"user"`
+}
+
+// ECSRBACFields carries the scope of the change and the fields ECS has no place for
+type ECSRBACFields struct {
+	Scope             string     `json:"scope"`
+	Namespace         string     `json:"namespace,omitempty"`
+	PreviousNamespace string     `json:"previous_namespace,omitempty"`
+	ResourceID        string     `json:"resource_id,omitempty"`
+	ResourceType      string     `json:"resource_type,omitempty"`
+	ParentResourceID  string     `json:"parent_resource_id,omitempty"`
+	ChildResourceIDs  []string   `json:"child_resource_ids,omitempty"`
+	TargetUserIDs     []string   `json:"target_user_ids,omitempty"`
+	UserType          string     `json:"user_type,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	SuperadminBypass  bool       `json:"superadmin_bypass,omitempty"`
+}
+
+// historyDeletions are the operations exported with event.type deletion; every other one is a change
+var historyDeletions = map[string]bool{
+	"delete_user_role": true,
+	"delete_resource":  true,
+}
+
+// NewECSEvent maps a history record to an ECS event. History only records committed writes, so
+// the outcome is always success.
+func NewECSEvent(h *UserRoleHistory) *ECSEvent {
+	eventType := "change"
+	if historyDeletions[h.Operation] {
+		eventType = "deletion"
+	}
+
+	event := &ECSEvent{
+		Timestamp: h.CreatedAt,
+		ECS:       ECSMeta{Version: ECSVersion},
+		Event: ECSEventMeta{
+			ID:       h.ID,
+			Kind:     "event",
+			Category: []string{"iam"},
+			Type:     []string{eventType},
+			Action:   h.Operation,
+			Outcome:  "success",
+			Provider: "rbac",
+			Dataset:  "rbac.user_role_history",
+			Reason:   h.Reason,
+		},
+		User: ECSUser{ID: h.CallerID},
+		RBAC: ECSRBACFields{
+			Scope:             h.Scope,
+			Namespace:         h.Namespace,
+			PreviousNamespace: h.PreviousNamespace,
+			ResourceID:        h.ResourceID,
+			ResourceType:      h.ResourceType,
+			ParentResourceID:  h.ParentResourceID,
+			ChildResourceIDs:  h.ChildResourceIDs,
+			TargetUserIDs:     h.UserIDs,
+			UserType:          h.UserType,
+			ExpiresAt:         h.ExpiresAt,
+			SuperadminBypass:  h.SuperadminBypass,
+		},
+	}
+
+	// A transfer targets the new owner; other single-user operations the user they changed
+	target := h.UserID
+	if h.NewOwnerID != "" {
+		target = h.NewOwnerID
+	}
+	if target != "" {
+		event.User.Target = &ECSTargetUser{ID: target}
+		if h.Role != "" {
+			event.User.Target.Roles = []string{h.Role}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range append([]string{h.CallerID, h.UserID, h.NewOwnerID}, h.UserIDs...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			event.Related.User = append(event.Related.User, id)
+		}
+	}
+	return event
+}
+
+// GetUserRoleHistoryECSResp is a history page with each record as an ECS event
+type GetUserRoleHistoryECSResp struct {
+	Data []*ECSEvent `json:"data"`
+	Pagination
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// NamespaceResourceRolesReq identifies the namespace whose resource role override is read or removed (path param)
+type NamespaceResourceRolesReq struct {
+	Namespace string `param:"namespace" validate:"required,min=1,max=50"`
+}
+
+func (r *NamespaceResourceRolesReq) Validate() error {
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// PutNamespaceResourceRolesReq replaces the assignable resource roles of a namespace
+type PutNamespaceResourceRolesReq struct {
+	Namespace string   `param:"namespace" validate:"required,min=1,max=50"`
+	Roles     []string `json:"roles" validate:"required,min=1,max=10,dive,required,max=50"`
+}
+
+func (r *PutNamespaceResourceRolesReq) Validate() error {
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	seen := make(map[string]bool, len(r.Roles))
+	roles := make([]string, 0, len(r.Roles))
+	for _, role := range r.Roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	r.Roles = roles
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+
+	// An override can only narrow the global default
+	for _, role := range r.Roles {
+		if !AllowedResourceRoles[role] {
+			return &ErrorDetail{Code: "bad_request", Message: "invalid role: must be one of [admin, editor, viewer]"}
+		}
+	}
+	return nil
+}
+
+// NamespaceResourceRoles is the stored override of assignable resource roles for a namespace
+type NamespaceResourceRoles struct {
+	Namespace string    `bson:"_id" json:"namespace"`
+	Roles     []string  `bson:"roles" json:"roles"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	UpdatedBy string    `bson:"updated_by" json:"updated_by"`
+}
+
+// NamespaceResourceRolesResp returns the effective assignable resource roles of a namespace
+type NamespaceResourceRolesResp struct {
+	Namespace string   `json:"namespace"`
+	Roles     []string `json:"roles"`
+	// Overridden is false when the namespace uses the global default
+	Overridden bool `json:"overridden"`
+}
+
+// DefaultResourceRoles returns the globally assignable resource roles, sorted
+func DefaultResourceRoles() []string {
+	roles := make([]string, 0, len(AllowedResourceRoles))
+	for role := range AllowedResourceRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
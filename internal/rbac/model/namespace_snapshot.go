@@ -0,0 +1,122 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NamespaceSnapshotVersion is the format written by GET /namespaces/:namespace/snapshot; restores
+// reject any other version
+const NamespaceSnapshotVersion = 1
+
+// Restore modes: merge writes the snapshot over the namespace's roles, replace removes them first
+const (
+	RestoreModeMerge   = "merge"
+	RestoreModeReplace = "replace"
+)
+
+// NamespaceSnapshotReq selects the namespace to snapshot (path param)
+type NamespaceSnapshotReq struct {
+	Namespace      string `param:"namespace" validate:"required,min=1,max=50"`
+	IncludeHistory bool   `query:"include_history"`
+}
+
+func (r *NamespaceSnapshotReq) Validate() error {
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// NamespaceSnapshot is a portable copy of a namespace's active system and resource roles
+type NamespaceSnapshot struct {
+	Version   int         `json:"version"`
+	Namespace string      `json:"namespace"`
+	CreatedAt time.Time   `json:"created_at"`
+	Roles     []*UserRole `json:"roles"`
+	// ResourceRoles is the namespace's assignable resource roles override (omitted: global default)
+	ResourceRoles []string `json:"resource_roles,omitempty"`
+	// History holds the namespace's system scope history, newest first, with include_history=true.
+	// It is informational: restores do not replay it.
+	History []*UserRoleHistory `json:"history,omitempty"`
+}
+
+// RestoreNamespaceReq applies Snapshot to Namespace, which may differ from the snapshot's own
+type RestoreNamespaceReq struct {
+	Namespace string            `param:"namespace" validate:"required,min=1,max=50"`
+	Mode      string            `json:"mode" validate:"omitempty,oneof=merge replace"`
+	Snapshot  NamespaceSnapshot `json:"snapshot"`
+}
+
+func (r *RestoreNamespaceReq) Validate() error {
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.Mode = strings.ToLower(strings.TrimSpace(r.Mode))
+	if r.Mode == "" {
+		r.Mode = RestoreModeMerge
+	}
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	if r.Snapshot.Version != NamespaceSnapshotVersion {
+		return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("unsupported snapshot version %d", r.Snapshot.Version)}
+	}
+
+	for i, role := range r.Snapshot.Roles {
+		if role == nil {
+			return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("roles[%d] is empty", i)}
+		}
+		role.UserID = strings.TrimSpace(role.UserID)
+		role.Role = strings.ToLower(strings.TrimSpace(role.Role))
+		role.Scope = strings.ToLower(strings.TrimSpace(role.Scope))
+		role.ResourceType = strings.ToLower(strings.TrimSpace(role.ResourceType))
+		if role.UserType == "" {
+			role.UserType = UserTypeMember
+		}
+		if reason := snapshotRoleError(role); reason != "" {
+			return &ErrorDetail{Code: "bad_request", Message: fmt.Sprintf("roles[%d]: %s", i, reason)}
+		}
+	}
+	for _, role := range r.Snapshot.ResourceRoles {
+		if !AllowedResourceRoles[role] {
+			return &ErrorDetail{Code: "bad_request", Message: "invalid resource_roles: must be one of [admin, editor, viewer]"}
+		}
+	}
+	return nil
+}
+
+// snapshotRoleError returns why role cannot be restored, or "" if it can
+func snapshotRoleError(role *UserRole) string {
+	if role.UserID == "" || len(role.UserID) > 50 {
+		return "user_id is required (max 50)"
+	}
+	switch role.Scope {
+	case ScopeSystem:
+		if role.Role != RoleSystemOwner && !AllowedSystemRoles[role.Role] {
+			return "invalid system role " + role.Role
+		}
+	case ScopeResource:
+		if role.ResourceID == "" || role.ResourceType == "" {
+			return "resource_id and resource_type required for resource scope"
+		}
+		if role.Role != RoleResourceOwner && !AllowedResourceRoles[role.Role] {
+			return "invalid resource role " + role.Role
+		}
+	default:
+		return "scope must be system or resource"
+	}
+	return ""
+}
+
+// RestoreNamespaceResult counts the documents written by a restore
+type RestoreNamespaceResult struct {
+	Namespace     string `json:"namespace"`
+	Mode          string `json:"mode"`
+	RolesRestored int64  `json:"roles_restored"` // snapshot roles written (created, updated or unchanged)
+	RolesRemoved  int64  `json:"roles_removed"`  // active roles soft deleted first in replace mode
+	// ResourceRolesRestored reports whether the snapshot's resource roles override was applied
+	ResourceRolesRestored bool `json:"resource_roles_restored"`
+}
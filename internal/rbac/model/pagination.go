@@ -0,0 +1,29 @@
+package model
+
+// Page sizes of paginated endpoints: DefaultPageSize applies when size is omitted, MaxPageSize is
+// the hard ceiling (MAX_PAGE_SIZE may lower it)
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Pagination is the page metadata of a paginated response, embedded so its fields sit next to data
+type Pagination struct {
+	Page       int   `json:"page"`
+	Size       int   `json:"size"`
+	TotalCount int64 `json:"total_count"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
+}
+
+// NewPagination derives the navigation fields from a 1-based page, page size and total count
+func NewPagination(page, size int, total int64) Pagination {
+	p := Pagination{Page: page, Size: size, TotalCount: total}
+	if size > 0 {
+		p.TotalPages = int((total + int64(size) - 1) / int64(size))
+	}
+	p.HasNext = page < p.TotalPages
+	p.HasPrev = page > 1
+	return p
+}
@@ -0,0 +1,31 @@
+package model
+
+import "strings"
+
+// GetPermissionRolesReq asks which roles grant a permission (path param), optionally in one scope
+type GetPermissionRolesReq struct {
+	Permission string `param:"permission" validate:"required,min=1,max=100"`
+	Scope      string `query:"scope" validate:"omitempty,oneof=system resource"`
+}
+
+func (r *GetPermissionRolesReq) Validate() error {
+	r.Permission = strings.TrimSpace(r.Permission)
+	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// PermissionRole is a role that grants the requested permission in a scope
+type PermissionRole struct {
+	Role  string `json:"role"`
+	Scope string `json:"scope"`
+}
+
+// GetPermissionRolesResp lists the roles granting a permission (empty when no role grants it)
+type GetPermissionRolesResp struct {
+	Permission string            `json:"permission"`
+	Roles      []*PermissionRole `json:"roles"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// PolicySummary counts the policies the engine has loaded (POST /policy/reload response)
+type PolicySummary struct {
+	Entities      int       `json:"entities"`
+	Operations    int       `json:"operations"`
+	SystemRoles   int       `json:"system_roles"`
+	ResourceRoles int       `json:"resource_roles"`
+	LoadedAt      time.Time `json:"loaded_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "strings"
+
+// PurgeUserReq identifies the user whose role documents are hard deleted (path param)
+type PurgeUserReq struct {
+	UserID string `param:"user_id" validate:"required,min=1,max=50"`
+}
+
+func (r *PurgeUserReq) Validate() error {
+	r.UserID = strings.TrimSpace(r.UserID)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// PurgeUserResult counts the role documents removed by a purge
+type PurgeUserResult struct {
+	RolesDeleted int64 `json:"roles_deleted"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "strings"
+
+// ReassignOwnerReq hands every resource old_owner_id owns to new_owner_id (offboarding)
+type ReassignOwnerReq struct {
+	OldOwnerID string `json:"old_owner_id" validate:"required,min=1,max=50"`
+	NewOwnerID string `json:"new_owner_id" validate:"required,min=1,max=50"`
+}
+
+func (r *ReassignOwnerReq) Validate() error {
+	r.OldOwnerID = strings.TrimSpace(r.OldOwnerID)
+	r.NewOwnerID = strings.TrimSpace(r.NewOwnerID)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	return nil
+}
+
+// ReassignOwnerResult counts the resources whose ownership moved to the new owner
+type ReassignOwnerResult struct {
+	Transferred int64 `json:"transferred"`
+}
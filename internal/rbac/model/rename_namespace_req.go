@@ -0,0 +1,32 @@
+package model
+
+import "strings"
+
+// RenameNamespaceReq moves every role and history entry of namespace From to namespace To
+type RenameNamespaceReq struct {
+	From string `json:"from" validate:"required,min=1,max=50"`
+	To   string `json:"to" validate:"required,min=1,max=50"`
+}
+
+func (r *RenameNamespaceReq) Validate() error {
+	r.From = strings.ToUpper(strings.TrimSpace(r.From))
+	r.To = strings.ToUpper(strings.TrimSpace(r.To))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+	if r.From == r.To {
+		return &ErrorDetail{Code: "bad_request", Message: "to must differ from from"}
+	}
+	return nil
+}
+
+// RenameNamespaceResult counts the documents moved by a namespace rename
+type RenameNamespaceResult struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	RolesMigrated   int64  `json:"roles_migrated"`   // system and resource role documents, including soft-deleted ones
+	HistoryMigrated int64  `json:"history_migrated"` // history entries of the old namespace
+	// ResourceRolesMigrated reports whether the namespace's assignable resource roles override moved too
+	ResourceRolesMigrated bool `json:"resource_roles_migrated"`
+}
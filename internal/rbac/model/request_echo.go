@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// RequestEcho is the role an assign/transfer request stored, as returned with echo=true
+type RequestEcho struct {
+	Scope            string     `json:"scope"`
+	Namespace        string     `json:"namespace,omitempty"`
+	UserID           string     `json:"user_id,omitempty"`
+	UserType         string     `json:"user_type,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	ResourceID       string     `json:"resource_id,omitempty"`
+	ResourceType     string     `json:"resource_type,omitempty"`
+	ParentResourceID string     `json:"parent_resource_id,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+}
+
+// SuccessResp is returned by assign/transfer endpoints; Request is only set when the caller asks for echo=true
+type SuccessResp struct {
+	Status  string       `json:"status"`
+	Request *RequestEcho `json:"request,omitempty"`
+}
+
+// DeleteResp is returned by delete endpoints; DeletedCount is the number of roles soft deleted,
+// including cascaded ones (0 when the user or resource had none)
+type DeleteResp struct {
+	Status       string `json:"status"`
+	DeletedCount int64  `json:"deleted_count"`
+}
+
+// Echo reports the role as it was stored, so defaults the service filled in (user_type, the caller
+// as owner) and the normalized fields show up as persisted
+func (r *UserRole) Echo() RequestEcho {
+	return RequestEcho{
+		Scope:            r.Scope,
+		Namespace:        r.Namespace,
+		UserID:           r.UserID,
+		UserType:         r.UserType,
+		Role:             r.Role,
+		ResourceID:       r.ResourceID,
+		ResourceType:     r.ResourceType,
+		ParentResourceID: r.ParentResourceID,
+		ExpiresAt:        r.ExpiresAt,
+		Reason:           r.Reason,
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import "strings"
+
+// ResourceIDFolder is implemented by requests carrying resource IDs (and parent/child IDs). When the
+// server folds resource ID case, the handler calls FoldResourceIDCase before Validate, so "Dash_1" and
+// "dash_1" address the same resource.
+type ResourceIDFolder interface {
+	FoldResourceIDCase()
+}
+
+// NormalizeResourceID trims a resource ID; case folding is left to ResourceIDFolder
+func NormalizeResourceID(id string) string {
+	return strings.TrimSpace(id)
+}
+
+// foldIDs lower-cases ids in place
+func foldIDs(ids []string) {
+	for i := range ids {
+		ids[i] = strings.ToLower(ids[i])
+	}
+}
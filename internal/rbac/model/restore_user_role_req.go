@@ -0,0 +1,55 @@
+package model
+
+import "strings"
+
+// RestoreUserRoleReq undoes the soft delete of a user's system or resource role
+type RestoreUserRoleReq struct {
+	Scope            string `json:"scope" validate:"required,oneof=system resource"`
+	Namespace        string `json:"namespace" validate:"omitempty,max=50"`
+	UserID           string `json:"user_id" validate:"required,min=1,max=50"`
+	UserType         string `json:"user_type" validate:"omitempty,max=50"` // Optional, recorded in history
+	ResourceID       string `json:"resource_id" validate:"omitempty,max=50"`
+	ResourceType     string `json:"resource_type" validate:"omitempty,max=50"`
+	ParentResourceID string `json:"parent_resource_id" validate:"omitempty,max=50"` // Required for dashboard_widget
+}
+
+func (r *RestoreUserRoleReq) Validate() error {
+	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.UserID = strings.TrimSpace(r.UserID)
+	r.UserType = strings.ToLower(strings.TrimSpace(r.UserType))
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+
+	if r.Scope == ScopeSystem {
+		if r.Namespace == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "namespace required for system scope"}
+		}
+		if r.ResourceID != "" || r.ResourceType != "" {
+			return &ErrorDetail{Code: "bad_request", Message: "invalid parameters for system scope"}
+		}
+		return nil
+	}
+
+	if r.ResourceID == "" || r.ResourceType == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "resource_id and resource_type required for resource scope"}
+	}
+	if r.ResourceType == ResourceTypeLibraryWidget && r.Namespace == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "namespace required for resource library_widget"}
+	}
+	if r.ResourceType == ResourceTypeDashboardWidget && r.ParentResourceID == "" {
+		return &ErrorDetail{Code: "bad_request", Message: "parent_resource_id required for resource dashboard_widget"}
+	}
+	return nil
+}
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *RestoreUserRoleReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+}
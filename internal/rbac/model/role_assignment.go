@@ -0,0 +1,56 @@
+package model
+
+import "strings"
+
+// RoleAssignment pairs a user with the role to grant in a mixed-role batch
+type RoleAssignment struct {
+	UserID     string `json:"user_id" validate:"omitempty,max=50"`
+	ExternalID string `json:"external_id,omitempty" validate:"omitempty,max=100"` // Optional alternative to user_id, resolved by the user resolver
+	Email      string `json:"email,omitempty" validate:"omitempty,max=254,email"` // Optional alternative to user_id; user_id wins when both are set
+	Role       string `json:"role" validate:"required,max=50"`
+}
+
+// normalizeAssignments trims user identifiers and lower-cases emails and roles in place
+func normalizeAssignments(assignments []RoleAssignment) {
+	for i := range assignments {
+		assignments[i].UserID = strings.TrimSpace(assignments[i].UserID)
+		assignments[i].ExternalID = strings.TrimSpace(assignments[i].ExternalID)
+		assignments[i].Email = strings.ToLower(strings.TrimSpace(assignments[i].Email))
+		assignments[i].Role = strings.ToLower(strings.TrimSpace(assignments[i].Role))
+	}
+}
+
+// assignmentUsersError rejects an assignment that names no user
+func assignmentUsersError(assignments []RoleAssignment) error {
+	for _, a := range assignments {
+		if a.UserID == "" && a.ExternalID == "" && a.Email == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "each assignment needs user_id, external_id or email"}
+		}
+	}
+	return nil
+}
+
+// expandAssignments pairs every user with role, or returns assignments when the batch is mixed
+func expandAssignments(userIDs []string, role string, assignments []RoleAssignment) []RoleAssignment {
+	if len(assignments) > 0 {
+		return assignments
+	}
+	pairs := make([]RoleAssignment, 0, len(userIDs))
+	for _, userID := range userIDs {
+		pairs = append(pairs, RoleAssignment{UserID: userID, Role: role})
+	}
+	return pairs
+}
+
+// GroupAssignmentsByRole returns the distinct roles in first-seen order and the users granted each
+func GroupAssignmentsByRole(assignments []RoleAssignment) ([]string, map[string][]string) {
+	var roles []string
+	users := make(map[string][]string)
+	for _, a := range assignments {
+		if _, ok := users[a.Role]; !ok {
+			roles = append(roles, a.Role)
+		}
+		users[a.Role] = append(users[a.Role], a.UserID)
+	}
+	return roles, users
+}
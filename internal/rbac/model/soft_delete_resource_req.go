@@ -12,22 +12,25 @@ type SoftDeleteResourceReq struct {
 }
 
 func (r *SoftDeleteResourceReq) Validate() error {
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
-	r.ParentResourceID = strings.TrimSpace(r.ParentResourceID)
+	r.ParentResourceID = NormalizeResourceID(r.ParentResourceID)
 
 	// Namespace: TrimSpace and uppercase
 	if r.Namespace != "" {
 		r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 	}
 
-	// ChildResourceIDs: TrimSpace and remove duplicates
+	// ChildResourceIDs: TrimSpace and remove duplicates; a blank entry is a malformed request
 	if len(r.ChildResourceIDs) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]string, 0, len(r.ChildResourceIDs))
 		for _, id := range r.ChildResourceIDs {
-			trimmed := strings.TrimSpace(id)
-			if trimmed != "" && !seen[trimmed] {
+			trimmed := NormalizeResourceID(id)
+			if trimmed == "" {
+				return &ErrorDetail{Code: "bad_request", Message: "child_resource_ids must not contain empty values"}
+			}
+			if !seen[trimmed] {
 				seen[trimmed] = true
 				unique = append(unique, trimmed)
 			}
@@ -39,5 +42,27 @@ func (r *SoftDeleteResourceReq) Validate() error {
 		return FormatValidationError(err)
 	}
 
+	// Per-type requirements mirror the delete_resource operation configs
+	switch r.ResourceType {
+	case "dashboard_widget":
+		if r.ParentResourceID == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "parent_resource_id is required for dashboard_widget"}
+		}
+	case "library_widget":
+		if r.Namespace == "" {
+			return &ErrorDetail{Code: "bad_request", Message: "namespace is required for library_widget"}
+		}
+	}
+	if r.ResourceType != "dashboard" && len(r.ChildResourceIDs) > 0 {
+		return &ErrorDetail{Code: "bad_request", Message: "child_resource_ids is only allowed for dashboard"}
+	}
+
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *SoftDeleteResourceReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+	r.ParentResourceID = strings.ToLower(r.ParentResourceID)
+	foldIDs(r.ChildResourceIDs)
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// SyncUserRolesReq requests the role changes since a high-water mark (incremental sync)
+type SyncUserRolesReq struct {
+	ModifiedSince *time.Time `query:"modified_since"`
+	Scope         string     `query:"scope" validate:"omitempty,oneof=system resource"`
+	Namespace     string     `query:"namespace" validate:"omitempty,max=50"`
+	ResourceType  string     `query:"resource_type" validate:"omitempty,max=50"`
+}
+
+func (r *SyncUserRolesReq) Validate() error {
+	r.Scope = strings.ToLower(strings.TrimSpace(r.Scope))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
+	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+
+	if err := GetValidator().Struct(r); err != nil {
+		return FormatValidationError(err)
+	}
+
+	if r.ModifiedSince == nil {
+		return &ErrorDetail{Code: "bad_request", Message: "modified_since is required"}
+	}
+	if r.Scope == ScopeSystem && r.ResourceType != "" {
+		return &ErrorDetail{Code: "bad_request", Message: "invalid parameters for system scope"}
+	}
+	return nil
+}
+
+// SyncUserRolesResp carries changed and soft-deleted roles (deleted_at set) since modified_since, in change order.
+// Pass Cursor as the next modified_since; roles at exactly Cursor are returned again, so apply deltas idempotently.
+// Truncated is set when more changes remain after the last role; sync again from Cursor to fetch them.
+type SyncUserRolesResp struct {
+	Data      []*UserRole `json:"data"`
+	Cursor    time.Time   `json:"cursor"`
+	Truncated bool        `json:"truncated"`
+}
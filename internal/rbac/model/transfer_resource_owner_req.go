@@ -6,15 +6,22 @@ type TransferResourceOwnerReq struct {
 	UserID       string `json:"user_id" validate:"required,min=1,max=50"`
 	ResourceID   string `json:"resource_id" validate:"required,min=1,max=50"`
 	ResourceType string `json:"resource_type" validate:"required,min=1,max=50"`
+	Namespace    string `json:"namespace" validate:"omitempty,max=50"` // Scopes the resource when resources are namespaced
 }
 
 func (r *TransferResourceOwnerReq) Validate() error {
 	r.UserID = strings.TrimSpace(r.UserID)
-	r.ResourceID = strings.TrimSpace(r.ResourceID)
+	r.ResourceID = NormalizeResourceID(r.ResourceID)
 	r.ResourceType = strings.ToLower(strings.TrimSpace(r.ResourceType))
+	r.Namespace = strings.ToUpper(strings.TrimSpace(r.Namespace))
 
 	if err := GetValidator().Struct(r); err != nil {
 		return FormatValidationError(err)
 	}
 	return nil
 }
+
+// FoldResourceIDCase implements ResourceIDFolder
+func (r *TransferResourceOwnerReq) FoldResourceIDCase() {
+	r.ResourceID = strings.ToLower(r.ResourceID)
+}
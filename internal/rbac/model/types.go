@@ -24,38 +24,79 @@ type ErrorDetail struct {
 	Code      string `json:"code"`
 	Message   string `json:"message"`
 	RequestID string `json:"request_id,omitempty"`
+	// CurrentOwner names the existing owner when a resource owner assignment conflicts
+	CurrentOwner string `json:"current_owner,omitempty"`
+	// Debug explains an RBAC middleware rejection; only set when RBAC_DEBUG_DENIALS is enabled
+	Debug *RBACDebug `json:"debug,omitempty"`
 }
 
-// Internal Representation for Repo
+// RBACDebug names the operation the RBAC middleware matched (or the candidates it could not match)
+// and the requirement the request did not meet
+type RBACDebug struct {
+	Key          string   `json:"key"` // METHOD:PATH lookup key
+	Entity       string   `json:"entity,omitempty"`
+	Operation    string   `json:"operation,omitempty"`
+	Permission   string   `json:"permission,omitempty"`
+	CheckScope   string   `json:"check_scope,omitempty"`
+	MissingParam string   `json:"missing_param,omitempty"`
+	Candidates   []string `json:"candidates,omitempty"` // entity.operation configs whose conditions did not match
+}
+
+// Internal Representation for Repo (also returned by the list APIs)
 type UserRole struct {
-	ID        string `bson:"_id,omitempty"`
-	UserID    string `bson:"user_id"`
-	UserType  string `bson:"user_type"`
-	Role      string `bson:"role"`
-	Scope     string `bson:"scope"`
-	Namespace string `bson:"namespace,omitempty"`
+	ID        string `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    string `bson:"user_id" json:"user_id"`
+	UserType  string `bson:"user_type" json:"user_type"`
+	Role      string `bson:"role" json:"role"`
+	Scope     string `bson:"scope" json:"scope"`
+	Namespace string `bson:"namespace,omitempty" json:"namespace,omitempty"`
 	// Resource Scoping
-	ResourceID       string `bson:"resource_id,omitempty"`
-	ResourceType     string `bson:"resource_type,omitempty"`
-	ParentResourceID string `bson:"parent_resource_id,omitempty"`
+	ResourceID       string `bson:"resource_id,omitempty" json:"resource_id,omitempty"`
+	ResourceType     string `bson:"resource_type,omitempty" json:"resource_type,omitempty"`
+	ParentResourceID string `bson:"parent_resource_id,omitempty" json:"parent_resource_id,omitempty"`
+	// Temporary grants: the role stops granting access at ExpiresAt; Reason records why it was granted
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Reason    string     `bson:"reason,omitempty" json:"reason,omitempty"`
+	// Permissions the role grants; only filled for GET /user_roles?expand=permissions (never stored)
+	Permissions []string `bson:"-" json:"permissions,omitempty"`
+	// Inherited marks a namespace system role listed as a resource member (GET /user_roles?include_inherited=true)
+	Inherited bool `bson:"-" json:"inherited,omitempty"`
 
 	// Audit Fields
-	CreatedAt time.Time  `bson:"created_at"`
-	UpdatedAt time.Time  `bson:"updated_at"`
-	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
-	CreatedBy string     `bson:"created_by,omitempty"`
-	UpdatedBy string     `bson:"updated_by,omitempty"`
-	DeletedBy string     `bson:"deleted_by,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at,omitzero"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at,omitzero"`
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	CreatedBy string     `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy string     `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+// OmitAudit clears the created/updated audit fields so they are left out of the JSON
+func (r *UserRole) OmitAudit() {
+	r.CreatedAt, r.UpdatedAt = time.Time{}, time.Time{}
+	r.CreatedBy, r.UpdatedBy = "", ""
 }
 
 type UserRoleFilter struct {
-	UserID           string
+	UserID string
+	// UserIDs matches any of these users ($in); ignored when UserID is set
+	UserIDs          []string
 	Namespace        string
 	Role             string
 	Scope            string
 	ResourceID       string
 	ResourceType     string
 	ParentResourceID string
+	// Roles matches any of these roles ($in); ignored when Role is set
+	Roles []string
+	// ResourceIDs matches any of these resource IDs ($in); ignored when ResourceID is set
+	ResourceIDs []string
+	// ModifiedSince returns roles updated or soft deleted at/after this time (soft-deleted roles included)
+	ModifiedSince *time.Time
+	// Offset/Limit page the result ordered by created_at, or with ModifiedSince by change time
+	// (the later of updated_at and deleted_at); 0 Limit returns all
+	Offset int64
+	Limit  int64
 }
 
 // Resource Scope Requests
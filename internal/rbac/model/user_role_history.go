@@ -5,12 +5,15 @@ import "time"
 // UserRoleHistory 審計日誌記錄 (append-only, read-only after creation)
 type UserRoleHistory struct {
 	ID        string `bson:"_id,omitempty" json:"id"`
-	Operation string `bson:"operation" json:"operation"` // assign_owner, transfer_owner, assign_user_role, assign_user_roles_batch, delete_user_role, delete_resource
+	Operation string `bson:"operation" json:"operation"` // assign_owner, transfer_owner, assign_user_role, assign_user_roles_batch, delete_user_role, restore_user_role, delete_resource, rename_namespace, restore_namespace
 	CallerID  string `bson:"caller_id" json:"caller_id"`
 
 	// Scope Info
 	Scope     string `bson:"scope" json:"scope"` // system/resource
 	Namespace string `bson:"namespace,omitempty" json:"namespace,omitempty"`
+	// PreviousNamespace is the old name recorded by rename_namespace, or the snapshot's source
+	// namespace recorded by restore_namespace
+	PreviousNamespace string `bson:"previous_namespace,omitempty" json:"previous_namespace,omitempty"`
 
 	// Resource Info
 	ResourceID       string `bson:"resource_id,omitempty" json:"resource_id,omitempty"`
@@ -26,6 +29,13 @@ type UserRoleHistory struct {
 	Role       string `bson:"role,omitempty" json:"role,omitempty"`
 	NewOwnerID string `bson:"new_owner_id,omitempty" json:"new_owner_id,omitempty"` // transfer_owner
 
+	// Temporary Grant Info (assign_user_role, assign_user_roles_batch)
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Reason    string     `bson:"reason,omitempty" json:"reason,omitempty"`
+
+	// SuperadminBypass marks operations a superadmin performed without a permission check
+	SuperadminBypass bool `bson:"superadmin_bypass,omitempty" json:"superadmin_bypass,omitempty"`
+
 	// Soft Delete Info (for delete_resource)
 	ChildResourceIDs []string `bson:"child_resource_ids,omitempty" json:"child_resource_ids,omitempty"`
 
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"rbac7/internal/rbac/model"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned when the delivery queue cannot take another event
+var ErrQueueFull = errors.New("notification queue full")
+
+// ErrClosed is returned by Notify after Close
+var ErrClosed = errors.New("notifier closed")
+
+// WebhookNotifier queues grant events and POSTs them as JSON to a webhook URL from a background worker.
+// Notify never blocks on delivery; undeliverable events are logged and dropped.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+	queue  chan model.GrantEvent
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWebhookNotifier starts a notifier that holds up to queueSize pending events
+func NewWebhookNotifier(url string, timeout time.Duration, queueSize int) *WebhookNotifier {
+	n := &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan model.GrantEvent, queueSize),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Notify enqueues the event for delivery
+func (n *WebhookNotifier) Notify(ctx context.Context, event model.GrantEvent) error {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return ErrClosed
+	}
+	select {
+	case n.queue <- event:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting events and waits until queued events are delivered or ctx expires
+func (n *WebhookNotifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *WebhookNotifier) run() {
+	defer close(n.done)
+	for event := range n.queue {
+		if err := n.send(event); err != nil {
+			log.Printf("Warning: Grant notification dropped. Target=%s, Role=%s, err=%v", event.UserID, event.Role, err)
+		}
+	}
+}
+
+func (n *WebhookNotifier) send(event model.GrantEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("delivers queued events before close returns", func(t *testing.T) {
+		received := make(chan model.GrantEvent, 2)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event model.GrantEvent
+			json.NewDecoder(r.Body).Decode(&event)
+			received <- event
+		}))
+		defer srv.Close()
+
+		n := NewWebhookNotifier(srv.URL, time.Second, 10)
+		require.NoError(t, n.Notify(context.Background(), model.GrantEvent{Type: model.GrantEventRoleGranted, UserID: "u1", Role: "viewer"}))
+		require.NoError(t, n.Notify(context.Background(), model.GrantEvent{Type: model.GrantEventRoleGranted, UserID: "u2", Role: "editor"}))
+		require.NoError(t, n.Close(context.Background()))
+
+		assert.Len(t, received, 2)
+		assert.Equal(t, "u1", (<-received).UserID)
+		assert.Equal(t, "u2", (<-received).UserID)
+	})
+
+	t.Run("rejects events when the queue is full", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		n := NewWebhookNotifier(srv.URL, time.Second, 1)
+		// The worker picks up the first event and blocks on the webhook; the second fills the queue
+		require.NoError(t, n.Notify(context.Background(), model.GrantEvent{UserID: "u1"}))
+		assert.Eventually(t, func() bool { return len(n.queue) == 0 }, time.Second, time.Millisecond)
+		require.NoError(t, n.Notify(context.Background(), model.GrantEvent{UserID: "u2"}))
+
+		assert.ErrorIs(t, n.Notify(context.Background(), model.GrantEvent{UserID: "u3"}), ErrQueueFull)
+	})
+
+	t.Run("rejects events after close", func(t *testing.T) {
+		n := NewWebhookNotifier("http://127.0.0.1:0", time.Second, 1)
+		require.NoError(t, n.Close(context.Background()))
+
+		assert.ErrorIs(t, n.Notify(context.Background(), model.GrantEvent{UserID: "u1"}), ErrClosed)
+	})
+}
@@ -2,67 +2,158 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"rbac7/internal/rbac/model"
 	"rbac7/internal/rbac/repository"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrUnmappedPermission is returned in strict mode when no role grants the checked permission
+var ErrUnmappedPermission = errors.New("permission is not granted by any role")
+
 // Engine is the central policy engine for permission checking
 type Engine struct {
-	loader            *Loader
+	loader *Loader
+	// mu guards the loaded policies below, which Reload swaps while checks run
+	mu                sync.RWMutex
 	entityPolicies    map[string]*EntityPolicy
 	checkPermConfig   *CheckPermissionConfig
 	systemRolePerms   map[string][]string
 	resourceRolePerms map[string][]string
+	apiConfigs        map[string][]*APIConfig
+	loadedAt          time.Time
+	// superadmins bypass operation permission checks (break-glass, opt-in via config)
+	superadmins map[string]bool
+	// strictPermissions turns a check of a permission no role grants into an error instead of a deny
+	strictPermissions bool
+	// debugDenials adds the matched operation and unmet requirement to RBAC middleware error bodies
+	debugDenials bool
+	// unmappedChecks counts checks of permissions no role grants (likely a policy config bug)
+	unmappedChecks atomic.Uint64
 }
 
 // NewEngine creates a new PolicyEngine instance
 func NewEngine() (*Engine, error) {
-	loader := NewLoader()
+	engine := &Engine{loader: NewLoader()}
+	if err := engine.Reload(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
 
-	entityPolicies, err := loader.LoadEntityPolicies()
+// SetLoader replaces where policies are loaded from (startup only); call Reload to apply it
+func (e *Engine) SetLoader(loader *Loader) {
+	e.loader = loader
+}
+
+// Reload re-reads all policy files through the loader and swaps them in at once, so a check sees
+// either the old or the new policies, never a mix. On error the current policies stay in effect.
+func (e *Engine) Reload() error {
+	entityPolicies, err := e.loader.LoadEntityPolicies()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load entity policies: %w", err)
+		return fmt.Errorf("failed to load entity policies: %w", err)
 	}
 
-	checkPermConfig, err := loader.LoadCheckPermissionConfig()
+	checkPermConfig, err := e.loader.LoadCheckPermissionConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load check permission config: %w", err)
+		return fmt.Errorf("failed to load check permission config: %w", err)
 	}
 
 	// Load role permissions from JSON files
-	systemRolePerms, err := loader.LoadSystemRolePermissions()
+	systemRolePerms, err := e.loader.LoadSystemRolePermissions()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load system role permissions: %w", err)
+		return fmt.Errorf("failed to load system role permissions: %w", err)
 	}
 
-	resourceRolePerms, err := loader.LoadResourceRolePermissions()
+	resourceRolePerms, err := e.loader.LoadResourceRolePermissions()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load resource role permissions: %w", err)
+		return fmt.Errorf("failed to load resource role permissions: %w", err)
 	}
 
-	engine := &Engine{
-		loader:            loader,
-		entityPolicies:    entityPolicies,
-		checkPermConfig:   checkPermConfig,
-		systemRolePerms:   systemRolePerms,
-		resourceRolePerms: resourceRolePerms,
+	apiConfigs := e.loader.LoadAPIConfigs(entityPolicies)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entityPolicies = entityPolicies
+	e.checkPermConfig = checkPermConfig
+	e.systemRolePerms = systemRolePerms
+	e.resourceRolePerms = resourceRolePerms
+	e.apiConfigs = apiConfigs
+	e.loadedAt = time.Now()
+	return nil
+}
+
+// Summary counts the loaded policies
+func (e *Engine) Summary() *model.PolicySummary {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	summary := &model.PolicySummary{
+		Entities:      len(e.entityPolicies),
+		SystemRoles:   len(e.systemRolePerms),
+		ResourceRoles: len(e.resourceRolePerms),
+		LoadedAt:      e.loadedAt,
 	}
+	for _, entityPolicy := range e.entityPolicies {
+		summary.Operations += len(entityPolicy.Operations)
+	}
+	return summary
+}
 
-	return engine, nil
+// SetSuperadmins replaces the user IDs whose operations bypass permission checks
+func (e *Engine) SetSuperadmins(userIDs []string) {
+	e.superadmins = make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		e.superadmins[id] = true
+	}
+}
+
+// IsSuperadmin reports whether the user bypasses operation permission checks
+func (e *Engine) IsSuperadmin(userID string) bool {
+	return e.superadmins[userID]
+}
+
+// SetStrictPermissions makes checks of a permission no role grants fail with ErrUnmappedPermission
+func (e *Engine) SetStrictPermissions(strict bool) {
+	e.strictPermissions = strict
+}
+
+// SetDebugDenials makes RBAC middleware errors name the matched operation and the unmet requirement.
+// It exposes policy details to callers, so it is meant for development and staging only.
+func (e *Engine) SetDebugDenials(debug bool) {
+	e.debugDenials = debug
+}
+
+// DebugDenials reports whether RBAC middleware errors carry debug details
+func (e *Engine) DebugDenials() bool {
+	return e.debugDenials
 }
 
-// GetLoader returns the loader for building API configs
-func (e *Engine) GetLoader() *Loader {
-	return e.loader
+// UnmappedPermissionChecks returns how many checks hit a permission no role grants
+func (e *Engine) UnmappedPermissionChecks() uint64 {
+	return e.unmappedChecks.Load()
 }
 
 // GetEntityPolicies returns the entity policies map
 func (e *Engine) GetEntityPolicies() map[string]*EntityPolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	return e.entityPolicies
 }
 
+// APIConfigs returns the RBAC middleware's route index ("METHOD:PATH" to operation configs)
+func (e *Engine) APIConfigs() map[string][]*APIConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.apiConfigs
+}
+
 // normalizeRequest auto-infers Entity from Scope/ResourceType and adjusts Operation for special cases
 // For dashboard_widget with viewer role, it uses viewer-specific operations (assign_viewer, delete_viewer)
 func (e *Engine) normalizeRequest(req *OperationRequest) (entity, operation string) {
@@ -96,6 +187,9 @@ func (e *Engine) normalizeRequest(req *OperationRequest) (entity, operation stri
 
 // GetOperationPolicy returns the policy for a specific entity operation
 func (e *Engine) GetOperationPolicy(entity, operation string) (*OperationPolicy, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	entityPolicy, ok := e.entityPolicies[entity]
 	if !ok {
 		return nil, fmt.Errorf("unknown entity: %s", entity)
@@ -140,7 +234,7 @@ func (e *Engine) CheckOperationPermission(
 		return e.checkSystemPermission(ctx, repo, req.CallerID, req.Namespace, policy.Permission)
 
 	case CheckScopeResource:
-		return e.checkResourcePermission(ctx, repo, req.CallerID, req.ResourceID, req.ResourceType, policy.Permission)
+		return e.checkResourcePermission(ctx, repo, req.CallerID, req.Namespace, req.ResourceID, req.ResourceType, policy.Permission)
 
 	case CheckScopeParentResource:
 		if req.ParentResourceID == "" {
@@ -148,7 +242,7 @@ func (e *Engine) CheckOperationPermission(
 		}
 		// Get parent entity type from policy
 		parentType := e.getParentType(entity)
-		return e.checkResourcePermission(ctx, repo, req.CallerID, req.ParentResourceID, parentType, policy.Permission)
+		return e.checkResourcePermission(ctx, repo, req.CallerID, req.Namespace, req.ParentResourceID, parentType, policy.Permission)
 
 	case CheckScopeSelfRoles:
 		// For self_roles, this is typically checked differently (roles already loaded)
@@ -166,6 +260,9 @@ func (e *Engine) CheckOperationPermission(
 
 // getParentType returns the parent entity type for the given entity
 func (e *Engine) getParentType(entity string) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	entityPolicy, ok := e.entityPolicies[entity]
 	if ok && entityPolicy != nil && entityPolicy.ParentEntity != "" {
 		return entityPolicy.ParentEntity
@@ -199,28 +296,30 @@ func (e *Engine) inferEntity(scope, resourceType string) string {
 func (e *Engine) CheckResourceAccess(
 	ctx context.Context,
 	repo repository.RBACRepository,
-	callerID, resourceID, resourceType, permission, parentResourceID string,
+	callerID, namespace, resourceID, resourceType, permission, parentResourceID string,
 ) (bool, error) {
+	e.mu.RLock()
 	rule, ok := e.checkPermConfig.ResourceTypes[resourceType]
+	e.mu.RUnlock()
 	if !ok {
 		// No special rule, do standard check
-		return e.checkResourcePermission(ctx, repo, callerID, resourceID, resourceType, permission)
+		return e.checkResourcePermission(ctx, repo, callerID, namespace, resourceID, resourceType, permission)
 	}
 
 	switch rule.Inheritance {
 	case "none":
-		return e.checkResourcePermission(ctx, repo, callerID, resourceID, resourceType, permission)
+		return e.checkResourcePermission(ctx, repo, callerID, namespace, resourceID, resourceType, permission)
 
 	case "parent_if_no_roles":
 		// First check if any roles exist on this resource
-		count, err := repo.CountResourceRoles(ctx, resourceID, resourceType)
+		count, err := repo.CountResourceRoles(ctx, namespace, resourceID, resourceType)
 		if err != nil {
 			return false, err
 		}
 
 		if count > 0 {
 			// Whitelist mode: strict check on the resource itself
-			return e.checkResourcePermission(ctx, repo, callerID, resourceID, resourceType, permission)
+			return e.checkResourcePermission(ctx, repo, callerID, namespace, resourceID, resourceType, permission)
 		}
 
 		// Inheritance mode: check parent
@@ -231,24 +330,33 @@ func (e *Engine) CheckResourceAccess(
 		// Map permission if needed
 		mappedPerm := e.mapPermission(rule, permission)
 
-		return e.checkResourcePermission(ctx, repo, callerID, parentResourceID, rule.ParentType, mappedPerm)
+		return e.checkResourcePermission(ctx, repo, callerID, namespace, parentResourceID, rule.ParentType, mappedPerm)
 
 	default:
-		return e.checkResourcePermission(ctx, repo, callerID, resourceID, resourceType, permission)
+		return e.checkResourcePermission(ctx, repo, callerID, namespace, resourceID, resourceType, permission)
 	}
 }
 
+// CheckSystemAccess checks if user has a system permission in namespace (for CheckPermission API)
+func (e *Engine) CheckSystemAccess(
+	ctx context.Context,
+	repo repository.RBACRepository,
+	userID, namespace, permission string,
+) (bool, error) {
+	return e.checkSystemPermission(ctx, repo, userID, namespace, permission)
+}
+
 // checkSystemPermission checks if user has system-level permission
 func (e *Engine) checkSystemPermission(
 	ctx context.Context,
 	repo repository.RBACRepository,
 	userID, namespace, permission string,
 ) (bool, error) {
-	requiredRoles := e.GetRolesWithPermission(permission, true)
-	if len(requiredRoles) == 0 {
-		return false, nil
+	requiredRoles, err := e.requiredRoles(permission, true)
+	if err != nil || len(requiredRoles) == 0 {
+		return false, err
 	}
-	return repo.HasAnySystemRole(ctx, userID, namespace, requiredRoles)
+	return repo.HasAnySystemRole(ctx, namespace, userID, requiredRoles)
 }
 
 // checkGlobalPermission checks if user has global-level permission (without namespace)
@@ -258,29 +366,53 @@ func (e *Engine) checkGlobalPermission(
 	repo repository.RBACRepository,
 	userID, permission string,
 ) (bool, error) {
-	requiredRoles := e.GetRolesWithPermission(permission, true)
-	if len(requiredRoles) == 0 {
-		return false, nil
+	requiredRoles, err := e.requiredRoles(permission, true)
+	if err != nil || len(requiredRoles) == 0 {
+		return false, err
 	}
 	// Pass empty namespace to check for global roles
-	return repo.HasAnySystemRole(ctx, userID, "", requiredRoles)
+	return repo.HasAnySystemRole(ctx, "", userID, requiredRoles)
 }
 
 // checkResourcePermission checks if user has resource-level permission
 func (e *Engine) checkResourcePermission(
 	ctx context.Context,
 	repo repository.RBACRepository,
-	userID, resourceID, resourceType, permission string,
+	userID, namespace, resourceID, resourceType, permission string,
 ) (bool, error) {
-	requiredRoles := e.GetRolesWithPermission(permission, false)
-	if len(requiredRoles) == 0 {
-		return false, nil
+	requiredRoles, err := e.requiredRoles(permission, false)
+	if err != nil || len(requiredRoles) == 0 {
+		return false, err
+	}
+	return repo.HasAnyResourceRole(ctx, namespace, userID, resourceID, resourceType, requiredRoles)
+}
+
+// requiredRoles resolves permission to the roles granting it for a check. Zero roles usually means
+// a typo or a permission missing from the role files, so it is logged and counted instead of
+// silently denying; in strict mode it fails with ErrUnmappedPermission.
+func (e *Engine) requiredRoles(permission string, isSystem bool) ([]string, error) {
+	roles := e.GetRolesWithPermission(permission, isSystem)
+	if len(roles) > 0 {
+		return roles, nil
+	}
+
+	scope := model.ScopeResource
+	if isSystem {
+		scope = model.ScopeSystem
 	}
-	return repo.HasAnyResourceRole(ctx, userID, resourceID, resourceType, requiredRoles)
+	e.unmappedChecks.Add(1)
+	log.Printf("WARNING Audit:PolicyEngine. permission=%s is not granted by any %s role, strict=%v", permission, scope, e.strictPermissions)
+	if e.strictPermissions {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrUnmappedPermission, permission, scope)
+	}
+	return nil, nil
 }
 
-// GetRolesWithPermission returns roles that have the given permission
+// GetRolesWithPermission returns roles that have the given permission, directly or through a wildcard grant
 func (e *Engine) GetRolesWithPermission(permission string, isSystem bool) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	var rolePerms map[string][]string
 	if isSystem {
 		rolePerms = e.systemRolePerms
@@ -291,7 +423,7 @@ func (e *Engine) GetRolesWithPermission(permission string, isSystem bool) []stri
 	var roles []string
 	for role, perms := range rolePerms {
 		for _, p := range perms {
-			if p == permission {
+			if permissionMatches(p, permission) {
 				roles = append(roles, role)
 				break
 			}
@@ -301,6 +433,49 @@ func (e *Engine) GetRolesWithPermission(permission string, isSystem bool) []stri
 	return roles
 }
 
+// permissionMatches reports whether a granted permission covers the checked one. Permissions are
+// compared segment by segment (split on "."); a "*" segment in the grant matches any one segment,
+// so resource.dashboard.* grants resource.dashboard.read but neither resource.dashboard_widget.read
+// nor a permission with more segments.
+func permissionMatches(granted, permission string) bool {
+	if granted == permission {
+		return true
+	}
+	if !strings.Contains(granted, "*") {
+		return false
+	}
+
+	grantedSegs := strings.Split(granted, ".")
+	permSegs := strings.Split(permission, ".")
+	if len(grantedSegs) != len(permSegs) {
+		return false
+	}
+	for i, seg := range grantedSegs {
+		if seg != "*" && seg != permSegs[i] {
+			return false
+		}
+		if permSegs[i] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRolePermissions returns the permissions a role confers, sorted
+func (e *Engine) GetRolePermissions(role string, isSystem bool) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rolePerms := e.resourceRolePerms
+	if isSystem {
+		rolePerms = e.systemRolePerms
+	}
+
+	perms := append([]string{}, rolePerms[role]...)
+	sort.Strings(perms)
+	return perms
+}
+
 // CheckRolesHavePermission checks if any of the provided roles have the permission
 func (e *Engine) CheckRolesHavePermission(roles []*model.UserRole, permission string) bool {
 	systemAllowed := e.GetRolesWithPermission(permission, true)
@@ -1,9 +1,23 @@
 package policy
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewEngine(t *testing.T) {
@@ -46,12 +60,21 @@ func TestAllOperationsPolicies(t *testing.T) {
 	}{
 		// === system.json ===
 		{"system", "assign_owner", "platform.system.add_owner", CheckScopeGlobal, false, false, false},
+		{"system", "sync_roles", "platform.role.sync", CheckScopeGlobal, false, false, false},
+		{"system", "access_snapshot", "platform.user.read_access", CheckScopeGlobal, false, false, false},
+		{"system", "get_resource_owners", "platform.resource.read_owners", CheckScopeGlobal, false, false, false},
+		{"system", "reload_policy", "platform.policy.reload", CheckScopeGlobal, false, false, false},
+		{"system", "reassign_owner", "platform.user.reassign_owner", CheckScopeGlobal, false, false, false},
 		{"system", "transfer_owner", "platform.system.transfer_owner", CheckScopeSystem, true, false, false},
 		{"system", "assign_user_role", "platform.system.add_member", CheckScopeSystem, true, false, false},
 		{"system", "assign_user_roles_batch", "platform.system.add_member", CheckScopeSystem, true, false, false},
 		{"system", "delete_user_role", "platform.system.remove_member", CheckScopeSystem, true, false, false},
 		{"system", "get_members", "platform.system.get_member", CheckScopeSystem, true, false, false},
+		{"system", "check_members", "platform.system.get_member", CheckScopeSystem, true, false, false},
 		{"system", "get_my_roles", "platform.system.read", CheckScopeSelfRoles, false, false, false},
+		{"system", "get_my_resource_roles", "", CheckScopeNone, false, false, false},
+		{"system", "read_log", "platform.system.read_audit", CheckScopeSystem, true, false, false},
+		{"system", "set_resource_role_policy", "platform.system.update", CheckScopeSystem, true, false, false},
 
 		// === dashboard.json ===
 		{"dashboard", "assign_owner", "", CheckScopeNone, false, false, false},
@@ -60,6 +83,9 @@ func TestAllOperationsPolicies(t *testing.T) {
 		{"dashboard", "assign_user_roles_batch", "resource.dashboard.add_member", CheckScopeResource, false, false, false},
 		{"dashboard", "delete_user_role", "resource.dashboard.remove_member", CheckScopeResource, false, true, false},
 		{"dashboard", "get_members", "resource.dashboard.get_member", CheckScopeResource, false, true, false},
+		{"dashboard", "check_members", "resource.dashboard.get_member", CheckScopeResource, false, true, false},
+		{"dashboard", "get_capable_users", "resource.dashboard.get_member", CheckScopeResource, false, true, false},
+		{"dashboard", "get_owner", "resource.dashboard.get_member", CheckScopeResource, false, true, false},
 		{"dashboard", "get_my_roles", "resource.dashboard.read", CheckScopeSelfRoles, false, false, false},
 
 		// === dashboard_widget.json ===
@@ -67,12 +93,17 @@ func TestAllOperationsPolicies(t *testing.T) {
 		{"dashboard_widget", "assign_user_roles_batch", "resource.dashboard.add_widget_viewer", CheckScopeParentResource, false, true, true},
 		{"dashboard_widget", "delete_viewer", "resource.dashboard.add_widget_viewer", CheckScopeParentResource, false, true, true},
 		{"dashboard_widget", "get_members", "resource.dashboard_widget.get_member", CheckScopeParentResource, false, true, true},
+		{"dashboard_widget", "check_members", "resource.dashboard_widget.get_member", CheckScopeParentResource, false, true, true},
+		{"dashboard_widget", "get_capable_users", "resource.dashboard_widget.get_member", CheckScopeParentResource, false, true, true},
 
 		// === library_widget.json ===
 		{"library_widget", "assign_viewer", "platform.system.add_member", CheckScopeSystem, true, false, false},
 		{"library_widget", "assign_viewers_batch", "platform.system.add_member", CheckScopeSystem, true, false, false},
 		{"library_widget", "delete_viewer", "platform.system.remove_member", CheckScopeSystem, true, false, false},
 		{"library_widget", "get_members", "resource.library_widget.get_member", CheckScopeSystem, true, false, false},
+		{"library_widget", "check_members", "resource.library_widget.get_member", CheckScopeSystem, true, false, false},
+		{"library_widget", "get_capable_users", "resource.library_widget.get_member", CheckScopeSystem, true, false, false},
+		{"library_widget", "read_log", "platform.system.read_audit", CheckScopeSystem, true, false, false},
 		{"library_widget", "get_my_roles", "resource.library_widget.read", CheckScopeSelfRoles, false, false, false},
 	}
 
@@ -142,3 +173,191 @@ func TestGetRolesWithPermission(t *testing.T) {
 		assert.Contains(t, roles, "viewer")
 	})
 }
+
+func TestUnmappedPermission(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	t.Run("unmapped permission denies, logs and counts", func(t *testing.T) {
+		engine, err := NewEngine()
+		assert.NoError(t, err)
+		logs.Reset()
+
+		// Zero roles short-circuits before the repository is used
+		allowed, err := engine.CheckResourceAccess(context.Background(), nil, "user_1", "", "d1", "dashboard", "resource.dashboard.dlete", "")
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, uint64(1), engine.UnmappedPermissionChecks())
+		assert.Contains(t, logs.String(), "permission=resource.dashboard.dlete is not granted by any resource role")
+	})
+
+	t.Run("strict mode fails the check", func(t *testing.T) {
+		engine, err := NewEngine()
+		assert.NoError(t, err)
+		engine.SetStrictPermissions(true)
+
+		allowed, err := engine.CheckSystemAccess(context.Background(), nil, "user_1", "NS_1", "platform.system.dlete")
+		assert.ErrorIs(t, err, ErrUnmappedPermission)
+		assert.False(t, allowed)
+		assert.Equal(t, uint64(1), engine.UnmappedPermissionChecks())
+	})
+
+	t.Run("mapped permission is not counted", func(t *testing.T) {
+		engine, err := NewEngine()
+		assert.NoError(t, err)
+		engine.SetStrictPermissions(true)
+
+		roles, err := engine.requiredRoles("resource.dashboard.delete", false)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, roles)
+		assert.Zero(t, engine.UnmappedPermissionChecks())
+	})
+}
+
+// heldRolesRepo answers role checks from a fixed set of roles held on every resource
+type heldRolesRepo struct {
+	repository.RBACRepository
+	held []string
+}
+
+func (r heldRolesRepo) HasAnyResourceRole(_ context.Context, _, _, _, _ string, roles []string) (bool, error) {
+	for _, role := range roles {
+		if slices.Contains(r.held, role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestMultiRoleUnion(t *testing.T) {
+	engine, err := NewEngine()
+	assert.NoError(t, err)
+	engine.resourceRolePerms["publisher"] = []string{"resource.dashboard.publish"}
+
+	repo := heldRolesRepo{held: []string{"editor", "publisher"}}
+	for _, permission := range []string{"resource.dashboard.read", "resource.dashboard.update", "resource.dashboard.publish"} {
+		allowed, err := engine.CheckResourceAccess(context.Background(), repo, "user_1", "", "d1", "dashboard", permission, "")
+		assert.NoError(t, err)
+		assert.True(t, allowed, "%s should be granted by editor or publisher", permission)
+	}
+
+	allowed, err := engine.CheckResourceAccess(context.Background(), repo, "user_1", "", "d1", "dashboard", "resource.dashboard.delete", "")
+	assert.NoError(t, err)
+	assert.False(t, allowed, "neither editor nor publisher grants delete")
+}
+
+// writePolicyDir copies the embedded policies to a temporary directory for a dir loader
+func writePolicyDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := fs.WalkDir(policiesFS, "policies", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, strings.TrimPrefix(path, "policies"))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := policiesFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	require.NoError(t, err)
+	return dir
+}
+
+func TestReload(t *testing.T) {
+	t.Run("reload applies edited role permissions from a policy dir", func(t *testing.T) {
+		dir := writePolicyDir(t)
+		engine, err := NewEngine()
+		require.NoError(t, err)
+		engine.SetLoader(NewDirLoader(dir))
+		require.NoError(t, engine.Reload())
+		assert.NotContains(t, engine.GetRolesWithPermission("resource.dashboard.delete", false), "editor")
+
+		rolesFile := filepath.Join(dir, "roles", "resource_roles.json")
+		var perms map[string][]string
+		data, err := os.ReadFile(rolesFile)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &perms))
+		perms["editor"] = append(perms["editor"], "resource.dashboard.delete")
+		data, err = json.Marshal(perms)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(rolesFile, data, 0o644))
+
+		before := engine.Summary().LoadedAt
+		require.NoError(t, engine.Reload())
+		assert.Contains(t, engine.GetRolesWithPermission("resource.dashboard.delete", false), "editor")
+		assert.False(t, engine.Summary().LoadedAt.Before(before))
+	})
+
+	t.Run("failed reload keeps the current policies", func(t *testing.T) {
+		dir := writePolicyDir(t)
+		engine, err := NewEngine()
+		require.NoError(t, err)
+		summary := engine.Summary()
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "roles", "system_roles.json"), []byte("{"), 0o644))
+		engine.SetLoader(NewDirLoader(dir))
+		err = engine.Reload()
+		assert.ErrorContains(t, err, "system_roles.json")
+		assert.Equal(t, summary, engine.Summary())
+		assert.Contains(t, engine.GetRolesWithPermission("platform.system.add_member", true), "owner")
+	})
+
+	t.Run("summary counts loaded entities, operations and roles", func(t *testing.T) {
+		engine, err := NewEngine()
+		require.NoError(t, err)
+
+		summary := engine.Summary()
+		assert.Equal(t, len(engine.GetEntityPolicies()), summary.Entities)
+		assert.Equal(t, len(engine.systemRolePerms), summary.SystemRoles)
+		assert.Equal(t, len(engine.resourceRolePerms), summary.ResourceRoles)
+		assert.Greater(t, summary.Operations, summary.Entities)
+		assert.False(t, summary.LoadedAt.IsZero())
+	})
+}
+
+// TestReloadConcurrentChecks reloads while permission checks run; run with -race to catch unguarded reads
+func TestReloadConcurrentChecks(t *testing.T) {
+	engine, err := NewEngine()
+	require.NoError(t, err)
+	repo := heldRolesRepo{held: []string{"editor"}}
+	roles := []*model.UserRole{{UserID: "user_1", Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_1"}}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				allowed, err := engine.CheckResourceAccess(context.Background(), repo, "user_1", "", "d1", "dashboard", "resource.dashboard.update", "")
+				assert.NoError(t, err)
+				assert.True(t, allowed)
+				allowed, err = engine.CheckOperationPermission(context.Background(), repo, &OperationRequest{
+					CallerID: "user_1", Entity: "dashboard", Operation: "delete_resource", ResourceID: "d1", ResourceType: "dashboard",
+				})
+				assert.NoError(t, err)
+				assert.False(t, allowed)
+				assert.True(t, engine.CheckRolesHavePermission(roles, "platform.system.add_member"))
+				assert.NotEmpty(t, engine.APIConfigs()["POST:/api/v1/user_roles"])
+				assert.NotEmpty(t, engine.GetRolePermissions("owner", true))
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, engine.Reload())
+	}
+	close(stop)
+	wg.Wait()
+}
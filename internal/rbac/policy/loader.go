@@ -4,24 +4,37 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 )
 
 //go:embed policies/operations/*.json policies/check_permission.json policies/roles/*.json
 var policiesFS embed.FS
 
-// Loader loads policy configurations from embedded JSON files
-type Loader struct{}
+// Loader loads policy configurations from JSON files: operations/*.json, check_permission.json
+// and roles/*.json under its root
+type Loader struct {
+	fsys fs.FS
+}
 
+// NewLoader loads the policies embedded in the binary
 func NewLoader() *Loader {
-	return &Loader{}
+	fsys, _ := fs.Sub(policiesFS, "policies") // constant, valid path
+	return &Loader{fsys: fsys}
+}
+
+// NewDirLoader loads the policies from a directory laid out like the embedded policies, so they
+// can be edited and reloaded without a redeploy
+func NewDirLoader(dir string) *Loader {
+	return &Loader{fsys: os.DirFS(dir)}
 }
 
 // LoadEntityPolicies loads all entity operation policies
 func (l *Loader) LoadEntityPolicies() (map[string]*EntityPolicy, error) {
 	policies := make(map[string]*EntityPolicy)
 
-	entries, err := policiesFS.ReadDir("policies/operations")
+	entries, err := fs.ReadDir(l.fsys, "operations")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read policies directory: %w", err)
 	}
@@ -31,7 +44,7 @@ func (l *Loader) LoadEntityPolicies() (map[string]*EntityPolicy, error) {
 			continue
 		}
 
-		data, err := policiesFS.ReadFile("policies/operations/" + entry.Name())
+		data, err := fs.ReadFile(l.fsys, "operations/"+entry.Name())
 		if err != nil {
 			return nil, fmt.Errorf("failed to read policy file %s: %w", entry.Name(), err)
 		}
@@ -49,7 +62,7 @@ func (l *Loader) LoadEntityPolicies() (map[string]*EntityPolicy, error) {
 
 // LoadCheckPermissionConfig loads the check permission configuration
 func (l *Loader) LoadCheckPermissionConfig() (*CheckPermissionConfig, error) {
-	data, err := policiesFS.ReadFile("policies/check_permission.json")
+	data, err := fs.ReadFile(l.fsys, "check_permission.json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read check_permission.json: %w", err)
 	}
@@ -64,7 +77,7 @@ func (l *Loader) LoadCheckPermissionConfig() (*CheckPermissionConfig, error) {
 
 // LoadSystemRolePermissions loads system role permissions from JSON
 func (l *Loader) LoadSystemRolePermissions() (map[string][]string, error) {
-	data, err := policiesFS.ReadFile("policies/roles/system_roles.json")
+	data, err := fs.ReadFile(l.fsys, "roles/system_roles.json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read system_roles.json: %w", err)
 	}
@@ -79,7 +92,7 @@ func (l *Loader) LoadSystemRolePermissions() (map[string][]string, error) {
 
 // LoadResourceRolePermissions loads resource role permissions from JSON
 func (l *Loader) LoadResourceRolePermissions() (map[string][]string, error) {
-	data, err := policiesFS.ReadFile("policies/roles/resource_roles.json")
+	data, err := fs.ReadFile(l.fsys, "roles/resource_roles.json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read resource_roles.json: %w", err)
 	}
@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"strings"
 	"testing"
 
 	"rbac7/internal/rbac/model"
@@ -270,3 +271,66 @@ func TestRolePermissionCounts(t *testing.T) {
 		}
 	})
 }
+
+// TestWildcardPermissions tests that a wildcard grant implies every permission of its segments
+// and nothing outside them, checked against all permissions loaded from JSON
+func TestWildcardPermissions(t *testing.T) {
+	engine, err := NewEngine()
+	assert.NoError(t, err)
+	engine.resourceRolePerms["dashboard_manager"] = []string{"resource.dashboard.*"}
+	engine.systemRolePerms["resource_reader"] = []string{"system.resource.read", "platform.*.read"}
+
+	loader := NewLoader()
+	resourceRolePerms, err := loader.LoadResourceRolePermissions()
+	assert.NoError(t, err)
+	systemRolePerms, err := loader.LoadSystemRolePermissions()
+	assert.NoError(t, err)
+
+	for _, perm := range extractUniquePermissions(resourceRolePerms) {
+		t.Run("dashboard_manager/"+perm, func(t *testing.T) {
+			roles := []*model.UserRole{
+				{UserID: "user1", Role: "dashboard_manager", Scope: model.ScopeResource, ResourceID: "res1", ResourceType: "dashboard"},
+			}
+			segs := strings.Split(perm, ".")
+			expected := len(segs) == 3 && segs[0] == "resource" && segs[1] == "dashboard"
+			assert.Equal(t, expected, engine.CheckRolesHavePermission(roles, perm),
+				"resource.dashboard.* should grant %s = %v", perm, expected)
+		})
+	}
+
+	for _, perm := range extractUniquePermissions(systemRolePerms) {
+		t.Run("resource_reader/"+perm, func(t *testing.T) {
+			roles := []*model.UserRole{
+				{UserID: "user1", Role: "resource_reader", Scope: model.ScopeSystem, Namespace: "ns1"},
+			}
+			segs := strings.Split(perm, ".")
+			expected := perm == "system.resource.read" || (len(segs) == 3 && segs[0] == "platform" && segs[2] == "read")
+			assert.Equal(t, expected, engine.CheckRolesHavePermission(roles, perm),
+				"platform.*.read should grant %s = %v", perm, expected)
+		})
+	}
+
+	t.Run("wildcard grant is returned for sub-permissions", func(t *testing.T) {
+		assert.Contains(t, engine.GetRolesWithPermission("resource.dashboard.read", false), "dashboard_manager")
+		assert.Contains(t, engine.GetRolesWithPermission("resource.dashboard.publish", false), "dashboard_manager")
+	})
+
+	t.Run("wildcard is segment-aware", func(t *testing.T) {
+		cases := map[string]bool{
+			"resource.dashboard.read":            true,
+			"resource.dashboard.custom":          true,
+			"resource.dashboard_widget.read":     false,
+			"resource.dashboards.read":           false,
+			"resource.dashboard":                 false,
+			"resource.dashboard.":                false,
+			"resource.dashboard.share.link":      false,
+			"platform.dashboard.read":            false,
+			"resource.library_widget.get_member": false,
+		}
+		for perm, expected := range cases {
+			assert.Equal(t, expected, permissionMatches("resource.dashboard.*", perm), perm)
+		}
+		assert.False(t, permissionMatches("resource.dashboard.read", "resource.dashboard.*"),
+			"a specific grant does not cover a wildcard check")
+	})
+}
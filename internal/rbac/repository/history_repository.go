@@ -14,6 +14,8 @@ type HistoryRepository interface {
 	FindHistory(ctx context.Context, req model.GetUserRoleHistoryReq) ([]*model.UserRoleHistory, int64, error)
 	// EnsureHistoryIndexes creates indexes for efficient querying
 	EnsureHistoryIndexes(ctx context.Context) error
+	// WithHistory runs write and appends history in one transaction, so history order matches commit order
+	WithHistory(ctx context.Context, history *model.UserRoleHistory, write func(ctx context.Context) error) error
 }
 
 // HistoryEntry is a helper struct for creating history records
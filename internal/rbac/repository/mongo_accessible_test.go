@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCountAccessibleResourcesByType(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("counts per resource type", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "dashboard"}, {Key: "count", Value: int32(3)}},
+			bson.D{{Key: "_id", Value: "library_widget"}, {Key: "count", Value: int32(2)}},
+		))
+
+		counts, err := repo.CountAccessibleResourcesByType(context.Background(), "user_1")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"dashboard": 3, "library_widget": 2}, counts)
+
+		cmd := mt.GetStartedEvent().Command
+		stages, _ := cmd.Lookup("pipeline").Array().Values()
+		match := stages[0].Document().Lookup("$match").Document()
+		assert.Equal(t, "user_1", match.Lookup("user_id").StringValue())
+		assert.Equal(t, "resource", match.Lookup("scope").StringValue())
+		assert.Equal(t, "$_id.resource_type", stages[2].Document().Lookup("$group", "_id").StringValue())
+	})
+
+	mt.Run("merges routed collections", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RouteResourceTypes(map[string]string{"library_widget": "library_widget_roles"})
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".user_resource_roles", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: "dashboard"}, {Key: "count", Value: int32(4)}}),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".library_widget_roles", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: "library_widget"}, {Key: "count", Value: int32(1)}}),
+		)
+
+		counts, err := repo.CountAccessibleResourcesByType(context.Background(), "user_1")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"dashboard": 4, "library_widget": 1}, counts)
+	})
+}
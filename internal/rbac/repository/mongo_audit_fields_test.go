@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestAuditFields(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("upsert then list returns the stored audit fields", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "r_1"}}}}))
+
+		before := time.Now()
+		err := repo.UpsertUserRole(context.Background(), &model.UserRole{
+			UserID: "u1", UserType: model.UserTypeMember, Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1",
+			CreatedBy: "owner_1", UpdatedBy: "owner_1",
+		})
+		require.NoError(t, err)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		set := update.Lookup("$set").Document()
+		assert.Equal(t, "owner_1", set.Lookup("created_by").StringValue())
+		assert.Equal(t, "owner_1", set.Lookup("updated_by").StringValue())
+		updatedAt := set.Lookup("updated_at").Time()
+		createdAt := update.Lookup("$setOnInsert", "created_at").Time()
+		assert.False(t, updatedAt.Before(before.Truncate(time.Millisecond)))
+		assert.Equal(t, updatedAt, createdAt, "a new role is created and updated at the same time")
+
+		// The listing reads back the document as the upsert wrote it
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "r_1"},
+			{Key: "user_id", Value: "u1"},
+			{Key: "user_type", Value: model.UserTypeMember},
+			{Key: "role", Value: "viewer"},
+			{Key: "scope", Value: model.ScopeSystem},
+			{Key: "namespace", Value: "NS_1"},
+			{Key: "created_at", Value: createdAt},
+			{Key: "updated_at", Value: updatedAt},
+			{Key: "created_by", Value: "owner_1"},
+			{Key: "updated_by", Value: "owner_1"},
+		}))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeSystem, Namespace: "NS_1"})
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.True(t, roles[0].CreatedAt.Equal(createdAt))
+		assert.True(t, roles[0].UpdatedAt.Equal(updatedAt))
+		assert.Equal(t, "owner_1", roles[0].CreatedBy)
+		assert.Equal(t, "owner_1", roles[0].UpdatedBy)
+
+		// No projection: every audit field is read
+		_, err = mt.GetStartedEvent().Command.LookupErr("projection")
+		assert.Error(t, err)
+	})
+}
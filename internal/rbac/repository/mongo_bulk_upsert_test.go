@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBulkUpsertUserRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	viewer := func(userID string) *model.UserRole {
+		return &model.UserRole{UserID: userID, UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: "dashboard"}
+	}
+
+	mt.Run("all upserts succeed in one unordered bulk write", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 0}))
+
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{viewer("u1"), viewer("u2")})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.SuccessCount)
+		assert.Equal(t, 0, result.FailedCount)
+		assert.Empty(t, result.FailedUsers)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.False(t, cmd.Lookup("ordered").Boolean())
+		updates, _ := cmd.Lookup("updates").Array().Values()
+		assert.Len(t, updates, 2)
+		for _, u := range updates {
+			assert.True(t, u.Document().Lookup("upsert").Boolean())
+			assert.Equal(t, model.RoleResourceOwner, u.Document().Lookup("q", "role", "$ne").StringValue(), "owners are never overwritten")
+		}
+	})
+
+	mt.Run("an owner in the batch fails alone as owner protected", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}),
+		)
+
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{viewer("u1"), viewer("owner_1"), viewer("u3")})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.SuccessCount)
+		assert.Equal(t, 1, result.FailedCount)
+		assert.Equal(t, []model.FailedUserInfo{{UserID: "owner_1", Reason: ownerProtectedReason}}, result.FailedUsers)
+
+		mt.GetStartedEvent() // bulk write
+		count := mt.GetStartedEvent().Command
+		assert.Equal(t, "owner_1", count.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match", "user_id").StringValue())
+	})
+
+	mt.Run("other write errors keep the server message", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(
+				mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"},
+				mtest.WriteError{Index: 1, Code: 121, Message: "document failed validation"},
+			),
+			mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch),
+		)
+
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{viewer("u1"), viewer("u2")})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result.SuccessCount)
+		assert.Equal(t, []model.FailedUserInfo{
+			{UserID: "u1", Reason: "duplicate key"},
+			{UserID: "u2", Reason: "document failed validation"},
+		}, result.FailedUsers)
+	})
+
+	mt.Run("a failed bulk write is returned as an error", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 91, Message: "shutdown in progress"}))
+
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{viewer("u1")})
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	mt.Run("system roles go to the system collection", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		role := &model.UserRole{UserID: "u1", UserType: model.UserTypeMember, Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_1"}
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{role})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.SuccessCount)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("update").StringValue())
+		updates, _ := cmd.Lookup("updates").Array().Values()
+		assert.Equal(t, model.RoleSystemOwner, updates[0].Document().Lookup("q", "role", "$ne").StringValue())
+	})
+}
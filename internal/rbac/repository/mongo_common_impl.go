@@ -3,97 +3,301 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"rbac7/internal/rbac/model"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 type MongoRepository struct {
 	SystemRoles   *mongo.Collection
 	ResourceRoles *mongo.Collection
-	History       *mongo.Collection
-	Client        *mongo.Client // Added Client for transactions
+	// ResourceTypeRoles holds dedicated collections for routed resource types (key: resource_type)
+	ResourceTypeRoles map[string]*mongo.Collection
+	History           *mongo.Collection
+	// ErasureLog is the append-only record of user erasures
+	ErasureLog *mongo.Collection
+	// NamespaceResourceRoles holds per-namespace overrides of assignable resource roles (keyed by namespace)
+	NamespaceResourceRoles *mongo.Collection
+	Client                 *mongo.Client // Added Client for transactions
+	// NamespacedResources adds namespace to the resource unique key so the same
+	// resource ID can hold roles independently in different namespaces
+	NamespacedResources bool
+	// MultiRole adds role to the unique keys so a user can hold several roles in one namespace or
+	// resource. Assigning then adds a role instead of replacing it, and removing a member removes all
+	// of their roles. Permission checks already match any held role.
+	MultiRole bool
+	// PendingOwners makes an ownership transfer to a user without a member role on the target create
+	// the owner with user_type pending until ActivatePendingRole. Pending roles are ignored by
+	// permission checks unless PendingOwnersActive is set.
+	PendingOwners       bool
+	PendingOwnersActive bool
+	// MultipleOwners drops the unique system owner index so a namespace can have several owners
+	MultipleOwners bool
+	// Standalone disables multi-document transactions for deployments without a replica set;
+	// multi-step writes then run sequentially and are not atomic
+	Standalone bool
+	// ReadPreference routes permission checks (HasAny*Role) and listing reads (e.g. to secondaries); nil reads
+	// from the client default. Owner lookups and single-role checks that guard writes always read the primary.
+	ReadPreference *readpref.ReadPref
+	// TxnMaxRetries caps how often a transaction is retried after a transient error (e.g. a write
+	// conflict under contention); TxnTimeout bounds a transaction including its retries (0: unbounded)
+	TxnMaxRetries int
+	TxnTimeout    time.Duration
+	// SoftDeleteGracePeriod makes re-adding a user whose role was soft deleted less than this long
+	// ago fail with ErrRecentlyRemoved (0 allows immediate re-adding)
+	SoftDeleteGracePeriod time.Duration
+	// ExpiredRoleRetention adds a TTL index so MongoDB removes temporary grants this long after they
+	// expire (0 keeps them). Permission checks ignore expired roles either way, including while the
+	// TTL monitor has not yet removed them.
+	ExpiredRoleRetention time.Duration
+	// RejectExistingRole makes UpsertUserRole fail with ErrAlreadyHasRole when the user already holds
+	// the assigned role (active, unexpired) instead of refreshing it, without writing anything.
+	// BulkUpsertUserRoles reports such users as failed.
+	RejectExistingRole bool
 }
 
+// Transaction limits used unless configured otherwise
+const (
+	DefaultTxnMaxRetries = 3
+	DefaultTxnTimeout    = 10 * time.Second
+)
+
+// Server error labels marking a transaction as safe to retry
+const (
+	labelTransientTransaction = "TransientTransactionError"
+	labelUnknownCommitResult  = "UnknownTransactionCommitResult"
+)
+
 func NewMongoRepository(db *mongo.Database, systemCollectionName, resourceCollectionName string) *MongoRepository {
 	repo := &MongoRepository{
-		SystemRoles:   db.Collection(systemCollectionName),
-		ResourceRoles: db.Collection(resourceCollectionName),
-		History:       db.Collection("user_role_history"),
-		Client:        db.Client(),
+		SystemRoles:            db.Collection(systemCollectionName),
+		ResourceRoles:          db.Collection(resourceCollectionName),
+		ResourceTypeRoles:      make(map[string]*mongo.Collection),
+		History:                db.Collection("user_role_history"),
+		ErasureLog:             db.Collection("user_erasure_log"),
+		NamespaceResourceRoles: db.Collection("namespace_resource_roles"),
+		Client:                 db.Client(),
+		TxnMaxRetries:          DefaultTxnMaxRetries,
+		TxnTimeout:             DefaultTxnTimeout,
 	}
 	return repo
 }
 
+// RouteResourceTypes stores roles of the given resource types in dedicated collections.
+// collections maps resource_type -> collection name; unlisted types stay in ResourceRoles.
+func (r *MongoRepository) RouteResourceTypes(collections map[string]string) {
+	for resourceType, name := range collections {
+		r.ResourceTypeRoles[resourceType] = r.ResourceRoles.Database().Collection(name)
+	}
+}
+
+// resourceCollection returns the collection holding roles of the given resource type
+func (r *MongoRepository) resourceCollection(resourceType string) *mongo.Collection {
+	if coll, ok := r.ResourceTypeRoles[resourceType]; ok {
+		return coll
+	}
+	return r.ResourceRoles
+}
+
+// reader returns coll configured with ReadPreference for lag-tolerant reads.
+// Reads inside a transaction keep the collection as is, since transactions must read the primary.
+func (r *MongoRepository) reader(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	if r.ReadPreference == nil || mongo.SessionFromContext(ctx) != nil {
+		return coll
+	}
+	clone, err := coll.Clone(options.Collection().SetReadPreference(r.ReadPreference))
+	if err != nil {
+		return coll
+	}
+	return clone
+}
+
+// resourceCollections returns the collections to query for a resource type.
+// An empty resource type means the type is unknown, so every resource collection is returned.
+func (r *MongoRepository) resourceCollections(resourceType string) []*mongo.Collection {
+	if resourceType != "" {
+		return []*mongo.Collection{r.resourceCollection(resourceType)}
+	}
+	colls := []*mongo.Collection{r.ResourceRoles}
+	seen := map[string]bool{r.ResourceRoles.Name(): true}
+	for _, coll := range r.ResourceTypeRoles {
+		if !seen[coll.Name()] {
+			seen[coll.Name()] = true
+			colls = append(colls, coll)
+		}
+	}
+	return colls
+}
+
 func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
-	// 1. System Roles Index: (user_id, user_type, scope, namespace) unique
+	idxSystemUnique, idxSystemOwner := r.systemUniqueIndexes()
+
+	// 5. Sync Indexes: modified_since matches updated_at or deleted_at
+	idxUpdatedAt := mongo.IndexModel{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}},
+		Options: options.Index().SetName("idx_updated_at"),
+	}
+	idxDeletedAt := mongo.IndexModel{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetName("idx_deleted_at").SetSparse(true),
+	}
+
+	shared := []mongo.IndexModel{idxUpdatedAt, idxDeletedAt}
+	// 6. Expiry Index: removes temporary grants ExpiredRoleRetention after expires_at.
+	// Changing the retention requires dropping the index (or collMod), like the other index options.
+	if r.ExpiredRoleRetention > 0 {
+		shared = append(shared, mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(int32(r.ExpiredRoleRetention / time.Second)),
+		})
+	}
+
+	_, err := r.SystemRoles.Indexes().CreateMany(ctx, append([]mongo.IndexModel{idxSystemUnique, idxSystemOwner}, shared...))
+	if err != nil {
+		return err
+	}
+
+	idxResourceUnique, idxResourceOwner := r.resourceUniqueIndexes()
+	for _, coll := range r.resourceCollections("") {
+		if _, err := coll.Indexes().CreateMany(ctx, append([]mongo.IndexModel{idxResourceUnique, idxResourceOwner}, shared...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// systemUniqueIndexes returns the system unique indexes for the configured key strategy
+func (r *MongoRepository) systemUniqueIndexes() (mongo.IndexModel, mongo.IndexModel) {
+	// 1. System Roles Index: (user_id, user_type, scope, namespace[, role]) unique
 	// "uniq_user_per_namespace_scope"
+	systemKeys := bson.D{
+		{Key: "user_id", Value: 1},
+		{Key: "user_type", Value: 1},
+		{Key: "scope", Value: 1},
+		{Key: "namespace", Value: 1},
+	}
+	systemName := "uniq_user_per_namespace_scope"
+	if r.MultiRole {
+		systemKeys = append(systemKeys, bson.E{Key: "role", Value: 1})
+		systemName = "uniq_user_role_per_namespace_scope"
+	}
 	idxSystemUnique := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "user_id", Value: 1},
-			{Key: "user_type", Value: 1},
-			{Key: "scope", Value: 1},
-			{Key: "namespace", Value: 1},
-		},
-		Options: options.Index().SetUnique(true).SetName("uniq_user_per_namespace_scope"),
+		Keys:    systemKeys,
+		Options: options.Index().SetUnique(true).SetName(systemName),
 	}
 
-	// 2. System Owner Index: (scope, namespace, role) unique where role="owner"
+	// 2. System Owner Index: (scope, namespace, role) unique where role="owner".
+	// With MultipleOwners it only speeds up owner lookups; switching modes requires dropping the other index.
+	ownerOpts := options.Index().
+		SetUnique(true).
+		SetName("unique_system_owner_v2")
+	if r.MultipleOwners {
+		ownerOpts = options.Index().SetName("idx_system_owners")
+	}
 	idxSystemOwner := mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "scope", Value: 1},
 			{Key: "namespace", Value: 1},
 		},
-		Options: options.Index().
-			SetUnique(true).
-			SetName("unique_system_owner_v2").
-			SetPartialFilterExpression(bson.M{
-				"scope":      model.ScopeSystem,
-				"role":       model.RoleSystemOwner,
-				"deleted_at": nil,
-			}),
+		Options: ownerOpts.SetPartialFilterExpression(bson.M{
+			"scope":      model.ScopeSystem,
+			"role":       model.RoleSystemOwner,
+			"deleted_at": nil,
+		}),
 	}
+	return idxSystemUnique, idxSystemOwner
+}
 
-	_, err := r.SystemRoles.Indexes().CreateMany(ctx, []mongo.IndexModel{idxSystemUnique, idxSystemOwner})
-	if err != nil {
-		return err
+// resourceUniqueIndexes returns the resource unique indexes for the configured key strategy.
+// The namespaced and multi-role variants use different names, so switching strategy requires
+// dropping the old indexes.
+func (r *MongoRepository) resourceUniqueIndexes() (mongo.IndexModel, mongo.IndexModel) {
+	// 3. Resource Roles Index: (user_id, user_type, scope, [namespace,] resource_type, resource_id[, role]) unique
+	uniqueKeys := bson.D{
+		{Key: "user_id", Value: 1},
+		{Key: "user_type", Value: 1},
+		{Key: "scope", Value: 1},
+	}
+	// 4. Resource Owner Unique Index: (scope, [namespace,] resource_id, resource_type)
+	ownerKeys := bson.D{
+		{Key: "scope", Value: 1},
 	}
+	uniquePrefix := "uniq_user_"
+	if r.MultiRole {
+		uniquePrefix = "uniq_user_role_"
+	}
+	uniqueName := uniquePrefix + "per_resource_scope"
+	ownerName := "unique_resource_owner"
+	if r.NamespacedResources {
+		uniqueKeys = append(uniqueKeys, bson.E{Key: "namespace", Value: 1})
+		ownerKeys = append(ownerKeys, bson.E{Key: "namespace", Value: 1})
+		uniqueName = uniquePrefix + "per_namespace_resource_scope"
+		ownerName = "unique_namespace_resource_owner"
+	}
+	uniqueKeys = append(uniqueKeys, bson.E{Key: "resource_type", Value: 1}, bson.E{Key: "resource_id", Value: 1})
+	if r.MultiRole {
+		uniqueKeys = append(uniqueKeys, bson.E{Key: "role", Value: 1})
+	}
+	ownerKeys = append(ownerKeys, bson.E{Key: "resource_id", Value: 1}, bson.E{Key: "resource_type", Value: 1})
 
-	// 3. Resource Roles Index: (user_id, user_type, scope, resource_type, resource_id) unique
-	// "uniq_user_per_resource_scope"
 	idxResourceUnique := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "user_id", Value: 1},
-			{Key: "user_type", Value: 1},
-			{Key: "scope", Value: 1},
-			{Key: "resource_type", Value: 1},
-			{Key: "resource_id", Value: 1},
-		},
-		Options: options.Index().SetUnique(true).SetName("uniq_user_per_resource_scope"),
+		Keys:    uniqueKeys,
+		Options: options.Index().SetUnique(true).SetName(uniqueName),
 	}
-
-	// 4. Resource Owner Unique Index
 	idxResourceOwner := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "scope", Value: 1},
-			{Key: "resource_id", Value: 1},
-			{Key: "resource_type", Value: 1},
-		},
+		Keys: ownerKeys,
 		Options: options.Index().
 			SetUnique(true).
-			SetName("unique_resource_owner").
+			SetName(ownerName).
 			SetPartialFilterExpression(bson.M{
 				"scope":      model.ScopeResource,
 				"role":       model.RoleResourceOwner,
 				"deleted_at": nil,
 			}),
 	}
+	return idxResourceUnique, idxResourceOwner
+}
 
-	_, err = r.ResourceRoles.Indexes().CreateMany(ctx, []mongo.IndexModel{idxResourceUnique, idxResourceOwner})
-	return err
+// RepositoryHealth checks that every role collection answers a query and carries its unique indexes,
+// so a reachable but mis-provisioned database is reported as not ready
+func (r *MongoRepository) RepositoryHealth(ctx context.Context) error {
+	idxSystemUnique, idxSystemOwner := r.systemUniqueIndexes()
+	if err := collectionHealth(ctx, r.SystemRoles, idxSystemUnique, idxSystemOwner); err != nil {
+		return err
+	}
+	idxResourceUnique, idxResourceOwner := r.resourceUniqueIndexes()
+	for _, coll := range r.resourceCollections("") {
+		if err := collectionHealth(ctx, coll, idxResourceUnique, idxResourceOwner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectionHealth(ctx context.Context, coll *mongo.Collection, required ...mongo.IndexModel) error {
+	if _, err := coll.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1)); err != nil {
+		return fmt.Errorf("%w: %s is not queryable: %v", ErrUnhealthy, coll.Name(), err)
+	}
+
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %s indexes cannot be listed: %v", ErrUnhealthy, coll.Name(), err)
+	}
+	present := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		present[spec.Name] = true
+	}
+	for _, idx := range required {
+		if name := *idx.Options.Name; !present[name] {
+			return fmt.Errorf("%w: %s is missing index %s", ErrUnhealthy, coll.Name(), name)
+		}
+	}
+	return nil
 }
 
 func (r *MongoRepository) CreateUserRole(ctx context.Context, role *model.UserRole) error {
@@ -104,7 +308,7 @@ func (r *MongoRepository) CreateUserRole(ctx context.Context, role *model.UserRo
 	if role.Scope == model.ScopeSystem {
 		coll = r.SystemRoles
 	} else if role.Scope == model.ScopeResource {
-		coll = r.ResourceRoles
+		coll = r.resourceCollection(role.ResourceType)
 	} else {
 		return errors.New("invalid scope")
 	}
@@ -132,15 +336,308 @@ func (r *MongoRepository) UpsertUserRole(ctx context.Context, role *model.UserRo
 	} else if role.Scope == model.ScopeResource {
 		filter["resource_id"] = role.ResourceID
 		filter["resource_type"] = role.ResourceType
+		if r.NamespacedResources {
+			filter["namespace"] = role.Namespace
+		}
 		// For resource scope, also protect resource owner if needed
 		if role.Scope == model.ScopeResource {
 			filter["role"] = bson.M{"$ne": model.RoleResourceOwner}
 		}
 	}
 
+	r.keyOnRole(filter, role)
+
 	now := time.Now()
 	role.UpdatedAt = now
+	r.skipRecentlyRemoved(filter, now)
+	r.skipHeldRole(filter, role, now)
+
+	update := roleUpdate(role, now)
+	opts := options.Update().SetUpsert(true)
+
+	var coll *mongo.Collection
+	if role.Scope == model.ScopeSystem {
+		coll = r.SystemRoles
+	} else {
+		coll = r.resourceCollection(role.ResourceType)
+	}
+
+	_, err := coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil && mongo.IsDuplicateKeyError(err) {
+		if r.recentlyRemoved(ctx, coll, filter, now) {
+			return ErrRecentlyRemoved
+		}
+		if r.holdsRole(ctx, coll, filter, role, now) {
+			return ErrAlreadyHasRole
+		}
+	}
+	return err
+}
+
+// keyOnRole matches the upsert on the assigned role in MultiRole mode, so assigning adds a role
+// document instead of replacing the user's current role. Owner roles never match an assignable role.
+func (r *MongoRepository) keyOnRole(filter bson.M, role *model.UserRole) {
+	if r.MultiRole {
+		filter["role"] = role.Role
+	}
+}
+
+// keyOnNamespace scopes a resource filter to namespace when NamespacedResources is set, since the
+// same resource ID may then exist in several namespaces
+func (r *MongoRepository) keyOnNamespace(filter bson.M, namespace string) bson.M {
+	if r.NamespacedResources {
+		filter["namespace"] = namespace
+	}
+	return filter
+}
+
+// ownerUserTypes matches the user types an owner document may have
+func (r *MongoRepository) ownerUserTypes() interface{} {
+	if r.PendingOwners {
+		return bson.M{"$in": []string{model.UserTypeMember, model.UserTypePending}}
+	}
+	return model.UserTypeMember
+}
+
+// promoteOwner applies the new owner's update to their member role, creating it if missing.
+// With PendingOwners, a user without a member role gets a pending owner role instead.
+func (r *MongoRepository) promoteOwner(ctx context.Context, coll *mongo.Collection, filter, update bson.M) error {
+	if !r.PendingOwners {
+		_, err := coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		return err
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	filter["user_type"] = model.UserTypePending
+	update["$set"].(bson.M)["user_type"] = model.UserTypePending
+	_, err = coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// excludePending drops pending roles from a permission check unless they are configured active
+func (r *MongoRepository) excludePending(filter bson.M) bson.M {
+	if r.PendingOwners && !r.PendingOwnersActive {
+		filter["user_type"] = bson.M{"$ne": model.UserTypePending}
+	}
+	return filter
+}
+
+// FindPendingRoles returns the user's pending roles in every role collection. It runs outside a
+// transaction, so the common case of a user without pending roles costs a read per collection.
+func (r *MongoRepository) FindPendingRoles(ctx context.Context, userID string) ([]*model.UserRole, error) {
+	var pending []*model.UserRole
+	for _, coll := range append([]*mongo.Collection{r.SystemRoles}, r.resourceCollections("")...) {
+		cursor, err := coll.Find(ctx, bson.M{"user_id": userID, "user_type": model.UserTypePending})
+		if err != nil {
+			return nil, err
+		}
+		var roles []*model.UserRole
+		if err := cursor.All(ctx, &roles); err != nil {
+			return nil, err
+		}
+		pending = append(pending, roles...)
+	}
+	return pending, nil
+}
+
+// ActivatePendingRole turns a pending role into a member role; mongo.ErrNoDocuments when it is no
+// longer pending. A member role given to the user while they were pending collides with the activated
+// role on the unique key; it is removed, as the owner role supersedes it.
+func (r *MongoRepository) ActivatePendingRole(ctx context.Context, role *model.UserRole) error {
+	coll := r.SystemRoles
+	if role.Scope != model.ScopeSystem {
+		coll = r.resourceCollection(role.ResourceType)
+	}
+	return r.inTransaction(ctx, func(sessCtx context.Context) error {
+		key := r.roleKey(role)
+		key["user_type"] = model.UserTypeMember
+		if _, err := coll.DeleteOne(sessCtx, key); err != nil {
+			return err
+		}
+		key["user_type"] = model.UserTypePending
+		res, err := coll.UpdateOne(sessCtx, key, bson.M{"$set": bson.M{
+			"user_type":  model.UserTypeMember,
+			"updated_at": time.Now(),
+			"updated_by": role.UserID,
+		}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	})
+}
+
+// roleKey returns the unique-index key of role, without user_type
+func (r *MongoRepository) roleKey(role *model.UserRole) bson.M {
+	key := bson.M{"user_id": role.UserID, "scope": role.Scope}
+	if role.Scope == model.ScopeSystem {
+		key["namespace"] = role.Namespace
+	} else {
+		key["resource_id"] = role.ResourceID
+		key["resource_type"] = role.ResourceType
+		if r.NamespacedResources {
+			key["namespace"] = role.Namespace
+		}
+	}
+	if r.MultiRole {
+		key["role"] = role.Role
+	}
+	return key
+}
+
+// demoteOwner turns the owner role matched by filter into demotedRole and reports whether it matched.
+// In MultiRole mode the old owner may already hold demotedRole, so the owner document is soft deleted
+// and demotedRole is upserted as a document of its own.
+func (r *MongoRepository) demoteOwner(ctx context.Context, coll *mongo.Collection, filter bson.M, demotedRole, updatedBy string, now time.Time) (bool, error) {
+	if !r.MultiRole {
+		res, err := coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+			"role":       demotedRole,
+			"updated_at": now,
+			"updated_by": updatedBy,
+		}})
+		if err != nil {
+			return false, err
+		}
+		return res.MatchedCount > 0, nil
+	}
+
+	var owner model.UserRole
+	err := coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{
+		"deleted_at": now,
+		"deleted_by": updatedBy,
+	}}).Decode(&owner)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	demotedFilter := bson.M{"role": demotedRole}
+	for key, value := range filter {
+		if key != "role" && key != "deleted_at" {
+			demotedFilter[key] = value
+		}
+	}
+	if _, ok := demotedFilter["user_type"]; ok {
+		demotedFilter["user_type"] = owner.UserType // filter may match several user types
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"updated_at": now,
+			"updated_by": updatedBy,
+		},
+		"$setOnInsert": bson.M{
+			"namespace":          owner.Namespace,
+			"parent_resource_id": owner.ParentResourceID,
+			"created_at":         now,
+			"created_by":         updatedBy,
+		},
+		"$unset": bson.M{
+			"deleted_at": "",
+			"deleted_by": "",
+		},
+	}
+	if _, ok := demotedFilter["namespace"]; ok {
+		delete(update["$setOnInsert"].(bson.M), "namespace")
+	}
+	_, err = coll.UpdateOne(ctx, demotedFilter, update, options.Update().SetUpsert(true))
+	return err == nil, err
+}
+
+// skipRecentlyRemoved narrows an upsert filter so it does not revive a role soft deleted within
+// SoftDeleteGracePeriod. The upsert then inserts instead and hits the unique index.
+func (r *MongoRepository) skipRecentlyRemoved(filter bson.M, now time.Time) {
+	if r.SoftDeleteGracePeriod <= 0 {
+		return
+	}
+	filter["$or"] = bson.A{
+		bson.M{"deleted_at": nil},
+		bson.M{"deleted_at": bson.M{"$lte": now.Add(-r.SoftDeleteGracePeriod)}},
+	}
+}
+
+// recentlyRemoved reports whether the role matched by an upsert filter was soft deleted within
+// SoftDeleteGracePeriod, i.e. whether a duplicate key error of the upsert means ErrRecentlyRemoved
+func (r *MongoRepository) recentlyRemoved(ctx context.Context, coll *mongo.Collection, filter bson.M, now time.Time) bool {
+	if r.SoftDeleteGracePeriod <= 0 {
+		return false
+	}
+	query := bson.M{"deleted_at": bson.M{"$gt": now.Add(-r.SoftDeleteGracePeriod)}}
+	for key, value := range filter {
+		if key != "$or" && key != "$nor" && key != "role" {
+			query[key] = value
+		}
+	}
+	count, err := coll.CountDocuments(ctx, query, options.Count().SetLimit(1))
+	return err == nil && count > 0
+}
+
+// skipHeldRole narrows an upsert filter with RejectExistingRole so it does not match a document that
+// already grants the role (active, unexpired). The upsert then inserts instead and hits the unique
+// index, so nothing is written.
+func (r *MongoRepository) skipHeldRole(filter bson.M, role *model.UserRole, now time.Time) {
+	if !r.RejectExistingRole {
+		return
+	}
+	filter["$nor"] = bson.A{bson.M{
+		"role":       role.Role,
+		"deleted_at": nil,
+		"expires_at": notExpired(now),
+	}}
+}
+
+// holdsRole reports whether the user matched by an upsert filter already holds role, i.e. whether a
+// duplicate key error of the upsert comes from skipHeldRole
+func (r *MongoRepository) holdsRole(ctx context.Context, coll *mongo.Collection, filter bson.M, role *model.UserRole, now time.Time) bool {
+	if !r.RejectExistingRole {
+		return false
+	}
+	query := bson.M{
+		"role":       role.Role,
+		"deleted_at": nil,
+		"expires_at": notExpired(now),
+	}
+	for key, value := range filter {
+		if key != "$or" && key != "$nor" && key != "role" {
+			query[key] = value
+		}
+	}
+	count, err := coll.CountDocuments(ctx, query, options.Count().SetLimit(1))
+	return err == nil && count > 0
+}
 
+// ownerProtectedReason is the batch failure reason of a user the upsert skipped because they own the target
+const ownerProtectedReason = "user is the owner; transfer ownership to change their role"
+
+// holdsOwner reports whether the user matched by an upsert filter owns the target, i.e. whether a
+// duplicate key error of the upsert comes from the owner protection in the filter
+func (r *MongoRepository) holdsOwner(ctx context.Context, coll *mongo.Collection, filter bson.M) bool {
+	query := bson.M{
+		"role":       bson.M{"$in": []string{model.RoleSystemOwner, model.RoleResourceOwner}},
+		"deleted_at": nil,
+	}
+	for key, value := range filter {
+		if key != "$or" && key != "$nor" && key != "role" && key != "user_type" {
+			query[key] = value
+		}
+	}
+	count, err := coll.CountDocuments(ctx, query, options.Count().SetLimit(1))
+	return err == nil && count > 0
+}
+
+// roleUpdate is the update an upsert of role applies: the role and its grant fields, with the key
+// fields set on insert and any soft delete undone
+func roleUpdate(role *model.UserRole, now time.Time) bson.M {
 	update := bson.M{
 		"$set": bson.M{
 			"role":               role.Role,
@@ -163,17 +660,28 @@ func (r *MongoRepository) UpsertUserRole(ctx context.Context, role *model.UserRo
 			"deleted_by": "",
 		},
 	}
-	opts := options.Update().SetUpsert(true)
+	setTemporaryGrant(update, role)
+	return update
+}
 
-	var coll *mongo.Collection
-	if role.Scope == model.ScopeSystem {
-		coll = r.SystemRoles
+// setTemporaryGrant sets expires_at/reason from the role, or unsets them so a re-grant without them is permanent
+func setTemporaryGrant(update bson.M, role *model.UserRole) {
+	set, unset := update["$set"].(bson.M), update["$unset"].(bson.M)
+	if role.ExpiresAt != nil {
+		set["expires_at"] = *role.ExpiresAt
 	} else {
-		coll = r.ResourceRoles
+		unset["expires_at"] = ""
 	}
+	if role.Reason != "" {
+		set["reason"] = role.Reason
+	} else {
+		unset["reason"] = ""
+	}
+}
 
-	_, err := coll.UpdateOne(ctx, filter, update, opts)
-	return err
+// notExpired matches roles without expires_at or expiring after now
+func notExpired(now time.Time) bson.M {
+	return bson.M{"$not": bson.M{"$lte": now}}
 }
 
 func (r *MongoRepository) BulkUpsertUserRoles(ctx context.Context, roles []*model.UserRole) (*model.BatchUpsertResult, error) {
@@ -181,18 +689,19 @@ func (r *MongoRepository) BulkUpsertUserRoles(ctx context.Context, roles []*mode
 		return &model.BatchUpsertResult{SuccessCount: 0, FailedCount: 0}, nil
 	}
 
-	// Assume all roles have the same scope for batch operation
+	// Assume all roles have the same scope (and resource type) for batch operation
 	scope := roles[0].Scope
 	var coll *mongo.Collection
 	if scope == model.ScopeSystem {
 		coll = r.SystemRoles
 	} else {
-		coll = r.ResourceRoles
+		coll = r.resourceCollection(roles[0].ResourceType)
 	}
 
 	now := time.Now()
 
 	writeModels := make([]mongo.WriteModel, 0, len(roles))
+	filters := make([]bson.M, 0, len(roles))
 	for _, role := range roles {
 		role.UpdatedAt = now
 
@@ -207,31 +716,17 @@ func (r *MongoRepository) BulkUpsertUserRoles(ctx context.Context, roles []*mode
 		} else {
 			filter["resource_id"] = role.ResourceID
 			filter["resource_type"] = role.ResourceType
+			if r.NamespacedResources {
+				filter["namespace"] = role.Namespace
+			}
 			filter["role"] = bson.M{"$ne": model.RoleResourceOwner}
 		}
+		r.keyOnRole(filter, role)
+		r.skipRecentlyRemoved(filter, now)
+		r.skipHeldRole(filter, role, now)
+		filters = append(filters, filter)
 
-		update := bson.M{
-			"$set": bson.M{
-				"role":               role.Role,
-				"updated_at":         now,
-				"updated_by":         role.UpdatedBy,
-				"created_by":         role.CreatedBy,
-				"namespace":          role.Namespace,
-				"resource_id":        role.ResourceID,
-				"resource_type":      role.ResourceType,
-				"parent_resource_id": role.ParentResourceID,
-			},
-			"$setOnInsert": bson.M{
-				"created_at": now,
-				"user_id":    role.UserID,
-				"user_type":  role.UserType,
-				"scope":      role.Scope,
-			},
-			"$unset": bson.M{
-				"deleted_at": "",
-				"deleted_by": "",
-			},
-		}
+		update := roleUpdate(role, now)
 
 		writeModel := mongo.NewUpdateOneModel().
 			SetFilter(filter).
@@ -262,9 +757,19 @@ func (r *MongoRepository) BulkUpsertUserRoles(ctx context.Context, roles []*mode
 			for _, writeErr := range bulkErr.WriteErrors {
 				idx := writeErr.Index
 				if idx >= 0 && idx < len(roles) {
+					reason := writeErr.Message
+					if writeErr.Code == 11000 {
+						if r.recentlyRemoved(ctx, coll, filters[idx], now) {
+							reason = ErrRecentlyRemoved.Error()
+						} else if r.holdsOwner(ctx, coll, filters[idx]) {
+							reason = ownerProtectedReason
+						} else if r.holdsRole(ctx, coll, filters[idx], roles[idx], now) {
+							reason = ErrAlreadyHasRole.Error()
+						}
+					}
 					batchResult.FailedUsers = append(batchResult.FailedUsers, model.FailedUserInfo{
 						UserID: roles[idx].UserID,
-						Reason: writeErr.Message,
+						Reason: reason,
 					})
 				}
 			}
@@ -282,7 +787,7 @@ func (r *MongoRepository) BulkUpsertUserRoles(ctx context.Context, roles []*mode
 	return batchResult, nil
 }
 
-func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) error {
+func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) (int64, error) {
 	filter := bson.M{
 		"user_id":    userID,
 		"scope":      scope,
@@ -296,7 +801,7 @@ func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID,
 		coll = r.SystemRoles
 		filter["namespace"] = namespace
 	} else if scope == model.ScopeResource {
-		coll = r.ResourceRoles
+		coll = r.resourceCollection(resourceType)
 		filter["role"] = bson.M{"$ne": model.RoleResourceOwner} // Protect resource owner
 
 		if resourceID != "" {
@@ -312,7 +817,7 @@ func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID,
 			filter["parent_resource_id"] = parentResourceID
 		}
 	} else {
-		return errors.New("invalid scope")
+		return 0, errors.New("invalid scope")
 	}
 
 	update := bson.M{
@@ -321,7 +826,63 @@ func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID,
 			"deleted_by": deletedBy,
 		},
 	}
-	res, err := coll.UpdateOne(ctx, filter, update)
+	// In MultiRole mode removing a member removes every role they hold there
+	var res *mongo.UpdateResult
+	var err error
+	if r.MultiRole {
+		res, err = coll.UpdateMany(ctx, filter, update)
+	} else {
+		res, err = coll.UpdateOne(ctx, filter, update)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if res.MatchedCount == 0 {
+		return 0, mongo.ErrNoDocuments
+	}
+	return res.ModifiedCount, nil
+}
+
+// RestoreUserRole undoes the soft delete of a user's role, keeping its original created_at/created_by.
+// Owner roles are never restored, like DeleteUserRole never removes them. Returns
+// mongo.ErrNoDocuments when the user has no soft-deleted role there.
+func (r *MongoRepository) RestoreUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType string) error {
+	filter := bson.M{
+		"user_id":    userID,
+		"scope":      scope,
+		"deleted_at": bson.M{"$ne": nil},
+		"role":       bson.M{"$ne": model.RoleSystemOwner},
+	}
+
+	var coll *mongo.Collection
+	switch scope {
+	case model.ScopeSystem:
+		coll = r.SystemRoles
+		filter["namespace"] = namespace
+	case model.ScopeResource:
+		coll = r.resourceCollection(resourceType)
+		filter["role"] = bson.M{"$ne": model.RoleResourceOwner}
+		filter["resource_id"] = resourceID
+		filter["resource_type"] = resourceType
+		if namespace != "" {
+			filter["namespace"] = namespace
+		}
+	default:
+		return errors.New("invalid scope")
+	}
+
+	// updated_at moves so incremental sync clients see the role come back
+	update := bson.M{
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+	}
+	var res *mongo.UpdateResult
+	var err error
+	if r.MultiRole {
+		res, err = coll.UpdateMany(ctx, filter, update)
+	} else {
+		res, err = coll.UpdateOne(ctx, filter, update)
+	}
 	if err != nil {
 		return err
 	}
@@ -334,21 +895,28 @@ func (r *MongoRepository) DeleteUserRole(ctx context.Context, namespace, userID,
 func (r *MongoRepository) FindUserRoles(ctx context.Context, filter model.UserRoleFilter) ([]*model.UserRole, error) {
 	query := bson.M{
 		"deleted_at": nil,
+		"expires_at": notExpired(time.Now()),
 	}
 	if filter.UserID != "" {
 		query["user_id"] = filter.UserID
+	} else if len(filter.UserIDs) > 0 {
+		query["user_id"] = bson.M{"$in": filter.UserIDs}
 	}
 	if filter.Namespace != "" {
 		query["namespace"] = filter.Namespace
 	}
 	if filter.Role != "" {
 		query["role"] = filter.Role
+	} else if len(filter.Roles) > 0 {
+		query["role"] = bson.M{"$in": filter.Roles}
 	}
 	if filter.Scope != "" {
 		query["scope"] = filter.Scope
 	}
 	if filter.ResourceID != "" {
 		query["resource_id"] = filter.ResourceID
+	} else if len(filter.ResourceIDs) > 0 {
+		query["resource_id"] = bson.M{"$in": filter.ResourceIDs}
 	}
 	if filter.ResourceType != "" {
 		query["resource_type"] = filter.ResourceType
@@ -356,62 +924,110 @@ func (r *MongoRepository) FindUserRoles(ctx context.Context, filter model.UserRo
 	if filter.ParentResourceID != "" {
 		query["parent_resource_id"] = filter.ParentResourceID
 	}
+	if filter.ModifiedSince != nil {
+		// Deletions only set deleted_at, so match either timestamp and keep soft-deleted (and expired) roles
+		delete(query, "deleted_at")
+		delete(query, "expires_at")
+		query["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$gte": *filter.ModifiedSince}},
+			bson.M{"deleted_at": bson.M{"$gte": *filter.ModifiedSince}},
+		}
+	}
 
-	// Logic: If scope is strict, query that one.
-	// If filter.Scope is empty, we must query BOTH and merge.
-	// API usually enforces scope for specific listings, but GetUserRoles might not?
+	// Scoped queries read one collection unless resource types are routed to several; they match
+	// on scope, so every record has one. Anything spanning collections is merged server-side with
+	// $unionWith (MongoDB 4.4+), grouped by scope in created_at order, and Offset/Limit page the merged set.
+	var colls []*mongo.Collection
+	if filter.Scope != model.ScopeResource {
+		colls = append(colls, r.SystemRoles)
+	}
+	if filter.Scope != model.ScopeSystem {
+		colls = append(colls, r.resourceCollections(filter.ResourceType)...)
+	}
 
-	if filter.Scope == model.ScopeSystem {
-		cursor, err := r.SystemRoles.Find(ctx, query)
-		if err != nil {
-			return nil, err
+	var cursor *mongo.Cursor
+	var err error
+	if filter.ModifiedSince != nil && (filter.Offset > 0 || filter.Limit > 0) {
+		cursor, err = r.reader(ctx, colls[0]).Aggregate(ctx, r.changesPipeline(query, colls, filter.Offset, filter.Limit))
+	} else if len(colls) == 1 {
+		opts := options.Find()
+		if filter.Offset > 0 || filter.Limit > 0 {
+			opts.SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).SetSkip(filter.Offset).SetLimit(filter.Limit)
 		}
-		defer cursor.Close(ctx)
-		var roles []*model.UserRole
-		if err = cursor.All(ctx, &roles); err != nil {
-			return nil, err
-		}
-		return roles, nil
-	} else if filter.Scope == model.ScopeResource {
-		cursor, err := r.ResourceRoles.Find(ctx, query)
-		if err != nil {
-			return nil, err
-		}
-		defer cursor.Close(ctx)
-		var roles []*model.UserRole
-		if err = cursor.All(ctx, &roles); err != nil {
-			return nil, err
-		}
-		return roles, nil
+		cursor, err = r.reader(ctx, colls[0]).Find(ctx, query, opts)
+	} else {
+		cursor, err = r.reader(ctx, colls[0]).Aggregate(ctx, r.unionPipeline(query, colls, filter.Offset, filter.Limit))
+	}
+	if err != nil {
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// If no scope specified, query both
-	var allRoles []*model.UserRole
+	var roles []*model.UserRole
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
 
-	// System
-	cursorSys, err := r.SystemRoles.Find(ctx, query)
-	if err == nil {
-		var roles []*model.UserRole
-		_ = cursorSys.All(ctx, &roles)
-		cursorSys.Close(ctx)
-		allRoles = append(allRoles, roles...)
+// unionPipeline matches query in colls (aggregated from the first), sorted by scope then created_at and paged.
+// Each record gets the scope of its collection when a legacy document lacks one, so merged system and
+// resource roles are never ambiguous.
+func (r *MongoRepository) unionPipeline(query bson.M, colls []*mongo.Collection, offset, limit int64) mongo.Pipeline {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: query}}, r.defaultScopeStage(colls[0])}
+	for _, coll := range colls[1:] {
+		pipeline = append(pipeline, bson.D{{Key: "$unionWith", Value: bson.M{
+			"coll":     coll.Name(),
+			"pipeline": bson.A{bson.M{"$match": query}, r.defaultScopeStage(coll)},
+		}}})
+	}
+	// scope descending lists system roles before resource roles
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: "scope", Value: -1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}})
+	if offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: offset}})
 	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	return pipeline
+}
 
-	// Resource
-	cursorRes, err := r.ResourceRoles.Find(ctx, query)
-	if err == nil {
-		var roles []*model.UserRole
-		_ = cursorRes.All(ctx, &roles)
-		cursorRes.Close(ctx)
-		allRoles = append(allRoles, roles...)
+// changesPipeline matches query in colls like unionPipeline but pages in change order: by the later of
+// updated_at and deleted_at (a soft delete only sets deleted_at), so a sync cursor taken from the last
+// role of a page never skips a change.
+func (r *MongoRepository) changesPipeline(query bson.M, colls []*mongo.Collection, offset, limit int64) mongo.Pipeline {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: query}}, r.defaultScopeStage(colls[0])}
+	for _, coll := range colls[1:] {
+		pipeline = append(pipeline, bson.D{{Key: "$unionWith", Value: bson.M{
+			"coll":     coll.Name(),
+			"pipeline": bson.A{bson.M{"$match": query}, r.defaultScopeStage(coll)},
+		}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$addFields", Value: bson.M{"changed_at": bson.M{"$max": bson.A{"$updated_at", "$deleted_at"}}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "changed_at", Value: 1}, {Key: "_id", Value: 1}}}},
+	)
+	if offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: offset}})
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
 	}
+	return append(pipeline, bson.D{{Key: "$unset", Value: "changed_at"}})
+}
 
-	return allRoles, nil
+// defaultScopeStage fills in scope from the collection a role was read from
+func (r *MongoRepository) defaultScopeStage(coll *mongo.Collection) bson.D {
+	scope := model.ScopeResource
+	if coll == r.SystemRoles {
+		scope = model.ScopeSystem
+	}
+	return bson.D{{Key: "$addFields", Value: bson.M{"scope": bson.M{"$ifNull": bson.A{"$scope", scope}}}}}
 }
 
-// DeleteUserRolesByParent soft deletes user roles by parent_resource_id.
+// DeleteUserRolesByParent soft deletes user roles by parent_resource_id and returns how many.
 // Used for cascade deletion when removing dashboard member.
-func (r *MongoRepository) DeleteUserRolesByParent(ctx context.Context, userID, parentResourceID, resourceType, deletedBy string) error {
+func (r *MongoRepository) DeleteUserRolesByParent(ctx context.Context, namespace, userID, parentResourceID, resourceType, deletedBy string) (int64, error) {
 	filter := bson.M{
 		"user_id":            userID,
 		"parent_resource_id": parentResourceID,
@@ -419,6 +1035,7 @@ func (r *MongoRepository) DeleteUserRolesByParent(ctx context.Context, userID, p
 		"scope":              model.ScopeResource,
 		"deleted_at":         nil,
 	}
+	r.keyOnNamespace(filter, namespace)
 
 	update := bson.M{
 		"$set": bson.M{
@@ -427,14 +1044,17 @@ func (r *MongoRepository) DeleteUserRolesByParent(ctx context.Context, userID, p
 		},
 	}
 
-	_, err := r.ResourceRoles.UpdateMany(ctx, filter, update)
-	return err
+	res, err := r.resourceCollection(resourceType).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
 }
 
-// SoftDeleteResourceUserRoles soft deletes all user roles for a resource (including owner).
+// SoftDeleteResourceUserRoles soft deletes all user roles for a resource (including owner) and returns how many.
 // This is used when deleting a resource entirely.
 // For dashboard: also deletes all child widget user roles.
-func (r *MongoRepository) SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) error {
+func (r *MongoRepository) SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) (int64, error) {
 	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
@@ -443,27 +1063,45 @@ func (r *MongoRepository) SoftDeleteResourceUserRoles(ctx context.Context, req *
 		},
 	}
 
-	// Collect all resource IDs to delete
-	resourceIDs := []string{req.ResourceID}
-	if len(req.ChildResourceIDs) > 0 {
-		resourceIDs = append(resourceIDs, req.ChildResourceIDs...)
-	}
-
-	// Build filter based on resource type
+	// The resource itself, and for a dashboard its child widgets
 	filter := bson.M{
-		"resource_id": bson.M{"$in": resourceIDs},
-		"scope":       model.ScopeResource,
-		"deleted_at":  nil, // Only delete active roles
+		"resource_id":   req.ResourceID,
+		"resource_type": req.ResourceType,
+		"scope":         model.ScopeResource,
+		"deleted_at":    nil, // Only delete active roles
+	}
+	if len(req.ChildResourceIDs) > 0 {
+		delete(filter, "resource_id")
+		delete(filter, "resource_type")
+		filter["$or"] = bson.A{
+			bson.M{"resource_id": req.ResourceID, "resource_type": req.ResourceType},
+			bson.M{"resource_id": bson.M{"$in": req.ChildResourceIDs}, "resource_type": model.ResourceTypeDashboardWidget},
+		}
 	}
 
-	// For library_widget, also match namespace
-	if req.ResourceType == "library_widget" && req.Namespace != "" {
+	// Library widgets always match namespace; other types only when resources are keyed on it
+	if req.ResourceType == model.ResourceTypeLibraryWidget && req.Namespace != "" {
 		filter["namespace"] = req.Namespace
 	}
+	r.keyOnNamespace(filter, req.Namespace)
 
 	// Execute update (no owner protection - this deletes everything including owner)
-	_, err := r.ResourceRoles.UpdateMany(ctx, filter, update)
-	return err
+	// Child widgets may be routed to another collection than the resource
+	colls := r.resourceCollections(req.ResourceType)
+	if len(req.ChildResourceIDs) > 0 {
+		if widgets := r.resourceCollection(model.ResourceTypeDashboardWidget); widgets != colls[0] {
+			colls = append(colls, widgets)
+		}
+	}
+	var deleted int64
+	for _, coll := range colls {
+		res, err := coll.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += res.ModifiedCount
+	}
+	return deleted, nil
 }
 
 // HistoryRepository implementation
@@ -490,6 +1128,23 @@ func (r *MongoRepository) EnsureHistoryIndexes(ctx context.Context) error {
 			},
 			Options: options.Index().SetName("idx_resource_scope_query"),
 		},
+		// Widget query: dashboard deletions listing the widget in child_resource_ids (multikey)
+		{
+			Keys: bson.D{
+				{Key: "scope", Value: 1},
+				{Key: "child_resource_ids", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_child_resource_query"),
+		},
+		// Target user query: user_id + created_at
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("idx_target_user_query"),
+		},
 		// Created at for time-based queries
 		{
 			Keys:    bson.D{{Key: "created_at", Value: -1}},
@@ -510,16 +1165,125 @@ func (r *MongoRepository) CreateHistory(ctx context.Context, history *model.User
 	return err
 }
 
+// WithHistory runs write and inserts history in one transaction.
+// Concurrent transactions touching the same role document are serialized by MongoDB (write conflicts
+// are retried by WithTransaction), and history is stamped after the write inside the transaction,
+// so the newest history entry of a role always describes its committed state.
+// A failed write or history insert rolls both back; in Standalone mode history is only skipped
+// when the write fails.
+func (r *MongoRepository) WithHistory(ctx context.Context, history *model.UserRoleHistory, write func(ctx context.Context) error) error {
+	return r.inTransaction(ctx, func(ctx context.Context) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+
+		// Stamp on every attempt: a retried transaction must not keep a stale timestamp
+		entry := *history
+		entry.CreatedAt = time.Now()
+		_, err := r.History.InsertOne(ctx, &entry)
+		return err
+	})
+}
+
+// inTransaction runs fn in a transaction. A ctx already inside a session joins that transaction,
+// so transactional repository methods compose under WithHistory. In Standalone mode fn runs directly.
+// Transient errors retry the whole transaction at most TxnMaxRetries times and within TxnTimeout;
+// running out of retries returns ErrTransactionContention, running out of time ErrTransactionTimeout.
+func (r *MongoRepository) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.Standalone || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	if r.TxnTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.TxnTimeout)
+		defer cancel()
+	}
+
+	session, err := r.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	for attempt := 1; ; attempt++ {
+		err := r.runTransaction(ctx, session, fn)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: exceeded %s: %v", ErrTransactionTimeout, r.TxnTimeout, err)
+		}
+		if !hasErrorLabel(err, labelTransientTransaction) {
+			return err
+		}
+		if attempt > r.TxnMaxRetries {
+			return fmt.Errorf("%w: gave up after %d attempts: %v", ErrTransactionContention, attempt, err)
+		}
+	}
+}
+
+// runTransaction makes one attempt at fn in a new transaction of session.
+// A commit with an unknown result may have been applied, so only the commit is retried (up to TxnMaxRetries).
+func (r *MongoRepository) runTransaction(ctx context.Context, session mongo.Session, fn func(ctx context.Context) error) error {
+	if err := session.StartTransaction(); err != nil {
+		return err
+	}
+	if err := fn(mongo.NewSessionContext(ctx, session)); err != nil {
+		// Abort must run even when ctx has expired, so the server releases the transaction's locks
+		_ = session.AbortTransaction(context.Background())
+		return err
+	}
+	for retry := 0; ; retry++ {
+		err := session.CommitTransaction(ctx)
+		if err == nil || !hasErrorLabel(err, labelUnknownCommitResult) || ctx.Err() != nil || retry >= r.TxnMaxRetries {
+			return err
+		}
+	}
+}
+
+// hasErrorLabel reports whether err carries the given server error label
+func hasErrorLabel(err error, label string) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel(label)
+}
+
 // FindHistory finds history records with pagination and filtering
 func (r *MongoRepository) FindHistory(ctx context.Context, req model.GetUserRoleHistoryReq) ([]*model.UserRoleHistory, int64, error) {
 	filter := bson.M{"scope": req.Scope}
+	var clauses []bson.M
 
 	// Add scope-specific filters
 	if req.Scope == model.ScopeSystem {
 		filter["namespace"] = req.Namespace
 	} else if req.Scope == model.ScopeResource {
-		filter["resource_id"] = req.ResourceID
-		filter["resource_type"] = req.ResourceType
+		if req.ResourceType == model.ResourceTypeDashboardWidget {
+			// A dashboard deletion records its widgets in child_resource_ids, so it belongs to their history too
+			clauses = append(clauses, bson.M{"$or": bson.A{
+				bson.M{"resource_id": req.ResourceID, "resource_type": req.ResourceType},
+				bson.M{"resource_type": model.ResourceTypeDashboard, "child_resource_ids": bson.M{"$in": bson.A{req.ResourceID}}},
+			}})
+		} else {
+			filter["resource_id"] = req.ResourceID
+			filter["resource_type"] = req.ResourceType
+		}
+	}
+
+	// Add target user filter: single ops store user_id, batch ops user_ids, transfers new_owner_id
+	if req.TargetUserID != "" {
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"user_id": req.TargetUserID},
+			bson.M{"user_ids": req.TargetUserID},
+			bson.M{"new_owner_id": req.TargetUserID},
+		}})
+	}
+
+	// A single alternative keeps its top-level $or; both need $and to combine
+	switch len(clauses) {
+	case 1:
+		filter["$or"] = clauses[0]["$or"]
+	case 2:
+		filter["$and"] = clauses
 	}
 
 	// Add time range filter
@@ -535,7 +1299,7 @@ func (r *MongoRepository) FindHistory(ctx context.Context, req model.GetUserRole
 	}
 
 	// Count total records
-	total, err := r.History.CountDocuments(ctx, filter)
+	total, err := r.reader(ctx, r.History).CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -549,7 +1313,7 @@ func (r *MongoRepository) FindHistory(ctx context.Context, req model.GetUserRole
 		SetSkip(skip).
 		SetLimit(int64(req.Size))
 
-	cursor, err := r.History.Find(ctx, filter, findOptions)
+	cursor, err := r.reader(ctx, r.History).Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -562,3 +1326,107 @@ func (r *MongoRepository) FindHistory(ctx context.Context, req model.GetUserRole
 
 	return results, total, nil
 }
+
+// EraseUser hard deletes every role document of userID and replaces the ID with tombstone in
+// other roles' actor fields and in history, then appends an erasure record. All in one transaction.
+func (r *MongoRepository) EraseUser(ctx context.Context, userID, tombstone, callerID string) (*model.EraseUserResult, error) {
+	var result *model.EraseUserResult
+	err := r.inTransaction(ctx, func(sessCtx context.Context) error {
+		result = &model.EraseUserResult{Tombstone: tombstone}
+
+		roleColls := append([]*mongo.Collection{r.SystemRoles}, r.resourceCollections("")...)
+		actorFilter := bson.M{"$or": bson.A{
+			bson.M{"created_by": userID},
+			bson.M{"updated_by": userID},
+			bson.M{"deleted_by": userID},
+		}}
+		actorUpdate := tombstonePipeline(userID, tombstone, "created_by", "updated_by", "deleted_by")
+		for _, coll := range roleColls {
+			// 1. Own roles, including soft-deleted ones
+			res, err := coll.DeleteMany(sessCtx, bson.M{"user_id": userID})
+			if err != nil {
+				return err
+			}
+			result.RolesDeleted += res.DeletedCount
+
+			// 2. Roles of other users the erased user granted, changed or revoked
+			upd, err := coll.UpdateMany(sessCtx, actorFilter, actorUpdate)
+			if err != nil {
+				return err
+			}
+			result.RolesAnonymized += upd.ModifiedCount
+		}
+
+		// 3. History entries naming the user as caller, target, batch member or new owner
+		historyFilter := bson.M{"$or": bson.A{
+			bson.M{"user_id": userID},
+			bson.M{"user_ids": userID},
+			bson.M{"caller_id": userID},
+			bson.M{"new_owner_id": userID},
+		}}
+		historyUpdate := tombstonePipeline(userID, tombstone, "user_id", "caller_id", "new_owner_id")
+		historyUpdate[0]["$set"].(bson.M)["user_ids"] = bson.M{"$cond": bson.A{
+			bson.M{"$isArray": "$user_ids"},
+			bson.M{"$map": bson.M{
+				"input": "$user_ids",
+				"in":    bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$this", userID}}, tombstone, "$$this"}},
+			}},
+			"$user_ids",
+		}}
+		upd, err := r.History.UpdateMany(sessCtx, historyFilter, historyUpdate)
+		if err != nil {
+			return err
+		}
+		result.HistoryAnonymized = upd.ModifiedCount
+
+		// 4. Immutable erasure record
+		_, err = r.ErasureLog.InsertOne(sessCtx, &model.UserErasure{
+			Tombstone:         tombstone,
+			CallerID:          callerID,
+			RolesDeleted:      result.RolesDeleted,
+			RolesAnonymized:   result.RolesAnonymized,
+			HistoryAnonymized: result.HistoryAnonymized,
+			CreatedAt:         time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// HardDeleteUserRole physically removes every role document of userID from the system and resource
+// collections, soft-deleted ones included, in one transaction. It returns the total deleted count.
+func (r *MongoRepository) HardDeleteUserRole(ctx context.Context, userID string) (int64, error) {
+	var deleted int64
+	err := r.inTransaction(ctx, func(sessCtx context.Context) error {
+		deleted = 0
+		for _, coll := range append([]*mongo.Collection{r.SystemRoles}, r.resourceCollections("")...) {
+			res, err := coll.DeleteMany(sessCtx, bson.M{"user_id": userID})
+			if err != nil {
+				return err
+			}
+			deleted += res.DeletedCount
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// tombstonePipeline builds an update pipeline replacing userID with tombstone in the given fields.
+// Fields holding other values (or missing) are left as they are.
+func tombstonePipeline(userID, tombstone string, fields ...string) []bson.M {
+	set := bson.M{}
+	for _, field := range fields {
+		set[field] = bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$" + field, userID}}, tombstone, "$" + field}}
+	}
+	return []bson.M{{"$set": set}}
+}
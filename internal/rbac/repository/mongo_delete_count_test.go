@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestDeleteCounts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	updated := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+	}
+
+	mt.Run("delete user role returns the deleted count", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(1))
+
+		deleted, err := repo.DeleteUserRole(context.Background(), "NS_1", "u1", model.ScopeSystem, "", "", "", "caller")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	mt.Run("delete user role with no match returns ErrNoDocuments", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(0))
+
+		deleted, err := repo.DeleteUserRole(context.Background(), "NS_1", "u1", model.ScopeSystem, "", "", "", "caller")
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		assert.Zero(t, deleted)
+	})
+
+	mt.Run("cascade by parent returns every widget role deleted", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(3))
+
+		deleted, err := repo.DeleteUserRolesByParent(context.Background(), "", "u1", "d1", model.ResourceTypeDashboardWidget, "caller")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		assert.True(t, updates[0].Document().Lookup("multi").Boolean())
+	})
+}
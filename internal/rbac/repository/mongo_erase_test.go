@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestEraseUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("roles deleted, history anonymized and erasure recorded in one transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),                                            // system delete
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // system actor update
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(2)}),                                            // resource delete
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(0)}, bson.E{Key: "nModified", Value: int32(0)}), // resource actor update
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(3)}, bson.E{Key: "nModified", Value: int32(3)}), // history update
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),                                            // erasure log insert
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		result, err := repo.EraseUser(context.Background(), "user_x", "erased_1", "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, "erased_1", result.Tombstone)
+		assert.Equal(t, int64(3), result.RolesDeleted)
+		assert.Equal(t, int64(1), result.RolesAnonymized)
+		assert.Equal(t, int64(3), result.HistoryAnonymized)
+
+		// Only the erased user's role documents are deleted
+		del := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", del.Lookup("delete").StringValue())
+		assert.NotNil(t, del.Lookup("lsid"))
+		deletes, _ := del.Lookup("deletes").Array().Values()
+		assert.Equal(t, "user_x", deletes[0].Document().Lookup("q", "user_id").StringValue())
+
+		// Actor fields of other users' roles are rewritten only where they equal the erased user
+		upd := mt.GetStartedEvent().Command
+		updates, _ := upd.Lookup("updates").Array().Values()
+		stage, _ := updates[0].Document().Lookup("u").Array().Values()
+		cond, _ := stage[0].Document().Lookup("$set", "created_by", "$cond").Array().Values()
+		eq, _ := cond[0].Document().Lookup("$eq").Array().Values()
+		assert.Equal(t, "$created_by", eq[0].StringValue())
+		assert.Equal(t, "user_x", eq[1].StringValue())
+		assert.Equal(t, "erased_1", cond[1].StringValue())
+		assert.Equal(t, "$created_by", cond[2].StringValue())
+
+		mt.GetStartedEvent() // resource delete
+		mt.GetStartedEvent() // resource actor update
+
+		hist := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_role_history", hist.Lookup("update").StringValue())
+		updates, _ = hist.Lookup("updates").Array().Values()
+		clauses, _ := updates[0].Document().Lookup("q", "$or").Array().Values()
+		assert.Len(t, clauses, 4)
+		stage, _ = updates[0].Document().Lookup("u").Array().Values()
+		set := stage[0].Document().Lookup("$set").Document()
+		for _, field := range []string{"user_id", "user_ids", "caller_id", "new_owner_id"} {
+			assert.NotNil(t, set.Lookup(field), field)
+		}
+
+		insert := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_erasure_log", insert.Lookup("insert").StringValue())
+		docs, _ := insert.Lookup("documents").Array().Values()
+		record := docs[0].Document()
+		assert.Equal(t, "erased_1", record.Lookup("tombstone").StringValue())
+		assert.Equal(t, "mod_1", record.Lookup("caller_id").StringValue())
+		assert.NotContains(t, record.String(), "user_x")
+
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+	})
+}
+
+func TestHardDeleteUserRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	deleted := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+	}
+
+	mt.Run("roles in both collections are summed", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(deleted(1), deleted(2), mtest.CreateSuccessResponse()) // system, resource, commit
+
+		n, err := repo.HardDeleteUserRole(context.Background(), "user_x")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		for _, coll := range []string{"user_roles", "user_resource_roles"} {
+			del := mt.GetStartedEvent().Command
+			assert.Equal(t, coll, del.Lookup("delete").StringValue())
+			deletes, _ := del.Lookup("deletes").Array().Values()
+			q := deletes[0].Document().Lookup("q").Document()
+			assert.Equal(t, "user_x", q.Lookup("user_id").StringValue())
+			_, err := q.LookupErr("deleted_at")
+			assert.Error(t, err, "soft-deleted roles are purged too")
+		}
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("user without roles deletes nothing", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(deleted(0), deleted(0), mtest.CreateSuccessResponse())
+
+		n, err := repo.HardDeleteUserRole(context.Background(), "nobody")
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSoftDeleteGracePeriod(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	newRole := func() *model.UserRole {
+		return &model.UserRole{UserID: "user_x", UserType: "member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1"}
+	}
+	duplicateKey := mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"})
+
+	mt.Run("re-adding within the grace period returns ErrRecentlyRemoved", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.SoftDeleteGracePeriod = time.Hour
+		mt.AddMockResponses(
+			duplicateKey, // upsert skips the just-deleted role, so it inserts and hits the unique index
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}), // recent removal found
+		)
+
+		before := time.Now()
+		err := repo.UpsertUserRole(context.Background(), newRole())
+		assert.ErrorIs(t, err, ErrRecentlyRemoved)
+
+		upsert := mt.GetStartedEvent().Command
+		updates, _ := upsert.Lookup("updates").Array().Values()
+		clauses, _ := updates[0].Document().Lookup("q", "$or").Array().Values()
+		assert.Len(t, clauses, 2)
+		cutoff := clauses[1].Document().Lookup("deleted_at", "$lte").Time()
+		assert.WithinDuration(t, before.Add(-time.Hour), cutoff, time.Second)
+
+		count := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", count.Lookup("aggregate").StringValue())
+		assert.Contains(t, count.String(), "$gt")
+		assert.Contains(t, count.String(), "user_x")
+	})
+
+	mt.Run("re-adding after the grace period revives the role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.SoftDeleteGracePeriod = time.Hour
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}))
+
+		err := repo.UpsertUserRole(context.Background(), newRole())
+		assert.NoError(t, err)
+
+		upsert := mt.GetStartedEvent().Command
+		updates, _ := upsert.Lookup("updates").Array().Values()
+		assert.NotNil(t, updates[0].Document().Lookup("u", "$unset", "deleted_at"))
+	})
+
+	mt.Run("duplicate key without a recent removal is returned as is", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.SoftDeleteGracePeriod = time.Hour
+		mt.AddMockResponses(
+			duplicateKey,
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch), // e.g. the user is the active owner
+		)
+
+		err := repo.UpsertUserRole(context.Background(), newRole())
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrRecentlyRemoved)
+	})
+
+	mt.Run("default off leaves the filter unchanged", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}))
+
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), newRole()))
+
+		upsert := mt.GetStartedEvent().Command
+		updates, _ := upsert.Lookup("updates").Array().Values()
+		_, err := updates[0].Document().LookupErr("q", "$or")
+		assert.Error(t, err)
+	})
+
+	mt.Run("batch reports recently removed users", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.SoftDeleteGracePeriod = time.Hour
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}),
+		)
+
+		other := newRole()
+		other.UserID = "user_y"
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{other, newRole()})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.SuccessCount)
+		assert.Equal(t, []model.FailedUserInfo{{UserID: "user_x", Reason: ErrRecentlyRemoved.Error()}}, result.FailedUsers)
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRepositoryHealth(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	counted := func(ns string) bson.D {
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}})
+	}
+	indexes := func(ns string, names ...string) bson.D {
+		docs := []bson.D{{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}}, {Key: "name", Value: "_id_"}}}
+		for _, name := range names {
+			docs = append(docs, bson.D{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "user_id", Value: int32(1)}}}, {Key: "name", Value: name}})
+		}
+		return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, docs...)
+	}
+
+	mt.Run("healthy when collections are queryable and indexed", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			counted("db.user_roles"),
+			indexes("db.user_roles", "uniq_user_per_namespace_scope", "unique_system_owner_v2", "idx_updated_at"),
+			counted("db.user_resource_roles"),
+			indexes("db.user_resource_roles", "uniq_user_per_resource_scope", "unique_resource_owner"),
+		)
+
+		assert.NoError(t, repo.RepositoryHealth(context.Background()))
+
+		count := mt.GetStartedEvent().Command
+		assert.Equal(t, "aggregate", count.Index(0).Key())
+		assert.Contains(t, count.Lookup("pipeline").String(), `"$limit"`)
+	})
+
+	mt.Run("missing unique index is unhealthy", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			counted("db.user_roles"),
+			indexes("db.user_roles", "uniq_user_per_namespace_scope", "unique_system_owner_v2"),
+			counted("db.user_resource_roles"),
+			indexes("db.user_resource_roles", "unique_resource_owner"),
+		)
+
+		err := repo.RepositoryHealth(context.Background())
+		assert.ErrorIs(t, err, ErrUnhealthy)
+		assert.ErrorContains(t, err, "user_resource_roles is missing index uniq_user_per_resource_scope")
+	})
+
+	mt.Run("multi-role mode expects the role-keyed index", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultiRole = true
+		mt.AddMockResponses(
+			counted("db.user_roles"),
+			indexes("db.user_roles", "uniq_user_per_namespace_scope", "unique_system_owner_v2"),
+		)
+
+		assert.ErrorContains(t, repo.RepositoryHealth(context.Background()), "missing index uniq_user_role_per_namespace_scope")
+	})
+
+	mt.Run("unqueryable collection is unhealthy", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Message: "not authorized"}))
+
+		err := repo.RepositoryHealth(context.Background())
+		assert.ErrorIs(t, err, ErrUnhealthy)
+		assert.ErrorContains(t, err, "user_roles is not queryable")
+	})
+}
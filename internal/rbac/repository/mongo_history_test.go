@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFindHistoryTargetUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("target user matches single, batch and transfer records", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "operation", Value: "assign_user_role"}, {Key: "user_id", Value: "user_1"}}),
+		)
+
+		results, total, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeSystem, Namespace: "NS_1", TargetUserID: "user_1", Page: 1, Size: 10,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Len(t, results, 1)
+
+		mt.GetStartedEvent() // count
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, "NS_1", filter.Lookup("namespace").StringValue())
+		clauses, _ := filter.Lookup("$or").Array().Values()
+		var keys []string
+		for _, c := range clauses {
+			elems, _ := c.Document().Elements()
+			keys = append(keys, elems[0].Key())
+			assert.Equal(t, "user_1", elems[0].Value().StringValue())
+		}
+		assert.Equal(t, []string{"user_id", "user_ids", "new_owner_id"}, keys)
+	})
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("records three operations and reads them back newest first", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		operations := []string{"assign_user_role", "transfer_owner", "delete_user_role"}
+
+		for range operations {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}))
+		}
+		for i, op := range operations {
+			err := repo.CreateHistory(context.Background(), &model.UserRoleHistory{
+				Operation: op, CallerID: "admin_1", Scope: model.ScopeSystem, Namespace: "NS_1",
+				CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			})
+			assert.NoError(t, err)
+		}
+		for i, op := range operations {
+			insert := mt.GetStartedEvent()
+			assert.Equal(t, "user_role_history", insert.Command.Lookup("insert").StringValue())
+			docs, _ := insert.Command.Lookup("documents").Array().Values()
+			assert.Equal(t, op, docs[0].Document().Lookup("operation").StringValue())
+			assert.True(t, base.Add(time.Duration(i)*time.Minute).Equal(docs[0].Document().Lookup("created_at").Time()))
+		}
+
+		// The server applies the sort; the mock returns what it would for the newest page of two
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(3)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "operation", Value: "delete_user_role"}, {Key: "created_at", Value: base.Add(2 * time.Minute)}},
+				bson.D{{Key: "operation", Value: "transfer_owner"}, {Key: "created_at", Value: base.Add(time.Minute)}},
+			),
+		)
+		results, total, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeSystem, Namespace: "NS_1", Page: 1, Size: 2,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, "delete_user_role", results[0].Operation)
+			assert.Equal(t, "transfer_owner", results[1].Operation)
+		}
+
+		mt.GetStartedEvent() // count
+		find := mt.GetStartedEvent().Command
+		sort, _ := find.Lookup("sort").Document().Elements()
+		assert.Equal(t, "created_at", sort[0].Key())
+		assert.Equal(t, int32(-1), sort[0].Value().Int32())
+		assert.Equal(t, int64(2), find.Lookup("limit").Int64())
+	})
+
+	mt.Run("page two skips the first page", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(3)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "operation", Value: "assign_user_role"}}),
+		)
+
+		_, _, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeSystem, Namespace: "NS_1", Page: 2, Size: 2,
+		})
+		assert.NoError(t, err)
+
+		mt.GetStartedEvent() // count
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, int64(2), find.Lookup("skip").Int64())
+		assert.Equal(t, int64(2), find.Lookup("limit").Int64())
+	})
+}
+
+func TestFindHistoryWidget(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("widget history includes parent dashboard deletions", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(0)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+		)
+
+		_, _, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeResource, ResourceID: "w_1", ResourceType: model.ResourceTypeDashboardWidget, Page: 1, Size: 10,
+		})
+		assert.NoError(t, err)
+
+		mt.GetStartedEvent() // count
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, model.ScopeResource, filter.Lookup("scope").StringValue())
+		clauses, _ := filter.Lookup("$or").Array().Values()
+		if assert.Len(t, clauses, 2) {
+			direct := clauses[0].Document()
+			assert.Equal(t, "w_1", direct.Lookup("resource_id").StringValue())
+			assert.Equal(t, model.ResourceTypeDashboardWidget, direct.Lookup("resource_type").StringValue())
+			parent := clauses[1].Document()
+			assert.Equal(t, model.ResourceTypeDashboard, parent.Lookup("resource_type").StringValue())
+			ids, _ := parent.Lookup("child_resource_ids", "$in").Array().Values()
+			assert.Equal(t, "w_1", ids[0].StringValue())
+		}
+	})
+
+	mt.Run("widget history with a target user combines both with $and", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(0)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+		)
+
+		_, _, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeResource, ResourceID: "w_1", ResourceType: model.ResourceTypeDashboardWidget,
+			TargetUserID: "user_1", Page: 1, Size: 10,
+		})
+		assert.NoError(t, err)
+
+		mt.GetStartedEvent() // count
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		_, err = filter.LookupErr("$or")
+		assert.Error(t, err, "alternatives must not overwrite each other at the top level")
+		clauses, _ := filter.Lookup("$and").Array().Values()
+		assert.Len(t, clauses, 2)
+	})
+
+	mt.Run("dashboard history matches the dashboard only", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_role_history"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(0)}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+		)
+
+		_, _, err := repo.FindHistory(context.Background(), model.GetUserRoleHistoryReq{
+			Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: model.ResourceTypeDashboard, Page: 1, Size: 10,
+		})
+		assert.NoError(t, err)
+
+		mt.GetStartedEvent() // count
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, "d_1", filter.Lookup("resource_id").StringValue())
+		_, err = filter.LookupErr("$or")
+		assert.Error(t, err)
+	})
+}
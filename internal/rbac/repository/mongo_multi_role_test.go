@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMultiRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	indexNames := func(cmd bson.Raw) []string {
+		indexes, _ := cmd.Lookup("indexes").Array().Values()
+		names := make([]string, 0, len(indexes))
+		for _, idx := range indexes {
+			names = append(names, idx.Document().Lookup("name").StringValue())
+		}
+		return names
+	}
+	upsertFilter := func(mt *mtest.T) bson.Raw {
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		return updates[0].Document().Lookup("q").Document()
+	}
+	editor := func() *model.UserRole {
+		return &model.UserRole{
+			UserID: "u1", UserType: model.UserTypeMember, Role: model.RoleResourceEditor, Scope: model.ScopeResource,
+			ResourceID: "d1", ResourceType: model.ResourceTypeDashboard,
+		}
+	}
+
+	mt.Run("unique indexes include role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultiRole = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		system := mt.GetStartedEvent().Command
+		assert.Contains(t, indexNames(system), "uniq_user_role_per_namespace_scope")
+		resource := mt.GetStartedEvent().Command
+		assert.Contains(t, indexNames(resource), "uniq_user_role_per_resource_scope")
+		assert.Contains(t, resource.Lookup("indexes").String(), `"role"`)
+	})
+
+	mt.Run("upsert filter is keyed on the assigned role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultiRole = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), editor()))
+		publisher := editor()
+		publisher.Role = "publisher"
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), publisher))
+
+		assert.Equal(t, model.RoleResourceEditor, upsertFilter(mt).Lookup("role").StringValue())
+		assert.Equal(t, "publisher", upsertFilter(mt).Lookup("role").StringValue())
+	})
+
+	mt.Run("default upsert filter replaces any role but owner", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), editor()))
+		assert.Equal(t, model.RoleResourceOwner, upsertFilter(mt).Lookup("role", "$ne").StringValue())
+	})
+
+	mt.Run("delete removes every held role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultiRole = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(2)}, bson.E{Key: "nModified", Value: int32(2)}))
+
+		deleted, err := repo.DeleteUserRole(context.Background(), "", "u1", model.ScopeResource, "d1", model.ResourceTypeDashboard, "", "caller")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		assert.True(t, updates[0].Document().Lookup("multi").Boolean())
+	})
+
+	mt.Run("transfer keeps the old owner's other roles", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultiRole = true
+		repo.Standalone = true
+		retired := bson.D{{Key: "user_id", Value: "owner_1"}, {Key: "role", Value: model.RoleResourceOwner}}
+		updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)})
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: retired}), // retire owner role
+			updated, // upsert admin role
+			updated, // promote
+		)
+
+		err := repo.TransferResourceOwner(context.Background(), "", "d1", model.ResourceTypeDashboard, "owner_1", "user_x", "owner_1")
+		assert.NoError(t, err)
+
+		retire := mt.GetStartedEvent().Command
+		assert.Equal(t, "findAndModify", retire.Index(0).Key())
+		assert.NotNil(t, retire.Lookup("update", "$set", "deleted_at"))
+		assert.Equal(t, model.RoleResourceAdmin, upsertFilter(mt).Lookup("role").StringValue())
+		assert.Equal(t, model.RoleResourceOwner, upsertFilter(mt).Lookup("role").StringValue())
+	})
+}
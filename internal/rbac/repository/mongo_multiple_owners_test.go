@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMultipleOwnersIndex(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	index := func(cmd bson.Raw, name string) bson.Raw {
+		indexes, _ := cmd.Lookup("indexes").Array().Values()
+		for _, idx := range indexes {
+			if doc := idx.Document(); doc.Lookup("name").StringValue() == name {
+				return doc
+			}
+		}
+		return nil
+	}
+
+	mt.Run("default owner index is unique", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		owner := index(mt.GetStartedEvent().Command, "unique_system_owner_v2")
+		if assert.NotNil(t, owner) {
+			assert.True(t, owner.Lookup("unique").Boolean())
+		}
+	})
+
+	mt.Run("multiple owners index is not unique", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		cmd := mt.GetStartedEvent().Command
+		assert.Nil(t, index(cmd, "unique_system_owner_v2"))
+		owner := index(cmd, "idx_system_owners")
+		if assert.NotNil(t, owner) {
+			_, err := owner.LookupErr("unique")
+			assert.Error(t, err, "co-owners must not be rejected by the index")
+			assert.NotNil(t, owner.Lookup("partialFilterExpression", "role"))
+		}
+	})
+}
+
+func TestCoOwnerDowngradeAndRemoval(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	update := func(mt *mtest.T) bson.Raw {
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		return updates[0].Document()
+	}
+	matched := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+	}
+	admin := func() *model.UserRole {
+		return &model.UserRole{UserID: "owner_2", UserType: model.UserTypeMember, Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_1", UpdatedBy: "owner_1"}
+	}
+
+	mt.Run("downgrade updates the owner role in place", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		mt.AddMockResponses(matched(1))
+
+		assert.NoError(t, repo.DemoteSystemOwner(context.Background(), admin()))
+		u := update(mt)
+		assert.Equal(t, model.RoleSystemOwner, u.Lookup("q", "role").StringValue(), "the owner role itself is matched")
+		assert.Equal(t, "owner_2", u.Lookup("q", "user_id").StringValue())
+		assert.Equal(t, "NS_1", u.Lookup("q", "namespace").StringValue())
+		assert.Equal(t, "admin", u.Lookup("u", "$set", "role").StringValue())
+		_, err := u.LookupErr("upsert")
+		assert.Error(t, err, "a missing owner must not insert a second role document")
+	})
+
+	mt.Run("downgrade of a user who no longer owns the namespace returns ErrOwnerNotFound", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		mt.AddMockResponses(matched(0))
+
+		assert.ErrorIs(t, repo.DemoteSystemOwner(context.Background(), admin()), ErrOwnerNotFound)
+	})
+
+	mt.Run("multi-role downgrade soft deletes the owner role and upserts the new one", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		repo.MultiRole = true
+		mt.AddMockResponses(matched(1), matched(1))
+
+		assert.NoError(t, repo.DemoteSystemOwner(context.Background(), admin()))
+		owner := update(mt)
+		assert.Equal(t, model.RoleSystemOwner, owner.Lookup("q", "role").StringValue())
+		assert.NotNil(t, owner.Lookup("u", "$set", "deleted_at"))
+		added := update(mt)
+		assert.Equal(t, "admin", added.Lookup("q", "role").StringValue())
+		assert.True(t, added.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("removal soft deletes the owner role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		mt.AddMockResponses(matched(1))
+
+		deleted, err := repo.DeleteSystemOwner(context.Background(), "NS_1", "owner_2", "owner_1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		u := update(mt)
+		assert.Equal(t, model.RoleSystemOwner, u.Lookup("q", "role").StringValue(), "the owner role itself is matched")
+		assert.Equal(t, "owner_2", u.Lookup("q", "user_id").StringValue())
+		assert.Equal(t, "owner_1", u.Lookup("u", "$set", "deleted_by").StringValue())
+	})
+
+	mt.Run("removal of a user who no longer owns the namespace returns ErrOwnerNotFound", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		mt.AddMockResponses(matched(0))
+
+		_, err := repo.DeleteSystemOwner(context.Background(), "NS_1", "owner_2", "owner_1")
+		assert.ErrorIs(t, err, ErrOwnerNotFound)
+	})
+
+	mt.Run("multi-role removal also removes the user's other roles", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.MultipleOwners = true
+		repo.MultiRole = true
+		mt.AddMockResponses(matched(1), matched(2))
+
+		deleted, err := repo.DeleteSystemOwner(context.Background(), "NS_1", "owner_2", "owner_1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		update(mt)
+		others := update(mt)
+		_, err = others.LookupErr("q", "role")
+		assert.Error(t, err, "every remaining role of the user is matched")
+		assert.True(t, others.Lookup("multi").Boolean())
+	})
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"rbac7/internal/rbac/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepository) GetNamespaceResourceRoles(ctx context.Context, namespace string) (*model.NamespaceResourceRoles, error) {
+	var override model.NamespaceResourceRoles
+	err := r.NamespaceResourceRoles.FindOne(ctx, bson.M{"_id": namespace}).Decode(&override)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *MongoRepository) SetNamespaceResourceRoles(ctx context.Context, override *model.NamespaceResourceRoles) error {
+	override.UpdatedAt = time.Now()
+	_, err := r.NamespaceResourceRoles.ReplaceOne(ctx, bson.M{"_id": override.Namespace}, override, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoRepository) DeleteNamespaceResourceRoles(ctx context.Context, namespace string) error {
+	_, err := r.NamespaceResourceRoles.DeleteOne(ctx, bson.M{"_id": namespace})
+	return err
+}
+
+// RenameNamespace moves every role document (including soft-deleted ones), history entry and the
+// resource roles override of namespace from to namespace to, in one transaction.
+// It returns ErrDuplicate when to already holds data the unique indexes forbid merging with from:
+// a second system owner, a role of the same user, or its own override.
+func (r *MongoRepository) RenameNamespace(ctx context.Context, from, to, updatedBy string) (*model.RenameNamespaceResult, error) {
+	var result *model.RenameNamespaceResult
+	err := r.inTransaction(ctx, func(sessCtx context.Context) error {
+		result = &model.RenameNamespaceResult{From: from, To: to}
+
+		// 1. Owner conflict: checked up front so Standalone mode fails before moving anything
+		fromOwner, err := r.GetSystemOwner(sessCtx, from)
+		if err != nil {
+			return err
+		}
+		toOwner, err := r.GetSystemOwner(sessCtx, to)
+		if err != nil {
+			return err
+		}
+		if fromOwner != nil && toOwner != nil {
+			return ErrDuplicate
+		}
+
+		// 2. Roles; updated_at moves so incremental sync picks up the new namespace
+		update := bson.M{"$set": bson.M{
+			"namespace":  to,
+			"updated_at": time.Now(),
+			"updated_by": updatedBy,
+		}}
+		roleColls := append([]*mongo.Collection{r.SystemRoles}, r.resourceCollections("")...)
+		for _, coll := range roleColls {
+			res, err := coll.UpdateMany(sessCtx, bson.M{"namespace": from}, update)
+			if err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					return ErrDuplicate
+				}
+				return err
+			}
+			result.RolesMigrated += res.ModifiedCount
+		}
+
+		// 3. History
+		res, err := r.History.UpdateMany(sessCtx, bson.M{"namespace": from}, bson.M{"$set": bson.M{"namespace": to}})
+		if err != nil {
+			return err
+		}
+		result.HistoryMigrated = res.ModifiedCount
+
+		// 4. Resource roles override (keyed by namespace, so it is re-inserted under the new key)
+		override, err := r.GetNamespaceResourceRoles(sessCtx, from)
+		if err != nil {
+			return err
+		}
+		if override == nil {
+			return nil
+		}
+		override.Namespace = to
+		if _, err := r.NamespaceResourceRoles.InsertOne(sessCtx, override); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return ErrDuplicate
+			}
+			return err
+		}
+		if err := r.DeleteNamespaceResourceRoles(sessCtx, from); err != nil {
+			return err
+		}
+		result.ResourceRolesMigrated = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreNamespace writes the snapshot's roles (already moved to namespace) and resource roles override
+// into namespace, in one transaction. Replace soft deletes the namespace's active roles first; merge
+// overwrites the roles of users the snapshot names but never replaces a current owner. It returns
+// ErrDuplicate when the snapshot cannot be merged under the unique indexes, e.g. a second system owner.
+func (r *MongoRepository) RestoreNamespace(ctx context.Context, namespace string, snapshot *model.NamespaceSnapshot, replace bool, updatedBy string) (*model.RestoreNamespaceResult, error) {
+	var result *model.RestoreNamespaceResult
+	err := r.inTransaction(ctx, func(sessCtx context.Context) error {
+		result = &model.RestoreNamespaceResult{Namespace: namespace}
+		now := time.Now()
+
+		// 1. Replace clears the namespace; merge checks the owner up front so Standalone mode fails
+		// before writing anything
+		if replace {
+			remove := bson.M{"$set": bson.M{"deleted_at": now, "deleted_by": updatedBy}}
+			for _, coll := range append([]*mongo.Collection{r.SystemRoles}, r.resourceCollections("")...) {
+				res, err := coll.UpdateMany(sessCtx, bson.M{"namespace": namespace, "deleted_at": nil}, remove)
+				if err != nil {
+					return err
+				}
+				result.RolesRemoved += res.ModifiedCount
+			}
+		} else if owner := snapshotSystemOwner(snapshot); owner != "" && !r.MultipleOwners {
+			current, err := r.GetSystemOwner(sessCtx, namespace)
+			if err != nil {
+				return err
+			}
+			if current != nil && current.UserID != owner {
+				return ErrDuplicate
+			}
+		}
+
+		// 2. Roles, one bulk write per collection
+		writes := make(map[*mongo.Collection][]mongo.WriteModel)
+		var colls []*mongo.Collection
+		for _, role := range snapshot.Roles {
+			coll := r.SystemRoles
+			if role.Scope == model.ScopeResource {
+				coll = r.resourceCollection(role.ResourceType)
+			}
+			if _, ok := writes[coll]; !ok {
+				colls = append(colls, coll)
+			}
+			writes[coll] = append(writes[coll], mongo.NewUpdateOneModel().
+				SetFilter(r.restoreFilter(role, replace)).
+				SetUpdate(restoreUpdate(role, updatedBy, now)).
+				SetUpsert(true))
+		}
+		for _, coll := range colls {
+			if _, err := coll.BulkWrite(sessCtx, writes[coll]); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					return ErrDuplicate
+				}
+				return err
+			}
+		}
+		result.RolesRestored = int64(len(snapshot.Roles))
+
+		// 3. Resource roles override
+		if snapshot.ResourceRoles != nil {
+			override := &model.NamespaceResourceRoles{Namespace: namespace, Roles: snapshot.ResourceRoles, UpdatedBy: updatedBy}
+			if err := r.SetNamespaceResourceRoles(sessCtx, override); err != nil {
+				return err
+			}
+			result.ResourceRolesRestored = true
+		} else if replace {
+			if err := r.DeleteNamespaceResourceRoles(sessCtx, namespace); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// snapshotSystemOwner returns the user the snapshot makes system owner, or ""
+func snapshotSystemOwner(snapshot *model.NamespaceSnapshot) string {
+	for _, role := range snapshot.Roles {
+		if role.Scope == model.ScopeSystem && role.Role == model.RoleSystemOwner {
+			return role.UserID
+		}
+	}
+	return ""
+}
+
+// restoreFilter matches the document a restored role overwrites, keyed like the unique indexes.
+// When merging, a current owner role only matches a restored owner role.
+func (r *MongoRepository) restoreFilter(role *model.UserRole, replace bool) bson.M {
+	filter := bson.M{
+		"user_id":   role.UserID,
+		"user_type": role.UserType,
+		"scope":     role.Scope,
+	}
+	if role.Scope == model.ScopeSystem {
+		filter["namespace"] = role.Namespace
+	} else {
+		filter["resource_id"] = role.ResourceID
+		filter["resource_type"] = role.ResourceType
+		if r.NamespacedResources {
+			filter["namespace"] = role.Namespace
+		}
+	}
+	if !replace && role.Role != model.RoleSystemOwner {
+		filter["role"] = bson.M{"$ne": model.RoleSystemOwner} // resource owner is "owner" too
+	}
+	r.keyOnRole(filter, role)
+	return filter
+}
+
+// restoreUpdate writes role as a live role created by the restore
+func restoreUpdate(role *model.UserRole, updatedBy string, now time.Time) bson.M {
+	update := bson.M{
+		"$set": bson.M{
+			"role":               role.Role,
+			"namespace":          role.Namespace,
+			"resource_id":        role.ResourceID,
+			"resource_type":      role.ResourceType,
+			"parent_resource_id": role.ParentResourceID,
+			"updated_at":         now,
+			"updated_by":         updatedBy,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+			"created_by": updatedBy,
+			"user_id":    role.UserID,
+			"user_type":  role.UserType,
+			"scope":      role.Scope,
+		},
+		"$unset": bson.M{
+			"deleted_at": "",
+			"deleted_by": "",
+		},
+	}
+	setTemporaryGrant(update, role)
+	return update
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNamespacedResourceIndex(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	indexKeys := func(cmd bson.Raw, name string) []string {
+		indexes, _ := cmd.Lookup("indexes").Array().Values()
+		for _, idx := range indexes {
+			doc := idx.Document()
+			if doc.Lookup("name").StringValue() != name {
+				continue
+			}
+			elems, _ := doc.Lookup("key").Document().Elements()
+			keys := make([]string, 0, len(elems))
+			for _, e := range elems {
+				keys = append(keys, e.Key())
+			}
+			return keys
+		}
+		return nil
+	}
+
+	mt.Run("default index ignores namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		mt.GetStartedEvent() // system roles
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, []string{"user_id", "user_type", "scope", "resource_type", "resource_id"}, indexKeys(cmd, "uniq_user_per_resource_scope"))
+	})
+
+	mt.Run("namespaced index includes namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		mt.GetStartedEvent() // system roles
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, []string{"user_id", "user_type", "scope", "namespace", "resource_type", "resource_id"}, indexKeys(cmd, "uniq_user_per_namespace_resource_scope"))
+		assert.Equal(t, []string{"scope", "namespace", "resource_id", "resource_type"}, indexKeys(cmd, "unique_namespace_resource_owner"))
+	})
+
+	mt.Run("same resource id in two namespaces is upserted independently", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		for _, ns := range []string{"NS_A", "NS_B"} {
+			err := repo.UpsertUserRole(context.Background(), &model.UserRole{
+				UserID: "u1", UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource,
+				Namespace: ns, ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget,
+			})
+			assert.NoError(t, err)
+		}
+
+		for _, ns := range []string{"NS_A", "NS_B"} {
+			updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+			filter := updates[0].Document().Lookup("q").Document()
+			assert.Equal(t, ns, filter.Lookup("namespace").StringValue())
+			assert.Equal(t, "lw_1", filter.Lookup("resource_id").StringValue())
+		}
+	})
+
+	mt.Run("default upsert filter ignores namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.UpsertUserRole(context.Background(), &model.UserRole{
+			UserID: "u1", UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource,
+			Namespace: "NS_A", ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget,
+		})
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		_, err = updates[0].Document().Lookup("q").Document().LookupErr("namespace")
+		assert.Error(t, err)
+	})
+
+	// countFilter returns the $match of a CountDocuments aggregate
+	countFilter := func(cmd bson.Raw) bson.Raw {
+		return cmd.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+	}
+	countResponse := func(n int32) bson.D {
+		return mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+	}
+
+	mt.Run("permission checks stay inside the namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(countResponse(1), countResponse(0), countResponse(0))
+
+		ctx := context.Background()
+		ok, err := repo.HasAnyResourceRole(ctx, "NS_A", "u1", "lw_1", model.ResourceTypeLibraryWidget, []string{model.RoleResourceViewer})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = repo.HasResourceRole(ctx, "NS_B", "u1", "lw_1", model.ResourceTypeLibraryWidget, model.RoleResourceViewer)
+		assert.NoError(t, err)
+		assert.False(t, ok, "a role on lw_1 in NS_A grants nothing on lw_1 in NS_B")
+		_, err = repo.CountResourceRoles(ctx, "NS_B", "lw_1", model.ResourceTypeLibraryWidget)
+		assert.NoError(t, err)
+
+		for _, ns := range []string{"NS_A", "NS_B", "NS_B"} {
+			filter := countFilter(mt.GetStartedEvent().Command)
+			assert.Equal(t, ns, filter.Lookup("namespace").StringValue())
+			assert.Equal(t, "lw_1", filter.Lookup("resource_id").StringValue())
+		}
+	})
+
+	mt.Run("owner lookups stay inside the namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(
+			countResponse(0),
+			mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch),
+		)
+
+		ctx := context.Background()
+		count, err := repo.CountResourceOwners(ctx, "NS_B", "d1", model.ResourceTypeDashboard)
+		assert.NoError(t, err)
+		assert.Zero(t, count, "NS_A's owner does not count for NS_B")
+		owner, err := repo.GetResourceOwner(ctx, "NS_B", "d1", model.ResourceTypeDashboard)
+		assert.NoError(t, err)
+		assert.Nil(t, owner)
+
+		assert.Equal(t, "NS_B", countFilter(mt.GetStartedEvent().Command).Lookup("namespace").StringValue())
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, "NS_B", find.Lookup("filter", "namespace").StringValue())
+	})
+
+	mt.Run("owner transfer demotes and promotes inside the namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // demote
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // promote
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		err := repo.TransferResourceOwner(context.Background(), "NS_B", "d1", model.ResourceTypeDashboard, "owner_1", "user_x", "owner_1")
+		assert.NoError(t, err)
+
+		for _, user := range []string{"owner_1", "user_x"} {
+			updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+			filter := updates[0].Document().Lookup("q").Document()
+			assert.Equal(t, user, filter.Lookup("user_id").StringValue())
+			assert.Equal(t, "NS_B", filter.Lookup("namespace").StringValue())
+		}
+	})
+
+	mt.Run("resource delete stays inside the namespace and resource type", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(2)}, bson.E{Key: "nModified", Value: int32(2)}))
+
+		_, err := repo.SoftDeleteResourceUserRoles(context.Background(), &model.SoftDeleteResourceReq{
+			ResourceID: "d1", ResourceType: model.ResourceTypeDashboard, ChildResourceIDs: []string{"w1"}, Namespace: "NS_A",
+		}, "owner_1")
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		filter := updates[0].Document().Lookup("q").Document()
+		assert.Equal(t, "NS_A", filter.Lookup("namespace").StringValue())
+		targets, _ := filter.Lookup("$or").Array().Values()
+		if assert.Len(t, targets, 2) {
+			assert.Equal(t, "d1", targets[0].Document().Lookup("resource_id").StringValue())
+			assert.Equal(t, model.ResourceTypeDashboard, targets[0].Document().Lookup("resource_type").StringValue())
+			assert.Equal(t, model.ResourceTypeDashboardWidget, targets[1].Document().Lookup("resource_type").StringValue())
+		}
+	})
+
+	mt.Run("widget cascade stays inside the namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.NamespacedResources = true
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}))
+
+		_, err := repo.DeleteUserRolesByParent(context.Background(), "NS_A", "u1", "d1", model.ResourceTypeDashboardWidget, "owner_1")
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		assert.Equal(t, "NS_A", updates[0].Document().Lookup("q", "namespace").StringValue())
+	})
+
+	mt.Run("default lookups ignore namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(countResponse(1), countResponse(1))
+
+		ctx := context.Background()
+		_, err := repo.HasAnyResourceRole(ctx, "NS_A", "u1", "d1", model.ResourceTypeDashboard, []string{model.RoleResourceViewer})
+		assert.NoError(t, err)
+		_, err = repo.CountResourceOwners(ctx, "NS_A", "d1", model.ResourceTypeDashboard)
+		assert.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err := countFilter(mt.GetStartedEvent().Command).LookupErr("namespace")
+			assert.Error(t, err)
+		}
+	})
+
+	mt.Run("default resource delete ignores namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}))
+
+		_, err := repo.SoftDeleteResourceUserRoles(context.Background(), &model.SoftDeleteResourceReq{
+			ResourceID: "d1", ResourceType: model.ResourceTypeDashboard, Namespace: "NS_A",
+		}, "owner_1")
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		filter := updates[0].Document().Lookup("q").Document()
+		_, err = filter.LookupErr("namespace")
+		assert.Error(t, err)
+		assert.Equal(t, model.ResourceTypeDashboard, filter.Lookup("resource_type").StringValue())
+	})
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNamespaceResourceRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("get override found", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "rbac.namespace_resource_roles", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "NS_1"},
+			{Key: "roles", Value: bson.A{"admin", "viewer"}},
+		}))
+
+		override, err := repo.GetNamespaceResourceRoles(context.Background(), "NS_1")
+		assert.NoError(t, err)
+		assert.Equal(t, "NS_1", override.Namespace)
+		assert.Equal(t, []string{"admin", "viewer"}, override.Roles)
+		assert.Equal(t, "namespace_resource_roles", mt.GetStartedEvent().Command.Lookup("find").StringValue())
+	})
+
+	mt.Run("get override not found returns nil", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "rbac.namespace_resource_roles", mtest.FirstBatch))
+
+		override, err := repo.GetNamespaceResourceRoles(context.Background(), "NS_2")
+		assert.NoError(t, err)
+		assert.Nil(t, override)
+	})
+
+	mt.Run("set override upserts by namespace", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}))
+
+		err := repo.SetNamespaceResourceRoles(context.Background(), &model.NamespaceResourceRoles{
+			Namespace: "NS_1", Roles: []string{"viewer"}, UpdatedBy: "owner_1",
+		})
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		assert.Equal(t, "NS_1", updates[0].Document().Lookup("q", "_id").StringValue())
+		assert.True(t, updates[0].Document().Lookup("upsert").Boolean())
+	})
+}
+
+func TestRenameNamespace(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	ownerDoc := func(userID, namespace string) bson.D {
+		return bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "scope", Value: model.ScopeSystem},
+			{Key: "namespace", Value: namespace},
+			{Key: "role", Value: model.RoleSystemOwner},
+		}
+	}
+
+	mt.Run("clean rename moves roles, history and override in one transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, ownerDoc("owner_1", "OLD")),            // from owner
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch),                                        // to owner
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(3)}, bson.E{Key: "nModified", Value: int32(3)}), // system roles
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(2)}, bson.E{Key: "nModified", Value: int32(2)}), // resource roles
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(7)}, bson.E{Key: "nModified", Value: int32(7)}), // history
+			mtest.CreateCursorResponse(0, "rbac.namespace_resource_roles", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: "OLD"},
+				{Key: "roles", Value: bson.A{"viewer"}},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}), // override insert
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}), // override delete
+			mtest.CreateSuccessResponse(),                                  // commit
+		)
+
+		result, err := repo.RenameNamespace(context.Background(), "OLD", "NEW", "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, &model.RenameNamespaceResult{From: "OLD", To: "NEW", RolesMigrated: 5, HistoryMigrated: 7, ResourceRolesMigrated: true}, result)
+
+		mt.GetStartedEvent() // from owner
+		mt.GetStartedEvent() // to owner
+
+		for _, coll := range []string{"user_roles", "user_resource_roles"} {
+			upd := mt.GetStartedEvent().Command
+			assert.Equal(t, coll, upd.Lookup("update").StringValue())
+			assert.NotNil(t, upd.Lookup("lsid"))
+			updates, _ := upd.Lookup("updates").Array().Values()
+			assert.Equal(t, "OLD", updates[0].Document().Lookup("q", "namespace").StringValue())
+			assert.Equal(t, "NEW", updates[0].Document().Lookup("u", "$set", "namespace").StringValue())
+			assert.Equal(t, "mod_1", updates[0].Document().Lookup("u", "$set", "updated_by").StringValue())
+			assert.True(t, updates[0].Document().Lookup("multi").Boolean())
+		}
+
+		hist := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_role_history", hist.Lookup("update").StringValue())
+
+		mt.GetStartedEvent() // override find
+		insert := mt.GetStartedEvent().Command
+		docs, _ := insert.Lookup("documents").Array().Values()
+		assert.Equal(t, "NEW", docs[0].Document().Lookup("_id").StringValue())
+		del := mt.GetStartedEvent().Command
+		deletes, _ := del.Lookup("deletes").Array().Values()
+		assert.Equal(t, "OLD", deletes[0].Document().Lookup("q", "_id").StringValue())
+
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("target with its own owner is rejected before any write", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, ownerDoc("owner_1", "OLD")),
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, ownerDoc("owner_2", "NEW")),
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		result, err := repo.RenameNamespace(context.Background(), "OLD", "NEW", "mod_1")
+		assert.ErrorIs(t, err, ErrDuplicate)
+		assert.Nil(t, result)
+
+		mt.GetStartedEvent() // from owner
+		mt.GetStartedEvent() // to owner
+		assert.Equal(t, "abortTransaction", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("unique index violation aborts the rename", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		result, err := repo.RenameNamespace(context.Background(), "OLD", "NEW", "mod_1")
+		assert.ErrorIs(t, err, ErrDuplicate)
+		assert.Nil(t, result)
+	})
+}
+
+func TestRestoreNamespace(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	snapshot := func() *model.NamespaceSnapshot {
+		return &model.NamespaceSnapshot{
+			Version:   model.NamespaceSnapshotVersion,
+			Namespace: "SRC",
+			Roles: []*model.UserRole{
+				{UserID: "owner_1", UserType: model.UserTypeMember, Role: model.RoleSystemOwner, Scope: model.ScopeSystem, Namespace: "NEW"},
+				{UserID: "u_admin", UserType: model.UserTypeMember, Role: model.RoleSystemAdmin, Scope: model.ScopeSystem, Namespace: "NEW"},
+				{UserID: "owner_1", UserType: model.UserTypeMember, Role: model.RoleResourceOwner, Scope: model.ScopeResource,
+					Namespace: "NEW", ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget},
+			},
+			ResourceRoles: []string{"viewer"},
+		}
+	}
+	ok := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+	}
+
+	mt.Run("merge into a fresh namespace upserts every role and the override", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch), // current owner
+			ok(2),                         // system roles
+			ok(1),                         // resource roles
+			ok(1),                         // override
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		result, err := repo.RestoreNamespace(context.Background(), "NEW", snapshot(), false, "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, &model.RestoreNamespaceResult{Namespace: "NEW", RolesRestored: 3, ResourceRolesRestored: true}, result)
+
+		mt.GetStartedEvent() // current owner
+		system := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", system.Lookup("update").StringValue())
+		updates, _ := system.Lookup("updates").Array().Values()
+		if assert.Len(t, updates, 2) {
+			owner, admin := updates[0].Document(), updates[1].Document()
+			assert.True(t, owner.Lookup("upsert").Boolean())
+			assert.Equal(t, "NEW", owner.Lookup("q", "namespace").StringValue())
+			assert.Equal(t, model.RoleSystemOwner, owner.Lookup("u", "$set", "role").StringValue())
+			_, err := owner.LookupErr("q", "role")
+			assert.Error(t, err, "the owner role may overwrite the user's member role")
+			assert.Equal(t, model.RoleSystemOwner, admin.Lookup("q", "role", "$ne").StringValue(), "merging never overwrites an owner")
+			assert.Equal(t, "mod_1", admin.Lookup("u", "$set", "updated_by").StringValue())
+		}
+
+		resource := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_resource_roles", resource.Lookup("update").StringValue())
+		override := mt.GetStartedEvent().Command
+		assert.Equal(t, "namespace_resource_roles", override.Lookup("update").StringValue())
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("replace soft deletes the namespace's roles first", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		restore := snapshot()
+		restore.ResourceRoles = nil
+		mt.AddMockResponses(
+			ok(4), ok(1), // soft delete system, resource
+			ok(2), ok(1), // system roles, resource roles
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}), // override delete
+			mtest.CreateSuccessResponse(),                                  // commit
+		)
+
+		result, err := repo.RestoreNamespace(context.Background(), "NEW", restore, true, "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), result.RolesRemoved)
+		assert.Equal(t, int64(3), result.RolesRestored)
+		assert.False(t, result.ResourceRolesRestored)
+
+		for _, coll := range []string{"user_roles", "user_resource_roles"} {
+			upd := mt.GetStartedEvent().Command
+			assert.Equal(t, coll, upd.Lookup("update").StringValue())
+			updates, _ := upd.Lookup("updates").Array().Values()
+			assert.Equal(t, "NEW", updates[0].Document().Lookup("q", "namespace").StringValue())
+			assert.Equal(t, "mod_1", updates[0].Document().Lookup("u", "$set", "deleted_by").StringValue())
+			assert.True(t, updates[0].Document().Lookup("multi").Boolean())
+		}
+		mt.GetStartedEvent() // system roles
+		mt.GetStartedEvent() // resource roles
+		del := mt.GetStartedEvent().Command
+		assert.Equal(t, "namespace_resource_roles", del.Lookup("delete").StringValue())
+	})
+
+	mt.Run("merge onto another owner is rejected before any write", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, bson.D{
+				{Key: "user_id", Value: "owner_2"}, {Key: "scope", Value: model.ScopeSystem}, {Key: "role", Value: model.RoleSystemOwner},
+			}),
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		result, err := repo.RestoreNamespace(context.Background(), "NEW", snapshot(), false, "mod_1")
+		assert.ErrorIs(t, err, ErrDuplicate)
+		assert.Nil(t, result)
+
+		mt.GetStartedEvent() // current owner
+		assert.Equal(t, "abortTransaction", mt.GetStartedEvent().CommandName)
+	})
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestPendingOwners(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	updated := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+	}
+	update := func(mt *mtest.T) (bson.Raw, bson.Raw) {
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		doc := updates[0].Document()
+		return doc.Lookup("q").Document(), doc.Lookup("u").Document()
+	}
+	newRepo := func(mt *mtest.T) *MongoRepository {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.PendingOwners = true
+		repo.Standalone = true
+		return repo
+	}
+
+	mt.Run("transfer to a user without a member role creates a pending owner", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(
+			updated(1), // demote
+			updated(0), // no member role to promote
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: int32(0)}, {Key: "_id", Value: "x"}}}}),
+		)
+
+		err := repo.TransferSystemOwner(context.Background(), "NS_1", "owner_1", "invitee", "owner_1")
+		assert.NoError(t, err)
+
+		demoteFilter, _ := update(mt)
+		assert.Equal(t, bson.TypeEmbeddedDocument, demoteFilter.Lookup("user_type").Type, "pending owners can be demoted too")
+
+		memberFilter, _ := update(mt)
+		assert.Equal(t, model.UserTypeMember, memberFilter.Lookup("user_type").StringValue())
+
+		pendingFilter, pendingUpdate := update(mt)
+		assert.Equal(t, model.UserTypePending, pendingFilter.Lookup("user_type").StringValue())
+		assert.Equal(t, model.UserTypePending, pendingUpdate.Lookup("$set", "user_type").StringValue())
+		assert.Equal(t, model.RoleSystemOwner, pendingUpdate.Lookup("$set", "role").StringValue())
+	})
+
+	mt.Run("transfer to an existing member keeps them a member", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(updated(1), updated(1))
+
+		err := repo.TransferResourceOwner(context.Background(), "", "d1", model.ResourceTypeDashboard, "owner_1", "u_member", "owner_1")
+		assert.NoError(t, err)
+
+		mt.GetStartedEvent() // demote
+		_, promote := update(mt)
+		assert.Equal(t, model.UserTypeMember, promote.Lookup("$set", "user_type").StringValue())
+		assert.Nil(t, mt.GetStartedEvent(), "no pending upsert")
+	})
+
+	mt.Run("pending roles are looked up in every collection", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		repo.Standalone = false
+		pending := bson.D{
+			{Key: "user_id", Value: "invitee"}, {Key: "user_type", Value: model.UserTypePending},
+			{Key: "role", Value: model.RoleSystemOwner}, {Key: "scope", Value: model.ScopeSystem}, {Key: "namespace", Value: "NS_1"},
+		}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "db.user_roles", mtest.FirstBatch, pending),
+			mtest.CreateCursorResponse(0, "db.user_resource_roles", mtest.FirstBatch),
+		)
+
+		roles, err := repo.FindPendingRoles(context.Background(), "invitee")
+		assert.NoError(t, err)
+		assert.Len(t, roles, 1)
+		assert.Equal(t, "NS_1", roles[0].Namespace)
+
+		for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+			assert.Equal(t, "find", evt.CommandName)
+			assert.Equal(t, model.UserTypePending, evt.Command.Lookup("filter", "user_type").StringValue())
+			_, inTxn := evt.Command.LookupErr("txnNumber")
+			assert.Error(t, inTxn, "the lookup opens no transaction")
+		}
+	})
+
+	mt.Run("activation flips a pending role to member", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(0)}), // no colliding member role
+			updated(1),
+		)
+
+		role := &model.UserRole{UserID: "invitee", UserType: model.UserTypePending, Role: model.RoleSystemOwner, Scope: model.ScopeSystem, Namespace: "NS_1"}
+		assert.NoError(t, repo.ActivatePendingRole(context.Background(), role))
+
+		deletes, _ := mt.GetStartedEvent().Command.Lookup("deletes").Array().Values()
+		memberKey := deletes[0].Document().Lookup("q").Document()
+		assert.Equal(t, model.UserTypeMember, memberKey.Lookup("user_type").StringValue())
+		assert.Equal(t, "NS_1", memberKey.Lookup("namespace").StringValue())
+
+		filter, set := update(mt)
+		assert.Equal(t, model.UserTypePending, filter.Lookup("user_type").StringValue())
+		assert.Equal(t, model.UserTypeMember, set.Lookup("$set", "user_type").StringValue())
+	})
+
+	mt.Run("activating a role that is no longer pending returns ErrNoDocuments", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(0)}), updated(0))
+
+		role := &model.UserRole{UserID: "invitee", Role: model.RoleResourceOwner, Scope: model.ScopeResource, ResourceID: "d1", ResourceType: model.ResourceTypeDashboard}
+		assert.ErrorIs(t, repo.ActivatePendingRole(context.Background(), role), mongo.ErrNoDocuments)
+	})
+
+	mt.Run("permission checks skip pending owners unless configured active", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		counted := mtest.CreateCursorResponse(0, "db.user_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(0)}})
+		mt.AddMockResponses(counted, counted)
+
+		_, err := repo.HasAnySystemRole(context.Background(), "NS_1", "invitee", []string{model.RoleSystemOwner})
+		assert.NoError(t, err)
+		match := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		assert.Equal(t, model.UserTypePending, match.Lookup("user_type", "$ne").StringValue())
+
+		repo.PendingOwnersActive = true
+		_, err = repo.HasAnySystemRole(context.Background(), "NS_1", "invitee", []string{model.RoleSystemOwner})
+		assert.NoError(t, err)
+		match = mt.GetStartedEvent().Command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		_, err = match.LookupErr("user_type")
+		assert.Error(t, err, "active pending owners are checked like members")
+	})
+}
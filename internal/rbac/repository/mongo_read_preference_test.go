@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestReadPreference(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	readMode := func(mt *mtest.T) string {
+		evt := mt.GetStartedEvent()
+		mode, ok := evt.Command.Lookup("$readPreference", "mode").StringValueOK()
+		if !ok {
+			return ""
+		}
+		return mode
+	}
+	countResponse := func(mt *mtest.T, coll string) bson.D {
+		return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+coll, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}})
+	}
+
+	mt.Run("permission checks use the configured read preference", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.ReadPreference = readpref.SecondaryPreferred()
+		mt.AddMockResponses(countResponse(mt, "user_roles"), countResponse(mt, "user_resource_roles"))
+
+		_, err := repo.HasAnySystemRole(context.Background(), "NS_1", "u1", []string{"admin"})
+		assert.NoError(t, err)
+		assert.Equal(t, "secondaryPreferred", readMode(mt))
+
+		_, err = repo.HasAnyResourceRole(context.Background(), "", "u1", "d1", "dashboard", []string{"viewer"})
+		assert.NoError(t, err)
+		assert.Equal(t, "secondaryPreferred", readMode(mt))
+	})
+
+	mt.Run("listing reads use the configured read preference", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.ReadPreference = readpref.Secondary()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_resource_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeResource, ResourceID: "d1", ResourceType: "dashboard"})
+		assert.NoError(t, err)
+		assert.Equal(t, "secondary", readMode(mt))
+	})
+
+	mt.Run("owner lookups guarding writes stay on the primary", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.ReadPreference = readpref.Secondary()
+		mt.AddMockResponses(countResponse(mt, "user_resource_roles"))
+
+		_, err := repo.CountResourceOwners(context.Background(), "", "d1", "dashboard")
+		assert.NoError(t, err)
+		assert.NotContains(t, readMode(mt), "secondary")
+	})
+
+	mt.Run("single role checks guarding owner writes stay on the primary", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.ReadPreference = readpref.Secondary()
+		mt.AddMockResponses(countResponse(mt, "user_roles"), countResponse(mt, "user_resource_roles"))
+
+		_, err := repo.HasSystemRole(context.Background(), "NS_1", "u1", model.RoleSystemOwner)
+		assert.NoError(t, err)
+		assert.NotContains(t, readMode(mt), "secondary")
+
+		_, err = repo.HasResourceRole(context.Background(), "", "u1", "d1", "dashboard", model.RoleResourceOwner)
+		assert.NoError(t, err)
+		assert.NotContains(t, readMode(mt), "secondary")
+	})
+
+	mt.Run("no read preference leaves reads on the client default", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(countResponse(mt, "user_roles"))
+
+		_, err := repo.HasAnySystemRole(context.Background(), "NS_1", "u1", []string{"admin"})
+		assert.NoError(t, err)
+		assert.NotContains(t, readMode(mt), "secondary")
+	})
+}
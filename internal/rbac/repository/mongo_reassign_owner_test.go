@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestReassignOwnedResources(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	updated := func() bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)})
+	}
+	inserted := func() bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)})
+	}
+
+	mt.Run("transfers two dashboards and a library widget in one transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		owned := []bson.D{
+			{{Key: "user_id", Value: "leaver"}, {Key: "role", Value: "owner"}, {Key: "resource_id", Value: "d1"}, {Key: "resource_type", Value: "dashboard"}},
+			{{Key: "user_id", Value: "leaver"}, {Key: "role", Value: "owner"}, {Key: "resource_id", Value: "d2"}, {Key: "resource_type", Value: "dashboard"}},
+			{{Key: "user_id", Value: "leaver"}, {Key: "role", Value: "owner"}, {Key: "resource_id", Value: "lw1"}, {Key: "resource_type", Value: "library_widget"}},
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, owned...))
+		for range owned {
+			mt.AddMockResponses(updated(), updated(), inserted()) // demote, promote, history
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse()) // commit
+
+		transferred, err := repo.ReassignOwnedResources(context.Background(), "leaver", "successor", "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), transferred)
+
+		find := mt.GetStartedEvent()
+		assert.Equal(t, "find", find.CommandName)
+		filter := find.Command.Lookup("filter").Document()
+		assert.Equal(t, "leaver", filter.Lookup("user_id").StringValue())
+		assert.Equal(t, model.RoleResourceOwner, filter.Lookup("role").StringValue())
+		assert.Equal(t, model.ScopeResource, filter.Lookup("scope").StringValue())
+
+		for _, want := range []struct{ id, resourceType string }{{"d1", "dashboard"}, {"d2", "dashboard"}, {"lw1", "library_widget"}} {
+			demote := mt.GetStartedEvent()
+			updates, _ := demote.Command.Lookup("updates").Array().Values()
+			q := updates[0].Document().Lookup("q").Document()
+			assert.Equal(t, "leaver", q.Lookup("user_id").StringValue())
+			assert.Equal(t, want.id, q.Lookup("resource_id").StringValue())
+			assert.Equal(t, want.resourceType, q.Lookup("resource_type").StringValue())
+			assert.Equal(t, model.RoleResourceAdmin, updates[0].Document().Lookup("u", "$set", "role").StringValue())
+
+			promote := mt.GetStartedEvent()
+			updates, _ = promote.Command.Lookup("updates").Array().Values()
+			q = updates[0].Document().Lookup("q").Document()
+			assert.Equal(t, "successor", q.Lookup("user_id").StringValue())
+			assert.Equal(t, want.id, q.Lookup("resource_id").StringValue())
+			assert.True(t, updates[0].Document().Lookup("upsert").Boolean())
+
+			history := mt.GetStartedEvent()
+			assert.Equal(t, "user_role_history", history.Command.Lookup("insert").StringValue())
+			docs, _ := history.Command.Lookup("documents").Array().Values()
+			entry := docs[0].Document()
+			assert.Equal(t, "reassign_owner", entry.Lookup("operation").StringValue())
+			assert.Equal(t, want.id, entry.Lookup("resource_id").StringValue())
+			assert.Equal(t, "leaver", entry.Lookup("user_id").StringValue())
+			assert.Equal(t, "successor", entry.Lookup("new_owner_id").StringValue())
+
+			assert.Equal(t, demote.Command.Lookup("txnNumber").String(), history.Command.Lookup("txnNumber").String())
+		}
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("user without owned resources transfers nothing", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		transferred, err := repo.ReassignOwnedResources(context.Background(), "leaver", "successor", "mod_1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), transferred)
+	})
+
+	mt.Run("failed transfer rolls back every transfer", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "user_id", Value: "leaver"}, {Key: "role", Value: "owner"}, {Key: "resource_id", Value: "d1"}, {Key: "resource_type", Value: "dashboard"}},
+				bson.D{{Key: "user_id", Value: "leaver"}, {Key: "role", Value: "owner"}, {Key: "resource_id", Value: "d2"}, {Key: "resource_type", Value: "dashboard"}},
+			),
+			updated(), updated(), inserted(), // d1
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad update"}), // d2 demote
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		transferred, err := repo.ReassignOwnedResources(context.Background(), "leaver", "successor", "mod_1")
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), transferred)
+
+		var commands []string
+		for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+			commands = append(commands, evt.CommandName)
+		}
+		assert.Contains(t, commands, "abortTransaction")
+		assert.NotContains(t, commands, "commitTransaction")
+	})
+}
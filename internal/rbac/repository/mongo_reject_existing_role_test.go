@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRejectExistingRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	newRole := func() *model.UserRole {
+		return &model.UserRole{UserID: "user_x", UserType: "member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1"}
+	}
+	duplicateKey := mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"})
+	countResponse := func(n int32) bson.D {
+		if n == 0 {
+			return mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch)
+		}
+		return mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+	}
+
+	mt.Run("assigning a role the user holds returns ErrAlreadyHasRole without writing", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RejectExistingRole = true
+		mt.AddMockResponses(duplicateKey, countResponse(1))
+
+		err := repo.UpsertUserRole(context.Background(), newRole())
+		assert.ErrorIs(t, err, ErrAlreadyHasRole)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("update").StringValue(), "a plain upsert, not findAndModify")
+		held := cmd.Lookup("updates").Array().Index(0).Value().Document().Lookup("q", "$nor").Array().Index(0).Value().Document()
+		assert.Equal(t, "viewer", held.Lookup("role").StringValue(), "the held role is excluded from the filter")
+
+		count := mt.GetStartedEvent().Command
+		match := count.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		assert.Equal(t, "viewer", match.Lookup("role").StringValue())
+		assert.Equal(t, "user_x", match.Lookup("user_id").StringValue())
+		_, err = match.LookupErr("$nor")
+		assert.Error(t, err)
+	})
+
+	mt.Run("other duplicate keys are returned as is", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RejectExistingRole = true
+		mt.AddMockResponses(duplicateKey, countResponse(0))
+
+		err := repo.UpsertUserRole(context.Background(), newRole())
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrAlreadyHasRole)
+	})
+
+	mt.Run("changing the user's role or assigning a new member succeeds", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RejectExistingRole = true
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(0)}),
+		)
+
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), newRole()))
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), newRole()))
+	})
+
+	mt.Run("default mode keeps the no-op upsert", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}))
+
+		assert.NoError(t, repo.UpsertUserRole(context.Background(), newRole()))
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("update").StringValue())
+		_, err := cmd.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document().LookupErr("$nor")
+		assert.Error(t, err)
+	})
+
+	mt.Run("bulk upserts report users who hold the role as failed", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RejectExistingRole = true
+		viewer := func(userID string) *model.UserRole {
+			return &model.UserRole{UserID: userID, UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: "dashboard"}
+		}
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, "rbac.user_resource_roles", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}),
+		)
+
+		result, err := repo.BulkUpsertUserRoles(context.Background(), []*model.UserRole{viewer("u1"), viewer("u2"), viewer("u3")})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.SuccessCount)
+		assert.Equal(t, []model.FailedUserInfo{{UserID: "u2", Reason: ErrAlreadyHasRole.Error()}}, result.FailedUsers)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		for _, u := range updates {
+			held := u.Document().Lookup("q", "$nor").Array().Index(0).Value().Document()
+			assert.Equal(t, model.RoleResourceViewer, held.Lookup("role").StringValue())
+		}
+	})
+}
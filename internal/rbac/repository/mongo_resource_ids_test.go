@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFindUserRolesResourceIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("resource_ids queries the caller's roles with a single $in", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_resource_roles", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "resource_id", Value: "dash_1"}, {Key: "role", Value: "viewer"}},
+		))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{
+			UserID:       "u1",
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1", "dash_2"},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, roles, 1)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, "u1", filter.Lookup("user_id").StringValue())
+		ids, _ := filter.Lookup("resource_id", "$in").Array().Values()
+		assert.Len(t, ids, 2)
+		assert.Equal(t, "dash_2", ids[1].StringValue())
+	})
+
+	mt.Run("roles matches any granting role with a single $in", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_resource_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{
+			Roles:        []string{"admin", "owner"},
+			Scope:        model.ScopeResource,
+			ResourceID:   "dash_1",
+			ResourceType: "dashboard",
+		})
+		assert.NoError(t, err)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		roles, _ := filter.Lookup("role", "$in").Array().Values()
+		assert.Len(t, roles, 2)
+		assert.Equal(t, "dash_1", filter.Lookup("resource_id").StringValue())
+	})
+
+	mt.Run("user_ids matches active members with a single $in", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{
+			UserIDs:   []string{"u1", "u2"},
+			Scope:     model.ScopeSystem,
+			Namespace: "NS_1",
+		})
+		assert.NoError(t, err)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("find").StringValue())
+		filter := cmd.Lookup("filter").Document()
+		users, _ := filter.Lookup("user_id", "$in").Array().Values()
+		assert.Len(t, users, 2)
+		assert.Equal(t, bson.TypeNull, filter.Lookup("deleted_at").Type, "soft-deleted roles are not members")
+	})
+}
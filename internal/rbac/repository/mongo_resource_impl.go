@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"rbac7/internal/rbac/model"
 	"time"
 
@@ -11,7 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (r *MongoRepository) CountResourceOwners(ctx context.Context, resourceID, resourceType string) (int64, error) {
+func (r *MongoRepository) CountResourceOwners(ctx context.Context, namespace, resourceID, resourceType string) (int64, error) {
 	filter := bson.M{
 		"scope":         model.ScopeResource,
 		"resource_id":   resourceID,
@@ -19,43 +18,49 @@ func (r *MongoRepository) CountResourceOwners(ctx context.Context, resourceID, r
 		"role":          model.RoleResourceOwner,
 		"deleted_at":    nil,
 	}
-	return r.ResourceRoles.CountDocuments(ctx, filter)
+	return r.resourceCollection(resourceType).CountDocuments(ctx, r.keyOnNamespace(filter, namespace))
 }
 
-func (r *MongoRepository) TransferResourceOwner(ctx context.Context, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error {
-	session, err := r.Client.StartSession()
+func (r *MongoRepository) GetResourceOwner(ctx context.Context, namespace, resourceID, resourceType string) (*model.UserRole, error) {
+	filter := bson.M{
+		"scope":         model.ScopeResource,
+		"resource_id":   resourceID,
+		"resource_type": resourceType,
+		"role":          model.RoleResourceOwner,
+		"deleted_at":    nil,
+	}
+	var role model.UserRole
+	err := r.resourceCollection(resourceType).FindOne(ctx, r.keyOnNamespace(filter, namespace)).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer session.EndSession(ctx)
+	return &role, nil
+}
 
-	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+func (r *MongoRepository) TransferResourceOwner(ctx context.Context, namespace, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error {
+	return r.inTransaction(ctx, func(sessCtx context.Context) error {
 		// 1. Demote Old Owner to Admin
 		filterOld := bson.M{
 			"user_id":       oldOwnerID,
-			"user_type":     model.UserTypeMember,
+			"user_type":     r.ownerUserTypes(),
 			"scope":         model.ScopeResource,
 			"resource_id":   resourceID,
 			"resource_type": resourceType,
 			"role":          model.RoleResourceOwner,
 			"deleted_at":    nil,
 		}
+		r.keyOnNamespace(filterOld, namespace)
 
 		now := time.Now()
-		updateOld := bson.M{
-			"$set": bson.M{
-				"role":       model.RoleResourceAdmin,
-				"updated_at": now,
-				"updated_by": updatedBy,
-			},
-		}
-
-		resOld, err := r.ResourceRoles.UpdateOne(sessCtx, filterOld, updateOld)
+		demoted, err := r.demoteOwner(sessCtx, r.resourceCollection(resourceType), filterOld, model.RoleResourceAdmin, updatedBy, now)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if resOld.MatchedCount == 0 {
-			return nil, errors.New("current resource owner not found or role changed")
+		if !demoted {
+			return ErrOwnerNotFound
 		}
 
 		// 2. Promote New Owner
@@ -66,6 +71,10 @@ func (r *MongoRepository) TransferResourceOwner(ctx context.Context, resourceID,
 			"resource_id":   resourceID,
 			"resource_type": resourceType,
 		}
+		r.keyOnNamespace(filterNew, namespace)
+		if r.MultiRole {
+			filterNew["role"] = model.RoleResourceOwner
+		}
 		updateNew := bson.M{
 			"$set": bson.M{
 				"role":          model.RoleResourceOwner,
@@ -77,6 +86,7 @@ func (r *MongoRepository) TransferResourceOwner(ctx context.Context, resourceID,
 				"resource_type": resourceType,
 			},
 			"$setOnInsert": bson.M{
+				"namespace":  namespace,
 				"created_at": now,
 				"created_by": updatedBy,
 			},
@@ -85,38 +95,84 @@ func (r *MongoRepository) TransferResourceOwner(ctx context.Context, resourceID,
 				"deleted_by": "",
 			},
 		}
-		opts := options.Update().SetUpsert(true)
+		return r.promoteOwner(sessCtx, r.resourceCollection(resourceType), filterNew, updateNew)
+	})
+}
 
-		_, err = r.ResourceRoles.UpdateOne(sessCtx, filterNew, updateNew, opts)
-		if err != nil {
-			return nil, err
+// ReassignOwnedResources transfers every resource oldOwnerID owns, in any resource collection, to
+// newOwnerID as TransferResourceOwner does (old owner demoted to admin). Each transfer appends a
+// reassign_owner history entry; transfers and history commit or roll back together.
+func (r *MongoRepository) ReassignOwnedResources(ctx context.Context, oldOwnerID, newOwnerID, updatedBy string) (int64, error) {
+	var transferred int64
+	err := r.inTransaction(ctx, func(sessCtx context.Context) error {
+		transferred = 0
+		filter := bson.M{
+			"user_id":    oldOwnerID,
+			"user_type":  r.ownerUserTypes(),
+			"scope":      model.ScopeResource,
+			"role":       model.RoleResourceOwner,
+			"deleted_at": nil,
 		}
+		for _, coll := range r.resourceCollections("") {
+			cursor, err := coll.Find(sessCtx, filter)
+			if err != nil {
+				return err
+			}
+			var owned []*model.UserRole
+			if err := cursor.All(sessCtx, &owned); err != nil {
+				return err
+			}
 
-		return nil, nil
+			for _, role := range owned {
+				// Joins this transaction since sessCtx carries the session
+				if err := r.TransferResourceOwner(sessCtx, role.Namespace, role.ResourceID, role.ResourceType, oldOwnerID, newOwnerID, updatedBy); err != nil {
+					return err
+				}
+				_, err := r.History.InsertOne(sessCtx, &model.UserRoleHistory{
+					Operation:        "reassign_owner",
+					CallerID:         updatedBy,
+					Scope:            model.ScopeResource,
+					ResourceID:       role.ResourceID,
+					ResourceType:     role.ResourceType,
+					ParentResourceID: role.ParentResourceID,
+					UserID:           oldOwnerID,
+					NewOwnerID:       newOwnerID,
+					CreatedAt:        time.Now(),
+				})
+				if err != nil {
+					return err
+				}
+				transferred++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	_, err = session.WithTransaction(ctx, callback)
-	return err
+	return transferred, nil
 }
 
-func (r *MongoRepository) HasResourceRole(ctx context.Context, userID, resourceID, resourceType, role string) (bool, error) {
+func (r *MongoRepository) HasResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType, role string) (bool, error) {
 	opts := options.Count().SetLimit(1)
 	filter := bson.M{
 		"user_id":       userID,
 		"scope":         model.ScopeResource,
 		"role":          role,
 		"deleted_at":    nil,
+		"expires_at":    notExpired(time.Now()),
 		"resource_id":   resourceID,
 		"resource_type": resourceType,
 	}
-	count, err := r.ResourceRoles.CountDocuments(ctx, filter, opts)
+	// Reads the primary: the service uses it as the owner guard before writes
+	count, err := r.resourceCollection(resourceType).CountDocuments(ctx, r.excludePending(r.keyOnNamespace(filter, namespace)), opts)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *MongoRepository) HasAnyResourceRole(ctx context.Context, userID, resourceID, resourceType string, roles []string) (bool, error) {
+func (r *MongoRepository) HasAnyResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType string, roles []string) (bool, error) {
 	if len(roles) == 0 {
 		return false, nil
 	}
@@ -126,21 +182,63 @@ func (r *MongoRepository) HasAnyResourceRole(ctx context.Context, userID, resour
 		"scope":         model.ScopeResource,
 		"role":          bson.M{"$in": roles},
 		"deleted_at":    nil,
+		"expires_at":    notExpired(time.Now()),
 		"resource_id":   resourceID,
 		"resource_type": resourceType,
 	}
-	count, err := r.ResourceRoles.CountDocuments(ctx, filter, opts)
+	count, err := r.reader(ctx, r.resourceCollection(resourceType)).CountDocuments(ctx, r.excludePending(r.keyOnNamespace(filter, namespace)), opts)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
-func (r *MongoRepository) CountResourceRoles(ctx context.Context, resourceID, resourceType string) (int64, error) {
+func (r *MongoRepository) CountResourceRoles(ctx context.Context, namespace, resourceID, resourceType string) (int64, error) {
 	filter := bson.M{
 		"resource_id":   resourceID,
 		"resource_type": resourceType,
 		"scope":         model.ScopeResource,
 		"deleted_at":    nil,
+		"expires_at":    notExpired(time.Now()),
+	}
+	return r.reader(ctx, r.resourceCollection(resourceType)).CountDocuments(ctx, r.keyOnNamespace(filter, namespace))
+}
+
+// CountAccessibleResourcesByType counts the distinct resources per type on which the user holds any active role
+func (r *MongoRepository) CountAccessibleResourcesByType(ctx context.Context, userID string) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id":    userID,
+			"scope":      model.ScopeResource,
+			"deleted_at": nil,
+			"expires_at": notExpired(time.Now()),
+		}}},
+		// A user can hold several role documents on one resource (e.g. as member and org)
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"resource_type": "$resource_type", "resource_id": "$resource_id"},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$_id.resource_type",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	counts := make(map[string]int64)
+	for _, coll := range r.resourceCollections("") {
+		cursor, err := r.reader(ctx, coll).Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+
+		var rows []struct {
+			ResourceType string `bson:"_id"`
+			Count        int64  `bson:"count"`
+		}
+		if err := cursor.All(ctx, &rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.ResourceType] += row.Count
+		}
 	}
-	return r.ResourceRoles.CountDocuments(ctx, filter)
+	return counts, nil
 }
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestGetResourceOwner(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("active owner of the resource", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "user_id", Value: "owner_1"}, {Key: "role", Value: model.RoleResourceOwner},
+			{Key: "resource_id", Value: "d1"}, {Key: "resource_type", Value: "dashboard"},
+		}))
+
+		owner, err := repo.GetResourceOwner(context.Background(), "", "d1", "dashboard")
+		assert.NoError(t, err)
+		if assert.NotNil(t, owner) {
+			assert.Equal(t, "owner_1", owner.UserID)
+		}
+
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_resource_roles", find.Lookup("find").StringValue())
+		filter := find.Lookup("filter").Document()
+		assert.Equal(t, model.ScopeResource, filter.Lookup("scope").StringValue())
+		assert.Equal(t, "d1", filter.Lookup("resource_id").StringValue())
+		assert.Equal(t, "dashboard", filter.Lookup("resource_type").StringValue())
+		assert.Equal(t, model.RoleResourceOwner, filter.Lookup("role").StringValue())
+		assert.Equal(t, bson.TypeNull, filter.Lookup("deleted_at").Type)
+	})
+
+	mt.Run("no owner returns nil without an error", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		ns := mt.DB.Name() + ".user_resource_roles"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		owner, err := repo.GetResourceOwner(context.Background(), "", "d1", "dashboard")
+		assert.NoError(t, err)
+		assert.Nil(t, owner)
+	})
+}
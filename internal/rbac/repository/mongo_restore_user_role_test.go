@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRestoreUserRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	updated := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+	}
+	firstUpdate := func(mt *mtest.T) bson.Raw {
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		return updates[0].Document()
+	}
+
+	mt.Run("unsets deleted_at and deleted_by of the soft-deleted role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(1))
+
+		err := repo.RestoreUserRole(context.Background(), "NS_1", "u1", model.ScopeSystem, "", "")
+		assert.NoError(t, err)
+
+		update := firstUpdate(mt)
+		q := update.Lookup("q").Document()
+		assert.Equal(t, "NS_1", q.Lookup("namespace").StringValue())
+		assert.NotNil(t, q.Lookup("deleted_at", "$ne"), "only soft-deleted roles match")
+		assert.Equal(t, model.RoleSystemOwner, q.Lookup("role", "$ne").StringValue())
+		u := update.Lookup("u").Document()
+		assert.NotNil(t, u.Lookup("$unset", "deleted_at"))
+		assert.NotNil(t, u.Lookup("$unset", "deleted_by"))
+		assert.NotNil(t, u.Lookup("$set", "updated_at"))
+		_, err = u.Lookup("$set").Document().LookupErr("created_by")
+		assert.Error(t, err, "created_by is kept")
+	})
+
+	mt.Run("resource scope keys on the resource", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(1))
+
+		err := repo.RestoreUserRole(context.Background(), "", "u1", model.ScopeResource, "d1", model.ResourceTypeDashboard)
+		assert.NoError(t, err)
+
+		q := firstUpdate(mt).Lookup("q").Document()
+		assert.Equal(t, "d1", q.Lookup("resource_id").StringValue())
+		assert.Equal(t, model.RoleResourceOwner, q.Lookup("role", "$ne").StringValue())
+		_, err = q.LookupErr("namespace")
+		assert.Error(t, err, "namespace is only matched when given")
+	})
+
+	mt.Run("nothing soft deleted returns ErrNoDocuments", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(updated(0))
+
+		err := repo.RestoreUserRole(context.Background(), "NS_1", "u1", model.ScopeSystem, "", "")
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
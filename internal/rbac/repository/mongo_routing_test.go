@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestResourceTypeCollectionRouting(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	newRepo := func(mt *mtest.T) *MongoRepository {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RouteResourceTypes(map[string]string{"library_widget": "library_widget_roles"})
+		return repo
+	}
+
+	mt.Run("routed resource type is written to its collection", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.CreateUserRole(context.Background(), &model.UserRole{
+			UserID: "u1", Role: model.RoleResourceViewer, Scope: model.ScopeResource,
+			ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "library_widget_roles", mt.GetStartedEvent().Command.Lookup("insert").StringValue())
+	})
+
+	mt.Run("unrouted resource type is written to the default collection", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.UpsertUserRole(context.Background(), &model.UserRole{
+			UserID: "u1", Role: model.RoleResourceViewer, Scope: model.ScopeResource,
+			ResourceID: "d_1", ResourceType: model.ResourceTypeDashboard,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "user_resource_roles", mt.GetStartedEvent().Command.Lookup("update").StringValue())
+	})
+
+	mt.Run("routed resource type is read from its collection", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.library_widget_roles", mtest.FirstBatch, bson.D{
+			{Key: "user_id", Value: "u1"},
+			{Key: "role", Value: model.RoleResourceViewer},
+			{Key: "scope", Value: model.ScopeResource},
+			{Key: "resource_id", Value: "lw_1"},
+			{Key: "resource_type", Value: model.ResourceTypeLibraryWidget},
+		}))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{
+			Scope: model.ScopeResource, ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, roles, 1)
+		assert.Equal(t, "u1", roles[0].UserID)
+		assert.Equal(t, "library_widget_roles", mt.GetStartedEvent().Command.Lookup("find").StringValue())
+	})
+
+	mt.Run("resource delete covers the collections of the resource and its widgets", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		repo.RouteResourceTypes(map[string]string{"dashboard_widget": "dashboard_widget_roles"})
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(2)}, bson.E{Key: "nModified", Value: int32(2)}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}),
+		)
+
+		deleted, err := repo.SoftDeleteResourceUserRoles(context.Background(), &model.SoftDeleteResourceReq{
+			ResourceID: "d_1", ResourceType: model.ResourceTypeDashboard, ChildResourceIDs: []string{"w_1"},
+		}, "caller")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted, "counts roles deleted in every collection")
+		assert.Equal(t, "user_resource_roles", mt.GetStartedEvent().Command.Lookup("update").StringValue())
+		assert.Equal(t, "dashboard_widget_roles", mt.GetStartedEvent().Command.Lookup("update").StringValue())
+		assert.Nil(t, mt.GetStartedEvent(), "library widget roles are not touched")
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFindUserRolesModifiedSince(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("modified_since matches updated or deleted after ts and keeps deleted roles", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		deletedAt := since.Add(time.Hour)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "scope", Value: "system"}, {Key: "updated_at", Value: since.Add(time.Minute)}},
+			bson.D{{Key: "user_id", Value: "u2"}, {Key: "scope", Value: "system"}, {Key: "deleted_at", Value: deletedAt}},
+		))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeSystem, ModifiedSince: &since})
+		assert.NoError(t, err)
+		assert.Len(t, roles, 2)
+		assert.NotNil(t, roles[1].DeletedAt)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		_, err = filter.LookupErr("deleted_at")
+		assert.Error(t, err, "soft-deleted roles must not be excluded")
+		clauses, _ := filter.Lookup("$or").Array().Values()
+		assert.Len(t, clauses, 2)
+		assert.Equal(t, since, clauses[0].Document().Lookup("updated_at", "$gte").Time().UTC())
+		assert.Equal(t, since, clauses[1].Document().Lookup("deleted_at", "$gte").Time().UTC())
+	})
+
+	mt.Run("without modified_since only active roles are matched", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeSystem})
+		assert.NoError(t, err)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, bson.TypeNull, filter.Lookup("deleted_at").Type)
+		_, err = filter.LookupErr("$or")
+		assert.Error(t, err)
+	})
+	mt.Run("a limited sync pages in change order", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "rbac.user_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeSystem, ModifiedSince: &since, Limit: 4})
+		assert.NoError(t, err)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("aggregate").StringValue())
+		stages, _ := cmd.Lookup("pipeline").Array().Values()
+		var sortKeys []string
+		var limit int64
+		for _, stage := range stages {
+			doc := stage.Document()
+			if v, err := doc.LookupErr("$sort"); err == nil {
+				keys, _ := v.Document().Elements()
+				for _, key := range keys {
+					sortKeys = append(sortKeys, key.Key())
+				}
+			}
+			if v, err := doc.LookupErr("$limit"); err == nil {
+				limit = v.AsInt64()
+			}
+		}
+		// A soft delete only sets deleted_at, so neither timestamp alone orders the changes
+		assert.Equal(t, []string{"changed_at", "_id"}, sortKeys)
+		assert.Equal(t, int64(4), limit)
+	})
+}
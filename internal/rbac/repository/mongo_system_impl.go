@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"rbac7/internal/rbac/model"
 	"time"
 
@@ -40,17 +39,11 @@ func (r *MongoRepository) CountSystemOwners(ctx context.Context, namespace strin
 }
 
 func (r *MongoRepository) TransferSystemOwner(ctx context.Context, namespace, oldOwnerID, newOwnerID, updatedBy string) error {
-	session, err := r.Client.StartSession()
-	if err != nil {
-		return err
-	}
-	defer session.EndSession(ctx)
-
-	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+	return r.inTransaction(ctx, func(sessCtx context.Context) error {
 		// 1. Demote Old Owner to Admin
 		filterOld := bson.M{
 			"user_id":    oldOwnerID,
-			"user_type":  model.UserTypeMember,
+			"user_type":  r.ownerUserTypes(),
 			"scope":      model.ScopeSystem,
 			"namespace":  namespace,
 			"role":       model.RoleSystemOwner,
@@ -59,21 +52,13 @@ func (r *MongoRepository) TransferSystemOwner(ctx context.Context, namespace, ol
 
 		now := time.Now()
 
-		updateOld := bson.M{
-			"$set": bson.M{
-				"role":       model.RoleSystemAdmin,
-				"updated_at": now,
-				"updated_by": updatedBy,
-			},
-		}
-
-		resOld, err := r.SystemRoles.UpdateOne(sessCtx, filterOld, updateOld)
+		demoted, err := r.demoteOwner(sessCtx, r.SystemRoles, filterOld, model.RoleSystemAdmin, updatedBy, now)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if resOld.MatchedCount == 0 {
+		if !demoted {
 			// Could happen if race condition or old owner removed
-			return nil, errors.New("current owner not found or role changed")
+			return ErrOwnerNotFound
 		}
 
 		// 2. Promote New Owner (Upsert to handle if they are already a member or not)
@@ -83,6 +68,9 @@ func (r *MongoRepository) TransferSystemOwner(ctx context.Context, namespace, ol
 			"scope":     model.ScopeSystem,
 			"namespace": namespace,
 		}
+		if r.MultiRole {
+			filterNew["role"] = model.RoleSystemOwner
+		}
 		updateNew := bson.M{
 			"$set": bson.M{
 				"role":       model.RoleSystemOwner,
@@ -99,21 +87,79 @@ func (r *MongoRepository) TransferSystemOwner(ctx context.Context, namespace, ol
 				"deleted_by": "",
 			},
 		}
-		opts := options.Update().SetUpsert(true)
+		return r.promoteOwner(sessCtx, r.SystemRoles, filterNew, updateNew)
+	})
+}
 
-		_, err = r.SystemRoles.UpdateOne(sessCtx, filterNew, updateNew, opts)
-		if err != nil {
-			return nil, err
-		}
+// DemoteSystemOwner turns role.UserID's owner role in role.Namespace into role.Role with role's grant
+// fields, for downgrading one of several owners; UpsertUserRole never matches an owner role.
+// In MultiRole mode the owner role is soft deleted and role is upserted as a document of its own.
+// ErrOwnerNotFound when the user no longer owns the namespace.
+func (r *MongoRepository) DemoteSystemOwner(ctx context.Context, role *model.UserRole) error {
+	filter := bson.M{
+		"user_id":    role.UserID,
+		"user_type":  r.ownerUserTypes(),
+		"scope":      model.ScopeSystem,
+		"namespace":  role.Namespace,
+		"role":       model.RoleSystemOwner,
+		"deleted_at": nil,
+	}
+	now := time.Now()
+	role.UpdatedAt = now
 
-		return nil, nil
+	update := roleUpdate(role, now)
+	if r.MultiRole {
+		update = bson.M{"$set": bson.M{"deleted_at": now, "deleted_by": role.UpdatedBy}}
+	}
+	res, err := r.SystemRoles.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrOwnerNotFound
+	}
+	if r.MultiRole {
+		return r.UpsertUserRole(ctx, role)
+	}
+	return nil
+}
+
+// DeleteSystemOwner soft deletes userID's owner role in namespace, for removing one of several owners;
+// DeleteUserRole never matches an owner role. In MultiRole mode the user's other roles there are
+// removed with it, as DeleteUserRole does. Returns the number of roles deleted, or ErrOwnerNotFound
+// when the user no longer owns the namespace.
+func (r *MongoRepository) DeleteSystemOwner(ctx context.Context, namespace, userID, deletedBy string) (int64, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"scope":      model.ScopeSystem,
+		"namespace":  namespace,
+		"role":       model.RoleSystemOwner,
+		"deleted_at": nil,
+	}
+	update := bson.M{"$set": bson.M{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}}
+	res, err := r.SystemRoles.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	if res.MatchedCount == 0 {
+		return 0, ErrOwnerNotFound
+	}
+	if !r.MultiRole {
+		return res.ModifiedCount, nil
 	}
 
-	_, err = session.WithTransaction(ctx, callback)
-	return err
+	delete(filter, "role")
+	others, err := r.SystemRoles.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount + others.ModifiedCount, nil
 }
 
-func (r *MongoRepository) HasSystemRole(ctx context.Context, userID, namespace, role string) (bool, error) {
+func (r *MongoRepository) HasSystemRole(ctx context.Context, namespace, userID, role string) (bool, error) {
 	// For performance, we add limit 1
 	opts := options.Count().SetLimit(1)
 	filter := bson.M{
@@ -121,18 +167,20 @@ func (r *MongoRepository) HasSystemRole(ctx context.Context, userID, namespace,
 		"scope":      model.ScopeSystem,
 		"role":       role,
 		"deleted_at": nil,
+		"expires_at": notExpired(time.Now()),
 	}
 	if namespace != "" {
 		filter["namespace"] = namespace
 	}
-	count, err := r.SystemRoles.CountDocuments(ctx, filter, opts)
+	// Reads the primary: the service uses it as the owner guard before writes (e.g. systemOwnership)
+	count, err := r.SystemRoles.CountDocuments(ctx, r.excludePending(filter), opts)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *MongoRepository) HasAnySystemRole(ctx context.Context, userID, namespace string, roles []string) (bool, error) {
+func (r *MongoRepository) HasAnySystemRole(ctx context.Context, namespace, userID string, roles []string) (bool, error) {
 	if len(roles) == 0 {
 		return false, nil
 	}
@@ -142,11 +190,12 @@ func (r *MongoRepository) HasAnySystemRole(ctx context.Context, userID, namespac
 		"scope":      model.ScopeSystem,
 		"role":       bson.M{"$in": roles},
 		"deleted_at": nil,
+		"expires_at": notExpired(time.Now()),
 	}
 	if namespace != "" {
 		filter["namespace"] = namespace
 	}
-	count, err := r.SystemRoles.CountDocuments(ctx, filter, opts)
+	count, err := r.reader(ctx, r.SystemRoles).CountDocuments(ctx, r.excludePending(filter), opts)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestTemporaryGrant(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	expiresAt := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("bulk upsert persists expires_at and reason per role", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+
+		roles := []*model.UserRole{
+			{UserID: "u1", UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: "dashboard", ExpiresAt: &expiresAt, Reason: "Q3 project"},
+			{UserID: "u2", UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: "dashboard", ExpiresAt: &expiresAt, Reason: "Q3 project"},
+		}
+		result, err := repo.BulkUpsertUserRoles(context.Background(), roles)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.SuccessCount)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		assert.Len(t, updates, 2)
+		for _, u := range updates {
+			set := u.Document().Lookup("u", "$set").Document()
+			assert.Equal(t, expiresAt, set.Lookup("expires_at").Time().UTC())
+			assert.Equal(t, "Q3 project", set.Lookup("reason").StringValue())
+		}
+	})
+
+	mt.Run("upsert without expiry clears a previous temporary grant", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.UpsertUserRole(context.Background(), &model.UserRole{
+			UserID: "u1", UserType: model.UserTypeMember, Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "d_1", ResourceType: "dashboard",
+		})
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		unset := updates[0].Document().Lookup("u", "$unset").Document()
+		_, err = unset.LookupErr("expires_at")
+		assert.NoError(t, err)
+		_, err = unset.LookupErr("reason")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("permission checks skip expired grants", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_resource_roles", mtest.FirstBatch,
+			bson.D{{Key: "n", Value: int32(0)}}))
+
+		ok, err := repo.HasAnyResourceRole(context.Background(), "", "u1", "d_1", "dashboard", []string{model.RoleResourceViewer})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		match := stages[0].Document().Lookup("$match").Document()
+		assert.False(t, match.Lookup("expires_at", "$not", "$lte").Time().IsZero())
+	})
+
+	mt.Run("system permission checks skip expired grants", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch,
+			bson.D{{Key: "n", Value: int32(0)}}))
+
+		ok, err := repo.HasAnySystemRole(context.Background(), "NS_1", "u1", []string{"admin"})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		match := stages[0].Document().Lookup("$match").Document()
+		assert.False(t, match.Lookup("expires_at", "$not", "$lte").Time().IsZero())
+	})
+
+	mt.Run("expired roles get a TTL index when a retention is set", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.ExpiredRoleRetention = 7 * 24 * time.Hour
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		for _, coll := range []string{"user_roles", "user_resource_roles"} {
+			cmd := mt.GetStartedEvent().Command
+			assert.Equal(t, coll, cmd.Lookup("createIndexes").StringValue())
+			ttl := findIndex(cmd, "ttl_expires_at")
+			if assert.NotNil(t, ttl, coll) {
+				assert.Equal(t, int32(7*24*3600), ttl.Lookup("expireAfterSeconds").Int32())
+			}
+		}
+	})
+
+	mt.Run("no TTL index by default", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		assert.NoError(t, repo.EnsureIndexes(context.Background()))
+		assert.Nil(t, findIndex(mt.GetStartedEvent().Command, "ttl_expires_at"))
+	})
+}
+
+// findIndex returns the index named name of a createIndexes command, or nil
+func findIndex(cmd bson.Raw, name string) bson.Raw {
+	indexes, _ := cmd.Lookup("indexes").Array().Values()
+	for _, idx := range indexes {
+		if idx.Document().Lookup("name").StringValue() == name {
+			return idx.Document()
+		}
+	}
+	return nil
+}
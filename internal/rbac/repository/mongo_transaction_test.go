@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestTransactionLimits(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	writeConflict := mtest.CreateCommandErrorResponse(mtest.CommandError{
+		Code:    112,
+		Name:    "WriteConflict",
+		Message: "write conflict",
+		Labels:  []string{labelTransientTransaction},
+	})
+
+	mt.Run("transient failures past the retry cap return ErrTransactionContention", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.TxnMaxRetries = 2
+		for i := 0; i < 3; i++ {
+			mt.AddMockResponses(writeConflict, mtest.CreateSuccessResponse()) // demote, abort
+		}
+
+		err := repo.TransferSystemOwner(context.Background(), "NS_1", "owner_1", "user_x", "owner_1")
+		assert.ErrorIs(t, err, ErrTransactionContention)
+		assert.Contains(t, err.Error(), "gave up after 3 attempts")
+
+		var updates, aborts int
+		for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+			switch evt.CommandName {
+			case "update":
+				updates++
+			case "abortTransaction":
+				aborts++
+			}
+		}
+		assert.Equal(t, 3, updates, "first attempt plus two retries")
+		assert.Equal(t, 3, aborts)
+	})
+
+	mt.Run("transient failure within the cap is retried and commits", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			writeConflict, mtest.CreateSuccessResponse(), // first attempt: demote, abort
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // demote
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // promote
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		err := repo.TransferSystemOwner(context.Background(), "NS_1", "owner_1", "user_x", "owner_1")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("non-transient failure is not retried", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Name: "BadValue", Message: "bad value"}),
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		err := repo.TransferSystemOwner(context.Background(), "NS_1", "owner_1", "user_x", "owner_1")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrTransactionTimeout)
+		assert.NotErrorIs(t, err, ErrTransactionContention)
+	})
+
+	mt.Run("transfer without a current owner returns ErrOwnerNotFound", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(0)}, bson.E{Key: "nModified", Value: int32(0)}), // demote
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		err := repo.TransferResourceOwner(context.Background(), "", "d_gone", "dashboard", "owner_1", "user_x", "owner_1")
+		assert.ErrorIs(t, err, ErrOwnerNotFound)
+	})
+
+	mt.Run("expired timeout returns ErrTransactionTimeout", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.TxnTimeout = time.Nanosecond
+		mt.AddMockResponses(mtest.CreateSuccessResponse()) // abort
+
+		err := repo.TransferResourceOwner(context.Background(), "", "d1", "dashboard", "owner_1", "user_x", "owner_1")
+		assert.ErrorIs(t, err, ErrTransactionTimeout)
+	})
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFindUserRolesUnion(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("no scope merges both scopes grouped by scope and paged server-side", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "scope", Value: "system"}, {Key: "namespace", Value: "NS_1"}, {Key: "created_at", Value: base.Add(2 * time.Hour)}},
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "scope", Value: "resource"}, {Key: "resource_id", Value: "d_1"}, {Key: "created_at", Value: base.Add(time.Hour)}},
+		))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{UserID: "u1", Offset: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, model.ScopeSystem, roles[0].Scope)
+		assert.Equal(t, model.ScopeResource, roles[1].Scope)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("aggregate").StringValue())
+		stages, _ := cmd.Lookup("pipeline").Array().Values()
+		require.Len(t, stages, 6)
+		assert.Equal(t, "u1", stages[0].Document().Lookup("$match", "user_id").StringValue())
+		assert.Equal(t, "user_resource_roles", stages[2].Document().Lookup("$unionWith", "coll").StringValue())
+		union, _ := stages[2].Document().Lookup("$unionWith", "pipeline").Array().Values()
+		assert.Equal(t, "u1", union[0].Document().Lookup("$match", "user_id").StringValue())
+		sort := stages[3].Document().Lookup("$sort").Document()
+		keys, _ := sort.Elements()
+		assert.Equal(t, "scope", keys[0].Key())
+		assert.Equal(t, int32(-1), keys[0].Value().Int32(), "system roles first")
+		assert.Equal(t, "created_at", keys[1].Key())
+		assert.Equal(t, "_id", keys[2].Key())
+		assert.Equal(t, int64(1), stages[4].Document().Lookup("$skip").Int64())
+		assert.Equal(t, int64(2), stages[5].Document().Lookup("$limit").Int64())
+	})
+
+	mt.Run("no scope fills in scope of legacy roles from their collection", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		// The mock returns what $addFields yields for two roles stored without scope
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "scope", Value: "system"}, {Key: "role", Value: "admin"}, {Key: "namespace", Value: "NS_1"}},
+			bson.D{{Key: "user_id", Value: "u1"}, {Key: "scope", Value: "resource"}, {Key: "role", Value: "admin"}, {Key: "resource_id", Value: "d_1"}},
+		))
+
+		roles, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{UserID: "u1"})
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, model.ScopeSystem, roles[0].Scope)
+		assert.Equal(t, "NS_1", roles[0].Namespace)
+		assert.Equal(t, model.ScopeResource, roles[1].Scope)
+		assert.Equal(t, "d_1", roles[1].ResourceID)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		assert.Equal(t, "system", stages[1].Document().Lookup("$addFields", "scope", "$ifNull").Array().Index(1).Value().StringValue())
+		union, _ := stages[2].Document().Lookup("$unionWith", "pipeline").Array().Values()
+		assert.Equal(t, "resource", union[1].Document().Lookup("$addFields", "scope", "$ifNull").Array().Index(1).Value().StringValue())
+	})
+
+	mt.Run("no scope includes every routed resource collection", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.RouteResourceTypes(map[string]string{"library_widget": "library_widget_roles"})
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{UserID: "u1"})
+		require.NoError(t, err)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		require.Len(t, stages, 5, "match, default scope, two unions and sort; no paging stages without offset/limit")
+		assert.Equal(t, "user_resource_roles", stages[2].Document().Lookup("$unionWith", "coll").StringValue())
+		assert.Equal(t, "library_widget_roles", stages[3].Document().Lookup("$unionWith", "coll").StringValue())
+	})
+
+	mt.Run("single scope pages with find options", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".user_roles", mtest.FirstBatch))
+
+		_, err := repo.FindUserRoles(context.Background(), model.UserRoleFilter{Scope: model.ScopeSystem, UserID: "u1", Limit: 10})
+		require.NoError(t, err)
+
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "user_roles", cmd.Lookup("find").StringValue())
+		assert.Equal(t, int64(10), cmd.Lookup("limit").Int64())
+		_, err = cmd.Lookup("sort").Document().LookupErr("created_at")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestWithHistory(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("write and history share one transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // upsert
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),                                            // history insert
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		role := &model.UserRole{UserID: "user_x", UserType: "member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1"}
+		history := &model.UserRoleHistory{Operation: "assign_user_role", CallerID: "admin_1", Scope: model.ScopeSystem, Namespace: "NS_1", UserID: "user_x"}
+		err := repo.WithHistory(context.Background(), history, func(ctx context.Context) error {
+			return repo.UpsertUserRole(ctx, role)
+		})
+		assert.NoError(t, err)
+
+		write := mt.GetStartedEvent()
+		assert.Equal(t, "update", write.CommandName)
+		insert := mt.GetStartedEvent()
+		assert.Equal(t, "insert", insert.CommandName)
+		assert.Equal(t, "user_role_history", insert.Command.Lookup("insert").StringValue())
+		docs, _ := insert.Command.Lookup("documents").Array().Values()
+		assert.False(t, docs[0].Document().Lookup("created_at").Time().IsZero())
+		assert.Equal(t, write.Command.Lookup("lsid").String(), insert.Command.Lookup("lsid").String())
+		assert.Equal(t, write.Command.Lookup("txnNumber").String(), insert.Command.Lookup("txnNumber").String())
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+		assert.True(t, history.CreatedAt.IsZero(), "caller's entry is not mutated")
+	})
+
+	mt.Run("failed write aborts without history", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(mtest.CreateSuccessResponse()) // abort
+
+		err := repo.WithHistory(context.Background(), &model.UserRoleHistory{Operation: "delete_user_role"}, func(ctx context.Context) error {
+			return mongo.ErrNoDocuments
+		})
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+			assert.NotEqual(t, "insert", evt.CommandName)
+		}
+	})
+
+	mt.Run("transfer joins the outer transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // demote
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // promote
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),                                            // history insert
+			mtest.CreateSuccessResponse(), // commit
+		)
+
+		history := &model.UserRoleHistory{Operation: "transfer_owner", CallerID: "owner_1", Scope: model.ScopeSystem, Namespace: "NS_1", NewOwnerID: "user_x"}
+		err := repo.WithHistory(context.Background(), history, func(ctx context.Context) error {
+			return repo.TransferSystemOwner(ctx, "NS_1", "owner_1", "user_x", "owner_1")
+		})
+		assert.NoError(t, err)
+
+		demote := mt.GetStartedEvent()
+		promote := mt.GetStartedEvent()
+		insert := mt.GetStartedEvent()
+		assert.Equal(t, "update", demote.CommandName)
+		assert.Equal(t, "update", promote.CommandName)
+		assert.Equal(t, "insert", insert.CommandName)
+		txn := demote.Command.Lookup("txnNumber").String()
+		assert.Equal(t, txn, promote.Command.Lookup("txnNumber").String())
+		assert.Equal(t, txn, insert.Command.Lookup("txnNumber").String())
+		assert.Equal(t, "commitTransaction", mt.GetStartedEvent().CommandName)
+		assert.Nil(t, mt.GetStartedEvent(), "one commit for the whole transfer")
+	})
+
+	mt.Run("failed transfer aborts without history", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(0)}, bson.E{Key: "nModified", Value: int32(0)}), // demote matches nothing
+			mtest.CreateSuccessResponse(), // abort
+		)
+
+		err := repo.WithHistory(context.Background(), &model.UserRoleHistory{Operation: "transfer_owner"}, func(ctx context.Context) error {
+			return repo.TransferSystemOwner(ctx, "NS_1", "owner_1", "user_x", "owner_1")
+		})
+		assert.Error(t, err)
+		for evt := mt.GetStartedEvent(); evt != nil; evt = mt.GetStartedEvent() {
+			assert.NotEqual(t, "insert", evt.CommandName)
+		}
+	})
+
+	mt.Run("standalone writes without a transaction", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.Standalone = true
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}, bson.E{Key: "nModified", Value: int32(1)}), // upsert
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),                                            // history insert
+		)
+
+		role := &model.UserRole{UserID: "user_x", UserType: "member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1"}
+		err := repo.WithHistory(context.Background(), &model.UserRoleHistory{Operation: "assign_user_role"}, func(ctx context.Context) error {
+			return repo.UpsertUserRole(ctx, role)
+		})
+		assert.NoError(t, err)
+
+		write := mt.GetStartedEvent()
+		insert := mt.GetStartedEvent()
+		assert.Equal(t, "update", write.CommandName)
+		assert.Equal(t, "insert", insert.CommandName)
+		_, err = write.Command.LookupErr("startTransaction")
+		assert.Error(t, err, "write is not part of a transaction")
+		assert.Nil(t, mt.GetStartedEvent(), "no commitTransaction")
+	})
+
+	mt.Run("standalone failed write skips history", func(mt *mtest.T) {
+		repo := NewMongoRepository(mt.DB, "user_roles", "user_resource_roles")
+		repo.Standalone = true
+
+		err := repo.WithHistory(context.Background(), &model.UserRoleHistory{Operation: "delete_user_role"}, func(ctx context.Context) error {
+			return mongo.ErrNoDocuments
+		})
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		assert.Nil(t, mt.GetStartedEvent())
+	})
+}
@@ -8,41 +8,95 @@ import (
 
 var ErrDuplicate = errors.New("duplicate record")
 
+// ErrRecentlyRemoved rejects re-adding a user whose role was soft deleted within the grace period
+var ErrRecentlyRemoved = errors.New("user was removed recently and cannot be re-added yet")
+
+// ErrAlreadyHasRole is returned by UpsertUserRole with RejectExistingRole when the user already holds the role;
+// nothing is written
+var ErrAlreadyHasRole = errors.New("user already holds this role")
+
+// ErrOwnerNotFound means an ownership transfer found no current owner to demote: the caller does
+// not own the namespace or resource (any more), or it does not exist
+var ErrOwnerNotFound = errors.New("current owner not found or role changed")
+
+// ErrTransactionTimeout means a transaction did not commit within the configured timeout
+var ErrTransactionTimeout = errors.New("transaction did not complete in time")
+
+// ErrTransactionContention means a transaction kept aborting with transient errors until its retries ran out
+var ErrTransactionContention = errors.New("transaction aborted by write contention")
+
+// ErrUnhealthy means the database is reachable but the role collections are not usable
+var ErrUnhealthy = errors.New("repository is not ready")
+
 type RBACRepository interface {
 	// Check if a system owner already exists for the namespace
 	GetSystemOwner(ctx context.Context, namespace string) (*model.UserRole, error)
 	// Create a new user role
 	CreateUserRole(ctx context.Context, role *model.UserRole) error
 	// Check if user has specific system role (ignoring namespace for now or just checking existence)
-	HasSystemRole(ctx context.Context, userID, namespace, role string) (bool, error)
+	HasSystemRole(ctx context.Context, namespace, userID, role string) (bool, error)
 	// Check if user has ANY of the specified system roles
-	HasAnySystemRole(ctx context.Context, userID, namespace string, roles []string) (bool, error)
+	HasAnySystemRole(ctx context.Context, namespace, userID string, roles []string) (bool, error)
 	// Find user roles with filter
 	FindUserRoles(ctx context.Context, filter model.UserRoleFilter) ([]*model.UserRole, error)
 	// Initialize Indexes
 	EnsureIndexes(ctx context.Context) error
+	// Check the role collections are queryable and have their unique indexes (readiness probe)
+	RepositoryHealth(ctx context.Context) error
+	// Find a user's pending owner roles (activated on first sign-in)
+	FindPendingRoles(ctx context.Context, userID string) ([]*model.UserRole, error)
+	// Turn a pending role into a member role; mongo.ErrNoDocuments when it is no longer pending
+	ActivatePendingRole(ctx context.Context, role *model.UserRole) error
 	// Transfer ownership safely using transaction
 	TransferSystemOwner(ctx context.Context, namespace, oldOwnerID, newOwnerID, updatedBy string) error
 	// Upsert a user role (Create or Update)
 	UpsertUserRole(ctx context.Context, role *model.UserRole) error
-	// Delete a user role (Soft Delete)
-	DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) error
+	// Delete a user role (Soft Delete); returns the number of roles deleted
+	DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) (int64, error)
+	// Undo the soft delete of a user role; mongo.ErrNoDocuments when none is soft deleted
+	RestoreUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType string) error
+	// Turn one of several system owners into role.Role (ErrOwnerNotFound when they no longer own the namespace)
+	DemoteSystemOwner(ctx context.Context, role *model.UserRole) error
+	// Soft delete one of several system owners; returns the number of roles deleted
+	DeleteSystemOwner(ctx context.Context, namespace, userID, deletedBy string) (int64, error)
 	// Count owners in a system
 	CountSystemOwners(ctx context.Context, namespace string) (int64, error)
+	// Resource lookups below take the resource's namespace; it only narrows them when resources are
+	// keyed on namespace (NamespacedResources), and is otherwise ignored.
 	// Count owners in a resource
-	CountResourceOwners(ctx context.Context, resourceID, resourceType string) (int64, error)
+	CountResourceOwners(ctx context.Context, namespace, resourceID, resourceType string) (int64, error)
+	// Get the active owner of a resource (unique per resource); nil when it has none
+	GetResourceOwner(ctx context.Context, namespace, resourceID, resourceType string) (*model.UserRole, error)
 	// Check if user has specific resource role
-	HasResourceRole(ctx context.Context, userID, resourceID, resourceType, role string) (bool, error)
+	HasResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType, role string) (bool, error)
 	// Check if user has ANY of the specified resource roles
-	HasAnyResourceRole(ctx context.Context, userID, resourceID, resourceType string, roles []string) (bool, error)
+	HasAnyResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType string, roles []string) (bool, error)
 	// Transfer resource ownership
-	TransferResourceOwner(ctx context.Context, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error
+	TransferResourceOwner(ctx context.Context, namespace, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error
+	// Transfer every resource oldOwnerID owns to newOwnerID in one transaction; returns the number transferred
+	ReassignOwnedResources(ctx context.Context, oldOwnerID, newOwnerID, updatedBy string) (int64, error)
 	// Count total roles assigned to a resource (used for whitelist check)
-	CountResourceRoles(ctx context.Context, resourceID, resourceType string) (int64, error)
+	CountResourceRoles(ctx context.Context, namespace, resourceID, resourceType string) (int64, error)
 	// Bulk upsert user roles (partial success allowed)
 	BulkUpsertUserRoles(ctx context.Context, roles []*model.UserRole) (*model.BatchUpsertResult, error)
 	// Delete user roles by parent_resource_id (用於刪除 dashboard member 時移除所有 child widget 權限)
-	DeleteUserRolesByParent(ctx context.Context, userID, parentResourceID, resourceType, deletedBy string) error
-	// Soft delete all user roles for a resource (including owner)
-	SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) error
+	DeleteUserRolesByParent(ctx context.Context, namespace, userID, parentResourceID, resourceType, deletedBy string) (int64, error)
+	// Soft delete all user roles for a resource (including owner); returns the number of roles deleted
+	SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) (int64, error)
+	// Count distinct resources per type on which the user holds any role
+	CountAccessibleResourcesByType(ctx context.Context, userID string) (map[string]int64, error)
+	// Erase a user's data: hard delete their roles and replace their ID with tombstone elsewhere (transaction)
+	EraseUser(ctx context.Context, userID, tombstone, callerID string) (*model.EraseUserResult, error)
+	// Hard delete every system and resource role document of a user, including soft-deleted ones
+	HardDeleteUserRole(ctx context.Context, userID string) (int64, error)
+	// Get the namespace's assignable resource roles override (nil when the namespace has none)
+	GetNamespaceResourceRoles(ctx context.Context, namespace string) (*model.NamespaceResourceRoles, error)
+	// Create or replace the namespace's assignable resource roles override
+	SetNamespaceResourceRoles(ctx context.Context, override *model.NamespaceResourceRoles) error
+	// Remove the namespace's override so it falls back to the global default
+	DeleteNamespaceResourceRoles(ctx context.Context, namespace string) error
+	// Move all roles, history and the resource roles override of a namespace to a new name (transaction)
+	RenameNamespace(ctx context.Context, from, to, updatedBy string) (*model.RenameNamespaceResult, error)
+	// Write a namespace snapshot's roles and override into namespace (replace soft deletes its roles first)
+	RestoreNamespace(ctx context.Context, namespace string, snapshot *model.NamespaceSnapshot, replace bool, updatedBy string) (*model.RestoreNamespaceResult, error)
 }
@@ -6,32 +6,29 @@ import (
 	"rbac7/internal/rbac/repository"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 )
 
-func RegisterRoutes(e *echo.Echo, h *handler.SystemHandler, policyEngine *policy.Engine, repo repository.RBACRepository, apiConfigs map[string][]*policy.APIConfig) {
-	// Enable CORS for Swagger UI interaction
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.PUT, echo.POST, echo.DELETE, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "authentication", "x-user-id"},
-	}))
-
+func RegisterRoutes(e *echo.Echo, h *handler.SystemHandler, policyEngine *policy.Engine, repo repository.RBACRepository) {
 	// Serve Swagger Spec
 	e.File("/docs/rbac.yaml", "docs/rbac.yaml")
 
 	// Health Check
 	e.GET("/health", handler.HealthCheck)
+	e.GET("/ready", handler.ReadinessCheck(repo)) // Role collections queryable and indexed
 
 	// Prefix from rbac.yaml: /api/v1
 	v1 := e.Group("/api/v1")
-	v1.Use(handler.RequestIDMiddleware) // Add Request ID middleware to API routes
+	v1.Use(handler.RequestIDMiddleware)  // Add Request ID middleware to API routes
+	v1.Use(handler.RetryAfterMiddleware) // Ask clients to back off on 429 (write contention)
 
 	// Permissions check endpoint - NO RBAC middleware (anyone can check permissions)
 	v1.POST("/permissions/check", h.PostPermissionsCheck)
+	v1.POST("/permissions/check/batch", h.PostPermissionsCheckBatch) // Many checks in one round trip
+	v1.GET("/permissions/:permission/roles", h.GetPermissionRoles)   // Policy metadata: roles granting a permission
 
 	// Create and apply RBAC middleware for protected routes
-	rbacMiddleware := handler.NewRBACMiddleware(policyEngine, repo, apiConfigs)
+	rbacMiddleware := handler.NewRBACMiddleware(policyEngine, repo)
+	rbacMiddleware.FoldResourceIDCase = h.FoldResourceIDCase
 	v1.Use(rbacMiddleware.Middleware())
 
 	// System Scope Routes
@@ -42,7 +39,10 @@ func RegisterRoutes(e *echo.Echo, h *handler.SystemHandler, policyEngine *policy
 	v1.DELETE("/user_roles", h.DeleteUserRoles)
 	v1.GET("/user_roles/me", h.GetUserRolesMe)
 	v1.GET("/user_roles", h.GetUserRoles)
-	v1.GET("/user_roles/logs", h.GetUserRoleHistory) // History logs for both system and resource scope
+	v1.POST("/user_roles/members/check", h.PostCheckMembers) // Which of the given users are already members
+	v1.POST("/user_roles/restore", h.PostRestoreUserRole)    // Undo a soft delete (system or resource scope)
+	v1.GET("/user_roles/logs", h.GetUserRoleHistory)         // History logs for both system and resource scope
+	v1.GET("/user_roles/sync", h.GetUserRolesSync)           // Incremental sync (changed and soft-deleted roles)
 
 	// Resource Scope Routes
 	v1.POST("/user_roles/resources/owner", h.PostResourceOwner)
@@ -50,8 +50,28 @@ func RegisterRoutes(e *echo.Echo, h *handler.SystemHandler, policyEngine *policy
 	v1.POST("/user_roles/resources", h.PostResourceUserRoles)
 	v1.POST("/user_roles/resources/batch", h.PostResourceUserRolesBatch)
 	v1.DELETE("/user_roles/resources", h.DeleteResourceUserRoles)
+	v1.POST("/user_roles/resources/my_roles", h.PostMyResourceRoles) // Caller's role on many resources at once
 
 	// Resource Management Routes
 	v1.PUT("/resources/delete", h.PutDeleteResource)
 	v1.POST("/resources/dashboards", h.GetDashboardResource)
+	v1.GET("/resources/accessible/summary", h.GetAccessibleResourceSummary)
+	v1.GET("/resources/capable_users", h.GetCapableUsers) // Members whose role grants a permission
+	v1.GET("/resources/owner", h.GetResourceOwner)        // Current owner, null when none
+	v1.POST("/resources/owners", h.PostResourceOwners)    // Billing: owner of many resources at once
+
+	// Namespace Policy Routes
+	v1.GET("/namespaces/:namespace/resource_roles", h.GetNamespaceResourceRoles)
+	v1.PUT("/namespaces/:namespace/resource_roles", h.PutNamespaceResourceRoles)
+	v1.DELETE("/namespaces/:namespace/resource_roles", h.DeleteNamespaceResourceRoles)
+	v1.POST("/namespaces/rename", h.PostRenameNamespace)              // Moderator-only: move roles and history to a new name
+	v1.GET("/namespaces/:namespace/snapshot", h.GetNamespaceSnapshot) // Moderator-only: export roles for cloning/recovery
+	v1.POST("/namespaces/:namespace/restore", h.PostRestoreNamespace) // Moderator-only: apply an exported snapshot
+
+	// Admin APIs
+	v1.POST("/admin/users/:id/erase", h.PostEraseUser)
+	v1.GET("/admin/users/:id/access_snapshot", h.GetAccessSnapshot)
+	v1.POST("/policy/reload", h.PostPolicyReload)              // Moderator-only: re-read policy files without a restart
+	v1.DELETE("/users/:user_id/purge", h.DeletePurgeUser)      // Hard delete of the user's roles only
+	v1.POST("/user_roles/reassign_owner", h.PostReassignOwner) // Moderator-only: offboarding, move all owned resources
 }
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"log"
+	"rbac7/internal/rbac/model"
+	"time"
+)
+
+// Notifier enqueues grant notifications for delivery (e.g. webhook)
+type Notifier interface {
+	Notify(ctx context.Context, event model.GrantEvent) error
+}
+
+// notifyGrants enqueues a role_granted event per granted role.
+// It runs after the grant is stored, so a failed notification is logged and never fails the grant.
+func (s *Service) notifyGrants(ctx context.Context, roles ...*model.UserRole) {
+	if s.Notifier == nil {
+		return
+	}
+	now := time.Now()
+	for _, role := range roles {
+		event := model.GrantEvent{
+			Type:             model.GrantEventRoleGranted,
+			UserID:           role.UserID,
+			UserType:         role.UserType,
+			Role:             role.Role,
+			Scope:            role.Scope,
+			Namespace:        role.Namespace,
+			ResourceID:       role.ResourceID,
+			ResourceType:     role.ResourceType,
+			ParentResourceID: role.ParentResourceID,
+			GrantedBy:        role.UpdatedBy,
+			GrantedAt:        now,
+		}
+		if err := s.Notifier.Notify(ctx, event); err != nil {
+			log.Printf("Warning: Grant notification failed. Target=%s, Role=%s, err=%v", role.UserID, role.Role, err)
+		}
+	}
+}
+
+// succeededRoles drops roles whose user is listed as failed in a batch result
+func succeededRoles(roles []*model.UserRole, result *model.BatchUpsertResult) []*model.UserRole {
+	if len(result.FailedUsers) == 0 {
+		return roles
+	}
+	failed := make(map[string]bool, len(result.FailedUsers))
+	for _, f := range result.FailedUsers {
+		failed[f.UserID] = true
+	}
+	succeeded := make([]*model.UserRole, 0, len(roles))
+	for _, role := range roles {
+		if !failed[role.UserID] {
+			succeeded = append(succeeded, role)
+		}
+	}
+	return succeeded
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"log"
+	"rbac7/internal/rbac/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EraseUser removes a user's role data for a data-subject erasure request
+func (s *Service) EraseUser(ctx context.Context, callerID string, req model.EraseUserReq) (*model.EraseUserResult, error) {
+	// Permission check handled by RBAC middleware (global platform.user.erase)
+
+	// Random tombstone: the erased ID must not be derivable from it
+	tombstone := "erased_" + primitive.NewObjectID().Hex()
+
+	result, err := s.Repo.EraseUser(ctx, req.UserID, tombstone, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The erased user ID is deliberately not logged; the erasure log keys on the tombstone
+	log.Printf("Audit: User Erased. Caller=%s, Tombstone=%s, RolesDeleted=%d, RolesAnonymized=%d, HistoryAnonymized=%d",
+		callerID, tombstone, result.RolesDeleted, result.RolesAnonymized, result.HistoryAnonymized)
+
+	return result, nil
+}
+
+// PurgeUser hard deletes a user's role documents. Unlike EraseUser it leaves existing history and
+// the actor fields of other roles untouched, and records a purge_user entry of its own.
+func (s *Service) PurgeUser(ctx context.Context, callerID string, req model.PurgeUserReq) (*model.PurgeUserResult, error) {
+	// Permission check handled by RBAC middleware (global platform.user.purge)
+
+	history := &model.UserRoleHistory{
+		Operation: "purge_user",
+		CallerID:  callerID,
+		UserID:    req.UserID,
+	}
+
+	var deleted int64
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.Repo.HardDeleteUserRole(ctx, req.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Audit: User Purged. Caller=%s, Target=%s, RolesDeleted=%d", callerID, req.UserID, deleted)
+
+	return &model.PurgeUserResult{RolesDeleted: deleted}, nil
+}
+
+// ReassignOwner hands every resource a departing user owns to a successor in one transaction.
+// The departing user keeps admin on each resource, as after a regular transfer.
+func (s *Service) ReassignOwner(ctx context.Context, callerID string, req model.ReassignOwnerReq) (*model.ReassignOwnerResult, error) {
+	// Permission check handled by RBAC middleware (global platform.user.reassign_owner)
+
+	if req.OldOwnerID == req.NewOwnerID {
+		return nil, ErrBadRequest
+	}
+
+	transferred, err := s.Repo.ReassignOwnedResources(ctx, req.OldOwnerID, req.NewOwnerID, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Audit: Owned Resources Reassigned. Caller=%s, OldOwner=%s, NewOwner=%s, Transferred=%d",
+		callerID, req.OldOwnerID, req.NewOwnerID, transferred)
+
+	return &model.ReassignOwnerResult{Transferred: transferred}, nil
+}
+
+// AccessSnapshotExpiringWindow flags temporary grants ending within this window as expiring
+const AccessSnapshotExpiringWindow = 7 * 24 * time.Hour
+
+// GetAccessSnapshot lists a user's active roles with the permissions each confers
+func (s *Service) GetAccessSnapshot(ctx context.Context, callerID string, req model.AccessSnapshotReq) (*model.AccessSnapshotResp, error) {
+	// Permission check handled by RBAC middleware (global platform.user.read_access)
+
+	roles, err := s.Repo.FindUserRoles(ctx, model.UserRoleFilter{UserID: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resp := &model.AccessSnapshotResp{
+		UserID:      req.UserID,
+		Grants:      make([]*model.AccessGrant, 0, len(roles)),
+		GeneratedAt: now,
+	}
+	for _, role := range roles {
+		grant := &model.AccessGrant{
+			Role:             role.Role,
+			UserType:         role.UserType,
+			Scope:            role.Scope,
+			Namespace:        role.Namespace,
+			ResourceID:       role.ResourceID,
+			ResourceType:     role.ResourceType,
+			ParentResourceID: role.ParentResourceID,
+			Permissions:      s.Policy.GetRolePermissions(role.Role, role.Scope == model.ScopeSystem),
+			ExpiresAt:        role.ExpiresAt,
+			Reason:           role.Reason,
+			Expiring:         role.ExpiresAt != nil && role.ExpiresAt.Before(now.Add(AccessSnapshotExpiringWindow)),
+		}
+		if grant.Expiring {
+			resp.ExpiringCount++
+		}
+		resp.Grants = append(resp.Grants, grant)
+	}
+
+	log.Printf("Audit: Access Snapshot Read. Caller=%s, Target=%s, Grants=%d", callerID, req.UserID, len(resp.Grants))
+
+	return resp, nil
+}
+
+// ReloadPolicy re-reads the policy files so permission changes apply without a restart
+func (s *Service) ReloadPolicy(ctx context.Context, callerID string) (*model.PolicySummary, error) {
+	// Permission check handled by RBAC middleware (global platform.policy.reload)
+
+	if err := s.Policy.Reload(); err != nil {
+		log.Printf("WARNING Audit: Policy Reload Failed. Caller=%s, Error=%v", callerID, err)
+		return nil, err
+	}
+
+	summary := s.Policy.Summary()
+	log.Printf("Audit: Policy Reloaded. Caller=%s, Entities=%d, Operations=%d, SystemRoles=%d, ResourceRoles=%d",
+		callerID, summary.Entities, summary.Operations, summary.SystemRoles, summary.ResourceRoles)
+
+	return summary, nil
+}
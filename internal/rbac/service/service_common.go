@@ -3,59 +3,182 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"rbac7/internal/rbac/model"
 	"rbac7/internal/rbac/policy"
 	"rbac7/internal/rbac/repository"
+	"slices"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 var (
-	ErrUnauthorized     = errors.New("unauthorized")
-	ErrForbidden        = errors.New("forbidden")
-	ErrConflict         = errors.New("conflict: system owner already exists")
-	ErrInvalidNamespace = errors.New("invalid namespace")
-	ErrBadRequest       = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict: system owner already exists")
+	// ErrNamespaceConflict rejects a rename onto a namespace whose roles cannot be merged with the source
+	ErrNamespaceConflict = errors.New("conflict: target namespace has conflicting roles")
+	ErrInvalidNamespace  = errors.New("invalid namespace")
+	ErrBadRequest        = errors.New("bad request")
+	// ErrResourceNotFound means the namespace or resource to act on has no active owner, i.e. does not exist
+	ErrResourceNotFound = errors.New("system not found or has no owner")
+	// ErrUserNotResolved means no user matches the external_id or email of an assignment
+	ErrUserNotResolved = errors.New("no user matches the external_id or email")
 )
 
+// OwnerExistsError rejects assigning an owner to a resource that already has one. It matches
+// ErrConflict; CurrentOwner is empty when the existing owner could not be looked up.
+type OwnerExistsError struct {
+	CurrentOwner string
+}
+
+func (e *OwnerExistsError) Error() string {
+	return "conflict: resource already has an owner"
+}
+
+func (e *OwnerExistsError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 type RBACService interface {
-	AssignSystemOwner(ctx context.Context, callerID string, req model.AssignSystemOwnerReq) error
-	TransferSystemOwner(ctx context.Context, callerID string, req model.TransferSystemOwnerReq) error
-	AssignSystemUserRole(ctx context.Context, callerID string, req model.AssignSystemUserRoleReq) error
+	AssignSystemOwner(ctx context.Context, callerID string, req model.AssignSystemOwnerReq) (*model.UserRole, error)
+	TransferSystemOwner(ctx context.Context, callerID string, req model.TransferSystemOwnerReq) (*model.UserRole, error)
+	ResolveUserID(ctx context.Context, userID, externalID, email string) (string, error)
+	ResolveAssignments(ctx context.Context, assignments []model.RoleAssignment) error
+	AssignSystemUserRole(ctx context.Context, callerID string, req model.AssignSystemUserRoleReq) (*model.UserRole, error)
 	AssignSystemUserRoles(ctx context.Context, callerID string, req model.AssignSystemUserRolesReq) (*model.BatchUpsertResult, error) // Batch
-	DeleteSystemUserRole(ctx context.Context, callerID string, req model.DeleteSystemUserRoleReq) error
-	GetUserRolesMe(ctx context.Context, callerID string, req model.GetUserRolesMeReq) ([]*model.UserRole, error)
-	GetUserRoles(ctx context.Context, callerID string, req model.GetUserRolesReq) ([]*model.UserRole, error)
-	AssignResourceOwner(ctx context.Context, callerID string, req model.AssignResourceOwnerReq) error
-	TransferResourceOwner(ctx context.Context, callerID string, req model.TransferResourceOwnerReq) error
-	AssignResourceUserRole(ctx context.Context, callerID string, req model.AssignResourceUserRoleReq) error
+	DeleteSystemUserRole(ctx context.Context, callerID string, req model.DeleteSystemUserRoleReq) (int64, error)
+	GetUserRolesMe(ctx context.Context, callerID string, req model.GetUserRolesMeReq) (roles []*model.UserRole, truncated bool, err error)
+	GetUserRoles(ctx context.Context, callerID string, req model.GetUserRolesReq) (roles []*model.UserRole, truncated bool, err error)
+	CheckMembers(ctx context.Context, callerID string, req model.CheckMembersReq) (*model.CheckMembersResp, error)
+	RestoreUserRole(ctx context.Context, callerID string, req model.RestoreUserRoleReq) error
+	AssignResourceOwner(ctx context.Context, callerID string, req model.AssignResourceOwnerReq) (*model.UserRole, error)
+	TransferResourceOwner(ctx context.Context, callerID string, req model.TransferResourceOwnerReq) (*model.UserRole, error)
+	AssignResourceUserRole(ctx context.Context, callerID string, req model.AssignResourceUserRoleReq) (*model.UserRole, error)
 	AssignResourceUserRoles(ctx context.Context, callerID string, req model.AssignResourceUserRolesReq) (*model.BatchUpsertResult, error) // Batch
-	DeleteResourceUserRole(ctx context.Context, callerID string, req model.DeleteResourceUserRoleReq) error
+	DeleteResourceUserRole(ctx context.Context, callerID string, req model.DeleteResourceUserRoleReq) (int64, error)
 	CheckPermission(ctx context.Context, callerID string, req model.CheckPermissionReq) (bool, error)
+	CheckPermissionsBatch(ctx context.Context, callerID string, req model.CheckPermissionsBatchReq) (*model.CheckPermissionsBatchResp, error)
+	GetPermissionRoles(ctx context.Context, callerID string, req model.GetPermissionRolesReq) (*model.GetPermissionRolesResp, error)
 	// Resource Management
-	SoftDeleteResource(ctx context.Context, callerID string, req *model.SoftDeleteResourceReq) error
+	SoftDeleteResource(ctx context.Context, callerID string, req *model.SoftDeleteResourceReq) (int64, error)
 	GetDashboardResource(ctx context.Context, callerID string, req model.GetDashboardResourceReq) (*model.GetDashboardResourceResp, error)
+	GetAccessibleResourceSummary(ctx context.Context, callerID string) (*model.AccessibleResourceSummaryResp, error)
+	GetMyResourceRoles(ctx context.Context, callerID string, req model.GetMyResourceRolesReq) (*model.GetMyResourceRolesResp, error)
+	GetResourceOwner(ctx context.Context, callerID string, req model.GetResourceOwnerReq) (*model.UserRole, error)
+	GetResourceOwners(ctx context.Context, callerID string, req model.GetResourceOwnersReq) (*model.GetResourceOwnersResp, error)
+	GetCapableUsers(ctx context.Context, callerID string, req model.GetCapableUsersReq) (*model.GetCapableUsersResp, error)
+	// Sync
+	SyncUserRoles(ctx context.Context, callerID string, req model.SyncUserRolesReq) (*model.SyncUserRolesResp, error)
 	// History
 	GetUserRoleHistory(ctx context.Context, callerID string, req model.GetUserRoleHistoryReq) (*model.GetUserRoleHistoryResp, error)
+	// Namespace Policy
+	GetNamespaceResourceRoles(ctx context.Context, callerID string, req model.NamespaceResourceRolesReq) (*model.NamespaceResourceRolesResp, error)
+	PutNamespaceResourceRoles(ctx context.Context, callerID string, req model.PutNamespaceResourceRolesReq) error
+	DeleteNamespaceResourceRoles(ctx context.Context, callerID string, req model.NamespaceResourceRolesReq) error
+	RenameNamespace(ctx context.Context, callerID string, req model.RenameNamespaceReq) (*model.RenameNamespaceResult, error)
+	SnapshotNamespace(ctx context.Context, callerID string, req model.NamespaceSnapshotReq) (*model.NamespaceSnapshot, error)
+	RestoreNamespace(ctx context.Context, callerID string, req model.RestoreNamespaceReq) (*model.RestoreNamespaceResult, error)
+	// Admin
+	EraseUser(ctx context.Context, callerID string, req model.EraseUserReq) (*model.EraseUserResult, error)
+	PurgeUser(ctx context.Context, callerID string, req model.PurgeUserReq) (*model.PurgeUserResult, error)
+	ReassignOwner(ctx context.Context, callerID string, req model.ReassignOwnerReq) (*model.ReassignOwnerResult, error)
+	GetAccessSnapshot(ctx context.Context, callerID string, req model.AccessSnapshotReq) (*model.AccessSnapshotResp, error)
+	ReloadPolicy(ctx context.Context, callerID string) (*model.PolicySummary, error)
 }
 
 type Service struct {
 	Repo        repository.RBACRepository
 	HistoryRepo repository.HistoryRepository
 	Policy      *policy.Engine
+	// Notifier delivers grant notifications for requests with notify=true (nil disables them)
+	Notifier Notifier
+	// UserResolver maps external_id or email of assignment requests to a user_id (nil requires user_id)
+	UserResolver UserResolver
+	// PendingOwners activates the caller's pending owner roles on GET /user_roles/me (their first sign-in)
+	PendingOwners bool
+	// AllowMultipleOwners lets a namespace have several owners; only removing or downgrading the
+	// last one is forbidden
+	AllowMultipleOwners bool
+	// WidgetCheckConcurrency bounds the child widget checks of one GetDashboardResource call
+	WidgetCheckConcurrency int
+	// MaxUnpagedRoles caps the roles returned by the unpaginated listings (0 disables the cap)
+	MaxUnpagedRoles int
+	// NamespacedResources matches resource role lookups on the request's namespace, as the repository
+	// keys resources on namespace in this mode (RESOURCE_INDEX_INCLUDE_NAMESPACE)
+	NamespacedResources bool
 }
 
+// DefaultWidgetCheckConcurrency is the number of child widgets checked at once unless configured
+const DefaultWidgetCheckConcurrency = 8
+
+// DefaultMaxUnpagedRoles is the unpaginated listing cap unless configured
+const DefaultMaxUnpagedRoles = 10000
+
 func NewService(repo repository.RBACRepository, historyRepo repository.HistoryRepository) *Service {
 	policyEngine, err := policy.NewEngine()
 	if err != nil {
 		// Policy engine is essential, panic if it fails to load
 		panic("failed to initialize policy engine: " + err.Error())
 	}
-	return &Service{Repo: repo, HistoryRepo: historyRepo, Policy: policyEngine}
+	return &Service{Repo: repo, HistoryRepo: historyRepo, Policy: policyEngine, WidgetCheckConcurrency: DefaultWidgetCheckConcurrency, MaxUnpagedRoles: DefaultMaxUnpagedRoles}
+}
+
+// resourceNamespace returns the namespace a resource role lookup filters on: the request's namespace
+// when resources are keyed on namespace, otherwise none, since roles may then be stored under any namespace
+func (s *Service) resourceNamespace(namespace string) string {
+	if s.NamespacedResources {
+		return namespace
+	}
+	return ""
 }
 
-func (s *Service) GetUserRolesMe(ctx context.Context, callerID string, req model.GetUserRolesMeReq) ([]*model.UserRole, error) {
+// activatePendingRoles turns the caller's pending owner roles into member roles on first sign-in,
+// each with its history entry. Users without pending roles only pay for the lookup.
+func (s *Service) activatePendingRoles(ctx context.Context, callerID string) error {
+	pending, err := s.Repo.FindPendingRoles(ctx, callerID)
+	if err != nil {
+		return err
+	}
+	for _, role := range pending {
+		history := &model.UserRoleHistory{
+			Operation:        "activate_pending_owner",
+			CallerID:         callerID,
+			Scope:            role.Scope,
+			Namespace:        role.Namespace,
+			ResourceID:       role.ResourceID,
+			ResourceType:     role.ResourceType,
+			ParentResourceID: role.ParentResourceID,
+			UserID:           callerID,
+			UserType:         model.UserTypeMember,
+			Role:             role.Role,
+		}
+		err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+			return s.Repo.ActivatePendingRole(ctx, role)
+		})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue // activated by a concurrent request
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Audit: Pending Owner Activated. User=%s, Role=%s, Scope=%s", callerID, role.Role, role.Scope)
+	}
+	return nil
+}
+
+func (s *Service) GetUserRolesMe(ctx context.Context, callerID string, req model.GetUserRolesMeReq) ([]*model.UserRole, bool, error) {
 	// Permission check handled by RBAC middleware for self_roles check_scope
 
+	if s.PendingOwners {
+		if err := s.activatePendingRoles(ctx, callerID); err != nil {
+			return nil, false, err
+		}
+	}
+
 	filter := model.UserRoleFilter{UserID: callerID}
 	if req.Scope != "" {
 		filter.Scope = req.Scope
@@ -64,20 +187,20 @@ func (s *Service) GetUserRolesMe(ctx context.Context, callerID string, req model
 		filter.ResourceType = req.ResourceType
 	}
 
-	roles, err := s.Repo.FindUserRoles(ctx, filter)
+	roles, truncated, err := s.findUserRolesCapped(ctx, filter, "GET /user_roles/me")
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Self-roles permission check: verify caller has read permission
 	if !s.Policy.CheckSelfRolesPermission(roles, req.Scope, req.ResourceType) {
-		return nil, ErrForbidden
+		return nil, false, ErrForbidden
 	}
 
-	return roles, nil
+	return roles, truncated, nil
 }
 
-func (s *Service) GetUserRoles(ctx context.Context, callerID string, req model.GetUserRolesReq) ([]*model.UserRole, error) {
+func (s *Service) GetUserRoles(ctx context.Context, callerID string, req model.GetUserRolesReq) ([]*model.UserRole, bool, error) {
 	// Permission check handled by RBAC middleware
 
 	filter := model.UserRoleFilter{
@@ -90,32 +213,195 @@ func (s *Service) GetUserRoles(ctx context.Context, callerID string, req model.G
 		ParentResourceID: req.ParentResourceID,
 	}
 
-	return s.Repo.FindUserRoles(ctx, filter)
+	roles, truncated, err := s.findUserRolesCapped(ctx, filter, "GET /user_roles")
+	if err != nil {
+		return nil, false, err
+	}
+	if req.IncludeInherited && !truncated {
+		inherited, err := s.findInheritedMembers(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		// The cap covers direct and inherited members together
+		roles, truncated = s.capRoles(append(roles, inherited...), "GET /user_roles")
+	}
+	for _, role := range roles {
+		if req.Expand == model.ExpandPermissions {
+			role.Permissions = s.Policy.GetRolePermissions(role.Role, role.Scope == model.ScopeSystem)
+		}
+		if !req.IncludeAudit {
+			role.OmitAudit()
+		}
+	}
+	return roles, truncated, nil
+}
+
+// findInheritedMembers returns the system owners and admins of the resource's namespace, flagged as
+// inherited. A user with a direct role on the resource is listed under both.
+func (s *Service) findInheritedMembers(ctx context.Context, req model.GetUserRolesReq) ([]*model.UserRole, error) {
+	filter := model.UserRoleFilter{
+		UserID:    req.UserID,
+		Namespace: req.Namespace,
+		Scope:     model.ScopeSystem,
+		Roles:     model.InheritedSystemRoles,
+	}
+	if req.Role != "" {
+		if !slices.Contains(model.InheritedSystemRoles, req.Role) {
+			return nil, nil
+		}
+		filter.Role = req.Role
+	}
+
+	// Capped like the direct roles; GetUserRoles caps the combined list again
+	roles, _, err := s.findUserRolesCapped(ctx, filter, "GET /user_roles")
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		role.Inherited = true
+	}
+	return roles, nil
+}
+
+// findUserRolesCapped runs an unpaginated listing query, returning at most s.MaxUnpagedRoles roles
+// (in created_at order) and whether more matched. Truncation is logged to flag endpoints that need paging.
+func (s *Service) findUserRolesCapped(ctx context.Context, filter model.UserRoleFilter, endpoint string) ([]*model.UserRole, bool, error) {
+	if s.MaxUnpagedRoles <= 0 {
+		roles, err := s.Repo.FindUserRoles(ctx, filter)
+		return roles, false, err
+	}
+
+	// One extra role tells a result of exactly the cap from a truncated one
+	filter.Limit = int64(s.MaxUnpagedRoles) + 1
+	roles, err := s.Repo.FindUserRoles(ctx, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	capped, truncated := s.capRoles(roles, endpoint)
+	return capped, truncated, nil
+}
+
+// capRoles cuts roles to s.MaxUnpagedRoles and reports whether it did, logging the truncation
+func (s *Service) capRoles(roles []*model.UserRole, endpoint string) ([]*model.UserRole, bool) {
+	if s.MaxUnpagedRoles <= 0 || len(roles) <= s.MaxUnpagedRoles {
+		return roles, false
+	}
+	log.Printf("Warning: Unpaginated role listing truncated. Endpoint=%s, Cap=%d", endpoint, s.MaxUnpagedRoles)
+	return roles[:s.MaxUnpagedRoles], true
+}
+
+// CheckMembers returns the subset of req.UserIDs holding an active role in the namespace or on the
+// resource, fetched with one $in query. Permission check (get_member) is handled by RBAC middleware
+func (s *Service) CheckMembers(ctx context.Context, callerID string, req model.CheckMembersReq) (*model.CheckMembersResp, error) {
+	roles, err := s.Repo.FindUserRoles(ctx, model.UserRoleFilter{
+		UserIDs:          req.UserIDs,
+		Namespace:        req.Namespace,
+		Scope:            req.Scope,
+		ResourceID:       req.ResourceID,
+		ResourceType:     req.ResourceType,
+		ParentResourceID: req.ParentResourceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	held := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		held[role.UserID] = true
+	}
+	resp := &model.CheckMembersResp{Members: []string{}}
+	for _, id := range req.UserIDs {
+		if held[id] {
+			resp.Members = append(resp.Members, id)
+		}
+	}
+	return resp, nil
+}
+
+// RestoreUserRole brings back a soft-deleted system or resource role with its original
+// created_at/created_by. Permission check (same as the matching delete) is handled by RBAC middleware.
+// Returns mongo.ErrNoDocuments when the user has no soft-deleted role there.
+func (s *Service) RestoreUserRole(ctx context.Context, callerID string, req model.RestoreUserRoleReq) error {
+	history := &model.UserRoleHistory{
+		Operation:        "restore_user_role",
+		CallerID:         callerID,
+		Scope:            req.Scope,
+		Namespace:        req.Namespace,
+		ResourceID:       req.ResourceID,
+		ResourceType:     req.ResourceType,
+		ParentResourceID: req.ParentResourceID,
+		UserID:           req.UserID,
+		UserType:         req.UserType,
+	}
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.RestoreUserRole(ctx, req.Namespace, req.UserID, req.Scope, req.ResourceID, req.ResourceType)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Audit: User Role Restored. Caller=%s, Target=%s, Scope=%s, Namespace=%s, Resource=%s:%s", callerID, req.UserID, req.Scope, req.Namespace, req.ResourceType, req.ResourceID)
+	return nil
 }
 
 func (s *Service) CheckPermission(ctx context.Context, callerID string, req model.CheckPermissionReq) (bool, error) {
-	if req.Scope == model.ScopeSystem {
-		return s.checkSystemPermissionInternal(ctx, callerID, req.Namespace, req.Permission)
-	} else if req.Scope == model.ScopeResource {
-		return s.Policy.CheckResourceAccess(ctx, s.Repo, callerID, req.ResourceID, req.ResourceType, req.Permission, req.ParentResourceID)
+	var allowed bool
+	var err error
+	switch req.Scope {
+	case model.ScopeSystem:
+		allowed, err = s.Policy.CheckSystemAccess(ctx, s.Repo, callerID, req.Namespace, req.Permission)
+	case model.ScopeResource:
+		allowed, err = s.Policy.CheckResourceAccess(ctx, s.Repo, callerID, req.Namespace, req.ResourceID, req.ResourceType, req.Permission, req.ParentResourceID)
+	default:
+		return false, ErrBadRequest
 	}
 
-	return false, ErrBadRequest
+	// In strict mode an unknown permission here came from the caller, not from policy config
+	if errors.Is(err, policy.ErrUnmappedPermission) {
+		return false, fmt.Errorf("%w: %v", ErrBadRequest, err)
+	}
+	return allowed, err
+}
+
+// CheckPermissionsBatch runs several permission checks of the caller, keyed by check ID. Checks that
+// differ only by ID are evaluated once; the first failing check fails the batch.
+func (s *Service) CheckPermissionsBatch(ctx context.Context, callerID string, req model.CheckPermissionsBatchReq) (*model.CheckPermissionsBatchResp, error) {
+	resp := &model.CheckPermissionsBatchResp{Results: make(map[string]bool, len(req.Checks))}
+	evaluated := make(map[model.CheckPermissionReq]bool, len(req.Checks))
+	for _, check := range req.Checks {
+		allowed, ok := evaluated[check.CheckPermissionReq]
+		if !ok {
+			var err error
+			allowed, err = s.CheckPermission(ctx, callerID, check.CheckPermissionReq)
+			if err != nil {
+				return nil, err
+			}
+			evaluated[check.CheckPermissionReq] = allowed
+		}
+		resp.Results[check.ID] = allowed
+	}
+	return resp, nil
 }
 
-// checkSystemPermissionInternal checks system permission using PolicyEngine's internal methods
-func (s *Service) checkSystemPermissionInternal(ctx context.Context, callerID, namespace, permission string) (bool, error) {
-	requiredRoles := s.Policy.GetRolesWithPermission(permission, true)
-	if len(requiredRoles) == 0 {
-		return false, nil
+// GetPermissionRoles lists the roles granting a permission, from the policy role maps.
+// System roles come first; an empty scope covers both.
+func (s *Service) GetPermissionRoles(ctx context.Context, callerID string, req model.GetPermissionRolesReq) (*model.GetPermissionRolesResp, error) {
+	resp := &model.GetPermissionRolesResp{Permission: req.Permission, Roles: []*model.PermissionRole{}}
+	for _, scope := range []string{model.ScopeSystem, model.ScopeResource} {
+		if req.Scope != "" && req.Scope != scope {
+			continue
+		}
+		for _, role := range s.Policy.GetRolesWithPermission(req.Permission, scope == model.ScopeSystem) {
+			resp.Roles = append(resp.Roles, &model.PermissionRole{Role: role, Scope: scope})
+		}
 	}
-	return s.Repo.HasAnySystemRole(ctx, callerID, namespace, requiredRoles)
+	return resp, nil
 }
 
 // GetUserRoleHistory retrieves user role history with pagination
 func (s *Service) GetUserRoleHistory(ctx context.Context, callerID string, req model.GetUserRoleHistoryReq) (*model.GetUserRoleHistoryResp, error) {
-	// Permission check handled by RBAC middleware
-
+	// read_log is checked by RBAC middleware. Filtering by target_user_id needs nothing more: the
+	// unfiltered log already contains those entries.
 	data, total, err := s.HistoryRepo.FindHistory(ctx, req)
 	if err != nil {
 		return nil, err
@@ -123,20 +409,67 @@ func (s *Service) GetUserRoleHistory(ctx context.Context, callerID string, req m
 
 	return &model.GetUserRoleHistoryResp{
 		Data:       data,
-		Page:       req.Page,
-		Size:       req.Size,
-		TotalCount: total,
+		Pagination: model.NewPagination(req.Page, req.Size, total),
 	}, nil
 }
 
+// writeWithHistory applies a single-role write and records its history atomically.
+// Semantics for racing writes on the same role (e.g. delete vs reassign): the last committed
+// write wins (a reassign after a delete clears deleted_at, a delete after a reassign removes it),
+// and history lists both operations in commit order.
+func (s *Service) writeWithHistory(ctx context.Context, history *model.UserRoleHistory, write func(ctx context.Context) error) error {
+	history.SuperadminBypass = isSuperadminBypass(ctx)
+	if s.HistoryRepo == nil {
+		return write(ctx)
+	}
+	return s.HistoryRepo.WithHistory(ctx, history, write)
+}
+
 // recordHistory is a helper to record history asynchronously (fire-and-forget)
-func (s *Service) recordHistory(history *model.UserRoleHistory) {
+func (s *Service) recordHistory(ctx context.Context, history *model.UserRoleHistory) {
 	if s.HistoryRepo == nil {
 		return
 	}
+	history.SuperadminBypass = isSuperadminBypass(ctx)
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		_ = s.HistoryRepo.CreateHistory(ctx, history)
 	}()
 }
+
+// bulkUpsertWithRejected upserts roles and reports the pairs rejected before the write as failed users
+func (s *Service) bulkUpsertWithRejected(ctx context.Context, roles []*model.UserRole, rejected []model.FailedUserInfo) (*model.BatchUpsertResult, error) {
+	result := &model.BatchUpsertResult{}
+	if len(roles) > 0 {
+		var err error
+		result, err = s.Repo.BulkUpsertUserRoles(ctx, roles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(rejected) > 0 {
+		result.FailedCount += len(rejected)
+		result.FailedUsers = append(result.FailedUsers, rejected...)
+	}
+	return result, nil
+}
+
+// assignedPairs lists the user/role pairs of a batch that were written
+func assignedPairs(roles []*model.UserRole, result *model.BatchUpsertResult) []model.RoleAssignment {
+	succeeded := succeededRoles(roles, result)
+	pairs := make([]model.RoleAssignment, 0, len(succeeded))
+	for _, role := range succeeded {
+		pairs = append(pairs, model.RoleAssignment{UserID: role.UserID, Role: role.Role})
+	}
+	return pairs
+}
+
+// batchRoleLabel names the role of a batch for audit logs, listing every role of a mixed batch
+func batchRoleLabel(role string, accepted []model.RoleAssignment) string {
+	if role != "" {
+		return role
+	}
+	roleNames, _ := model.GroupAssignmentsByRole(accepted)
+	return strings.Join(roleNames, ",")
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+	"time"
+)
+
+// GetNamespaceResourceRoles returns the resource roles assignable in a namespace
+func (s *Service) GetNamespaceResourceRoles(ctx context.Context, callerID string, req model.NamespaceResourceRolesReq) (*model.NamespaceResourceRolesResp, error) {
+	// Permission check handled by RBAC middleware
+
+	override, err := s.Repo.GetNamespaceResourceRoles(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if override == nil {
+		return &model.NamespaceResourceRolesResp{Namespace: req.Namespace, Roles: model.DefaultResourceRoles()}, nil
+	}
+	return &model.NamespaceResourceRolesResp{Namespace: req.Namespace, Roles: override.Roles, Overridden: true}, nil
+}
+
+// PutNamespaceResourceRoles replaces the namespace's assignable resource roles override
+func (s *Service) PutNamespaceResourceRoles(ctx context.Context, callerID string, req model.PutNamespaceResourceRolesReq) error {
+	// Permission check handled by RBAC middleware
+
+	history := &model.UserRoleHistory{
+		Operation: "set_namespace_resource_roles",
+		CallerID:  callerID,
+		Scope:     model.ScopeSystem,
+		Namespace: req.Namespace,
+	}
+
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.SetNamespaceResourceRoles(ctx, &model.NamespaceResourceRoles{
+			Namespace: req.Namespace,
+			Roles:     req.Roles,
+			UpdatedBy: callerID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Audit: Namespace Resource Roles Set. Caller=%s, Namespace=%s, Roles=%v", callerID, req.Namespace, req.Roles)
+	return nil
+}
+
+// DeleteNamespaceResourceRoles removes the override so the namespace uses the global default
+func (s *Service) DeleteNamespaceResourceRoles(ctx context.Context, callerID string, req model.NamespaceResourceRolesReq) error {
+	// Permission check handled by RBAC middleware
+
+	history := &model.UserRoleHistory{
+		Operation: "reset_namespace_resource_roles",
+		CallerID:  callerID,
+		Scope:     model.ScopeSystem,
+		Namespace: req.Namespace,
+	}
+
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.DeleteNamespaceResourceRoles(ctx, req.Namespace)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Audit: Namespace Resource Roles Reset. Caller=%s, Namespace=%s", callerID, req.Namespace)
+	return nil
+}
+
+// isAssignableResourceRole reports whether role may be assigned in namespace.
+// Without a namespace, or when the namespace has no override, the global default applies.
+func (s *Service) isAssignableResourceRole(ctx context.Context, namespace, role string) (bool, error) {
+	if !model.AllowedResourceRoles[role] {
+		return false, nil
+	}
+	if namespace == "" {
+		return true, nil
+	}
+	override, err := s.Repo.GetNamespaceResourceRoles(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	if override == nil {
+		return true, nil
+	}
+	for _, allowed := range override.Roles {
+		if allowed == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RenameNamespace moves a namespace's roles, history and override to a new name
+func (s *Service) RenameNamespace(ctx context.Context, callerID string, req model.RenameNamespaceReq) (*model.RenameNamespaceResult, error) {
+	// Permission check handled by RBAC middleware (global platform.namespace.rename)
+
+	history := &model.UserRoleHistory{
+		Operation:         "rename_namespace",
+		CallerID:          callerID,
+		Scope:             model.ScopeSystem,
+		Namespace:         req.To,
+		PreviousNamespace: req.From,
+	}
+
+	var result *model.RenameNamespaceResult
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		result, err = s.Repo.RenameNamespace(ctx, req.From, req.To, callerID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrNamespaceConflict
+		}
+		return nil, err
+	}
+
+	log.Printf("Audit: Namespace Renamed. Caller=%s, From=%s, To=%s, RolesMigrated=%d, HistoryMigrated=%d",
+		callerID, req.From, req.To, result.RolesMigrated, result.HistoryMigrated)
+
+	return result, nil
+}
+
+// snapshotHistoryPageSize is the page size used to read a namespace's history into a snapshot
+const snapshotHistoryPageSize = 1000
+
+// SnapshotNamespace copies a namespace's active roles and resource roles override, and its history when asked
+func (s *Service) SnapshotNamespace(ctx context.Context, callerID string, req model.NamespaceSnapshotReq) (*model.NamespaceSnapshot, error) {
+	// Permission check handled by RBAC middleware (global platform.namespace.snapshot)
+
+	roles, err := s.Repo.FindUserRoles(ctx, model.UserRoleFilter{Namespace: req.Namespace})
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &model.NamespaceSnapshot{
+		Version:   model.NamespaceSnapshotVersion,
+		Namespace: req.Namespace,
+		CreatedAt: time.Now(),
+		Roles:     roles,
+	}
+	if snapshot.Roles == nil {
+		snapshot.Roles = []*model.UserRole{}
+	}
+
+	override, err := s.Repo.GetNamespaceResourceRoles(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		snapshot.ResourceRoles = override.Roles
+	}
+
+	if req.IncludeHistory {
+		for page := 1; ; page++ {
+			entries, _, err := s.HistoryRepo.FindHistory(ctx, model.GetUserRoleHistoryReq{
+				Scope:     model.ScopeSystem,
+				Namespace: req.Namespace,
+				Page:      page,
+				Size:      snapshotHistoryPageSize,
+			})
+			if err != nil {
+				return nil, err
+			}
+			snapshot.History = append(snapshot.History, entries...)
+			if len(entries) < snapshotHistoryPageSize {
+				break
+			}
+		}
+	}
+
+	log.Printf("Audit: Namespace Snapshot. Caller=%s, Namespace=%s, Roles=%d, History=%d",
+		callerID, req.Namespace, len(snapshot.Roles), len(snapshot.History))
+
+	return snapshot, nil
+}
+
+// RestoreNamespace applies a snapshot to a namespace, cloning it when the snapshot was taken elsewhere
+func (s *Service) RestoreNamespace(ctx context.Context, callerID string, req model.RestoreNamespaceReq) (*model.RestoreNamespaceResult, error) {
+	// Permission check handled by RBAC middleware (global platform.namespace.restore)
+
+	snapshot := req.Snapshot
+	for _, role := range snapshot.Roles {
+		role.Namespace = req.Namespace
+	}
+
+	history := &model.UserRoleHistory{
+		Operation:         "restore_namespace",
+		CallerID:          callerID,
+		Scope:             model.ScopeSystem,
+		Namespace:         req.Namespace,
+		PreviousNamespace: snapshot.Namespace,
+	}
+
+	var result *model.RestoreNamespaceResult
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		result, err = s.Repo.RestoreNamespace(ctx, req.Namespace, &snapshot, req.Mode == model.RestoreModeReplace, callerID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrNamespaceConflict
+		}
+		return nil, err
+	}
+	result.Mode = req.Mode
+
+	log.Printf("Audit: Namespace Restored. Caller=%s, Namespace=%s, Source=%s, Mode=%s, RolesRestored=%d, RolesRemoved=%d",
+		callerID, req.Namespace, snapshot.Namespace, req.Mode, result.RolesRestored, result.RolesRemoved)
+
+	return result, nil
+}
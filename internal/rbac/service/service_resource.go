@@ -6,26 +6,29 @@ import (
 	"log"
 	"rbac7/internal/rbac/model"
 	"rbac7/internal/rbac/repository"
+	"sort"
+	"sync"
 
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func (s *Service) AssignResourceOwner(ctx context.Context, callerID string, req model.AssignResourceOwnerReq) error {
+func (s *Service) AssignResourceOwner(ctx context.Context, callerID string, req model.AssignResourceOwnerReq) (*model.UserRole, error) {
 	// Permission check handled by RBAC middleware (check_scope: none)
 
 	// Check if owner already exists
-	count, err := s.Repo.CountResourceOwners(ctx, req.ResourceID, req.ResourceType)
+	count, err := s.Repo.CountResourceOwners(ctx, req.Namespace, req.ResourceID, req.ResourceType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if count > 0 {
-		return ErrConflict
+		return nil, s.resourceOwnerConflict(ctx, req.Namespace, req.ResourceID, req.ResourceType)
 	}
 
 	newRole := &model.UserRole{
 		UserID:       callerID, // Caller becomes owner
 		Role:         model.RoleResourceOwner,
 		Scope:        model.ScopeResource,
+		Namespace:    req.Namespace,
 		ResourceID:   req.ResourceID,
 		ResourceType: req.ResourceType,
 		UserType:     model.UserTypeMember,
@@ -33,86 +36,152 @@ func (s *Service) AssignResourceOwner(ctx context.Context, callerID string, req
 		UpdatedBy:    callerID,
 	}
 
-	err = s.Repo.CreateUserRole(ctx, newRole)
-	if err != nil {
-		if errors.Is(err, repository.ErrDuplicate) {
-			return ErrConflict
-		}
-		return err
-	}
-
-	log.Printf("Audit: Resource Owner Assigned. Caller=%s, Target=%s, Resource=%s:%s", callerID, callerID, req.ResourceType, req.ResourceID)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Insert and history in one transaction
+	history := &model.UserRoleHistory{
 		Operation:    "assign_owner",
 		CallerID:     callerID,
 		Scope:        model.ScopeResource,
+		Namespace:    req.Namespace,
 		ResourceID:   req.ResourceID,
 		ResourceType: req.ResourceType,
 		UserID:       callerID,
+	}
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.CreateUserRole(ctx, newRole)
 	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, s.resourceOwnerConflict(ctx, req.Namespace, req.ResourceID, req.ResourceType)
+		}
+		return nil, err
+	}
 
-	return nil
+	log.Printf("Audit: Resource Owner Assigned. Caller=%s, Target=%s, Resource=%s:%s", callerID, callerID, req.ResourceType, req.ResourceID)
+
+	return newRole, nil
+}
+
+// resourceOwnerConflict names the resource's current owner in the conflict, so the caller knows
+// whom to ask for a transfer. A failed lookup still reports the conflict, just without the owner.
+func (s *Service) resourceOwnerConflict(ctx context.Context, namespace, resourceID, resourceType string) error {
+	conflict := &OwnerExistsError{}
+	owner, err := s.Repo.GetResourceOwner(ctx, namespace, resourceID, resourceType)
+	if err != nil {
+		log.Printf("Warning: Owner lookup for conflict failed. Resource=%s:%s, err=%v", resourceType, resourceID, err)
+		return conflict
+	}
+	if owner != nil {
+		conflict.CurrentOwner = owner.UserID
+	}
+	return conflict
 }
 
-func (s *Service) TransferResourceOwner(ctx context.Context, callerID string, req model.TransferResourceOwnerReq) error {
+func (s *Service) TransferResourceOwner(ctx context.Context, callerID string, req model.TransferResourceOwnerReq) (*model.UserRole, error) {
 	if req.UserID == callerID {
-		return ErrBadRequest
+		return nil, ErrBadRequest
 	}
 
 	// Permission check handled by RBAC middleware
 
-	oldOwnerID := callerID
-
-	err := s.Repo.TransferResourceOwner(ctx, req.ResourceID, req.ResourceType, oldOwnerID, req.UserID, callerID)
+	oldOwnerID, err := s.resourceOwnerToDemote(ctx, callerID, req.Namespace, req.ResourceID, req.ResourceType)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if req.UserID == oldOwnerID {
+		return nil, ErrBadRequest
 	}
 
-	log.Printf("Audit: Resource Owner Transferred. Caller=%s, NewOwner=%s, OldOwner=%s, Resource=%s:%s", callerID, req.UserID, oldOwnerID, req.ResourceType, req.ResourceID)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Demote, promote and history in one transaction
+	history := &model.UserRoleHistory{
 		Operation:    "transfer_owner",
 		CallerID:     callerID,
 		Scope:        model.ScopeResource,
+		Namespace:    req.Namespace,
 		ResourceID:   req.ResourceID,
 		ResourceType: req.ResourceType,
+		UserID:       oldOwnerID,
 		NewOwnerID:   req.UserID,
+	}
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.TransferResourceOwner(ctx, req.Namespace, req.ResourceID, req.ResourceType, oldOwnerID, req.UserID, callerID)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Audit: Resource Owner Transferred. Caller=%s, NewOwner=%s, OldOwner=%s, Resource=%s:%s", callerID, req.UserID, oldOwnerID, req.ResourceType, req.ResourceID)
+
+	// The owner role as the transfer promoted it
+	newOwner := &model.UserRole{
+		UserID:       req.UserID,
+		Role:         model.RoleResourceOwner,
+		Scope:        model.ScopeResource,
+		Namespace:    req.Namespace,
+		ResourceID:   req.ResourceID,
+		ResourceType: req.ResourceType,
+		UserType:     model.UserTypeMember,
+		UpdatedBy:    callerID,
+	}
+
+	return newOwner, nil
+}
+
+// resourceOwnerToDemote picks the owner a transfer demotes. A resource has at most one owner
+// (unique_resource_owner index), so an owner caller hands over their own ownership and anyone else
+// allowed to transfer (e.g. a superadmin) replaces the current owner. ErrResourceNotFound when the
+// resource has no owner.
+func (s *Service) resourceOwnerToDemote(ctx context.Context, callerID, namespace, resourceID, resourceType string) (string, error) {
+	isOwner, err := s.Repo.HasResourceRole(ctx, namespace, callerID, resourceID, resourceType, model.RoleResourceOwner)
+	if err != nil {
+		return "", err
+	}
+	if isOwner {
+		return callerID, nil
+	}
 
-	return nil
+	currentOwner, err := s.Repo.GetResourceOwner(ctx, namespace, resourceID, resourceType)
+	if err != nil {
+		return "", err
+	}
+	if currentOwner == nil {
+		return "", ErrResourceNotFound
+	}
+	return currentOwner.UserID, nil
 }
 
-func (s *Service) AssignResourceUserRole(ctx context.Context, callerID string, req model.AssignResourceUserRoleReq) error {
+func (s *Service) AssignResourceUserRole(ctx context.Context, callerID string, req model.AssignResourceUserRoleReq) (*model.UserRole, error) {
 	if req.Role == model.RoleResourceOwner {
-		return ErrForbidden // Use Transfer or AssignOwner
+		return nil, ErrForbidden // Use Transfer or AssignOwner
 	}
-	if req.Role != "admin" && req.Role != "editor" && req.Role != "viewer" {
-		return ErrBadRequest
+	// Role must be assignable in the namespace (global default unless the namespace overrides it)
+	assignable, err := s.isAssignableResourceRole(ctx, req.Namespace, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !assignable {
+		return nil, ErrBadRequest
 	}
 
 	// Permission check handled by RBAC middleware
 
 	// Check if target user is already owner
-	isOwner, err := s.Repo.HasResourceRole(ctx, req.UserID, req.ResourceID, req.ResourceType, model.RoleResourceOwner)
+	isOwner, err := s.Repo.HasResourceRole(ctx, req.Namespace, req.UserID, req.ResourceID, req.ResourceType, model.RoleResourceOwner)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if isOwner {
-		return ErrForbidden
+		return nil, ErrForbidden
 	}
 
 	// For dashboard_widget: target user must have parent dashboard read permission
 	if req.ResourceType == model.ResourceTypeDashboardWidget {
 		viewerRoles := s.Policy.GetRolesWithPermission(model.PermResourceDashboardRead, false)
-		hasParentAccess, err := s.Repo.HasAnyResourceRole(ctx, req.UserID, req.ParentResourceID, model.ResourceTypeDashboard, viewerRoles)
+		hasParentAccess, err := s.Repo.HasAnyResourceRole(ctx, req.Namespace, req.UserID, req.ParentResourceID, model.ResourceTypeDashboard, viewerRoles)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if !hasParentAccess {
-			return ErrBadRequest // User must have parent dashboard read permission to be added to widget whitelist
+			return nil, ErrBadRequest // User must have parent dashboard read permission to be added to widget whitelist
 		}
 	}
 
@@ -120,73 +189,71 @@ func (s *Service) AssignResourceUserRole(ctx context.Context, callerID string, r
 		UserID:           req.UserID,
 		Role:             req.Role,
 		Scope:            model.ScopeResource,
-		Namespace:        "",
+		Namespace:        req.Namespace,
 		ResourceID:       req.ResourceID,
 		ResourceType:     req.ResourceType,
 		ParentResourceID: req.ParentResourceID,
 		UserType:         req.UserType,
+		ExpiresAt:        req.ExpiresAt,
+		Reason:           req.Reason,
 		CreatedBy:        callerID,
 		UpdatedBy:        callerID,
 	}
 	if role.UserType == "" {
 		role.UserType = model.UserTypeMember
 	}
-	if err := s.Repo.UpsertUserRole(ctx, role); err != nil {
-		return err
-	}
-
-	log.Printf("Audit: Resource User Role Assigned. Caller=%s, Target=%s, Role=%s, Resource=%s:%s", callerID, req.UserID, req.Role, req.ResourceType, req.ResourceID)
 
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Upsert and history in one transaction (a reassign racing a delete is ordered in history)
+	history := &model.UserRoleHistory{
 		Operation:        "assign_user_role",
 		CallerID:         callerID,
 		Scope:            model.ScopeResource,
+		Namespace:        req.Namespace,
 		ResourceID:       req.ResourceID,
 		ResourceType:     req.ResourceType,
 		ParentResourceID: req.ParentResourceID,
 		UserID:           req.UserID,
 		UserType:         req.UserType,
 		Role:             req.Role,
+		ExpiresAt:        req.ExpiresAt,
+		Reason:           req.Reason,
+	}
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.UpsertUserRole(ctx, role)
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	log.Printf("Audit: Resource User Role Assigned. Caller=%s, Target=%s, Role=%s, Resource=%s:%s", callerID, req.UserID, req.Role, req.ResourceType, req.ResourceID)
+
+	if req.Notify {
+		s.notifyGrants(ctx, role)
+	}
+
+	return role, nil
 }
 
-func (s *Service) DeleteResourceUserRole(ctx context.Context, callerID string, req model.DeleteResourceUserRoleReq) error {
+// DeleteResourceUserRole removes the user from the resource and returns how many roles were deleted,
+// including the user's roles on a dashboard's widgets
+func (s *Service) DeleteResourceUserRole(ctx context.Context, callerID string, req model.DeleteResourceUserRoleReq) (int64, error) {
 	if req.UserID == "" || req.ResourceID == "" || req.ResourceType == "" {
-		return ErrBadRequest
+		return 0, ErrBadRequest
 	}
 
 	// Permission check handled by RBAC middleware
 
 	// Cannot remove Owner
-	isOwner, err := s.Repo.HasResourceRole(ctx, req.UserID, req.ResourceID, req.ResourceType, model.RoleResourceOwner)
+	isOwner, err := s.Repo.HasResourceRole(ctx, req.Namespace, req.UserID, req.ResourceID, req.ResourceType, model.RoleResourceOwner)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if isOwner {
-		return ErrForbidden
-	}
-
-	err = s.Repo.DeleteUserRole(ctx, req.Namespace, req.UserID, model.ScopeResource, req.ResourceID, req.ResourceType, req.ParentResourceID, callerID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil
-		}
-		return err
+		return 0, ErrForbidden
 	}
 
-	log.Printf("Audit: Resource User Role Deleted. Caller=%s, Target=%s, Resource=%s:%s", callerID, req.UserID, req.ResourceType, req.ResourceID)
-
-	// For dashboard: cascade delete user's child widget whitelist roles
-	if req.ResourceType == model.ResourceTypeDashboard {
-		// Ignore errors - this is a best-effort cleanup
-		_ = s.Repo.DeleteUserRolesByParent(ctx, req.UserID, req.ResourceID, model.ResourceTypeDashboardWidget, callerID)
-	}
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Soft delete and history in one transaction; nothing is recorded if the role is already gone
+	history := &model.UserRoleHistory{
 		Operation:        "delete_user_role",
 		CallerID:         callerID,
 		Scope:            model.ScopeResource,
@@ -196,111 +263,158 @@ func (s *Service) DeleteResourceUserRole(ctx context.Context, callerID string, r
 		UserID:           req.UserID,
 		UserType:         req.UserType,
 		Namespace:        req.Namespace,
+	}
+	var deleted int64
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.Repo.DeleteUserRole(ctx, req.Namespace, req.UserID, model.ScopeResource, req.ResourceID, req.ResourceType, req.ParentResourceID, callerID)
+		return err
 	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	log.Printf("Audit: Resource User Role Deleted. Caller=%s, Target=%s, Resource=%s:%s, Deleted=%d", callerID, req.UserID, req.ResourceType, req.ResourceID, deleted)
+
+	// For dashboard: cascade delete user's child widget whitelist roles
+	if req.ResourceType == model.ResourceTypeDashboard {
+		// Ignore errors - this is a best-effort cleanup
+		cascaded, _ := s.Repo.DeleteUserRolesByParent(ctx, req.Namespace, req.UserID, req.ResourceID, model.ResourceTypeDashboardWidget, callerID)
+		deleted += cascaded
+	}
 
-	return nil
+	return deleted, nil
 }
 
 func (s *Service) AssignResourceUserRoles(ctx context.Context, callerID string, req model.AssignResourceUserRolesReq) (*model.BatchUpsertResult, error) {
 	// Permission check handled by RBAC middleware
 
-	// For dashboard_widget: filter users who have parent dashboard read permission
-	validUserIDs := req.UserIDs
-	var invalidUsers []model.FailedUserInfo
+	// A single-role batch is rejected outright; a mixed batch fails only the offending pairs below
+	assignable := make(map[string]bool)
+	if req.Role != "" {
+		ok, err := s.isAssignableResourceRole(ctx, req.Namespace, req.Role)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrBadRequest
+		}
+		assignable[req.Role] = true
+	}
+
+	var viewerRoles []string
 	if req.ResourceType == model.ResourceTypeDashboardWidget {
-		viewerRoles := s.Policy.GetRolesWithPermission(model.PermResourceDashboardRead, false)
-		validUserIDs = make([]string, 0, len(req.UserIDs))
-		for _, userID := range req.UserIDs {
-			hasParentAccess, err := s.Repo.HasAnyResourceRole(ctx, userID, req.ParentResourceID, model.ResourceTypeDashboard, viewerRoles)
+		viewerRoles = s.Policy.GetRolesWithPermission(model.PermResourceDashboardRead, false)
+	}
+
+	// Build roles slice for bulk upsert
+	userType := req.UserType
+	if userType == "" {
+		userType = model.UserTypeMember
+	}
+	var roles []*model.UserRole
+	var accepted []model.RoleAssignment
+	var invalidUsers []model.FailedUserInfo
+	for _, a := range req.RoleAssignments() {
+		reason := req.RoleError(a.Role)
+		if reason == "" {
+			ok, checked := assignable[a.Role]
+			if !checked {
+				var err error
+				if ok, err = s.isAssignableResourceRole(ctx, req.Namespace, a.Role); err != nil {
+					return nil, err
+				}
+				assignable[a.Role] = ok
+			}
+			if !ok {
+				reason = "role is not assignable in this namespace"
+			}
+		}
+		if reason != "" {
+			invalidUsers = append(invalidUsers, model.FailedUserInfo{UserID: a.UserID, Reason: reason})
+			continue
+		}
+		// For dashboard_widget: only users who have parent dashboard read permission
+		if req.ResourceType == model.ResourceTypeDashboardWidget {
+			hasParentAccess, err := s.Repo.HasAnyResourceRole(ctx, req.Namespace, a.UserID, req.ParentResourceID, model.ResourceTypeDashboard, viewerRoles)
 			if err != nil {
 				return nil, err
 			}
-			if hasParentAccess {
-				validUserIDs = append(validUserIDs, userID)
-			} else {
+			if !hasParentAccess {
 				invalidUsers = append(invalidUsers, model.FailedUserInfo{
-					UserID: userID,
+					UserID: a.UserID,
 					Reason: "user must have parent dashboard read permission",
 				})
+				continue
 			}
 		}
-		// If no valid users, return early with failure result
-		if len(validUserIDs) == 0 {
-			return &model.BatchUpsertResult{
-				SuccessCount: 0,
-				FailedCount:  len(invalidUsers),
-				FailedUsers:  invalidUsers,
-			}, nil
-		}
-	}
 
-	// Build roles slice for bulk upsert
-	roles := make([]*model.UserRole, 0, len(validUserIDs))
-	userType := req.UserType
-	if userType == "" {
-		userType = model.UserTypeMember
-	}
-	for _, userID := range validUserIDs {
 		role := &model.UserRole{
-			UserID:           userID,
-			Role:             req.Role,
+			UserID:           a.UserID,
+			Role:             a.Role,
 			Scope:            model.ScopeResource,
 			Namespace:        req.Namespace,
 			ResourceID:       req.ResourceID,
 			ResourceType:     req.ResourceType,
 			ParentResourceID: req.ParentResourceID,
 			UserType:         userType,
+			ExpiresAt:        req.ExpiresAt,
+			Reason:           req.Reason,
 			CreatedBy:        callerID,
 			UpdatedBy:        callerID,
 		}
+		accepted = append(accepted, a)
 		roles = append(roles, role)
 	}
 
-	result, err := s.Repo.BulkUpsertUserRoles(ctx, roles)
+	// Invalid users (bad role or no parent permission) are merged into the result
+	result, err := s.bulkUpsertWithRejected(ctx, roles, invalidUsers)
 	if err != nil {
 		return nil, err
 	}
-
-	// Merge invalid users (no parent permission) into result
-	if len(invalidUsers) > 0 {
-		result.FailedCount += len(invalidUsers)
-		result.FailedUsers = append(result.FailedUsers, invalidUsers...)
+	if len(req.Assignments) > 0 {
+		result.Assigned = assignedPairs(roles, result)
 	}
 
 	log.Printf("Audit: Resource User Roles Assigned (Batch). Caller=%s, Success=%d, Failed=%d, Role=%s, Resource=%s:%s",
-		callerID, result.SuccessCount, result.FailedCount, req.Role, req.ResourceType, req.ResourceID)
+		callerID, result.SuccessCount, result.FailedCount, batchRoleLabel(req.Role, accepted), req.ResourceType, req.ResourceID)
 
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
-		Operation:        "assign_user_roles_batch",
-		CallerID:         callerID,
-		Scope:            model.ScopeResource,
-		ResourceID:       req.ResourceID,
-		ResourceType:     req.ResourceType,
-		ParentResourceID: req.ParentResourceID,
-		UserIDs:          req.UserIDs,
-		UserType:         req.UserType,
-		Role:             req.Role,
-		Namespace:        req.Namespace,
-	})
+	if req.Notify {
+		s.notifyGrants(ctx, succeededRoles(roles, result)...)
+	}
+
+	// Record history, one entry per granted role
+	roleNames, usersByRole := model.GroupAssignmentsByRole(accepted)
+	for _, roleName := range roleNames {
+		s.recordHistory(ctx, &model.UserRoleHistory{
+			Operation:        "assign_user_roles_batch",
+			CallerID:         callerID,
+			Scope:            model.ScopeResource,
+			ResourceID:       req.ResourceID,
+			ResourceType:     req.ResourceType,
+			ParentResourceID: req.ParentResourceID,
+			UserIDs:          usersByRole[roleName],
+			UserType:         req.UserType,
+			Role:             roleName,
+			Namespace:        req.Namespace,
+			ExpiresAt:        req.ExpiresAt,
+			Reason:           req.Reason,
+		})
+	}
 
 	return result, nil
 }
 
-// SoftDeleteResource - Soft delete all user roles for a resource
+// SoftDeleteResource - Soft delete all user roles for a resource and return how many were deleted
 // This is used when deleting a resource entirely (dashboard, dashboard_widget, library_widget)
-func (s *Service) SoftDeleteResource(ctx context.Context, callerID string, req *model.SoftDeleteResourceReq) error {
+func (s *Service) SoftDeleteResource(ctx context.Context, callerID string, req *model.SoftDeleteResourceReq) (int64, error) {
 	// Permission check handled by RBAC middleware
 
-	if err := s.Repo.SoftDeleteResourceUserRoles(ctx, req, callerID); err != nil {
-		return err
-	}
-
-	log.Printf("Audit: Resource Soft Deleted. Caller=%s, Resource=%s:%s, ChildResources=%d",
-		callerID, req.ResourceType, req.ResourceID, len(req.ChildResourceIDs))
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Soft delete and history in one transaction
+	history := &model.UserRoleHistory{
 		Operation:        "delete_resource",
 		CallerID:         callerID,
 		Scope:            model.ScopeResource,
@@ -308,9 +422,22 @@ func (s *Service) SoftDeleteResource(ctx context.Context, callerID string, req *
 		ResourceType:     req.ResourceType,
 		ParentResourceID: req.ParentResourceID,
 		ChildResourceIDs: req.ChildResourceIDs,
+		Namespace:        req.Namespace,
+	}
+	var deleted int64
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.Repo.SoftDeleteResourceUserRoles(ctx, req, callerID)
+		return err
 	})
+	if err != nil {
+		return 0, err
+	}
 
-	return nil
+	log.Printf("Audit: Resource Soft Deleted. Caller=%s, Resource=%s:%s, ChildResources=%d, Deleted=%d",
+		callerID, req.ResourceType, req.ResourceID, len(req.ChildResourceIDs), deleted)
+
+	return deleted, nil
 }
 
 // GetDashboardResource - Get dashboard user roles and accessible widget IDs
@@ -322,6 +449,7 @@ func (s *Service) GetDashboardResource(ctx context.Context, callerID string, req
 	// Get dashboard user roles
 	filter := model.UserRoleFilter{
 		UserID:       callerID,
+		Namespace:    s.resourceNamespace(req.Namespace),
 		ResourceID:   req.ResourceID,
 		ResourceType: req.ResourceType,
 		Scope:        model.ScopeResource,
@@ -342,33 +470,199 @@ func (s *Service) GetDashboardResource(ctx context.Context, callerID string, req
 	}
 
 	// Determine accessible widget IDs
-	accessibleWidgetIDs := make([]string, 0)
-	viewerRoles := s.Policy.GetRolesWithPermission(model.PermResourceDashboardWidgetRead, false)
+	accessibleWidgetIDs, err := s.accessibleWidgets(ctx, callerID, req.Namespace, req.ChildResourceIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, widgetID := range req.ChildResourceIDs {
-		// Check if widget is in whitelist mode (has roles assigned)
-		roleCount, err := s.Repo.CountResourceRoles(ctx, widgetID, "dashboard_widget")
-		if err != nil {
-			return nil, err
-		}
+	return &model.GetDashboardResourceResp{
+		UserRoles:           roleDTOs,
+		AccessibleWidgetIDs: accessibleWidgetIDs,
+	}, nil
+}
 
-		if roleCount == 0 {
-			// Inheritance mode: inherit from parent dashboard -> accessible
-			accessibleWidgetIDs = append(accessibleWidgetIDs, widgetID)
-		} else {
-			// Whitelist mode: strict check on widget
-			hasRole, err := s.Repo.HasAnyResourceRole(ctx, callerID, widgetID, "dashboard_widget", viewerRoles)
+// accessibleWidgets returns the widgets the caller may read, in request order. Each widget needs up
+// to two queries, so at most WidgetCheckConcurrency widgets are checked at once; the first error
+// stops the remaining checks.
+func (s *Service) accessibleWidgets(ctx context.Context, callerID, namespace string, widgetIDs []string) ([]string, error) {
+	viewerRoles := s.Policy.GetRolesWithPermission(model.PermResourceDashboardWidgetRead, false)
+	limit := max(s.WidgetCheckConcurrency, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	accessible := make([]bool, len(widgetIDs))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, limit)
+	for i, widgetID := range widgetIDs {
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			<-slots
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			ok, err := s.widgetAccessible(ctx, callerID, namespace, widgetID, viewerRoles)
 			if err != nil {
-				return nil, err
-			}
-			if hasRole {
-				accessibleWidgetIDs = append(accessibleWidgetIDs, widgetID)
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
 			}
+			accessible[i] = ok
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	accessibleWidgetIDs := make([]string, 0, len(widgetIDs))
+	for i, widgetID := range widgetIDs {
+		if accessible[i] {
+			accessibleWidgetIDs = append(accessibleWidgetIDs, widgetID)
 		}
 	}
+	return accessibleWidgetIDs, nil
+}
 
-	return &model.GetDashboardResourceResp{
-		UserRoles:           roleDTOs,
-		AccessibleWidgetIDs: accessibleWidgetIDs,
-	}, nil
+// widgetAccessible reports whether the caller may read one dashboard widget
+func (s *Service) widgetAccessible(ctx context.Context, callerID, namespace, widgetID string, viewerRoles []string) (bool, error) {
+	// Check if widget is in whitelist mode (has roles assigned)
+	roleCount, err := s.Repo.CountResourceRoles(ctx, namespace, widgetID, "dashboard_widget")
+	if err != nil {
+		return false, err
+	}
+	if roleCount == 0 {
+		// Inheritance mode: inherit from parent dashboard -> accessible
+		return true, nil
+	}
+	// Whitelist mode: strict check on widget
+	return s.Repo.HasAnyResourceRole(ctx, namespace, callerID, widgetID, "dashboard_widget", viewerRoles)
+}
+
+// GetAccessibleResourceSummary counts, per resource type, the resources the caller holds any role on
+// No permission check: callers only ever see counts of their own roles
+func (s *Service) GetAccessibleResourceSummary(ctx context.Context, callerID string) (*model.AccessibleResourceSummaryResp, error) {
+	counts, err := s.Repo.CountAccessibleResourcesByType(ctx, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.AccessibleResourceSummaryResp{Counts: counts}
+	for _, count := range counts {
+		resp.Total += count
+	}
+	return resp, nil
+}
+
+// GetMyResourceRoles returns the caller's role on each requested resource in a single query
+// No permission check: callers only ever see their own roles
+func (s *Service) GetMyResourceRoles(ctx context.Context, callerID string, req model.GetMyResourceRolesReq) (*model.GetMyResourceRolesResp, error) {
+	roles, err := s.Repo.FindUserRoles(ctx, model.UserRoleFilter{
+		UserID:       callerID,
+		Namespace:    s.resourceNamespace(req.Namespace),
+		Scope:        model.ScopeResource,
+		ResourceType: req.ResourceType,
+		ResourceIDs:  req.ResourceIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.GetMyResourceRolesResp{Roles: make(map[string]*string, len(req.ResourceIDs))}
+	for _, id := range req.ResourceIDs {
+		resp.Roles[id] = nil
+	}
+	for _, role := range roles {
+		if current, ok := resp.Roles[role.ResourceID]; ok && current == nil {
+			resp.Roles[role.ResourceID] = &role.Role
+		}
+	}
+	return resp, nil
+}
+
+// GetResourceOwner returns the resource's owner, or nil when it has none
+// Permission check (get_member on the resource) is handled by RBAC middleware
+func (s *Service) GetResourceOwner(ctx context.Context, callerID string, req model.GetResourceOwnerReq) (*model.UserRole, error) {
+	owner, err := s.Repo.GetResourceOwner(ctx, req.Namespace, req.ResourceID, req.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Audit: Resource Owner Read. Caller=%s, Resource=%s:%s, Found=%v", callerID, req.ResourceType, req.ResourceID, owner != nil)
+
+	return owner, nil
+}
+
+// GetResourceOwners returns the owner of each requested resource in a single query
+// Permission check (global platform.resource.read_owners) is handled by RBAC middleware
+func (s *Service) GetResourceOwners(ctx context.Context, callerID string, req model.GetResourceOwnersReq) (*model.GetResourceOwnersResp, error) {
+	roles, err := s.Repo.FindUserRoles(ctx, model.UserRoleFilter{
+		Namespace:    s.resourceNamespace(req.Namespace),
+		Role:         model.RoleResourceOwner,
+		Scope:        model.ScopeResource,
+		ResourceType: req.ResourceType,
+		ResourceIDs:  req.ResourceIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.GetResourceOwnersResp{Owners: make(map[string]*string, len(req.ResourceIDs))}
+	for _, id := range req.ResourceIDs {
+		resp.Owners[id] = nil
+	}
+	for _, role := range roles {
+		if current, ok := resp.Owners[role.ResourceID]; ok && current == nil {
+			resp.Owners[role.ResourceID] = &role.UserID
+		}
+	}
+
+	log.Printf("Audit: Resource Owners Read. Caller=%s, Type=%s, Requested=%d, Owned=%d", callerID, req.ResourceType, len(req.ResourceIDs), len(roles))
+
+	return resp, nil
+}
+
+// GetCapableUsers lists members whose role on the resource grants the permission:
+// the permission is resolved to roles from the policy, then members are fetched with one $in query.
+// Permission check (get_member) is handled by RBAC middleware
+func (s *Service) GetCapableUsers(ctx context.Context, callerID string, req model.GetCapableUsersReq) (*model.GetCapableUsersResp, error) {
+	resp := &model.GetCapableUsersResp{
+		Permission: req.Permission,
+		Roles:      s.Policy.GetRolesWithPermission(req.Permission, false),
+		Users:      []*model.CapableUser{},
+	}
+	if len(resp.Roles) == 0 {
+		resp.Roles = []string{}
+		return resp, nil
+	}
+
+	roles, truncated, err := s.findUserRolesCapped(ctx, model.UserRoleFilter{
+		Namespace:        req.Namespace,
+		Roles:            resp.Roles,
+		Scope:            model.ScopeResource,
+		ResourceID:       req.ResourceID,
+		ResourceType:     req.ResourceType,
+		ParentResourceID: req.ParentResourceID,
+	}, "GET /resources/capable_users")
+	if err != nil {
+		return nil, err
+	}
+	resp.Truncated = truncated
+
+	for _, role := range roles {
+		resp.Users = append(resp.Users, &model.CapableUser{UserID: role.UserID, UserType: role.UserType, Role: role.Role})
+	}
+	sort.Slice(resp.Users, func(i, j int) bool { return resp.Users[i].UserID < resp.Users[j].UserID })
+	return resp, nil
 }
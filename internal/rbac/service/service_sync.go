@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"rbac7/internal/rbac/model"
+)
+
+// SyncUserRoles returns roles changed or soft deleted since req.ModifiedSince with the next high-water mark.
+// At most s.MaxUnpagedRoles roles are returned, oldest change first, so the cursor of a truncated page
+// is the change time of its last role.
+func (s *Service) SyncUserRoles(ctx context.Context, callerID string, req model.SyncUserRolesReq) (*model.SyncUserRolesResp, error) {
+	// Permission check handled by RBAC middleware (global platform.role.sync)
+
+	roles, truncated, err := s.findUserRolesCapped(ctx, model.UserRoleFilter{
+		Scope:         req.Scope,
+		Namespace:     req.Namespace,
+		ResourceType:  req.ResourceType,
+		ModifiedSince: req.ModifiedSince,
+	}, "GET /user_roles/sync")
+	if err != nil {
+		return nil, err
+	}
+
+	// High-water mark: latest change seen, or the requested time when nothing changed
+	cursor := *req.ModifiedSince
+	for _, role := range roles {
+		if role.UpdatedAt.After(cursor) {
+			cursor = role.UpdatedAt
+		}
+		if role.DeletedAt != nil && role.DeletedAt.After(cursor) {
+			cursor = *role.DeletedAt
+		}
+	}
+	if roles == nil {
+		roles = []*model.UserRole{}
+	}
+
+	return &model.SyncUserRolesResp{Data: roles, Cursor: cursor, Truncated: truncated}, nil
+}
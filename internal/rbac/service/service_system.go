@@ -10,7 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func (s *Service) AssignSystemOwner(ctx context.Context, callerID string, req model.AssignSystemOwnerReq) error {
+func (s *Service) AssignSystemOwner(ctx context.Context, callerID string, req model.AssignSystemOwnerReq) (*model.UserRole, error) {
 	// Permission check handled by RBAC middleware
 
 	newRole := &model.UserRole{
@@ -23,88 +23,122 @@ func (s *Service) AssignSystemOwner(ctx context.Context, callerID string, req mo
 		UpdatedBy: callerID,
 	}
 
-	err := s.Repo.CreateUserRole(ctx, newRole)
-	if err != nil {
-		if errors.Is(err, repository.ErrDuplicate) {
-			return ErrConflict
-		}
-		return err
-	}
-
-	log.Printf("Audit: System Owner Assigned. Caller=%s, Target=%s, Namespace=%s", callerID, req.UserID, req.Namespace)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Insert and history in one transaction
+	history := &model.UserRoleHistory{
 		Operation: "assign_owner",
 		CallerID:  callerID,
 		Scope:     model.ScopeSystem,
 		Namespace: req.Namespace,
 		UserID:    req.UserID,
+	}
+	err := s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.CreateUserRole(ctx, newRole)
 	})
+	if err != nil {
+		// With AllowMultipleOwners there is no owner unique index, so a duplicate means the user
+		// already holds a role in the namespace
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
 
-	return nil
+	log.Printf("Audit: System Owner Assigned. Caller=%s, Target=%s, Namespace=%s", callerID, req.UserID, req.Namespace)
+
+	return newRole, nil
 }
 
-func (s *Service) TransferSystemOwner(ctx context.Context, callerID string, req model.TransferSystemOwnerReq) error {
+func (s *Service) TransferSystemOwner(ctx context.Context, callerID string, req model.TransferSystemOwnerReq) (*model.UserRole, error) {
 	// Cannot transfer to self
 	if req.UserID == callerID {
-		return ErrBadRequest
+		return nil, ErrBadRequest
 	}
 
 	// Permission check handled by RBAC middleware
 
-	// Validate ownership specifics
-	currentOwner, err := s.Repo.GetSystemOwner(ctx, req.Namespace)
+	oldOwnerID, err := s.systemOwnerToDemote(ctx, callerID, req.Namespace)
 	if err != nil {
-		return err
-	}
-	if currentOwner == nil {
-		return errors.New("system not found or has no owner")
+		return nil, err
 	}
-
-	// Perform Transfer (Transaction)
-	err = s.Repo.TransferSystemOwner(ctx, req.Namespace, callerID, req.UserID, callerID)
-	if err != nil {
-		return err
+	if req.UserID == oldOwnerID {
+		return nil, ErrBadRequest
 	}
 
-	log.Printf("Audit: System Owner Transferred. Caller=%s, NewOwner=%s, OldOwner=%s, Namespace=%s", callerID, req.UserID, callerID, req.Namespace)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Perform Transfer (demote, promote and history in one transaction)
+	history := &model.UserRoleHistory{
 		Operation:  "transfer_owner",
 		CallerID:   callerID,
 		Scope:      model.ScopeSystem,
 		Namespace:  req.Namespace,
+		UserID:     oldOwnerID,
 		NewOwnerID: req.UserID,
+	}
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		return s.Repo.TransferSystemOwner(ctx, req.Namespace, oldOwnerID, req.UserID, callerID)
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	log.Printf("Audit: System Owner Transferred. Caller=%s, NewOwner=%s, OldOwner=%s, Namespace=%s", callerID, req.UserID, oldOwnerID, req.Namespace)
+
+	// The owner role as the transfer promoted it
+	newOwner := &model.UserRole{
+		UserID:    req.UserID,
+		Role:      model.RoleSystemOwner,
+		Scope:     model.ScopeSystem,
+		Namespace: req.Namespace,
+		UserType:  model.UserTypeMember,
+		UpdatedBy: callerID,
+	}
+
+	return newOwner, nil
+}
+
+// systemOwnerToDemote picks the owner a transfer demotes, as resourceOwnerToDemote does: an owner
+// caller hands over their own ownership (other owners keep theirs with MultipleOwners); anyone else
+// allowed to transfer (e.g. a superadmin) replaces the current owner. ErrResourceNotFound when the
+// namespace has no owner.
+func (s *Service) systemOwnerToDemote(ctx context.Context, callerID, namespace string) (string, error) {
+	currentOwner, err := s.Repo.GetSystemOwner(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+	if currentOwner == nil {
+		return "", ErrResourceNotFound
+	}
+	if currentOwner.UserID == callerID {
+		return callerID, nil
+	}
+
+	// With several owners GetSystemOwner returns any of them; the caller may be another one
+	isOwner, err := s.Repo.HasSystemRole(ctx, namespace, callerID, model.RoleSystemOwner)
+	if err != nil {
+		return "", err
+	}
+	if isOwner {
+		return callerID, nil
+	}
+	return currentOwner.UserID, nil
 }
 
-func (s *Service) AssignSystemUserRole(ctx context.Context, callerID string, req model.AssignSystemUserRoleReq) error {
+func (s *Service) AssignSystemUserRole(ctx context.Context, callerID string, req model.AssignSystemUserRoleReq) (*model.UserRole, error) {
 	if req.Role == model.RoleSystemOwner {
-		return ErrForbidden
+		return nil, ErrForbidden
 	}
 	// Check if role being assigned is valid
-	if req.Role != "admin" && req.Role != "viewer" && req.Role != "dev_user" && req.Role != "moderator" {
-		return ErrBadRequest
+	if req.Role != "admin" && req.Role != "viewer" && req.Role != "dev_user" && req.Role != "moderator" && req.Role != "auditor" {
+		return nil, ErrBadRequest
 	}
 
 	// Permission check handled by RBAC middleware
 
-	currentOwner, err := s.Repo.GetSystemOwner(ctx, req.Namespace)
+	isOwner, last, err := s.systemOwnership(ctx, req.Namespace, req.UserID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if currentOwner != nil && currentOwner.UserID == req.UserID {
-		count, err := s.Repo.CountSystemOwners(ctx, req.Namespace)
-		if err != nil {
-			return err
-		}
-		if count <= 1 {
-			return ErrForbidden
-		}
+	if last {
+		return nil, ErrForbidden
 	}
 
 	role := &model.UserRole{
@@ -113,20 +147,17 @@ func (s *Service) AssignSystemUserRole(ctx context.Context, callerID string, req
 		Scope:     model.ScopeSystem,
 		Namespace: req.Namespace,
 		UserType:  req.UserType,
+		ExpiresAt: req.ExpiresAt,
+		Reason:    req.Reason,
 		CreatedBy: callerID,
 		UpdatedBy: callerID,
 	}
 	if role.UserType == "" {
 		role.UserType = model.UserTypeMember
 	}
-	if err := s.Repo.UpsertUserRole(ctx, role); err != nil {
-		return err
-	}
 
-	log.Printf("Audit: System User Role Assigned. Caller=%s, Target=%s, Role=%s, Namespace=%s", callerID, req.UserID, req.Role, req.Namespace)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Upsert and history in one transaction (a reassign racing a delete is ordered in history)
+	history := &model.UserRoleHistory{
 		Operation: "assign_user_role",
 		CallerID:  callerID,
 		Scope:     model.ScopeSystem,
@@ -134,24 +165,51 @@ func (s *Service) AssignSystemUserRole(ctx context.Context, callerID string, req
 		UserID:    req.UserID,
 		UserType:  req.UserType,
 		Role:      req.Role,
+		ExpiresAt: req.ExpiresAt,
+		Reason:    req.Reason,
+	}
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		// Downgrading a co-owner replaces their owner role, which the upsert leaves alone.
+		// Owner roles belong to members, so another user type gets a role of its own.
+		if isOwner && role.UserType == model.UserTypeMember {
+			return s.Repo.DemoteSystemOwner(ctx, role)
+		}
+		return s.Repo.UpsertUserRole(ctx, role)
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	log.Printf("Audit: System User Role Assigned. Caller=%s, Target=%s, Role=%s, Namespace=%s", callerID, req.UserID, req.Role, req.Namespace)
+
+	if req.Notify {
+		s.notifyGrants(ctx, role)
+	}
+
+	return role, nil
 }
 
 func (s *Service) AssignSystemUserRoles(ctx context.Context, callerID string, req model.AssignSystemUserRolesReq) (*model.BatchUpsertResult, error) {
 	// Permission check handled by RBAC middleware
 
-	// Build roles slice for bulk upsert
+	userType := req.UserType
+	if userType == "" {
+		userType = model.UserTypeMember
+	}
+
+	// Build roles slice for bulk upsert; a pair whose role cannot be granted fails on its own
 	var roles []*model.UserRole
-	for _, userID := range req.UserIDs {
-		userType := req.UserType
-		if userType == "" {
-			userType = model.UserTypeMember
+	var accepted []model.RoleAssignment
+	var rejected []model.FailedUserInfo
+	for _, a := range req.RoleAssignments() {
+		if reason := req.RoleError(a.Role); reason != "" {
+			rejected = append(rejected, model.FailedUserInfo{UserID: a.UserID, Reason: reason})
+			continue
 		}
+		accepted = append(accepted, a)
 		role := &model.UserRole{
-			UserID:    userID,
-			Role:      req.Role,
+			UserID:    a.UserID,
+			Role:      a.Role,
 			Scope:     model.ScopeSystem,
 			Namespace: req.Namespace,
 			UserType:  userType,
@@ -161,64 +219,103 @@ func (s *Service) AssignSystemUserRoles(ctx context.Context, callerID string, re
 		roles = append(roles, role)
 	}
 
-	result, err := s.Repo.BulkUpsertUserRoles(ctx, roles)
+	result, err := s.bulkUpsertWithRejected(ctx, roles, rejected)
 	if err != nil {
 		return nil, err
 	}
+	if len(req.Assignments) > 0 {
+		result.Assigned = assignedPairs(roles, result)
+	}
 
 	log.Printf("Audit: System User Roles Assigned (Batch). Caller=%s, Success=%d, Failed=%d, Role=%s, Namespace=%s",
-		callerID, result.SuccessCount, result.FailedCount, req.Role, req.Namespace)
+		callerID, result.SuccessCount, result.FailedCount, batchRoleLabel(req.Role, accepted), req.Namespace)
 
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
-		Operation: "assign_user_roles_batch",
-		CallerID:  callerID,
-		Scope:     model.ScopeSystem,
-		Namespace: req.Namespace,
-		UserIDs:   req.UserIDs,
-		UserType:  req.UserType,
-		Role:      req.Role,
-	})
+	if req.Notify {
+		s.notifyGrants(ctx, succeededRoles(roles, result)...)
+	}
+
+	// Record history, one entry per granted role
+	roleNames, usersByRole := model.GroupAssignmentsByRole(accepted)
+	for _, roleName := range roleNames {
+		s.recordHistory(ctx, &model.UserRoleHistory{
+			Operation: "assign_user_roles_batch",
+			CallerID:  callerID,
+			Scope:     model.ScopeSystem,
+			Namespace: req.Namespace,
+			UserIDs:   usersByRole[roleName],
+			UserType:  req.UserType,
+			Role:      roleName,
+		})
+	}
 
 	return result, nil
 }
 
-func (s *Service) DeleteSystemUserRole(ctx context.Context, callerID string, req model.DeleteSystemUserRoleReq) error {
+func (s *Service) DeleteSystemUserRole(ctx context.Context, callerID string, req model.DeleteSystemUserRoleReq) (int64, error) {
 	// Permission check handled by RBAC middleware
 
-	currentOwner, err := s.Repo.GetSystemOwner(ctx, req.Namespace)
+	isOwner, last, err := s.systemOwnership(ctx, req.Namespace, req.UserID)
 	if err != nil {
-		return err
-	}
-	if currentOwner != nil && currentOwner.UserID == req.UserID {
-		count, err := s.Repo.CountSystemOwners(ctx, req.Namespace)
-		if err != nil {
-			return err
-		}
-		if count <= 1 {
-			return ErrForbidden
-		}
+		return 0, err
 	}
-
-	err = s.Repo.DeleteUserRole(ctx, req.Namespace, req.UserID, model.ScopeSystem, "", "", "", callerID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil
-		}
-		return err
+	if last {
+		return 0, ErrForbidden
 	}
 
-	log.Printf("Audit: System User Role Deleted. Caller=%s, Target=%s, Namespace=%s", callerID, req.UserID, req.Namespace)
-
-	// Record history
-	s.recordHistory(&model.UserRoleHistory{
+	// Soft delete and history in one transaction; nothing is recorded if the role is already gone
+	history := &model.UserRoleHistory{
 		Operation: "delete_user_role",
 		CallerID:  callerID,
 		Scope:     model.ScopeSystem,
 		Namespace: req.Namespace,
 		UserID:    req.UserID,
 		UserType:  req.UserType,
+	}
+	var deleted int64
+	err = s.writeWithHistory(ctx, history, func(ctx context.Context) error {
+		var err error
+		if isOwner {
+			// Removing a co-owner removes their owner role, which DeleteUserRole leaves alone
+			deleted, err = s.Repo.DeleteSystemOwner(ctx, req.Namespace, req.UserID, callerID)
+			return err
+		}
+		deleted, err = s.Repo.DeleteUserRole(ctx, req.Namespace, req.UserID, model.ScopeSystem, "", "", "", callerID)
+		return err
 	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	log.Printf("Audit: System User Role Deleted. Caller=%s, Target=%s, Namespace=%s, Deleted=%d", callerID, req.UserID, req.Namespace, deleted)
 
-	return nil
+	return deleted, nil
+}
+
+// systemOwnership reports whether userID owns namespace and, if so, whether they are its only owner,
+// whom removing or downgrading would leave the namespace without an owner
+func (s *Service) systemOwnership(ctx context.Context, namespace, userID string) (isOwner, last bool, err error) {
+	if s.AllowMultipleOwners {
+		// GetSystemOwner returns only one of several co-owners
+		isOwner, err = s.Repo.HasSystemRole(ctx, namespace, userID, model.RoleSystemOwner)
+		if err != nil {
+			return false, false, err
+		}
+	} else {
+		currentOwner, err := s.Repo.GetSystemOwner(ctx, namespace)
+		if err != nil {
+			return false, false, err
+		}
+		isOwner = currentOwner != nil && currentOwner.UserID == userID
+	}
+	if !isOwner {
+		return false, false, nil
+	}
+	count, err := s.Repo.CountSystemOwners(ctx, namespace)
+	if err != nil {
+		return false, false, err
+	}
+	return true, count <= 1, nil
 }
@@ -0,0 +1,16 @@
+package service
+
+import "context"
+
+type superadminBypassKey struct{}
+
+// WithSuperadminBypass marks ctx as a superadmin operation that skipped the permission check
+func WithSuperadminBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, superadminBypassKey{}, true)
+}
+
+// isSuperadminBypass reports whether ctx was marked by WithSuperadminBypass
+func isSuperadminBypass(ctx context.Context) bool {
+	bypass, _ := ctx.Value(superadminBypassKey{}).(bool)
+	return bypass
+}
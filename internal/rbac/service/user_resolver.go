@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"rbac7/internal/rbac/model"
+)
+
+// UserResolver maps an external identifier or email of an assignment request to its canonical user_id.
+// It returns an empty id when no user matches.
+type UserResolver interface {
+	ResolveUserID(ctx context.Context, externalID, email string) (string, error)
+}
+
+// ResolveUserID returns the user_id an assignment is stored under. An explicit userID wins; otherwise
+// the external_id or email is resolved by s.UserResolver.
+func (s *Service) ResolveUserID(ctx context.Context, userID, externalID, email string) (string, error) {
+	if userID != "" {
+		return userID, nil
+	}
+	if s.UserResolver == nil {
+		return "", fmt.Errorf("%w: user_id is required", ErrBadRequest)
+	}
+	resolved, err := s.UserResolver.ResolveUserID(ctx, externalID, email)
+	if err != nil {
+		log.Printf("Error: User resolution failed. ExternalID=%s, err=%v", externalID, err)
+		return "", err
+	}
+	if resolved == "" {
+		return "", ErrUserNotResolved
+	}
+	return resolved, nil
+}
+
+// ResolveAssignments resolves the user_id of each batch assignment in place, as ResolveUserID does for
+// a single assignment. The first entry that cannot be resolved fails the whole batch.
+func (s *Service) ResolveAssignments(ctx context.Context, assignments []model.RoleAssignment) error {
+	for i := range assignments {
+		userID, err := s.ResolveUserID(ctx, assignments[i].UserID, assignments[i].ExternalID, assignments[i].Email)
+		if err != nil {
+			return err
+		}
+		assignments[i].UserID = userID
+		assignments[i].ExternalID, assignments[i].Email = "", ""
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+//go:build !rbactesthooks
+
+package testhook
+
+// buildTagEnabled is false in regular builds, so only test binaries can install overrides
+const buildTagEnabled = false
@@ -0,0 +1,6 @@
+//go:build rbactesthooks
+
+package testhook
+
+// buildTagEnabled allows overrides outside test binaries, for integration tooling built with -tags rbactesthooks
+const buildTagEnabled = true
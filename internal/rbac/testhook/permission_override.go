@@ -0,0 +1,86 @@
+// Package testhook lets tests and integration tooling drive handlers as a given role without seeding Mongo.
+//
+// The hook only works in test binaries or in builds tagged rbactesthooks; Wrap panics anywhere else,
+// so a production build of cmd/server can never run with overridden role checks.
+package testhook
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"rbac7/internal/rbac/repository"
+)
+
+// PermissionResolver answers role checks in place of the repository.
+// decided=false falls through to the wrapped repository.
+type PermissionResolver interface {
+	ResolveSystemRole(ctx context.Context, userID, namespace string, roles []string) (allowed, decided bool)
+	ResolveResourceRole(ctx context.Context, userID, resourceID, resourceType string, roles []string) (allowed, decided bool)
+}
+
+// UserRoles is a PermissionResolver granting each listed user the given roles in every namespace and on
+// every resource. Users not listed fall through to the repository.
+type UserRoles map[string][]string
+
+func (u UserRoles) ResolveSystemRole(_ context.Context, userID, _ string, roles []string) (bool, bool) {
+	return u.resolve(userID, roles)
+}
+
+func (u UserRoles) ResolveResourceRole(_ context.Context, userID, _, _ string, roles []string) (bool, bool) {
+	return u.resolve(userID, roles)
+}
+
+func (u UserRoles) resolve(userID string, roles []string) (bool, bool) {
+	held, ok := u[userID]
+	if !ok {
+		return false, false
+	}
+	for _, role := range held {
+		if slices.Contains(roles, role) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// Enabled reports whether overrides may be installed: in test binaries or rbactesthooks builds only
+func Enabled() bool {
+	return buildTagEnabled || testing.Testing()
+}
+
+// Wrap returns repo with its role checks answered by resolver first. It panics when the hook is not Enabled.
+func Wrap(repo repository.RBACRepository, resolver PermissionResolver) repository.RBACRepository {
+	if !Enabled() {
+		panic("testhook: permission overrides require a test binary or the rbactesthooks build tag")
+	}
+	return &overrideRepository{RBACRepository: repo, resolver: resolver}
+}
+
+// overrideRepository consults the resolver before the embedded repository for every role check
+type overrideRepository struct {
+	repository.RBACRepository
+	resolver PermissionResolver
+}
+
+func (r *overrideRepository) HasSystemRole(ctx context.Context, namespace, userID, role string) (bool, error) {
+	return r.HasAnySystemRole(ctx, namespace, userID, []string{role})
+}
+
+func (r *overrideRepository) HasAnySystemRole(ctx context.Context, namespace, userID string, roles []string) (bool, error) {
+	if allowed, decided := r.resolver.ResolveSystemRole(ctx, userID, namespace, roles); decided {
+		return allowed, nil
+	}
+	return r.RBACRepository.HasAnySystemRole(ctx, namespace, userID, roles)
+}
+
+func (r *overrideRepository) HasResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType, role string) (bool, error) {
+	return r.HasAnyResourceRole(ctx, namespace, userID, resourceID, resourceType, []string{role})
+}
+
+func (r *overrideRepository) HasAnyResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType string, roles []string) (bool, error) {
+	if allowed, decided := r.resolver.ResolveResourceRole(ctx, userID, resourceID, resourceType, roles); decided {
+		return allowed, nil
+	}
+	return r.RBACRepository.HasAnyResourceRole(ctx, namespace, userID, resourceID, resourceType, roles)
+}
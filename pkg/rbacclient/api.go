@@ -0,0 +1,404 @@
+package rbacclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AssignSystemOwner makes req.UserID the owner of a namespace that has none
+func (c *Client) AssignSystemOwner(ctx context.Context, callerID string, req AssignSystemOwnerRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/user_roles/owner", callerID: callerID, body: req}, nil)
+}
+
+// TransferSystemOwner hands namespace ownership from the caller to req.UserID
+func (c *Client) TransferSystemOwner(ctx context.Context, callerID string, req TransferSystemOwnerRequest) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/user_roles/owner", callerID: callerID, body: req}, nil)
+}
+
+// AssignSystemUserRole grants a system role in a namespace
+func (c *Client) AssignSystemUserRole(ctx context.Context, callerID string, req AssignSystemUserRoleRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/user_roles", callerID: callerID, body: req}, nil)
+}
+
+// AssignSystemUserRoles grants a system role to several users at once
+func (c *Client) AssignSystemUserRoles(ctx context.Context, callerID string, req AssignSystemUserRolesRequest) (*BatchUpsertResult, error) {
+	var result BatchUpsertResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/user_roles/batch", callerID: callerID, body: req}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSystemUserRole removes a user's system role in a namespace
+func (c *Client) DeleteSystemUserRole(ctx context.Context, callerID string, req DeleteSystemUserRoleRequest) error {
+	query := url.Values{}
+	setQuery(query, "namespace", req.Namespace)
+	setQuery(query, "user_id", req.UserID)
+	setQuery(query, "user_type", req.UserType)
+	return c.do(ctx, request{method: http.MethodDelete, path: "/user_roles", callerID: callerID, query: query}, nil)
+}
+
+// AssignResourceOwner makes the caller owner of a resource that has none
+func (c *Client) AssignResourceOwner(ctx context.Context, callerID string, req AssignResourceOwnerRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/user_roles/resources/owner", callerID: callerID, body: req}, nil)
+}
+
+// TransferResourceOwner hands resource ownership from the caller to req.UserID
+func (c *Client) TransferResourceOwner(ctx context.Context, callerID string, req TransferResourceOwnerRequest) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/user_roles/resources/owner", callerID: callerID, body: req}, nil)
+}
+
+// AssignResourceUserRole grants a resource role
+func (c *Client) AssignResourceUserRole(ctx context.Context, callerID string, req AssignResourceUserRoleRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/user_roles/resources", callerID: callerID, body: req}, nil)
+}
+
+// AssignResourceUserRoles grants a resource role to several users at once
+func (c *Client) AssignResourceUserRoles(ctx context.Context, callerID string, req AssignResourceUserRolesRequest) (*BatchUpsertResult, error) {
+	var result BatchUpsertResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/user_roles/resources/batch", callerID: callerID, body: req}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteResourceUserRole removes a user's resource role
+func (c *Client) DeleteResourceUserRole(ctx context.Context, callerID string, req DeleteResourceUserRoleRequest) error {
+	query := url.Values{}
+	setQuery(query, "user_id", req.UserID)
+	setQuery(query, "resource_id", req.ResourceID)
+	setQuery(query, "resource_type", req.ResourceType)
+	setQuery(query, "parent_resource_id", req.ParentResourceID)
+	setQuery(query, "namespace", req.Namespace)
+	setQuery(query, "user_type", req.UserType)
+	return c.do(ctx, request{method: http.MethodDelete, path: "/user_roles/resources", callerID: callerID, query: query}, nil)
+}
+
+// RestoreUserRole undoes the soft delete of a user's system or resource role, keeping its original
+// created_at/created_by. The error's StatusCode is 404 when there is no soft-deleted role to restore.
+func (c *Client) RestoreUserRole(ctx context.Context, callerID string, req RestoreUserRoleRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/user_roles/restore", callerID: callerID, body: req}, nil)
+}
+
+// GetUserRolesMe lists the caller's own roles
+func (c *Client) GetUserRolesMe(ctx context.Context, callerID string, req GetUserRolesMeRequest) ([]UserRole, error) {
+	query := url.Values{}
+	setQuery(query, "scope", req.Scope)
+	setQuery(query, "resource_type", req.ResourceType)
+
+	var roles []UserRole
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/user_roles/me", callerID: callerID, query: query, retryable: true}, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetUserRoles lists role assignments matching the filter
+func (c *Client) GetUserRoles(ctx context.Context, callerID string, req GetUserRolesRequest) ([]UserRole, error) {
+	query := url.Values{}
+	setQuery(query, "user_id", req.UserID)
+	setQuery(query, "namespace", req.Namespace)
+	setQuery(query, "role", req.Role)
+	setQuery(query, "scope", req.Scope)
+	setQuery(query, "resource_id", req.ResourceID)
+	setQuery(query, "resource_type", req.ResourceType)
+	setQuery(query, "parent_resource_id", req.ParentResourceID)
+	if req.ExpandPermissions {
+		query.Set("expand", "permissions")
+	}
+	if req.IncludeAudit {
+		query.Set("include_audit", "true")
+	}
+	if req.IncludeInherited {
+		query.Set("include_inherited", "true")
+	}
+
+	var roles []UserRole
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/user_roles", callerID: callerID, query: query, retryable: true}, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// CheckMembers returns which of req.UserIDs are already active members, in request order
+func (c *Client) CheckMembers(ctx context.Context, callerID string, req CheckMembersRequest) ([]string, error) {
+	var resp struct {
+		Members []string `json:"members"`
+	}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/user_roles/members/check", callerID: callerID, body: req, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Members, nil
+}
+
+// SyncUserRoles returns roles changed or soft deleted since req.ModifiedSince (incremental sync)
+func (c *Client) SyncUserRoles(ctx context.Context, callerID string, req SyncUserRolesRequest) (*SyncUserRolesResponse, error) {
+	query := url.Values{}
+	query.Set("modified_since", req.ModifiedSince.Format(time.RFC3339Nano))
+	setQuery(query, "scope", req.Scope)
+	setQuery(query, "namespace", req.Namespace)
+	setQuery(query, "resource_type", req.ResourceType)
+
+	var resp SyncUserRolesResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/user_roles/sync", callerID: callerID, query: query, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUserRoleHistory returns a page of the role audit log
+func (c *Client) GetUserRoleHistory(ctx context.Context, callerID string, req GetUserRoleHistoryRequest) (*GetUserRoleHistoryResponse, error) {
+	query := url.Values{}
+	setQuery(query, "scope", req.Scope)
+	setQuery(query, "namespace", req.Namespace)
+	setQuery(query, "resource_id", req.ResourceID)
+	setQuery(query, "resource_type", req.ResourceType)
+	setQuery(query, "parent_resource_id", req.ParentResourceID)
+	setQuery(query, "target_user_id", req.TargetUserID)
+	if req.StartTime != nil {
+		query.Set("start_time", req.StartTime.Format(time.RFC3339))
+	}
+	if req.EndTime != nil {
+		query.Set("end_time", req.EndTime.Format(time.RFC3339))
+	}
+	if req.Page > 0 {
+		query.Set("page", strconv.Itoa(req.Page))
+	}
+	if req.Size > 0 {
+		query.Set("size", strconv.Itoa(req.Size))
+	}
+
+	var resp GetUserRoleHistoryResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/user_roles/logs", callerID: callerID, query: query, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CheckPermission reports whether the caller holds the permission in the given scope
+func (c *Client) CheckPermission(ctx context.Context, callerID string, req CheckPermissionRequest) (bool, error) {
+	var resp struct {
+		Allowed bool `json:"allowed"`
+	}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/permissions/check", callerID: callerID, body: req, retryable: true}, &resp); err != nil {
+		return false, err
+	}
+	return resp.Allowed, nil
+}
+
+// CheckPermissionsBatch runs up to 500 permission checks of the caller in one request and returns
+// whether each is allowed, keyed by PermissionCheck.ID
+func (c *Client) CheckPermissionsBatch(ctx context.Context, callerID string, checks []PermissionCheck) (map[string]bool, error) {
+	body := struct {
+		Checks []PermissionCheck `json:"checks"`
+	}{Checks: checks}
+	var resp struct {
+		Results map[string]bool `json:"results"`
+	}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/permissions/check/batch", callerID: callerID, body: body, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// GetPermissionRoles lists the roles granting permission; scope ("system" or "resource") may be empty for both
+func (c *Client) GetPermissionRoles(ctx context.Context, callerID, permission, scope string) (*PermissionRoles, error) {
+	var result PermissionRoles
+	query := url.Values{}
+	setQuery(query, "scope", scope)
+	path := "/permissions/" + url.PathEscape(permission) + "/roles"
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, callerID: callerID, query: query, retryable: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteResource soft deletes every role on a resource (and the given child resources)
+func (c *Client) DeleteResource(ctx context.Context, callerID string, req DeleteResourceRequest) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/resources/delete", callerID: callerID, body: req}, nil)
+}
+
+// GetDashboardResource returns the dashboard's roles and the widgets the caller may access
+func (c *Client) GetDashboardResource(ctx context.Context, callerID string, req GetDashboardResourceRequest) (*GetDashboardResourceResponse, error) {
+	var resp GetDashboardResourceResponse
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/resources/dashboards", callerID: callerID, body: req, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetAccessibleResourceSummary counts, per resource type, the resources the caller holds any role on
+func (c *Client) GetAccessibleResourceSummary(ctx context.Context, callerID string) (*AccessibleResourceSummary, error) {
+	var resp AccessibleResourceSummary
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/resources/accessible/summary", callerID: callerID, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetCapableUsers lists the members whose role on the resource grants the permission
+func (c *Client) GetCapableUsers(ctx context.Context, callerID string, req GetCapableUsersRequest) (*CapableUsers, error) {
+	var result CapableUsers
+	query := url.Values{}
+	setQuery(query, "resource_id", req.ResourceID)
+	setQuery(query, "resource_type", req.ResourceType)
+	setQuery(query, "permission", req.Permission)
+	setQuery(query, "parent_resource_id", req.ParentResourceID)
+	setQuery(query, "namespace", req.Namespace)
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/resources/capable_users", callerID: callerID, query: query, retryable: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMyResourceRoles returns the caller's role on each resource; resources without a role map to nil
+func (c *Client) GetMyResourceRoles(ctx context.Context, callerID, resourceType string, resourceIDs []string) (map[string]*string, error) {
+	var resp struct {
+		Roles map[string]*string `json:"roles"`
+	}
+	body := map[string]interface{}{"resource_type": resourceType, "resource_ids": resourceIDs}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/user_roles/resources/my_roles", callerID: callerID, body: body, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+// GetResourceOwner returns the resource's owner role, or nil when it has none
+func (c *Client) GetResourceOwner(ctx context.Context, callerID, resourceType, resourceID string) (*UserRole, error) {
+	var owner *UserRole
+	query := url.Values{}
+	setQuery(query, "resource_id", resourceID)
+	setQuery(query, "resource_type", resourceType)
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/resources/owner", callerID: callerID, query: query, retryable: true}, &owner); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+// GetResourceOwners returns the owner user_id of each resource; ownerless resources map to nil (moderator only)
+func (c *Client) GetResourceOwners(ctx context.Context, callerID, resourceType string, resourceIDs []string) (map[string]*string, error) {
+	var resp struct {
+		Owners map[string]*string `json:"owners"`
+	}
+	body := map[string]interface{}{"resource_type": resourceType, "resource_ids": resourceIDs}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/resources/owners", callerID: callerID, body: body, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Owners, nil
+}
+
+// EraseUser deletes or anonymizes all of a user's role data (GDPR erasure, moderator only)
+func (c *Client) EraseUser(ctx context.Context, callerID, userID string) (*EraseUserResult, error) {
+	var result EraseUserResult
+	path := "/admin/users/" + url.PathEscape(userID) + "/erase"
+	if err := c.do(ctx, request{method: http.MethodPost, path: path, callerID: callerID}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReassignOwner transfers every resource oldOwnerID owns to newOwnerID in one transaction (offboarding, moderator only)
+func (c *Client) ReassignOwner(ctx context.Context, callerID, oldOwnerID, newOwnerID string) (*ReassignOwnerResult, error) {
+	var result ReassignOwnerResult
+	body := map[string]string{"old_owner_id": oldOwnerID, "new_owner_id": newOwnerID}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/user_roles/reassign_owner", callerID: callerID, body: body}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PurgeUser hard deletes all of a user's role documents, leaving history untouched (moderator only)
+func (c *Client) PurgeUser(ctx context.Context, callerID, userID string) (*PurgeUserResult, error) {
+	var result PurgeUserResult
+	path := "/users/" + url.PathEscape(userID) + "/purge"
+	if err := c.do(ctx, request{method: http.MethodDelete, path: path, callerID: callerID}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAccessSnapshot lists a user's active roles with the permissions each confers (support diagnostics, moderator only)
+func (c *Client) GetAccessSnapshot(ctx context.Context, callerID, userID string) (*AccessSnapshot, error) {
+	var result AccessSnapshot
+	path := "/admin/users/" + url.PathEscape(userID) + "/access_snapshot"
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, callerID: callerID, retryable: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReloadPolicy makes the server re-read its policy files (moderator only)
+func (c *Client) ReloadPolicy(ctx context.Context, callerID string) (*PolicySummary, error) {
+	var result PolicySummary
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/policy/reload", callerID: callerID}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetNamespaceResourceRoles returns the resource roles assignable in a namespace
+func (c *Client) GetNamespaceResourceRoles(ctx context.Context, callerID, namespace string) (*NamespaceResourceRoles, error) {
+	var result NamespaceResourceRoles
+	path := "/namespaces/" + url.PathEscape(namespace) + "/resource_roles"
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, callerID: callerID, retryable: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetNamespaceResourceRoles overrides the resource roles assignable in a namespace
+func (c *Client) SetNamespaceResourceRoles(ctx context.Context, callerID, namespace string, roles []string) error {
+	path := "/namespaces/" + url.PathEscape(namespace) + "/resource_roles"
+	body := map[string][]string{"roles": roles}
+	return c.do(ctx, request{method: http.MethodPut, path: path, callerID: callerID, body: body}, nil)
+}
+
+// ResetNamespaceResourceRoles removes a namespace's override so the global default applies
+func (c *Client) ResetNamespaceResourceRoles(ctx context.Context, callerID, namespace string) error {
+	path := "/namespaces/" + url.PathEscape(namespace) + "/resource_roles"
+	return c.do(ctx, request{method: http.MethodDelete, path: path, callerID: callerID}, nil)
+}
+
+// RenameNamespace moves a namespace's roles and history to a new name (moderator only)
+func (c *Client) RenameNamespace(ctx context.Context, callerID, from, to string) (*RenameNamespaceResult, error) {
+	var result RenameNamespaceResult
+	body := map[string]string{"from": from, "to": to}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/namespaces/rename", callerID: callerID, body: body}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SnapshotNamespace exports a namespace's active roles, and its history with includeHistory (moderator only)
+func (c *Client) SnapshotNamespace(ctx context.Context, callerID, namespace string, includeHistory bool) (*NamespaceSnapshot, error) {
+	var snapshot NamespaceSnapshot
+	query := url.Values{}
+	if includeHistory {
+		query.Set("include_history", "true")
+	}
+	path := "/namespaces/" + url.PathEscape(namespace) + "/snapshot"
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, callerID: callerID, query: query, retryable: true}, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// RestoreNamespace applies a snapshot to namespace, which may differ from the snapshot's own (moderator only)
+func (c *Client) RestoreNamespace(ctx context.Context, callerID, namespace string, req RestoreNamespaceRequest) (*RestoreNamespaceResult, error) {
+	var result RestoreNamespaceResult
+	path := "/namespaces/" + url.PathEscape(namespace) + "/restore"
+	if err := c.do(ctx, request{method: http.MethodPost, path: path, callerID: callerID, body: req}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// setQuery adds key only when value is non-empty
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
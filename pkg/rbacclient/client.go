@@ -0,0 +1,183 @@
+// Package rbacclient is a typed Go client for the RBAC service HTTP API.
+package rbacclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the RBAC service. Every call acts on behalf of callerID (sent as x-user-id).
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying http.Client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the per-attempt timeout of the underlying http.Client
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithRetry retries read-only calls (lists, permission checks) up to maxRetries times
+// on transport errors and 502/503/504, waiting backoff * attempt between tries.
+// Mutations are never retried because a lost response may hide a successful write.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// New creates a client for the RBAC service at baseURL (e.g. "http://rbac:8080/api/v1")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("rbac: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// StatusCode returns the HTTP status of an *APIError, or 0 for other errors
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// request describes one API call
+type request struct {
+	method    string
+	path      string
+	callerID  string
+	query     url.Values
+	body      interface{}
+	retryable bool
+}
+
+// do sends the request and decodes a 2xx JSON response into out (if non-nil)
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		payload, err = json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("rbac: encode request: %w", err)
+		}
+	}
+
+	attempts := 1
+	if req.retryable {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.retryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		retry, err := c.send(ctx, req, payload, out)
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// send performs a single attempt and reports whether a failure is worth retrying
+func (c *Client) send(ctx context.Context, req request, payload []byte, out interface{}) (bool, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return false, fmt.Errorf("rbac: build request: %w", err)
+	}
+	httpReq.Header.Set("x-user-id", req.callerID)
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("rbac: %s %s: %w", req.method, req.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var envelope errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err == nil {
+			apiErr.Code = envelope.Error.Code
+			apiErr.Message = envelope.Error.Message
+		}
+		retry := resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable ||
+			resp.StatusCode == http.StatusGatewayTimeout
+		return retry, apiErr
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("rbac: decode response: %w", err)
+	}
+	return false, nil
+}
+
+// errorResponse is the service error envelope
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,595 @@
+package rbacclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captured is what the test server saw
+type captured struct {
+	method   string
+	path     string
+	query    map[string]string
+	callerID string
+	body     map[string]interface{}
+}
+
+// newServer answers every request with status/response and records the request
+func newServer(t *testing.T, status int, response string) (*Client, *captured) {
+	t.Helper()
+	got := &captured{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method = r.Method
+		got.path = r.URL.Path
+		got.callerID = r.Header.Get("x-user-id")
+		got.query = map[string]string{}
+		for k, v := range r.URL.Query() {
+			got.query[k] = v[0]
+		}
+		raw, _ := io.ReadAll(r.Body)
+		got.body = nil
+		if len(raw) > 0 {
+			require.NoError(t, json.Unmarshal(raw, &got.body))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL + "/api/v1"), got
+}
+
+func TestMutations(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		call   func(c *Client) error
+		method string
+		path   string
+		body   map[string]interface{}
+		query  map[string]string
+	}{
+		{
+			name: "assign system owner",
+			call: func(c *Client) error {
+				return c.AssignSystemOwner(ctx, "caller", AssignSystemOwnerRequest{UserID: "u1", Namespace: "NS"})
+			},
+			method: http.MethodPost, path: "/api/v1/user_roles/owner",
+			body: map[string]interface{}{"user_id": "u1", "namespace": "NS"},
+		},
+		{
+			name: "transfer system owner",
+			call: func(c *Client) error {
+				return c.TransferSystemOwner(ctx, "caller", TransferSystemOwnerRequest{UserID: "u2", Namespace: "NS"})
+			},
+			method: http.MethodPut, path: "/api/v1/user_roles/owner",
+			body: map[string]interface{}{"user_id": "u2", "namespace": "NS"},
+		},
+		{
+			name: "assign system user role",
+			call: func(c *Client) error {
+				return c.AssignSystemUserRole(ctx, "caller", AssignSystemUserRoleRequest{UserID: "u1", Role: "viewer", Namespace: "NS"})
+			},
+			method: http.MethodPost, path: "/api/v1/user_roles",
+			body: map[string]interface{}{"user_id": "u1", "role": "viewer", "namespace": "NS"},
+		},
+		{
+			name: "delete system user role",
+			call: func(c *Client) error {
+				return c.DeleteSystemUserRole(ctx, "caller", DeleteSystemUserRoleRequest{Namespace: "NS", UserID: "u1"})
+			},
+			method: http.MethodDelete, path: "/api/v1/user_roles",
+			query: map[string]string{"namespace": "NS", "user_id": "u1"},
+		},
+		{
+			name: "assign resource owner",
+			call: func(c *Client) error {
+				return c.AssignResourceOwner(ctx, "caller", AssignResourceOwnerRequest{ResourceID: "d1", ResourceType: "dashboard"})
+			},
+			method: http.MethodPost, path: "/api/v1/user_roles/resources/owner",
+			body: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard"},
+		},
+		{
+			name: "transfer resource owner",
+			call: func(c *Client) error {
+				return c.TransferResourceOwner(ctx, "caller", TransferResourceOwnerRequest{UserID: "u2", ResourceID: "d1", ResourceType: "dashboard"})
+			},
+			method: http.MethodPut, path: "/api/v1/user_roles/resources/owner",
+			body: map[string]interface{}{"user_id": "u2", "resource_id": "d1", "resource_type": "dashboard"},
+		},
+		{
+			name: "assign resource user role",
+			call: func(c *Client) error {
+				return c.AssignResourceUserRole(ctx, "caller", AssignResourceUserRoleRequest{UserID: "u1", Role: "editor", ResourceID: "w1", ResourceType: "dashboard_widget", ParentResourceID: "d1"})
+			},
+			method: http.MethodPost, path: "/api/v1/user_roles/resources",
+			body: map[string]interface{}{"user_id": "u1", "role": "editor", "resource_id": "w1", "resource_type": "dashboard_widget", "parent_resource_id": "d1"},
+		},
+		{
+			name: "delete resource user role",
+			call: func(c *Client) error {
+				return c.DeleteResourceUserRole(ctx, "caller", DeleteResourceUserRoleRequest{UserID: "u1", ResourceID: "d1", ResourceType: "dashboard"})
+			},
+			method: http.MethodDelete, path: "/api/v1/user_roles/resources",
+			query: map[string]string{"user_id": "u1", "resource_id": "d1", "resource_type": "dashboard"},
+		},
+		{
+			name: "delete resource",
+			call: func(c *Client) error {
+				return c.DeleteResource(ctx, "caller", DeleteResourceRequest{ResourceID: "d1", ResourceType: "dashboard", ChildResourceIDs: []string{"w1"}})
+			},
+			method: http.MethodPut, path: "/api/v1/resources/delete",
+			body: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "child_resource_ids": []interface{}{"w1"}},
+		},
+		{
+			name: "set namespace resource roles",
+			call: func(c *Client) error {
+				return c.SetNamespaceResourceRoles(ctx, "caller", "NS", []string{"admin", "viewer"})
+			},
+			method: http.MethodPut, path: "/api/v1/namespaces/NS/resource_roles",
+			body: map[string]interface{}{"roles": []interface{}{"admin", "viewer"}},
+		},
+		{
+			name: "reset namespace resource roles",
+			call: func(c *Client) error {
+				return c.ResetNamespaceResourceRoles(ctx, "caller", "NS")
+			},
+			method: http.MethodDelete, path: "/api/v1/namespaces/NS/resource_roles",
+		},
+		{
+			name: "restore user role",
+			call: func(c *Client) error {
+				return c.RestoreUserRole(ctx, "caller", RestoreUserRoleRequest{Scope: ScopeResource, UserID: "u1", ResourceID: "d1", ResourceType: "dashboard"})
+			},
+			method: http.MethodPost, path: "/api/v1/user_roles/restore",
+			body: map[string]interface{}{"scope": "resource", "user_id": "u1", "resource_id": "d1", "resource_type": "dashboard"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should send "+tt.name+" request", func(t *testing.T) {
+			c, got := newServer(t, http.StatusOK, `{"status":"success"}`)
+
+			require.NoError(t, tt.call(c))
+			assert.Equal(t, tt.method, got.method)
+			assert.Equal(t, tt.path, got.path)
+			assert.Equal(t, "caller", got.callerID)
+			assert.Equal(t, tt.body, got.body)
+			if tt.query != nil {
+				assert.Equal(t, tt.query, got.query)
+			}
+		})
+	}
+}
+
+func TestAssignUserRolesBatch(t *testing.T) {
+	t.Run("should parse system batch result", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"success_count":1,"failed_count":1,"failed_users":[{"user_id":"u2","reason":"conflict"}]}`)
+
+		result, err := c.AssignSystemUserRoles(context.Background(), "caller", AssignSystemUserRolesRequest{UserIDs: []string{"u1", "u2"}, Role: "viewer", Namespace: "NS"})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/user_roles/batch", got.path)
+		assert.Equal(t, []interface{}{"u1", "u2"}, got.body["user_ids"])
+		assert.Equal(t, &BatchUpsertResult{SuccessCount: 1, FailedCount: 1, FailedUsers: []FailedUserInfo{{UserID: "u2", Reason: "conflict"}}}, result)
+	})
+
+	t.Run("should parse resource batch result", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"success_count":2,"failed_count":0}`)
+
+		result, err := c.AssignResourceUserRoles(context.Background(), "caller", AssignResourceUserRolesRequest{UserIDs: []string{"u1", "u2"}, Role: "viewer", ResourceID: "d1", ResourceType: "dashboard"})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/user_roles/resources/batch", got.path)
+		assert.Equal(t, 2, result.SuccessCount)
+	})
+}
+
+func TestGetUserRoles(t *testing.T) {
+	t.Run("should list caller roles", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `[{"user_id":"caller","user_type":"member","role":"owner","scope":"resource","resource_id":"d1","resource_type":"dashboard"}]`)
+
+		roles, err := c.GetUserRolesMe(context.Background(), "caller", GetUserRolesMeRequest{Scope: ScopeResource, ResourceType: "dashboard"})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/user_roles/me", got.path)
+		assert.Equal(t, map[string]string{"scope": "resource", "resource_type": "dashboard"}, got.query)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "owner", roles[0].Role)
+		assert.Equal(t, "d1", roles[0].ResourceID)
+	})
+
+	t.Run("should list roles by filter", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `[{"user_id":"u1","role":"viewer","scope":"system","namespace":"NS"}]`)
+
+		roles, err := c.GetUserRoles(context.Background(), "caller", GetUserRolesRequest{Scope: ScopeSystem, Namespace: "NS"})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/user_roles", got.path)
+		assert.Equal(t, map[string]string{"scope": "system", "namespace": "NS"}, got.query)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "NS", roles[0].Namespace)
+	})
+
+	t.Run("should request expanded permissions", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `[{"user_id":"u1","role":"viewer","scope":"system","namespace":"NS","permissions":["platform.system.read"]}]`)
+
+		roles, err := c.GetUserRoles(context.Background(), "caller", GetUserRolesRequest{Scope: ScopeSystem, Namespace: "NS", ExpandPermissions: true})
+		require.NoError(t, err)
+		assert.Equal(t, "permissions", got.query["expand"])
+		require.Len(t, roles, 1)
+		assert.Equal(t, []string{"platform.system.read"}, roles[0].Permissions)
+	})
+
+	t.Run("should request audit fields", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `[{"user_id":"u1","role":"viewer","scope":"system","namespace":"NS","created_at":"2026-03-01T09:00:00Z","created_by":"owner_1"}]`)
+
+		roles, err := c.GetUserRoles(context.Background(), "caller", GetUserRolesRequest{Scope: ScopeSystem, Namespace: "NS", IncludeAudit: true})
+		require.NoError(t, err)
+		assert.Equal(t, "true", got.query["include_audit"])
+		require.Len(t, roles, 1)
+		assert.Equal(t, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), roles[0].CreatedAt)
+		assert.Equal(t, "owner_1", roles[0].CreatedBy)
+	})
+
+	t.Run("should request inherited members", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `[{"user_id":"u1","role":"viewer","scope":"resource","resource_id":"d1","resource_type":"dashboard"},{"user_id":"u2","role":"admin","scope":"system","namespace":"NS","inherited":true}]`)
+
+		roles, err := c.GetUserRoles(context.Background(), "caller", GetUserRolesRequest{Scope: ScopeResource, ResourceID: "d1", ResourceType: "dashboard", Namespace: "NS", IncludeInherited: true})
+		require.NoError(t, err)
+		assert.Equal(t, "true", got.query["include_inherited"])
+		require.Len(t, roles, 2)
+		assert.False(t, roles[0].Inherited)
+		assert.True(t, roles[1].Inherited)
+	})
+
+	t.Run("should page through history", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"data":[{"id":"h1","operation":"assign_owner","caller_id":"caller","scope":"system"}],"page":2,"size":10,"total_count":11,"total_pages":2,"has_next":false,"has_prev":true}`)
+		start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		resp, err := c.GetUserRoleHistory(context.Background(), "caller", GetUserRoleHistoryRequest{Scope: ScopeSystem, Namespace: "NS", StartTime: &start, Page: 2, Size: 10})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/user_roles/logs", got.path)
+		assert.Equal(t, map[string]string{"scope": "system", "namespace": "NS", "start_time": "2026-01-02T03:04:05Z", "page": "2", "size": "10"}, got.query)
+		assert.Equal(t, int64(11), resp.TotalCount)
+		assert.False(t, resp.HasNext)
+		assert.True(t, resp.HasPrev)
+		assert.Equal(t, "assign_owner", resp.Data[0].Operation)
+	})
+
+	t.Run("should sync changed roles since cursor", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"data":[{"user_id":"u1","role":"viewer","scope":"system","deleted_at":"2026-01-02T03:04:06Z"}],"cursor":"2026-01-02T03:04:06Z"}`)
+		since := time.Date(2026, 1, 2, 3, 4, 5, 500000000, time.UTC)
+
+		resp, err := c.SyncUserRoles(context.Background(), "caller", SyncUserRolesRequest{ModifiedSince: since, Scope: ScopeSystem})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/user_roles/sync", got.path)
+		assert.Equal(t, map[string]string{"modified_since": "2026-01-02T03:04:05.5Z", "scope": "system"}, got.query)
+		require.Len(t, resp.Data, 1)
+		assert.NotNil(t, resp.Data[0].DeletedAt)
+		assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC), resp.Cursor)
+	})
+}
+
+func TestCheckPermission(t *testing.T) {
+	t.Run("should return allowed", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"allowed":true}`)
+
+		allowed, err := c.CheckPermission(context.Background(), "caller", CheckPermissionRequest{Permission: "dashboard.read", Scope: ScopeResource, ResourceID: "d1", ResourceType: "dashboard"})
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, "/api/v1/permissions/check", got.path)
+		assert.Equal(t, map[string]interface{}{"permission": "dashboard.read", "scope": "resource", "resource_id": "d1", "resource_type": "dashboard"}, got.body)
+	})
+}
+
+func TestCheckPermissionsBatch(t *testing.T) {
+	t.Run("should post the checks and return results by id", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"results":{"w1":true,"w2":false}}`)
+
+		results, err := c.CheckPermissionsBatch(context.Background(), "caller", []PermissionCheck{
+			{ID: "w1", Permission: "resource.dashboard_widget.read", Scope: ScopeResource, ResourceID: "w1", ResourceType: "dashboard_widget", ParentResourceID: "d1"},
+			{ID: "w2", Permission: "resource.dashboard_widget.read", Scope: ScopeResource, ResourceID: "w2", ResourceType: "dashboard_widget", ParentResourceID: "d1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"w1": true, "w2": false}, results)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/permissions/check/batch", got.path)
+		checks, _ := got.body["checks"].([]interface{})
+		require.Len(t, checks, 2)
+		assert.Equal(t, map[string]interface{}{
+			"id": "w1", "permission": "resource.dashboard_widget.read", "scope": "resource",
+			"resource_id": "w1", "resource_type": "dashboard_widget", "parent_resource_id": "d1",
+		}, checks[0])
+	})
+}
+
+func TestGetDashboardResource(t *testing.T) {
+	t.Run("should parse roles and accessible widgets", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"user_roles":[{"user_id":"u1","role":"owner"}],"accessible_widget_ids":["w1"]}`)
+
+		resp, err := c.GetDashboardResource(context.Background(), "caller", GetDashboardResourceRequest{ResourceID: "d1", ResourceType: "dashboard", ChildResourceIDs: []string{"w1", "w2"}})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/resources/dashboards", got.path)
+		assert.Equal(t, []string{"w1"}, resp.AccessibleWidgetIDs)
+		assert.Equal(t, "owner", resp.UserRoles[0].Role)
+	})
+}
+
+func TestGetAccessibleResourceSummary(t *testing.T) {
+	t.Run("should parse counts per resource type", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"counts":{"dashboard":3,"library_widget":2},"total":5}`)
+
+		resp, err := c.GetAccessibleResourceSummary(context.Background(), "caller")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/resources/accessible/summary", got.path)
+		assert.Equal(t, &AccessibleResourceSummary{Counts: map[string]int64{"dashboard": 3, "library_widget": 2}, Total: 5}, resp)
+	})
+}
+
+func TestCheckMembers(t *testing.T) {
+	t.Run("should post user ids and parse members", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"members":["u1"]}`)
+
+		members, err := c.CheckMembers(context.Background(), "caller", CheckMembersRequest{Scope: "system", Namespace: "NS_1", UserIDs: []string{"u1", "u2"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/user_roles/members/check", got.path)
+		assert.Equal(t, map[string]interface{}{"scope": "system", "namespace": "NS_1", "user_ids": []interface{}{"u1", "u2"}}, got.body)
+		assert.Equal(t, []string{"u1"}, members)
+	})
+}
+
+func TestGetMyResourceRoles(t *testing.T) {
+	t.Run("should post ids and parse null roles", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"roles":{"dash_1":"owner","dash_2":null}}`)
+
+		roles, err := c.GetMyResourceRoles(context.Background(), "caller", "dashboard", []string{"dash_1", "dash_2"})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/user_roles/resources/my_roles", got.path)
+		assert.Equal(t, map[string]interface{}{"resource_type": "dashboard", "resource_ids": []interface{}{"dash_1", "dash_2"}}, got.body)
+		require.Len(t, roles, 2)
+		assert.Equal(t, "owner", *roles["dash_1"])
+		assert.Nil(t, roles["dash_2"])
+	})
+}
+
+func TestGetResourceOwner(t *testing.T) {
+	t.Run("should get the owner by query", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"user_id":"user_1","user_type":"member","role":"owner","scope":"resource","resource_id":"dash_1","resource_type":"dashboard"}`)
+
+		owner, err := c.GetResourceOwner(context.Background(), "caller", "dashboard", "dash_1")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/resources/owner", got.path)
+		assert.Equal(t, "dash_1", got.query["resource_id"])
+		assert.Equal(t, "dashboard", got.query["resource_type"])
+		require.NotNil(t, owner)
+		assert.Equal(t, "user_1", owner.UserID)
+	})
+
+	t.Run("should return nil for a null body", func(t *testing.T) {
+		c, _ := newServer(t, http.StatusOK, `null`)
+
+		owner, err := c.GetResourceOwner(context.Background(), "caller", "dashboard", "dash_1")
+		require.NoError(t, err)
+		assert.Nil(t, owner)
+	})
+}
+
+func TestGetResourceOwners(t *testing.T) {
+	t.Run("should post ids and parse null owners", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"owners":{"dash_1":"user_1","dash_2":null}}`)
+
+		owners, err := c.GetResourceOwners(context.Background(), "mod_1", "dashboard", []string{"dash_1", "dash_2"})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/resources/owners", got.path)
+		assert.Equal(t, map[string]interface{}{"resource_type": "dashboard", "resource_ids": []interface{}{"dash_1", "dash_2"}}, got.body)
+		require.Len(t, owners, 2)
+		assert.Equal(t, "user_1", *owners["dash_1"])
+		assert.Nil(t, owners["dash_2"])
+	})
+}
+
+func TestEraseUser(t *testing.T) {
+	t.Run("should post to the user's erase path", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"tombstone":"erased_1","roles_deleted":2,"roles_anonymized":0,"history_anonymized":4}`)
+
+		result, err := c.EraseUser(context.Background(), "mod_1", "user_x")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/admin/users/user_x/erase", got.path)
+		assert.Nil(t, got.body)
+		assert.Equal(t, &EraseUserResult{Tombstone: "erased_1", RolesDeleted: 2, HistoryAnonymized: 4}, result)
+	})
+}
+
+func TestPurgeUser(t *testing.T) {
+	t.Run("should delete the user's purge path", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"roles_deleted":3}`)
+
+		result, err := c.PurgeUser(context.Background(), "mod_1", "user_x")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, got.method)
+		assert.Equal(t, "/api/v1/users/user_x/purge", got.path)
+		assert.Equal(t, &PurgeUserResult{RolesDeleted: 3}, result)
+	})
+}
+
+func TestReassignOwner(t *testing.T) {
+	t.Run("should post both owners", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"transferred":3}`)
+
+		result, err := c.ReassignOwner(context.Background(), "mod_1", "leaver", "successor")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/user_roles/reassign_owner", got.path)
+		assert.Equal(t, map[string]interface{}{"old_owner_id": "leaver", "new_owner_id": "successor"}, got.body)
+		assert.Equal(t, &ReassignOwnerResult{Transferred: 3}, result)
+	})
+}
+
+func TestNamespaceSnapshot(t *testing.T) {
+	t.Run("should get the snapshot with history", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"version":1,"namespace":"NS_SRC","roles":[{"user_id":"u1","role":"owner","scope":"system"}]}`)
+
+		snapshot, err := c.SnapshotNamespace(context.Background(), "mod_1", "NS_SRC", true)
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/namespaces/NS_SRC/snapshot", got.path)
+		assert.Equal(t, "true", got.query["include_history"])
+		require.Len(t, snapshot.Roles, 1)
+		assert.Equal(t, "owner", snapshot.Roles[0].Role)
+	})
+
+	t.Run("should post the snapshot to restore", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"namespace":"NS_NEW","mode":"replace","roles_restored":1,"roles_removed":2}`)
+
+		req := RestoreNamespaceRequest{Mode: RestoreModeReplace, Snapshot: NamespaceSnapshot{Version: 1, Namespace: "NS_SRC"}}
+		result, err := c.RestoreNamespace(context.Background(), "mod_1", "NS_NEW", req)
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/namespaces/NS_NEW/restore", got.path)
+		assert.Equal(t, "replace", got.body["mode"])
+		assert.Equal(t, &RestoreNamespaceResult{Namespace: "NS_NEW", Mode: "replace", RolesRestored: 1, RolesRemoved: 2}, result)
+	})
+}
+
+func TestRenameNamespace(t *testing.T) {
+	t.Run("should post from and to and parse counts", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"from":"NS_OLD","to":"NS_NEW","roles_migrated":4,"history_migrated":9,"resource_roles_migrated":true}`)
+
+		result, err := c.RenameNamespace(context.Background(), "mod_1", "NS_OLD", "NS_NEW")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/namespaces/rename", got.path)
+		assert.Equal(t, map[string]interface{}{"from": "NS_OLD", "to": "NS_NEW"}, got.body)
+		assert.Equal(t, &RenameNamespaceResult{From: "NS_OLD", To: "NS_NEW", RolesMigrated: 4, HistoryMigrated: 9, ResourceRolesMigrated: true}, result)
+	})
+}
+
+func TestGetAccessSnapshot(t *testing.T) {
+	t.Run("should get the user's snapshot path and parse grants", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"user_id":"user_x","grants":[{"role":"viewer","user_type":"member","scope":"resource","resource_id":"d1","resource_type":"dashboard","permissions":["resource.dashboard.read"],"expiring":true}],"expiring_count":1}`)
+
+		snapshot, err := c.GetAccessSnapshot(context.Background(), "mod_1", "user_x")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/admin/users/user_x/access_snapshot", got.path)
+		require.Len(t, snapshot.Grants, 1)
+		assert.Equal(t, []string{"resource.dashboard.read"}, snapshot.Grants[0].Permissions)
+		assert.True(t, snapshot.Grants[0].Expiring)
+		assert.Equal(t, 1, snapshot.ExpiringCount)
+	})
+}
+
+func TestReloadPolicy(t *testing.T) {
+	t.Run("should post to the reload path and parse the summary", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"entities":4,"operations":40,"system_roles":6,"resource_roles":4,"loaded_at":"2026-01-18T12:00:00Z"}`)
+
+		summary, err := c.ReloadPolicy(context.Background(), "mod_1")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, got.method)
+		assert.Equal(t, "/api/v1/policy/reload", got.path)
+		assert.Equal(t, 4, summary.Entities)
+		assert.Equal(t, 40, summary.Operations)
+		assert.Equal(t, 6, summary.SystemRoles)
+		assert.Equal(t, 4, summary.ResourceRoles)
+	})
+}
+
+func TestGetPermissionRoles(t *testing.T) {
+	t.Run("should escape the permission and pass scope", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"permission":"resource.dashboard.delete","roles":[{"role":"admin","scope":"resource"},{"role":"owner","scope":"resource"}]}`)
+
+		result, err := c.GetPermissionRoles(context.Background(), "caller", "resource.dashboard.delete", "resource")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, got.method)
+		assert.Equal(t, "/api/v1/permissions/resource.dashboard.delete/roles", got.path)
+		assert.Equal(t, "resource", got.query["scope"])
+		require.Len(t, result.Roles, 2)
+		assert.Equal(t, PermissionRole{Role: "admin", Scope: "resource"}, result.Roles[0])
+	})
+}
+
+func TestGetCapableUsers(t *testing.T) {
+	t.Run("should send the resource and permission as query", func(t *testing.T) {
+		c, got := newServer(t, http.StatusOK, `{"permission":"resource.dashboard.delete","roles":["admin","owner"],"users":[{"user_id":"u1","user_type":"member","role":"owner"}]}`)
+
+		result, err := c.GetCapableUsers(context.Background(), "caller", GetCapableUsersRequest{ResourceID: "d1", ResourceType: "dashboard", Permission: "resource.dashboard.delete"})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/resources/capable_users", got.path)
+		assert.Equal(t, map[string]string{"resource_id": "d1", "resource_type": "dashboard", "permission": "resource.dashboard.delete"}, got.query)
+		require.Len(t, result.Users, 1)
+		assert.Equal(t, "owner", result.Users[0].Role)
+	})
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("should return APIError from error envelope", func(t *testing.T) {
+		c, _ := newServer(t, http.StatusForbidden, `{"error":{"code":"forbidden","message":"caller is not owner"}}`)
+
+		err := c.AssignSystemOwner(context.Background(), "caller", AssignSystemOwnerRequest{UserID: "u1", Namespace: "NS"})
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+		assert.Equal(t, "forbidden", apiErr.Code)
+		assert.Equal(t, "caller is not owner", apiErr.Message)
+		assert.Equal(t, http.StatusForbidden, StatusCode(err))
+	})
+}
+
+func TestRetry(t *testing.T) {
+	newFlakyServer := func(t *testing.T, failures int32) (*httptest.Server, *int32) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"allowed":true}`))
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &calls
+	}
+
+	t.Run("should retry permission check on 503", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, 2)
+		c := New(srv.URL, WithRetry(2, time.Millisecond))
+
+		allowed, err := c.CheckPermission(context.Background(), "caller", CheckPermissionRequest{Permission: "p", Scope: ScopeSystem})
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should not retry mutations", func(t *testing.T) {
+		srv, calls := newFlakyServer(t, 1)
+		c := New(srv.URL, WithRetry(2, time.Millisecond))
+
+		err := c.AssignSystemOwner(context.Background(), "caller", AssignSystemOwnerRequest{UserID: "u1", Namespace: "NS"})
+		assert.Equal(t, http.StatusServiceUnavailable, StatusCode(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should honour timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+		}))
+		t.Cleanup(srv.Close)
+		c := New(srv.URL, WithTimeout(5*time.Millisecond))
+
+		_, err := c.CheckPermission(context.Background(), "caller", CheckPermissionRequest{Permission: "p", Scope: ScopeSystem})
+		assert.Error(t, err)
+		assert.Equal(t, 0, StatusCode(err))
+	})
+}
@@ -0,0 +1,167 @@
+// Package rbacclienttest provides an in-memory RBAC API server for testing code that uses rbacclient.
+package rbacclienttest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"rbac7/pkg/rbacclient"
+)
+
+// apiPrefix is where the RBAC API is mounted
+const apiPrefix = "/api/v1"
+
+// Response is a canned reply for one endpoint. Body is JSON encoded unless it is a string.
+type Response struct {
+	Status int
+	Body   interface{}
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method   string
+	Path     string // relative to /api/v1, e.g. "/permissions/check"
+	Query    url.Values
+	CallerID string
+	Body     []byte
+}
+
+// DecodeBody unmarshals the JSON request body into v
+func (r Request) DecodeBody(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server records every request and answers from stubs keyed by method and path.
+// Requests without a stub get 501 with the standard error envelope so missing stubs fail loudly.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	stubs    map[string]func(Request) Response
+	requests []Request
+}
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{stubs: make(map[string]func(Request) Response)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns an rbacclient pointed at the server
+func (s *Server) Client(opts ...rbacclient.Option) *rbacclient.Client {
+	return rbacclient.New(s.URL+apiPrefix, opts...)
+}
+
+// Stub answers method+path with a fixed response
+func (s *Server) Stub(method, path string, status int, body interface{}) {
+	s.StubFunc(method, path, func(Request) Response {
+		return Response{Status: status, Body: body}
+	})
+}
+
+// StubFunc answers method+path with a response computed from the request
+func (s *Server) StubFunc(method, path string, fn func(Request) Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs[stubKey(method, path)] = fn
+}
+
+// StubSuccess answers method+path with the {"status":"success"} body mutations return
+func (s *Server) StubSuccess(method, path string) {
+	s.Stub(method, path, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// StubError answers method+path with the standard error envelope
+func (s *Server) StubError(method, path string, status int, code, message string) {
+	s.Stub(method, path, status, errorBody(code, message))
+}
+
+// StubPermission answers POST /permissions/check with a fixed decision
+func (s *Server) StubPermission(allowed bool) {
+	s.Stub(http.MethodPost, "/permissions/check", http.StatusOK, map[string]bool{"allowed": allowed})
+}
+
+// StubUserRoles answers GET /user_roles and GET /user_roles/me with roles
+func (s *Server) StubUserRoles(roles []rbacclient.UserRole) {
+	if roles == nil {
+		roles = []rbacclient.UserRole{}
+	}
+	s.Stub(http.MethodGet, "/user_roles", http.StatusOK, roles)
+	s.Stub(http.MethodGet, "/user_roles/me", http.StatusOK, roles)
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received for method+path
+func (s *Server) RequestsTo(method, path string) []Request {
+	var matched []Request
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Reset drops recorded requests, keeping stubs
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{
+		Method:   r.Method,
+		Path:     strings.TrimPrefix(r.URL.Path, apiPrefix),
+		Query:    r.URL.Query(),
+		CallerID: r.Header.Get("x-user-id"),
+		Body:     body,
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	fn, ok := s.stubs[stubKey(req.Method, req.Path)]
+	s.mu.Unlock()
+
+	resp := Response{Status: http.StatusNotImplemented, Body: errorBody("not_implemented", "no stub for "+req.Method+" "+req.Path)}
+	if ok {
+		resp = fn(req)
+	}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Status)
+	switch b := resp.Body.(type) {
+	case nil:
+	case string:
+		_, _ = io.WriteString(w, b)
+	default:
+		_ = json.NewEncoder(w).Encode(b)
+	}
+}
+
+func stubKey(method, path string) string {
+	return method + " " + path
+}
+
+func errorBody(code, message string) map[string]map[string]string {
+	return map[string]map[string]string{"error": {"code": code, "message": message}}
+}
@@ -0,0 +1,95 @@
+package rbacclienttest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rbac7/pkg/rbacclient"
+	"rbac7/pkg/rbacclient/rbacclienttest"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("should stub a batch check and record the request body", func(t *testing.T) {
+		srv := rbacclienttest.NewServer(t)
+		srv.Stub(http.MethodPost, "/permissions/check/batch", http.StatusOK, map[string]interface{}{
+			"results": []map[string]interface{}{{"allowed": true}, {"allowed": false}},
+		})
+
+		payload := `{"checks":[{"permission":"dashboard.read","scope":"resource","resource_id":"d1","resource_type":"dashboard"},{"permission":"dashboard.read","scope":"resource","resource_id":"d2","resource_type":"dashboard"}]}`
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/permissions/check/batch", bytes.NewBufferString(payload))
+		req.Header.Set("x-user-id", "caller")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		recorded := srv.RequestsTo(http.MethodPost, "/permissions/check/batch")
+		require.Len(t, recorded, 1)
+		assert.Equal(t, "caller", recorded[0].CallerID)
+		var body struct {
+			Checks []rbacclient.CheckPermissionRequest `json:"checks"`
+		}
+		require.NoError(t, recorded[0].DecodeBody(&body))
+		require.Len(t, body.Checks, 2)
+		assert.Equal(t, "d2", body.Checks[1].ResourceID)
+	})
+
+	t.Run("should serve the typed client", func(t *testing.T) {
+		srv := rbacclienttest.NewServer(t)
+		srv.StubPermission(true)
+		srv.StubUserRoles([]rbacclient.UserRole{{UserID: "caller", Role: "owner", Scope: rbacclient.ScopeSystem, Namespace: "NS"}})
+		srv.StubSuccess(http.MethodDelete, "/user_roles")
+		c := srv.Client()
+
+		allowed, err := c.CheckPermission(context.Background(), "caller", rbacclient.CheckPermissionRequest{Permission: "p", Scope: rbacclient.ScopeSystem, Namespace: "NS"})
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		roles, err := c.GetUserRolesMe(context.Background(), "caller", rbacclient.GetUserRolesMeRequest{Scope: rbacclient.ScopeSystem})
+		require.NoError(t, err)
+		assert.Equal(t, "owner", roles[0].Role)
+
+		require.NoError(t, c.DeleteSystemUserRole(context.Background(), "caller", rbacclient.DeleteSystemUserRoleRequest{Namespace: "NS", UserID: "u1"}))
+		deletes := srv.RequestsTo(http.MethodDelete, "/user_roles")
+		require.Len(t, deletes, 1)
+		assert.Equal(t, "u1", deletes[0].Query.Get("user_id"))
+		assert.Len(t, srv.Requests(), 3)
+	})
+
+	t.Run("should return stubbed errors and fail unstubbed endpoints", func(t *testing.T) {
+		srv := rbacclienttest.NewServer(t)
+		srv.StubError(http.MethodPost, "/user_roles/owner", http.StatusConflict, "conflict", "owner already exists")
+		c := srv.Client()
+
+		err := c.AssignSystemOwner(context.Background(), "caller", rbacclient.AssignSystemOwnerRequest{UserID: "u1", Namespace: "NS"})
+		var apiErr *rbacclient.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "conflict", apiErr.Code)
+
+		err = c.AssignSystemUserRole(context.Background(), "caller", rbacclient.AssignSystemUserRoleRequest{UserID: "u1", Role: "viewer", Namespace: "NS"})
+		assert.Equal(t, http.StatusNotImplemented, rbacclient.StatusCode(err))
+	})
+
+	t.Run("should compute responses from the request", func(t *testing.T) {
+		srv := rbacclienttest.NewServer(t)
+		srv.StubFunc(http.MethodPost, "/permissions/check", func(r rbacclienttest.Request) rbacclienttest.Response {
+			var req rbacclient.CheckPermissionRequest
+			_ = json.Unmarshal(r.Body, &req)
+			return rbacclienttest.Response{Body: map[string]bool{"allowed": req.ResourceID == "d1"}}
+		})
+		c := srv.Client()
+
+		allowed, err := c.CheckPermission(context.Background(), "caller", rbacclient.CheckPermissionRequest{Permission: "p", Scope: rbacclient.ScopeResource, ResourceID: "d1"})
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, err = c.CheckPermission(context.Background(), "caller", rbacclient.CheckPermissionRequest{Permission: "p", Scope: rbacclient.ScopeResource, ResourceID: "d2"})
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+}
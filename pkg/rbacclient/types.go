@@ -0,0 +1,456 @@
+package rbacclient
+
+import "time"
+
+// Scopes
+const (
+	ScopeSystem   = "system"
+	ScopeResource = "resource"
+)
+
+// UserRole is a role assignment as returned by the list endpoints
+type UserRole struct {
+	ID               string     `json:"id,omitempty"`
+	UserID           string     `json:"user_id"`
+	UserType         string     `json:"user_type"`
+	Role             string     `json:"role"`
+	Scope            string     `json:"scope"`
+	Namespace        string     `json:"namespace,omitempty"`
+	ResourceID       string     `json:"resource_id,omitempty"`
+	ResourceType     string     `json:"resource_type,omitempty"`
+	ParentResourceID string     `json:"parent_resource_id,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	Permissions      []string   `json:"permissions,omitempty"` // set by GetUserRoles with ExpandPermissions
+	Inherited        bool       `json:"inherited,omitempty"`   // set by GetUserRoles with IncludeInherited on namespace system roles
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	CreatedBy        string     `json:"created_by,omitempty"`
+	UpdatedBy        string     `json:"updated_by,omitempty"`
+}
+
+// AssignSystemOwnerRequest is the body of POST /user_roles/owner
+type AssignSystemOwnerRequest struct {
+	UserID    string `json:"user_id"`
+	Namespace string `json:"namespace"`
+}
+
+// TransferSystemOwnerRequest is the body of PUT /user_roles/owner
+type TransferSystemOwnerRequest struct {
+	UserID    string `json:"user_id"`
+	Namespace string `json:"namespace"`
+}
+
+// AssignSystemUserRoleRequest is the body of POST /user_roles
+type AssignSystemUserRoleRequest struct {
+	UserID     string `json:"user_id,omitempty"`
+	ExternalID string `json:"external_id,omitempty"` // resolved to a user_id by the server when UserID is empty
+	Email      string `json:"email,omitempty"`       // resolved to a user_id by the server when UserID is empty
+	Role       string `json:"role"`
+	Namespace  string `json:"namespace"`
+	UserType   string `json:"user_type,omitempty"`
+	Notify     bool   `json:"notify,omitempty"` // enqueue a grant notification after success
+	// ExpiresAt (or TTLSeconds from now) and Reason make a temporary grant
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// AssignSystemUserRolesRequest is the body of POST /user_roles/batch
+type AssignSystemUserRolesRequest struct {
+	UserIDs     []string         `json:"user_ids,omitempty"`
+	Role        string           `json:"role,omitempty"`
+	Assignments []RoleAssignment `json:"assignments,omitempty"` // per-user roles; replaces UserIDs and Role
+	Namespace   string           `json:"namespace"`
+	UserType    string           `json:"user_type,omitempty"`
+	Notify      bool             `json:"notify,omitempty"` // enqueue a grant notification after success
+}
+
+// RoleAssignment grants Role to UserID in a mixed-role batch
+type RoleAssignment struct {
+	UserID     string `json:"user_id,omitempty"`
+	ExternalID string `json:"external_id,omitempty"` // resolved to a user_id by the server when UserID is empty
+	Email      string `json:"email,omitempty"`       // resolved to a user_id by the server when UserID is empty
+	Role       string `json:"role"`
+}
+
+// DeleteSystemUserRoleRequest is the query of DELETE /user_roles
+type DeleteSystemUserRoleRequest struct {
+	Namespace string
+	UserID    string
+	UserType  string
+}
+
+// AssignResourceOwnerRequest is the body of POST /user_roles/resources/owner (the caller becomes owner)
+type AssignResourceOwnerRequest struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Namespace    string `json:"namespace,omitempty"`
+}
+
+// TransferResourceOwnerRequest is the body of PUT /user_roles/resources/owner
+type TransferResourceOwnerRequest struct {
+	UserID       string `json:"user_id"`
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Namespace    string `json:"namespace,omitempty"`
+}
+
+// AssignResourceUserRoleRequest is the body of POST /user_roles/resources
+type AssignResourceUserRoleRequest struct {
+	UserID           string `json:"user_id,omitempty"`
+	ExternalID       string `json:"external_id,omitempty"` // resolved to a user_id by the server when UserID is empty
+	Email            string `json:"email,omitempty"`       // resolved to a user_id by the server when UserID is empty
+	Role             string `json:"role"`
+	ResourceID       string `json:"resource_id"`
+	ResourceType     string `json:"resource_type"`
+	ParentResourceID string `json:"parent_resource_id,omitempty"`
+	UserType         string `json:"user_type,omitempty"`
+	// Namespace, when set, restricts Role to the namespace's assignable resource roles
+	Namespace string `json:"namespace,omitempty"`
+	Notify    bool   `json:"notify,omitempty"` // enqueue a grant notification after success
+	// ExpiresAt (or TTLSeconds from now) and Reason make a temporary grant (access ends at ExpiresAt)
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// AssignResourceUserRolesRequest is the body of POST /user_roles/resources/batch
+type AssignResourceUserRolesRequest struct {
+	UserIDs          []string         `json:"user_ids,omitempty"`
+	Role             string           `json:"role,omitempty"`
+	Assignments      []RoleAssignment `json:"assignments,omitempty"` // per-user roles; replaces UserIDs and Role
+	ResourceID       string           `json:"resource_id"`
+	ResourceType     string           `json:"resource_type"`
+	ParentResourceID string           `json:"parent_resource_id,omitempty"`
+	Namespace        string           `json:"namespace,omitempty"`
+	UserType         string           `json:"user_type,omitempty"`
+	Notify           bool             `json:"notify,omitempty"` // enqueue a grant notification after success
+	// ExpiresAt (or TTLSeconds from now) and Reason make a temporary grant for every user
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// DeleteResourceUserRoleRequest is the query of DELETE /user_roles/resources
+type DeleteResourceUserRoleRequest struct {
+	UserID           string
+	ResourceID       string
+	ResourceType     string
+	ParentResourceID string
+	Namespace        string
+	UserType         string
+}
+
+// BatchUpsertResult is returned by the batch assign endpoints
+type BatchUpsertResult struct {
+	SuccessCount int              `json:"success_count"`
+	FailedCount  int              `json:"failed_count"`
+	FailedUsers  []FailedUserInfo `json:"failed_users,omitempty"`
+	Assigned     []RoleAssignment `json:"assigned,omitempty"` // pairs granted by an Assignments batch
+}
+
+// FailedUserInfo explains why one user of a batch failed
+type FailedUserInfo struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// GetUserRolesMeRequest is the query of GET /user_roles/me
+type GetUserRolesMeRequest struct {
+	Scope        string
+	ResourceType string
+}
+
+// GetUserRolesRequest is the query of GET /user_roles
+type GetUserRolesRequest struct {
+	UserID           string
+	Namespace        string
+	Role             string
+	Scope            string
+	ResourceID       string
+	ResourceType     string
+	ParentResourceID string
+	// ExpandPermissions fills UserRole.Permissions with what each member's role grants
+	ExpandPermissions bool
+	// IncludeAudit fills the created/updated times and actors of each role
+	IncludeAudit bool
+	// IncludeInherited adds the namespace's system owners and admins to a resource member list
+	IncludeInherited bool
+}
+
+// CheckMembersRequest is the body of POST /user_roles/members/check
+type CheckMembersRequest struct {
+	Scope            string   `json:"scope"`
+	Namespace        string   `json:"namespace,omitempty"`
+	ResourceID       string   `json:"resource_id,omitempty"`
+	ResourceType     string   `json:"resource_type,omitempty"`
+	ParentResourceID string   `json:"parent_resource_id,omitempty"`
+	UserIDs          []string `json:"user_ids"`
+}
+
+// RestoreUserRoleRequest is the body of POST /user_roles/restore
+type RestoreUserRoleRequest struct {
+	Scope            string `json:"scope"`
+	Namespace        string `json:"namespace,omitempty"`
+	UserID           string `json:"user_id"`
+	UserType         string `json:"user_type,omitempty"`
+	ResourceID       string `json:"resource_id,omitempty"`
+	ResourceType     string `json:"resource_type,omitempty"`
+	ParentResourceID string `json:"parent_resource_id,omitempty"`
+}
+
+// CheckPermissionRequest is the body of POST /permissions/check
+type CheckPermissionRequest struct {
+	Permission       string `json:"permission"`
+	Scope            string `json:"scope"`
+	Namespace        string `json:"namespace,omitempty"`
+	ResourceID       string `json:"resource_id,omitempty"`
+	ResourceType     string `json:"resource_type,omitempty"`
+	ParentResourceID string `json:"parent_resource_id,omitempty"`
+}
+
+// PermissionCheck is one check of POST /permissions/check/batch; ID is chosen by the caller and keys
+// the result
+type PermissionCheck struct {
+	ID               string `json:"id"`
+	Permission       string `json:"permission"`
+	Scope            string `json:"scope"`
+	Namespace        string `json:"namespace,omitempty"`
+	ResourceID       string `json:"resource_id,omitempty"`
+	ResourceType     string `json:"resource_type,omitempty"`
+	ParentResourceID string `json:"parent_resource_id,omitempty"`
+}
+
+// PermissionRoles is returned by GET /permissions/{permission}/roles
+type PermissionRoles struct {
+	Permission string           `json:"permission"`
+	Roles      []PermissionRole `json:"roles"`
+}
+
+// PermissionRole is a role granting the permission in a scope ("system" or "resource")
+type PermissionRole struct {
+	Role  string `json:"role"`
+	Scope string `json:"scope"`
+}
+
+// GetCapableUsersRequest is the query of GET /resources/capable_users
+type GetCapableUsersRequest struct {
+	ResourceID       string
+	ResourceType     string
+	Permission       string
+	ParentResourceID string // Required for dashboard_widget
+	Namespace        string // Required for library_widget
+}
+
+// CapableUsers is returned by GET /resources/capable_users
+type CapableUsers struct {
+	Permission string        `json:"permission"`
+	Roles      []string      `json:"roles"`
+	Users      []CapableUser `json:"users"`
+	Truncated  bool          `json:"truncated"` // Users was cut to the server's MAX_UNPAGED_ROLES
+}
+
+// CapableUser is a member whose role on the resource grants the permission
+type CapableUser struct {
+	UserID   string `json:"user_id"`
+	UserType string `json:"user_type"`
+	Role     string `json:"role"`
+}
+
+// DeleteResourceRequest is the body of PUT /resources/delete
+type DeleteResourceRequest struct {
+	ResourceID       string   `json:"resource_id"`
+	ResourceType     string   `json:"resource_type"`
+	ParentResourceID string   `json:"parent_resource_id,omitempty"`
+	ChildResourceIDs []string `json:"child_resource_ids,omitempty"`
+	Namespace        string   `json:"namespace,omitempty"`
+}
+
+// GetDashboardResourceRequest is the body of POST /resources/dashboards
+type GetDashboardResourceRequest struct {
+	ResourceID       string   `json:"resource_id"`
+	ResourceType     string   `json:"resource_type"`
+	ChildResourceIDs []string `json:"child_resource_ids,omitempty"`
+	Namespace        string   `json:"namespace,omitempty"`
+}
+
+// GetDashboardResourceResponse lists the caller's dashboard roles and the widgets they may see
+type GetDashboardResourceResponse struct {
+	UserRoles           []DashboardUserRole `json:"user_roles"`
+	AccessibleWidgetIDs []string            `json:"accessible_widget_ids"`
+}
+
+// DashboardUserRole is a simplified role entry of GetDashboardResourceResponse
+type DashboardUserRole struct {
+	UserID   string `json:"user_id"`
+	UserType string `json:"user_type,omitempty"`
+	Role     string `json:"role"`
+}
+
+// GetUserRoleHistoryRequest is the query of GET /user_roles/logs
+type GetUserRoleHistoryRequest struct {
+	Scope            string
+	Namespace        string
+	ResourceID       string
+	ResourceType     string
+	ParentResourceID string
+	TargetUserID     string
+	StartTime        *time.Time
+	EndTime          *time.Time
+	Page             int
+	Size             int
+}
+
+// SyncUserRolesRequest is the query of GET /user_roles/sync
+type SyncUserRolesRequest struct {
+	ModifiedSince time.Time
+	Scope         string
+	Namespace     string
+	ResourceType  string
+}
+
+// SyncUserRolesResponse holds roles changed since ModifiedSince; soft-deleted roles have DeletedAt set.
+// Pass Cursor as the next ModifiedSince, and sync again while Truncated is set.
+type SyncUserRolesResponse struct {
+	Data      []UserRole `json:"data"`
+	Cursor    time.Time  `json:"cursor"`
+	Truncated bool       `json:"truncated"`
+}
+
+// UserRoleHistory is one audit log entry
+type UserRoleHistory struct {
+	ID               string     `json:"id"`
+	Operation        string     `json:"operation"`
+	CallerID         string     `json:"caller_id"`
+	Scope            string     `json:"scope"`
+	Namespace        string     `json:"namespace,omitempty"`
+	ResourceID       string     `json:"resource_id,omitempty"`
+	ResourceType     string     `json:"resource_type,omitempty"`
+	ParentResourceID string     `json:"parent_resource_id,omitempty"`
+	UserID           string     `json:"user_id,omitempty"`
+	UserIDs          []string   `json:"user_ids,omitempty"`
+	UserType         string     `json:"user_type,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	NewOwnerID       string     `json:"new_owner_id,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	SuperadminBypass bool       `json:"superadmin_bypass,omitempty"`
+	ChildResourceIDs []string   `json:"child_resource_ids,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// GetUserRoleHistoryResponse is a page of audit log entries
+type GetUserRoleHistoryResponse struct {
+	Data       []UserRoleHistory `json:"data"`
+	Page       int               `json:"page"`
+	Size       int               `json:"size"`
+	TotalCount int64             `json:"total_count"`
+	TotalPages int               `json:"total_pages"`
+	HasNext    bool              `json:"has_next"`
+	HasPrev    bool              `json:"has_prev"`
+}
+
+// EraseUserResult is returned by POST /admin/users/{id}/erase
+type EraseUserResult struct {
+	Tombstone         string `json:"tombstone"`
+	RolesDeleted      int64  `json:"roles_deleted"`
+	RolesAnonymized   int64  `json:"roles_anonymized"`
+	HistoryAnonymized int64  `json:"history_anonymized"`
+}
+
+// PurgeUserResult is returned by DELETE /users/{user_id}/purge
+type PurgeUserResult struct {
+	RolesDeleted int64 `json:"roles_deleted"`
+}
+
+// ReassignOwnerResult is returned by POST /user_roles/reassign_owner
+type ReassignOwnerResult struct {
+	Transferred int64 `json:"transferred"`
+}
+
+// AccessGrant is one active role of the user with the permissions it confers
+type AccessGrant struct {
+	Role             string     `json:"role"`
+	UserType         string     `json:"user_type"`
+	Scope            string     `json:"scope"`
+	Namespace        string     `json:"namespace,omitempty"`
+	ResourceID       string     `json:"resource_id,omitempty"`
+	ResourceType     string     `json:"resource_type,omitempty"`
+	ParentResourceID string     `json:"parent_resource_id,omitempty"`
+	Permissions      []string   `json:"permissions"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	Expiring         bool       `json:"expiring"`
+}
+
+// AccessSnapshot is returned by GET /admin/users/{id}/access_snapshot
+type AccessSnapshot struct {
+	UserID        string        `json:"user_id"`
+	Grants        []AccessGrant `json:"grants"`
+	ExpiringCount int           `json:"expiring_count"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+}
+
+// PolicySummary is returned by POST /policy/reload
+type PolicySummary struct {
+	Entities      int       `json:"entities"`
+	Operations    int       `json:"operations"`
+	SystemRoles   int       `json:"system_roles"`
+	ResourceRoles int       `json:"resource_roles"`
+	LoadedAt      time.Time `json:"loaded_at"`
+}
+
+// AccessibleResourceSummary is returned by GET /resources/accessible/summary
+type AccessibleResourceSummary struct {
+	Counts map[string]int64 `json:"counts"`
+	Total  int64            `json:"total"`
+}
+
+// NamespaceResourceRoles is returned by GET /namespaces/{namespace}/resource_roles
+type NamespaceResourceRoles struct {
+	Namespace  string   `json:"namespace"`
+	Roles      []string `json:"roles"`
+	Overridden bool     `json:"overridden"`
+}
+
+// RenameNamespaceResult is returned by POST /namespaces/rename
+type RenameNamespaceResult struct {
+	From                  string `json:"from"`
+	To                    string `json:"to"`
+	RolesMigrated         int64  `json:"roles_migrated"`
+	HistoryMigrated       int64  `json:"history_migrated"`
+	ResourceRolesMigrated bool   `json:"resource_roles_migrated"`
+}
+
+// NamespaceSnapshot is returned by GET /namespaces/{namespace}/snapshot
+type NamespaceSnapshot struct {
+	Version       int               `json:"version"`
+	Namespace     string            `json:"namespace"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Roles         []UserRole        `json:"roles"`
+	ResourceRoles []string          `json:"resource_roles,omitempty"`
+	History       []UserRoleHistory `json:"history,omitempty"`
+}
+
+// Restore modes
+const (
+	RestoreModeMerge   = "merge"
+	RestoreModeReplace = "replace"
+)
+
+// RestoreNamespaceRequest is the body of POST /namespaces/{namespace}/restore
+type RestoreNamespaceRequest struct {
+	Mode     string            `json:"mode,omitempty"` // RestoreModeMerge (default) or RestoreModeReplace
+	Snapshot NamespaceSnapshot `json:"snapshot"`
+}
+
+// RestoreNamespaceResult is returned by POST /namespaces/{namespace}/restore
+type RestoreNamespaceResult struct {
+	Namespace             string `json:"namespace"`
+	Mode                  string `json:"mode"`
+	RolesRestored         int64  `json:"roles_restored"`
+	RolesRemoved          int64  `json:"roles_removed"`
+	ResourceRolesRestored bool   `json:"resource_roles_restored"`
+}
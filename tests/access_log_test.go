@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rbac7/internal/rbac/handler"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	newServer := func(buf *bytes.Buffer, sampleRate float64) *echo.Echo {
+		e := echo.New()
+		e.Use(handler.AccessLogMiddleware(handler.AccessLogConfig{
+			Logger:         slog.New(slog.NewJSONHandler(buf, nil)),
+			ReadSampleRate: sampleRate,
+		}))
+		v1 := e.Group("/api/v1")
+		v1.Use(handler.RequestIDMiddleware)
+		v1.GET("/items/:id", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+		})
+		v1.POST("/items", func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]string{"status": "ok"})
+		})
+		return e
+	}
+
+	t.Run("log line contains request id, caller, route, status and latency", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf, 1)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/items/42", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-123")
+		req.Header.Set("x-user-id", "caller_1")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "req-123", entry["request_id"])
+		assert.Equal(t, "caller_1", entry["caller"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "/api/v1/items/:id", entry["route"])
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+		assert.Contains(t, entry, "latency_ms")
+	})
+
+	t.Run("generated request id is logged", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf, 1)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/items/42", nil))
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.NotEmpty(t, entry["request_id"])
+		assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), entry["request_id"])
+	})
+
+	t.Run("successful reads are skipped when sample rate is zero", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf, 0)
+
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/items/42", nil))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("writes and failed reads are always logged", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf, 0)
+
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/items", nil))
+		assert.Contains(t, buf.String(), `"status":201`)
+
+		buf.Reset()
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil))
+		assert.Contains(t, buf.String(), `"status":404`)
+	})
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingNotifier captures grant events; err makes every Notify fail after recording
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []model.GrantEvent
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event model.GrantEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func (n *recordingNotifier) Events() []model.GrantEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]model.GrantEvent(nil), n.events...)
+}
+
+func TestAssignNotify(t *testing.T) {
+	t.Run("assign system user role with notify emits event and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		body := map[string]interface{}{"user_id": "u_2", "role": "admin", "namespace": "NS_1", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		events := notifier.Events()
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, model.GrantEventRoleGranted, events[0].Type)
+			assert.Equal(t, "u_2", events[0].UserID)
+			assert.Equal(t, "admin", events[0].Role)
+			assert.Equal(t, model.ScopeSystem, events[0].Scope)
+			assert.Equal(t, "NS_1", events[0].Namespace)
+			assert.Equal(t, "owner_1", events[0].GrantedBy)
+		}
+	})
+
+	t.Run("assign resource user role with notify emits event and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		body := map[string]interface{}{"user_id": "u1", "role": "editor", "resource_id": "r1", "resource_type": "dashboard", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		events := notifier.Events()
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "u1", events[0].UserID)
+			assert.Equal(t, "editor", events[0].Role)
+			assert.Equal(t, "r1", events[0].ResourceID)
+			assert.Equal(t, "dashboard", events[0].ResourceType)
+		}
+	})
+
+	t.Run("assign without notify emits no event and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		body := map[string]interface{}{"user_id": "u_2", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, notifier.Events())
+	})
+
+	t.Run("failed grant with notify emits no event and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+		body := map[string]interface{}{"user_id": "u_2", "role": "admin", "namespace": "NS_1", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, notifier.Events())
+	})
+
+	t.Run("forbidden grant with notify emits no event and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(true, nil)
+
+		body := map[string]interface{}{"user_id": "u1", "role": "editor", "resource_id": "r1", "resource_type": "dashboard", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, notifier.Events())
+	})
+
+	t.Run("notification failure does not fail the grant and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{err: errors.New("queue full")}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		body := map[string]interface{}{"user_id": "u_2", "role": "admin", "namespace": "NS_1", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, notifier.Events(), 1)
+	})
+
+	t.Run("batch assign with notify emits events for succeeded users only and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		notifier := &recordingNotifier{}
+		e := SetupServerWithNotifier(mockRepo, notifier)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(&model.BatchUpsertResult{
+			SuccessCount: 1,
+			FailedCount:  1,
+			FailedUsers:  []model.FailedUserInfo{{UserID: "u_2", Reason: "duplicate"}},
+		}, nil)
+
+		body := map[string]interface{}{"user_ids": []string{"u_1", "u_2"}, "role": "viewer", "namespace": "NS_1", "notify": true}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		events := notifier.Events()
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "u_1", events[0].UserID)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubUserResolver maps emails and external ids to user ids; unknown identifiers resolve to ""
+type stubUserResolver struct {
+	byEmail      map[string]string
+	byExternalID map[string]string
+	err          error
+}
+
+func (r *stubUserResolver) ResolveUserID(ctx context.Context, externalID, email string) (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	if id, ok := r.byExternalID[externalID]; ok && externalID != "" {
+		return id, nil
+	}
+	return r.byEmail[email], nil
+}
+
+func newStubUserResolver() *stubUserResolver {
+	return &stubUserResolver{
+		byEmail:      map[string]string{"alice@example.com": "u_alice"},
+		byExternalID: map[string]string{"ext-bob": "u_bob"},
+	}
+}
+
+func TestAssignUserResolver(t *testing.T) {
+	storedUser := func(userID string) interface{} {
+		return mock.MatchedBy(func(role *model.UserRole) bool { return role.UserID == userID })
+	}
+
+	t.Run("assign system user role by email stores resolved id and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, storedUser("u_alice")).Return(nil)
+
+		body := map[string]interface{}{"email": " Alice@Example.com ", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("assign resource user role by external id stores resolved id and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u_bob", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, storedUser("u_bob")).Return(nil)
+
+		body := map[string]interface{}{"external_id": "ext-bob", "role": "editor", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("user_id wins over external_id and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, storedUser("u_2")).Return(nil)
+
+		body := map[string]interface{}{"user_id": "u_2", "external_id": "ext-bob", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown email and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"email": "nobody@example.com", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("resolver failure and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		resolver := newStubUserResolver()
+		resolver.err = errors.New("directory unavailable")
+		e := SetupServerWithUserResolver(mockRepo, resolver)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"email": "alice@example.com", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("email without a configured resolver and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"email": "alice@example.com", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("no user identifier and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "user_id, external_id or email is required")
+	})
+
+	t.Run("malformed email and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"email": "not-an-email", "role": "admin", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+	t.Run("batch assignments by email and external id store resolved ids and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 3 && roles[0].UserID == "u_alice" && roles[1].UserID == "u_bob" && roles[2].UserID == "u_2"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 3}, nil)
+
+		body := map[string]interface{}{
+			"namespace": "NS_1",
+			"assignments": []map[string]string{
+				{"email": "Alice@Example.com", "role": "admin"},
+				{"external_id": "ext-bob", "role": "viewer"},
+				{"user_id": "u_2", "role": "viewer"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"success_count":3,"failed_count":0,"assigned":[
+			{"user_id":"u_alice","role":"admin"},{"user_id":"u_bob","role":"viewer"},{"user_id":"u_2","role":"viewer"}]}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("resource batch assignment by external id stores resolved id and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 1 && roles[0].UserID == "u_bob" && roles[0].Role == "editor"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 1}, nil)
+
+		body := map[string]interface{}{
+			"resource_id": "r1", "resource_type": "dashboard",
+			"assignments": []map[string]string{{"external_id": "ext-bob", "role": "editor"}},
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", body, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("batch assignment with an unknown email fails the batch and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{
+			"namespace": "NS_1",
+			"assignments": []map[string]string{
+				{"user_id": "u_2", "role": "viewer"},
+				{"email": "nobody@example.com", "role": "viewer"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockRepo.AssertNotCalled(t, "BulkUpsertUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("batch assignment without a user identifier and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithUserResolver(mockRepo, newStubUserResolver())
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{
+			"namespace":   "NS_1",
+			"assignments": []map[string]string{{"role": "viewer"}},
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "each assignment needs user_id, external_id or email")
+	})
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TestConcurrentDeleteAndReassign races DELETE /user_roles against POST /user_roles for the same user.
+// Whichever write commits last decides the final state, and history lists both in commit order.
+func TestConcurrentDeleteAndReassign(t *testing.T) {
+	t.Run("final state matches the last history entry and return 200", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			mockRepo := new(MockRBACRepository)
+			e := SetupServerWithMiddleware(mockRepo)
+
+			// State of user_x's role, mutated only inside WithHistory (serialized like a transaction)
+			state := "active"
+			var history []string
+
+			mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+			mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1"}, nil)
+			mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "user_x", "system", "", "", "", "admin_1").
+				Run(func(mock.Arguments) { state = "deleted" }).Return(int64(1), nil)
+			mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+				return r.UserID == "user_x"
+			})).Run(func(mock.Arguments) { state = "active" }).Return(nil)
+			mockRepo.On("CreateHistory", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					history = append(history, args.Get(1).(*model.UserRoleHistory).Operation)
+				}).Return(nil)
+
+			headers := map[string]string{"x-user-id": "admin_1"}
+			var wg sync.WaitGroup
+			codes := make([]int, 2)
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				codes[0] = PerformRequest(e, http.MethodDelete, "/api/v1/user_roles?namespace=NS_1&user_id=user_x", nil, headers).Code
+			}()
+			go func() {
+				defer wg.Done()
+				payload := map[string]interface{}{"user_id": "user_x", "role": "viewer", "namespace": "NS_1"}
+				codes[1] = PerformRequest(e, http.MethodPost, "/api/v1/user_roles", payload, headers).Code
+			}()
+			wg.Wait()
+
+			assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+			assert.ElementsMatch(t, []string{"delete_user_role", "assign_user_role"}, history)
+			expected := map[string]string{"delete_user_role": "deleted", "assign_user_role": "active"}
+			assert.Equal(t, expected[history[len(history)-1]], state, "history order must match write order")
+		}
+	})
+
+	t.Run("delete of an already removed role records no history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1"}, nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "user_x", "system", "", "", "", "admin_1").Return(int64(0), mongo.ErrNoDocuments)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles?namespace=NS_1&user_id=user_x", nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rbac7/internal/rbac/handler"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	newServer := func(cfg handler.CORSConfig) *echo.Echo {
+		e := echo.New()
+		e.Use(handler.CORSMiddleware(cfg))
+		e.GET("/health", handler.HealthCheck)
+		return e
+	}
+	cfg := handler.CORSConfig{
+		AllowOrigins: []string{"https://app.example.com"},
+		AllowMethods: []string{http.MethodGet, http.MethodPost},
+		AllowHeaders: []string{echo.HeaderContentType, "x-user-id"},
+	}
+
+	t.Run("allowed origin gets CORS headers", func(t *testing.T) {
+		e := newServer(cfg)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://app.example.com")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("disallowed origin gets no CORS headers", func(t *testing.T) {
+		e := newServer(cfg)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://evil.example.com")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("preflight from allowed origin lists configured headers", func(t *testing.T) {
+		e := newServer(cfg)
+
+		req := httptest.NewRequest(http.MethodOptions, "/health", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://app.example.com")
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowHeaders), "x-user-id")
+	})
+
+	t.Run("empty origin list denies every origin", func(t *testing.T) {
+		e := newServer(handler.CORSConfig{})
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://app.example.com")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+}
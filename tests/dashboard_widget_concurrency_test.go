@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDashboardWidgetCheckConcurrency tests that GetDashboardResource checks child widgets concurrently,
+// never runs more than WidgetCheckConcurrency checks at once, and keeps the request order
+func TestDashboardWidgetCheckConcurrency(t *testing.T) {
+	req := model.GetDashboardResourceReq{ResourceID: "d1", ResourceType: "dashboard"}
+	for i := range 40 {
+		req.ChildResourceIDs = append(req.ChildResourceIDs, fmt.Sprintf("w%02d", i))
+	}
+
+	t.Run("bounded concurrent checks keep request order", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		svc := service.NewService(mockRepo, mockRepo)
+		svc.WidgetCheckConcurrency = 3
+
+		var inFlight, peak atomic.Int32
+		track := func(mock.Arguments) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			inFlight.Add(-1)
+		}
+
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil)
+		var expected []string
+		for i, widgetID := range req.ChildResourceIDs {
+			switch {
+			case i%2 == 1: // inherits from the dashboard
+				mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, widgetID, "dashboard_widget").Run(track).Return(int64(0), nil)
+				expected = append(expected, widgetID)
+			case i%4 == 0: // whitelisted and the caller is on it
+				mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, widgetID, "dashboard_widget").Run(track).Return(int64(2), nil)
+				mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", widgetID, "dashboard_widget", mock.Anything).Return(true, nil)
+				expected = append(expected, widgetID)
+			default: // whitelisted without the caller
+				mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, widgetID, "dashboard_widget").Run(track).Return(int64(2), nil)
+				mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", widgetID, "dashboard_widget", mock.Anything).Return(false, nil)
+			}
+		}
+
+		resp, err := svc.GetDashboardResource(context.Background(), "user_1", req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, resp.AccessibleWidgetIDs)
+		assert.LessOrEqual(t, peak.Load(), int32(3), "in-flight checks exceed the limit")
+		assert.Greater(t, peak.Load(), int32(1), "checks did not run concurrently")
+	})
+
+	t.Run("a failed check fails the request", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		svc := service.NewService(mockRepo, mockRepo)
+		svc.WidgetCheckConcurrency = 4
+
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w07", "dashboard_widget").Return(int64(0), errors.New("db error"))
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, mock.Anything, "dashboard_widget").Return(int64(0), nil)
+
+		_, err := svc.GetDashboardResource(context.Background(), "user_1", req)
+		assert.EqualError(t, err, "db error")
+	})
+}
@@ -42,8 +42,8 @@ func TestDashboardWidgetPermissions(t *testing.T) {
 		ctx := context.TODO()
 
 		// Scenario 1: Inherited Read (Widget has 0 roles) -> Checks Parent
-		mockRepo.On("CountResourceRoles", ctx, "widget1", "dashboard_widget").Return(int64(0), nil)
-		mockRepo.On("HasAnyResourceRole", ctx, "user1", "dashboard1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("CountResourceRoles", ctx, "", "widget1", "dashboard_widget").Return(int64(0), nil)
+		mockRepo.On("HasAnyResourceRole", ctx, "", "user1", "dashboard1", "dashboard", mock.Anything).Return(true, nil)
 
 		reqInherit := model.CheckPermissionReq{
 			Permission:       model.PermResourceDashboardWidgetRead,
@@ -58,8 +58,8 @@ func TestDashboardWidgetPermissions(t *testing.T) {
 		assert.True(t, allowed)
 
 		// Scenario 2: Whitelisted Read (Widget has roles) -> Checks Widget Strictly
-		mockRepo.On("CountResourceRoles", ctx, "widget2", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", ctx, "user2", "widget2", "dashboard_widget", mock.Anything).Return(false, nil)
+		mockRepo.On("CountResourceRoles", ctx, "", "widget2", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", ctx, "", "user2", "widget2", "dashboard_widget", mock.Anything).Return(false, nil)
 
 		reqWhitelistDeny := model.CheckPermissionReq{
 			Permission:       model.PermResourceDashboardWidgetRead,
@@ -74,8 +74,8 @@ func TestDashboardWidgetPermissions(t *testing.T) {
 		assert.False(t, allowedDeny)
 
 		// Scenario 3: Whitelisted Read (Widget has roles) -> Checks Widget Strictly (Allow)
-		mockRepo.On("CountResourceRoles", ctx, "widget3", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", ctx, "user3", "widget3", "dashboard_widget", mock.Anything).Return(true, nil)
+		mockRepo.On("CountResourceRoles", ctx, "", "widget3", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", ctx, "", "user3", "widget3", "dashboard_widget", mock.Anything).Return(true, nil)
 
 		reqWhitelistAllow := model.CheckPermissionReq{
 			Permission:       model.PermResourceDashboardWidgetRead,
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeletePurgeUser(t *testing.T) {
+	// API: DELETE /api/v1/users/{user_id}/purge (with middleware)
+	apiPath := "/api/v1/users/user_x/purge"
+
+	t.Run("purge user with system and resource roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("HardDeleteUserRole", mock.Anything, "user_x").Return(int64(3), nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "purge_user" && h.UserID == "user_x" && h.CallerID == "mod_1"
+		})).Return(nil)
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"roles_deleted":3}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("purge user without roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HardDeleteUserRole", mock.Anything, "user_x").Return(int64(0), nil)
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"roles_deleted":0}`, rec.Body.String())
+	})
+
+	t.Run("purge user without moderator role and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "HardDeleteUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("purge user unauthorized and return 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("purge user database failure and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HardDeleteUserRole", mock.Anything, "user_x").Return(int64(0), errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+}
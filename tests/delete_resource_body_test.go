@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestDeleteResourceBody tests strict body decoding and per-type validation of PUT /api/v1/resources/delete
+func TestDeleteResourceBody(t *testing.T) {
+	apiPath := "/api/v1/resources/delete"
+	headers := map[string]string{"x-user-id": "owner_1"}
+
+	badDashboardBodies := []struct {
+		name    string
+		payload map[string]interface{}
+		message string
+	}{
+		{
+			name:    "child_resource_ids as a string",
+			payload: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "child_resource_ids": "w1"},
+			message: "child_resource_ids must be an array of strings",
+		},
+		{
+			name:    "child_resource_ids with numeric entries",
+			payload: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "child_resource_ids": []interface{}{"w1", 2}},
+			message: "child_resource_ids must be an array of strings",
+		},
+		{
+			name:    "child_resource_ids with an empty entry",
+			payload: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "child_resource_ids": []string{"w1", "  "}},
+			message: "child_resource_ids must not contain empty values",
+		},
+		{
+			name:    "numeric parent_resource_id",
+			payload: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "parent_resource_id": 7},
+			message: "parent_resource_id must be a string",
+		},
+		{
+			name:    "unknown field",
+			payload: map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "child_ids": []string{"w1"}},
+			message: `unknown field \"child_ids\"`,
+		},
+	}
+
+	for _, tc := range badDashboardBodies {
+		t.Run("delete dashboard with "+tc.name+" and return 400", func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			e := SetupServerWithMiddleware(mockRepo)
+
+			mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+			rec := PerformRequest(e, http.MethodPut, apiPath, tc.payload, headers)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.message)
+			mockRepo.AssertNotCalled(t, "SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("delete with numeric resource_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"resource_id": 42, "resource_type": "dashboard"}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "resource_id")
+		mockRepo.AssertNotCalled(t, "SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete dashboard_widget with blank parent_resource_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"resource_id": "w1", "resource_type": "dashboard_widget", "parent_resource_id": " "}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "parent_resource_id is required")
+		mockRepo.AssertNotCalled(t, "SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete dashboard_widget with child_resource_ids and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"resource_id":        "w1",
+			"resource_type":      "dashboard_widget",
+			"parent_resource_id": "d1",
+			"child_resource_ids": []string{"w2"},
+		}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "child_resource_ids is only allowed for dashboard")
+		mockRepo.AssertNotCalled(t, "SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"rbac7/internal/rbac/handler"
 	"rbac7/internal/rbac/model"
 
 	"github.com/stretchr/testify/assert"
@@ -26,14 +27,14 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: check permission on dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: soft delete user roles for dashboard and child widgets
 		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
 			return req.ResourceID == "d1" &&
 				req.ResourceType == "dashboard" &&
 				len(req.ChildResourceIDs) == 2
-		}), "owner_1").Return(nil)
+		}), "owner_1").Return(int64(5), nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -42,6 +43,31 @@ func TestDeleteResource(t *testing.T) {
 		}
 		headers := map[string]string{"x-user-id": "owner_1"}
 
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success","deleted_count":5}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("delete dashboard in a namespace records the namespace in history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, "NS_A", "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
+			return req.ResourceID == "d1" && req.Namespace == "NS_A"
+		}), "owner_1").Return(int64(1), nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "delete_resource" && h.ResourceID == "d1" && h.Namespace == "NS_A"
+		})).Return(nil).Once()
+
+		payload := map[string]interface{}{
+			"namespace":     "ns_a",
+			"resource_id":   "d1",
+			"resource_type": "dashboard",
+		}
+		headers := map[string]string{"x-user-id": "owner_1"}
+
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
 		mockRepo.AssertExpectations(t)
@@ -51,13 +77,13 @@ func TestDeleteResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
 			return req.ResourceID == "d1" &&
 				req.ResourceType == "dashboard" &&
 				len(req.ChildResourceIDs) == 0
-		}), "owner_1").Return(nil)
+		}), "owner_1").Return(int64(1), nil)
 
 		payload := map[string]interface{}{
 			"resource_id":   "d1",
@@ -76,7 +102,7 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: no permission
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -114,14 +140,14 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: check permission on PARENT dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: soft delete user roles for widget
 		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
 			return req.ResourceID == "w1" &&
 				req.ResourceType == "dashboard_widget" &&
 				req.ParentResourceID == "d1"
-		}), "owner_1").Return(nil)
+		}), "owner_1").Return(int64(1), nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "w1",
@@ -140,7 +166,7 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: no permission on parent dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "w1",
@@ -179,14 +205,14 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: check system permission on namespace (uppercased)
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "admin_1", mock.Anything).Return(true, nil)
 
 		// Service: soft delete user roles for library widget (namespace uppercased by Validate())
 		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
 			return req.ResourceID == "lw1" &&
 				req.ResourceType == "library_widget" &&
 				req.Namespace == "NS1" // Uppercased by Validate()
-		}), "admin_1").Return(nil)
+		}), "admin_1").Return(int64(1), nil)
 
 		payload := map[string]interface{}{
 			"resource_id":   "lw1",
@@ -205,7 +231,7 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: no permission
-		mockRepo.On("HasAnySystemRole", mock.Anything, "user_1", "NS1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "user_1", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":   "lw1",
@@ -270,10 +296,10 @@ func TestDeleteResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission granted
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: returns error
-		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), assert.AnError)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -285,4 +311,43 @@ func TestDeleteResource(t *testing.T) {
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("delete dashboard with child_resource_ids at the maximum and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
+			return len(req.ChildResourceIDs) == handler.DefaultMaxChildResourceIDs
+		}), "owner_1").Return(int64(1), nil)
+
+		payload := map[string]interface{}{
+			"resource_id":        "d1",
+			"resource_type":      "dashboard",
+			"child_resource_ids": ChildResourceIDs(handler.DefaultMaxChildResourceIDs),
+		}
+		headers := map[string]string{"x-user-id": "owner_1"}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("delete dashboard with child_resource_ids over the maximum and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"resource_id":        "d1",
+			"resource_type":      "dashboard",
+			"child_resource_ids": ChildResourceIDs(handler.DefaultMaxChildResourceIDs + 1),
+		}
+		headers := map[string]string{"x-user-id": "owner_1"}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -18,18 +18,20 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: owner check
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
 		// Service: delete
-		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "r1", "dashboard", "", "caller").Return(nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "r1", "dashboard", "", "caller").Return(int64(1), nil)
 		// Service: cascade delete child widget roles
-		mockRepo.On("DeleteUserRolesByParent", mock.Anything, "u1", "r1", "dashboard_widget", "caller").Return(nil)
+		mockRepo.On("DeleteUserRolesByParent", mock.Anything, "", "u1", "r1", "dashboard_widget", "caller").Return(int64(2), nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=r1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
 		})
 		assert.Equal(t, http.StatusOK, rec.Code)
+		// The dashboard role plus the two cascaded widget roles
+		assert.JSONEq(t, `{"status":"success","deleted_count":3}`, rec.Body.String())
 	})
 
 	t.Run("delete resource user role missing user_id and return 400", func(t *testing.T) {
@@ -37,7 +39,7 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Middleware may pass, validation fails in handler
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?resource_id=r1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
@@ -49,7 +51,7 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
@@ -63,9 +65,9 @@ func TestDeleteResourceUserRole(t *testing.T) {
 
 		// Middleware may pass through when no matching config (missing resource_type)
 		// Handler validation will reject missing resource_type
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("DeleteUserRole", mock.Anything, mock.Anything).Return(nil).Maybe()
+		mockRepo.On("DeleteUserRole", mock.Anything, mock.Anything).Return(int64(1), nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=r1", nil, map[string]string{
 			"x-user-id": "caller",
@@ -87,7 +89,7 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=r1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
@@ -100,9 +102,9 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission granted
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: target is owner
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(true, nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=r1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
@@ -114,9 +116,9 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
-		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "r1", "dashboard", "", "caller").Return(errors.New("db error"))
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "r1", "dashboard", "", "caller").Return(int64(0), errors.New("db error"))
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=r1&resource_type=dashboard", nil, map[string]string{
 			"x-user-id": "caller",
@@ -131,11 +133,11 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: check permission on parent dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: owner check
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "w1", "dashboard_widget", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "w1", "dashboard_widget", model.RoleResourceOwner).Return(false, nil)
 		// Service: delete
-		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "w1", "dashboard_widget", "dash_1", "caller").Return(nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "w1", "dashboard_widget", "dash_1", "caller").Return(int64(1), nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=w1&resource_type=dashboard_widget&parent_resource_id=dash_1", nil, map[string]string{
 			"x-user-id": "caller",
@@ -161,7 +163,7 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied on parent dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=w1&resource_type=dashboard_widget&parent_resource_id=dash_1", nil, map[string]string{
 			"x-user-id": "caller",
@@ -176,11 +178,11 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check (system scope for library_widget)
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "caller", mock.Anything).Return(true, nil)
 		// Service: owner check (library_widget has no owner, so return false)
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "lw_1", "library_widget", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "lw_1", "library_widget", model.RoleResourceOwner).Return(false, nil)
 		// Service: delete with namespace
-		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u1", model.ScopeResource, "lw_1", "library_widget", "", "caller").Return(nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u1", model.ScopeResource, "lw_1", "library_widget", "", "caller").Return(int64(1), nil)
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=lw_1&resource_type=library_widget&namespace=NS_1", nil, map[string]string{
 			"x-user-id": "caller",
@@ -193,7 +195,7 @@ func TestDeleteResourceUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?user_id=u1&resource_id=lw_1&resource_type=library_widget", nil, map[string]string{
 			"x-user-id": "caller",
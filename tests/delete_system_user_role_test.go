@@ -19,14 +19,15 @@ func TestDeleteSystemUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		// Service: check if target is owner
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		// Service: delete
-		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(int64(1), nil)
 
 		rec := PerformRequest(e, http.MethodDelete, apiPath+"?namespace=NS_1&user_id=u_2", nil, map[string]string{"x-user-id": "owner_1"})
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success","deleted_count":1}`, rec.Body.String())
 	})
 
 	t.Run("remove system member missing user_id and return 400", func(t *testing.T) {
@@ -62,7 +63,7 @@ func TestDeleteSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 
 		ownerRole := &model.UserRole{UserID: "u_target", Role: model.RoleSystemOwner}
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(ownerRole, nil)
@@ -77,7 +78,7 @@ func TestDeleteSystemUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnySystemRole", mock.Anything, "u_common", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_common", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodDelete, apiPath+"?namespace=NS_1&user_id=u_2", nil, map[string]string{"x-user-id": "u_common"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
@@ -87,21 +88,22 @@ func TestDeleteSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
-		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(mongo.ErrNoDocuments)
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(int64(0), mongo.ErrNoDocuments)
 
 		rec := PerformRequest(e, http.MethodDelete, apiPath+"?namespace=NS_1&user_id=u_2", nil, map[string]string{"x-user-id": "owner_1"})
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success","deleted_count":0}`, rec.Body.String())
 	})
 
 	t.Run("remove system member internal error and return 500", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
-		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(errors.New("db error"))
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "owner_1").Return(int64(0), errors.New("db error"))
 
 		rec := PerformRequest(e, http.MethodDelete, apiPath+"?namespace=NS_1&user_id=u_2", nil, map[string]string{"x-user-id": "owner_1"})
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/handler"
+	"rbac7/internal/rbac/repository"
+	"rbac7/internal/rbac/service"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TestStatusForError tests the central service/repository error to HTTP status mapping
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{service.ErrUnauthorized, http.StatusUnauthorized},
+		{service.ErrForbidden, http.StatusForbidden},
+		{service.ErrConflict, http.StatusConflict},
+		{&service.OwnerExistsError{CurrentOwner: "u_1"}, http.StatusConflict},
+		{service.ErrNamespaceConflict, http.StatusConflict},
+		{repository.ErrRecentlyRemoved, http.StatusConflict},
+		{repository.ErrAlreadyHasRole, http.StatusConflict},
+		{service.ErrInvalidNamespace, http.StatusBadRequest},
+		{service.ErrBadRequest, http.StatusBadRequest},
+		{service.ErrResourceNotFound, http.StatusNotFound},
+		{service.ErrUserNotResolved, http.StatusNotFound},
+		{repository.ErrOwnerNotFound, http.StatusNotFound},
+		{mongo.ErrNoDocuments, http.StatusNotFound},
+		{repository.ErrTransactionContention, http.StatusTooManyRequests},
+		{repository.ErrTransactionTimeout, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.status, handler.StatusForError(tc.err))
+			assert.Equal(t, tc.status, handler.StatusForError(fmt.Errorf("wrapped: %w", tc.err)), "wrapped errors map like their sentinel")
+		})
+	}
+
+	t.Run("unknown errors map to 500", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, handler.StatusForError(errors.New("db error")))
+		assert.Equal(t, http.StatusInternalServerError, handler.StatusForError(repository.ErrDuplicate), "unmapped repository errors are internal")
+	})
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccessSnapshot(t *testing.T) {
+	// API: GET /api/v1/admin/users/{id}/access_snapshot (with middleware)
+	apiPath := "/api/v1/admin/users/user_x/access_snapshot"
+
+	t.Run("snapshot includes roles, derived permissions and flags an expiring grant and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		soon := time.Now().Add(48 * time.Hour)
+		later := time.Now().Add(60 * 24 * time.Hour)
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{UserID: "user_x"}).Return([]*model.UserRole{
+			{UserID: "user_x", UserType: "member", Role: model.RoleSystemViewer, Scope: model.ScopeSystem, Namespace: "NS_1"},
+			{UserID: "user_x", UserType: "member", Role: model.RoleResourceViewer, Scope: model.ScopeResource, ResourceID: "dash_1", ResourceType: "dashboard", ExpiresAt: &soon, Reason: "Q3 project"},
+			{UserID: "user_x", UserType: "member", Role: model.RoleResourceEditor, Scope: model.ScopeResource, ResourceID: "dash_2", ResourceType: "dashboard", ExpiresAt: &later},
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.AccessSnapshotResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "user_x", resp.UserID)
+		require.Len(t, resp.Grants, 3)
+
+		system := resp.Grants[0]
+		assert.Equal(t, "NS_1", system.Namespace)
+		assert.Contains(t, system.Permissions, model.PermPlatformSystemRead)
+		assert.Contains(t, system.Permissions, model.PermSystemResourceRead)
+		assert.NotContains(t, system.Permissions, model.PermSystemResourceDelete)
+		assert.False(t, system.Expiring)
+
+		expiring := resp.Grants[1]
+		assert.Equal(t, "dash_1", expiring.ResourceID)
+		assert.Contains(t, expiring.Permissions, model.PermResourceDashboardRead)
+		assert.NotContains(t, expiring.Permissions, model.PermResourceDashboardUpdate)
+		assert.True(t, expiring.Expiring)
+		assert.Equal(t, "Q3 project", expiring.Reason)
+
+		assert.Contains(t, resp.Grants[2].Permissions, model.PermResourceDashboardUpdate)
+		assert.False(t, resp.Grants[2].Expiring)
+		assert.Equal(t, 1, resp.ExpiringCount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("snapshot for user without roles returns 200 with empty grants", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"grants":[]`)
+	})
+
+	t.Run("snapshot without moderator role returns 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("snapshot repository error returns 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
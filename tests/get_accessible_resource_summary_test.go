@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAccessibleResourceSummary(t *testing.T) {
+	// API: GET /api/v1/resources/accessible/summary (with middleware)
+	apiPath := "/api/v1/resources/accessible/summary"
+
+	t.Run("get summary across two resource types and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountAccessibleResourcesByType", mock.Anything, "user_1").
+			Return(map[string]int64{"dashboard": 3, "library_widget": 2}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"counts":{"dashboard":3,"library_widget":2},"total":5}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get summary with no roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountAccessibleResourcesByType", mock.Anything, "user_2").Return(map[string]int64{}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "user_2"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"counts":{},"total":0}`, rec.Body.String())
+	})
+
+	t.Run("get summary unauthorized returns 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("get summary repository error returns 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountAccessibleResourcesByType", mock.Anything, "user_1").Return(nil, errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCapableUsers tests GET /api/v1/resources/capable_users
+// This API resolves a permission to roles, then lists the members holding those roles on the resource
+func TestGetCapableUsers(t *testing.T) {
+	headers := map[string]string{"x-user-id": "viewer_1"}
+
+	t.Run("owner and admin only permission excludes editors and viewers and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: caller can list dashboard members
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		// Service: one query for every granting role
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource && f.ResourceID == "d1" && f.ResourceType == "dashboard" &&
+				assert.ObjectsAreEqual([]string{"admin", "owner"}, f.Roles)
+		})).Return([]*model.UserRole{
+			{UserID: "owner_1", UserType: model.UserTypeMember, Role: "owner"},
+			{UserID: "admin_1", UserType: model.UserTypeMember, Role: "admin"},
+		}, nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/capable_users?resource_id=d1&resource_type=dashboard&permission=resource.dashboard.delete", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.GetCapableUsersResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"admin", "owner"}, resp.Roles)
+		assert.NotContains(t, resp.Roles, "editor")
+		assert.NotContains(t, resp.Roles, "viewer")
+		require.Len(t, resp.Users, 2)
+		assert.Equal(t, "admin_1", resp.Users[0].UserID)
+		assert.Equal(t, "owner_1", resp.Users[1].UserID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("permission granted by no role skips the query and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/capable_users?resource_id=d1&resource_type=dashboard&permission=resource.dashboard.fly", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"permission":"resource.dashboard.fly","roles":[],"users":[],"truncated":false}`, rec.Body.String())
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("caller without get_member permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/capable_users?resource_id=d1&resource_type=dashboard&permission=resource.dashboard.delete", nil, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing permission and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/capable_users?resource_id=d1&resource_type=dashboard", nil, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"testing"
 
+	"rbac7/internal/rbac/handler"
 	"rbac7/internal/rbac/model"
 
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ func TestGetDashboardResource(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: check permission on dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: get dashboard user roles
 		dashboardRoles := []*model.UserRole{
@@ -52,7 +53,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		dashboardRoles := []*model.UserRole{
 			{UserID: "owner_1", Role: "owner", ResourceID: "d1", ResourceType: "dashboard", Scope: "resource"},
@@ -62,8 +63,8 @@ func TestGetDashboardResource(t *testing.T) {
 		})).Return(dashboardRoles, nil)
 
 		// Widgets have 0 roles (inherit from parent)
-		mockRepo.On("CountResourceRoles", mock.Anything, "w1", "dashboard_widget").Return(int64(0), nil)
-		mockRepo.On("CountResourceRoles", mock.Anything, "w2", "dashboard_widget").Return(int64(0), nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(0), nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w2", "dashboard_widget").Return(int64(0), nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -83,7 +84,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		dashboardRoles := []*model.UserRole{
 			{UserID: "owner_1", Role: "owner", ResourceID: "d1", ResourceType: "dashboard", Scope: "resource"},
@@ -93,13 +94,13 @@ func TestGetDashboardResource(t *testing.T) {
 		})).Return(dashboardRoles, nil)
 
 		// w1: 0 roles (inherit) -> accessible
-		mockRepo.On("CountResourceRoles", mock.Anything, "w1", "dashboard_widget").Return(int64(0), nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(0), nil)
 		// w2: has roles, caller IS in whitelist -> accessible
-		mockRepo.On("CountResourceRoles", mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(true, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(true, nil)
 		// w3: has roles, caller NOT in whitelist -> NOT accessible
-		mockRepo.On("CountResourceRoles", mock.Anything, "w3", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w3", "dashboard_widget", mock.Anything).Return(false, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w3", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w3", "dashboard_widget", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -120,7 +121,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		dashboardRoles := []*model.UserRole{
 			{UserID: "owner_1", Role: "owner", ResourceID: "d1", ResourceType: "dashboard", Scope: "resource"},
@@ -130,10 +131,10 @@ func TestGetDashboardResource(t *testing.T) {
 		})).Return(dashboardRoles, nil)
 
 		// All widgets have roles, caller NOT in whitelist
-		mockRepo.On("CountResourceRoles", mock.Anything, "w1", "dashboard_widget").Return(int64(2), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(false, nil)
-		mockRepo.On("CountResourceRoles", mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(false, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(2), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(false, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -153,7 +154,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		dashboardRoles := []*model.UserRole{
 			{UserID: "owner_1", Role: "owner", ResourceID: "d1", ResourceType: "dashboard", Scope: "resource"},
@@ -163,10 +164,10 @@ func TestGetDashboardResource(t *testing.T) {
 		})).Return(dashboardRoles, nil)
 
 		// All widgets have roles, caller IS in whitelist for all
-		mockRepo.On("CountResourceRoles", mock.Anything, "w1", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(true, nil)
-		mockRepo.On("CountResourceRoles", mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(true, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(true, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w2", "dashboard_widget").Return(int64(1), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w2", "dashboard_widget", mock.Anything).Return(true, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -190,7 +191,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil)
 
 		payload := map[string]interface{}{
 			"resource_id":   "d1",
@@ -257,7 +258,7 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 
 		payload := map[string]interface{}{
@@ -274,13 +275,13 @@ func TestGetDashboardResource(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		dashboardRoles := []*model.UserRole{
 			{UserID: "owner_1", Role: "owner", ResourceID: "d1", ResourceType: "dashboard", Scope: "resource"},
 		}
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(dashboardRoles, nil)
-		mockRepo.On("CountResourceRoles", mock.Anything, "w1", "dashboard_widget").Return(int64(0), errors.New("db error"))
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(0), errors.New("db error"))
 
 		payload := map[string]interface{}{
 			"resource_id":        "d1",
@@ -292,4 +293,84 @@ func TestGetDashboardResource(t *testing.T) {
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("TC12: child_resource_ids at the maximum and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil)
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, mock.Anything, "dashboard_widget").Return(int64(0), nil)
+
+		payload := map[string]interface{}{
+			"resource_id":        "d1",
+			"resource_type":      "dashboard",
+			"child_resource_ids": ChildResourceIDs(handler.DefaultMaxChildResourceIDs),
+		}
+		headers := map[string]string{"x-user-id": "user_1"}
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("TC13: child_resource_ids over the maximum and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"resource_id":        "d1",
+			"resource_type":      "dashboard",
+			"child_resource_ids": ChildResourceIDs(handler.DefaultMaxChildResourceIDs + 1),
+		}
+		headers := map[string]string{"x-user-id": "user_1"}
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "child_resource_ids exceeds the maximum of 500")
+		mockRepo.AssertNotCalled(t, "CountResourceRoles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("TC14: namespaced resources look up dashboard roles in the namespace and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithNamespacedResources(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, "NS_A", "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Namespace == "NS_A" && f.ResourceID == "d1" && f.ResourceType == "dashboard"
+		})).Return([]*model.UserRole{}, nil)
+
+		payload := map[string]interface{}{
+			"namespace":     "ns_a",
+			"resource_id":   "d1",
+			"resource_type": "dashboard",
+		}
+		headers := map[string]string{"x-user-id": "user_1"}
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("TC15: default mode ignores the namespace for dashboard roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, "NS_A", "user_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Namespace == "" && f.ResourceID == "d1"
+		})).Return([]*model.UserRole{}, nil)
+
+		payload := map[string]interface{}{
+			"namespace":     "ns_a",
+			"resource_id":   "d1",
+			"resource_type": "dashboard",
+		}
+		headers := map[string]string{"x-user-id": "user_1"}
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetPermissionRoles tests GET /api/v1/permissions/:permission/roles
+// This API lists the roles granting a permission, straight from the policy role maps
+func TestGetPermissionRoles(t *testing.T) {
+	headers := map[string]string{"x-user-id": "user_1"}
+
+	roleNames := func(resp model.GetPermissionRolesResp, scope string) []string {
+		var names []string
+		for _, r := range resp.Roles {
+			if r.Scope == scope {
+				names = append(names, r.Role)
+			}
+		}
+		return names
+	}
+
+	t.Run("resource.dashboard.delete granted by owner and admin but not viewer and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/permissions/resource.dashboard.delete/roles?scope=resource", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.GetPermissionRolesResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "resource.dashboard.delete", resp.Permission)
+		roles := roleNames(resp, model.ScopeResource)
+		assert.Contains(t, roles, "owner")
+		assert.Contains(t, roles, "admin")
+		assert.NotContains(t, roles, "viewer")
+		assert.Empty(t, roleNames(resp, model.ScopeSystem))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("system permission without scope lists system roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/permissions/platform.system.add_member/roles", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.GetPermissionRolesResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Contains(t, roleNames(resp, model.ScopeSystem), "owner")
+		assert.NotContains(t, roleNames(resp, model.ScopeSystem), "viewer")
+	})
+
+	t.Run("unknown permission returns no roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/permissions/resource.dashboard.fly/roles", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"permission":"resource.dashboard.fly","roles":[]}`, rec.Body.String())
+	})
+
+	t.Run("invalid scope and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/permissions/resource.dashboard.delete/roles?scope=global", nil, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("missing x-user-id and return 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/permissions/resource.dashboard.delete/roles", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetReady tests GET /ready
+// This probe passes only when the role collections are queryable and indexed, not merely when Mongo answers
+func TestGetReady(t *testing.T) {
+	apiPath := "/ready"
+
+	t.Run("healthy repository and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("RepositoryHealth", mock.Anything).Return(nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing index and return 503", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		unhealthy := fmt.Errorf("%w: user_roles is missing index unique_system_owner_v2", repository.ErrUnhealthy)
+		mockRepo.On("RepositoryHealth", mock.Anything).Return(unhealthy).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, nil)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"status":"not_ready","error":"repository is not ready: user_roles is missing index unique_system_owner_v2"}`, rec.Body.String())
+	})
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetResourceOwner tests GET /api/v1/resources/owner
+func TestGetResourceOwner(t *testing.T) {
+	headers := map[string]string{"x-user-id": "viewer_1"}
+
+	t.Run("get owner of a dashboard and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: caller can list dashboard members
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "d1", "dashboard").Return(&model.UserRole{
+			UserID: "owner_1", UserType: model.UserTypeMember, Role: "owner", Scope: model.ScopeResource, ResourceID: "d1", ResourceType: "dashboard",
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=d1&resource_type=dashboard", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var owner model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &owner))
+		assert.Equal(t, "owner_1", owner.UserID)
+		assert.Equal(t, "owner", owner.Role)
+	})
+
+	t.Run("get owner of a dashboard without one and return 200 null", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "d1", "dashboard").Return(nil, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=d1&resource_type=dashboard", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `null`, rec.Body.String())
+	})
+
+	t.Run("get owner without get_member permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=d1&resource_type=dashboard", nil, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("get owner without resource_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_type=dashboard", nil, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("get owner of a resource type without owners and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=w1&resource_type=library_widget", nil, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("get owner unauthorized and return 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=d1&resource_type=dashboard", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("get owner internal error and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "d1", "dashboard").Return(nil, errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/owner?resource_id=d1&resource_type=dashboard", nil, headers)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
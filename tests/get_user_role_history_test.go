@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"testing"
@@ -21,7 +22,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedHistory := []*model.UserRoleHistory{
 			{ID: "h_1", Operation: "assign_owner", CallerID: "admin_1", Scope: "system", Namespace: "NS_1", CreatedAt: time.Now()},
@@ -46,7 +47,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 
 		expectedHistory := []*model.UserRoleHistory{
 			{ID: "h_2", Operation: "assign_user_role", CallerID: "admin_1", Scope: "resource", ResourceID: "dash_1", ResourceType: "dashboard", CreatedAt: time.Now()},
@@ -70,7 +71,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedHistory := []*model.UserRoleHistory{
 			{ID: "h_3", Operation: "delete_user_role", CallerID: "admin_1", Scope: "system", Namespace: "NS_1", CreatedAt: time.Now()},
@@ -93,12 +94,101 @@ func TestGetUserRoleHistory(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), "\"total_count\":100")
 	})
 
+	t.Run("get history mid-list page derives navigation fields", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.Anything).Return([]*model.UserRoleHistory{}, int64(120), nil)
+
+		for _, tc := range []struct {
+			page             string
+			hasNext, hasPrev bool
+		}{
+			{page: "2", hasNext: true, hasPrev: true},
+			{page: "3", hasNext: false, hasPrev: true},
+		} {
+			params := url.Values{}
+			params.Add("scope", "system")
+			params.Add("namespace", "NS_1")
+			params.Add("page", tc.page)
+			params.Add("size", "50")
+			path := apiPath + "?" + params.Encode()
+
+			rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp model.GetUserRoleHistoryResp
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, int64(120), resp.TotalCount)
+			assert.Equal(t, 3, resp.TotalPages, "page %s", tc.page)
+			assert.Equal(t, tc.hasNext, resp.HasNext, "page %s", tc.page)
+			assert.Equal(t, tc.hasPrev, resp.HasPrev, "page %s", tc.page)
+		}
+	})
+
+	t.Run("get history over-max size is clamped and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.Page == 1 && req.Size == model.MaxPageSize
+		})).Return([]*model.UserRoleHistory{}, int64(0), nil).Once()
+
+		path := apiPath + "?scope=system&namespace=NS_1&size=5000"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"size\":1000")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get history size above MAX_PAGE_SIZE is clamped to it and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxPageSize(mockRepo, 200)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.Size == 200
+		})).Return([]*model.UserRoleHistory{}, int64(0), nil).Once()
+
+		path := apiPath + "?scope=system&namespace=NS_1&size=500"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get history malformed page or size and return 400", func(t *testing.T) {
+		for _, tc := range []struct {
+			query, message string
+		}{
+			{query: "page=abc", message: "page must be an integer"},
+			{query: "size=ten", message: "size must be an integer"},
+			{query: "page=1.5", message: "page must be an integer"},
+			{query: "page=-1", message: "page must be at least 1"},
+			{query: "size=-5", message: "size must be at least 1"},
+			{query: "page=0", message: "page must be at least 1"},
+			{query: "size=0", message: "size must be at least 1"},
+		} {
+			mockRepo := new(MockRBACRepository)
+			e := SetupServerWithMiddleware(mockRepo)
+
+			mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+			path := apiPath + "?scope=system&namespace=NS_1&" + tc.query
+			rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+			assert.Equal(t, http.StatusBadRequest, rec.Code, tc.query)
+			assert.Contains(t, rec.Body.String(), tc.message, tc.query)
+			mockRepo.AssertNotCalled(t, "FindHistory", mock.Anything, mock.Anything)
+		}
+	})
+
 	t.Run("get history missing scope returns 400", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "admin_1"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -119,7 +209,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		path := apiPath + "?scope=resource&resource_type=dashboard"
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
@@ -139,7 +229,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "viewer_1", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(false, nil)
 
 		path := apiPath + "?scope=system&namespace=NS_1"
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "viewer_1"})
@@ -150,7 +240,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "viewer_1", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "dash_1", "dashboard", mock.Anything).Return(false, nil)
 
 		path := apiPath + "?scope=resource&resource_id=dash_1&resource_type=dashboard"
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "viewer_1"})
@@ -162,7 +252,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: dashboard_widget uses parent_resource check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 
 		expectedHistory := []*model.UserRoleHistory{
 			{ID: "h_4", Operation: "assign_viewer", CallerID: "admin_1", Scope: "resource", ResourceID: "widget_1", ResourceType: "dashboard_widget", CreatedAt: time.Now()},
@@ -187,7 +277,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		params := url.Values{}
 		params.Add("scope", "resource")
@@ -204,7 +294,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: library_widget uses system scope check (namespace)
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedHistory := []*model.UserRoleHistory{
 			{ID: "h_6", Operation: "assign_viewers_batch", CallerID: "admin_1", Scope: "resource", ResourceID: "lw_1", ResourceType: "library_widget", CreatedAt: time.Now()},
@@ -229,7 +319,7 @@ func TestGetUserRoleHistory(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "viewer_1", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(false, nil)
 
 		params := url.Values{}
 		params.Add("scope", "resource")
@@ -241,4 +331,196 @@ func TestGetUserRoleHistory(t *testing.T) {
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "viewer_1"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
 	})
+
+	t.Run("get history filtered by target user success and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware read_audit check only
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil).Once()
+
+		expectedHistory := []*model.UserRoleHistory{
+			{ID: "h_7", Operation: "assign_user_role", CallerID: "admin_1", Scope: "system", Namespace: "NS_1", UserID: "user_1", CreatedAt: time.Now()},
+		}
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.Scope == "system" && req.Namespace == "NS_1" && req.TargetUserID == "user_1"
+		})).Return(expectedHistory, int64(1), nil)
+
+		path := apiPath + "?scope=system&namespace=NS_1&target_user_id=user_1"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"user_id\":\"user_1\"")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get own history by target user and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "dash_1", "dashboard", mock.Anything).Return(true, nil).Once()
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.TargetUserID == "user_1"
+		})).Return([]*model.UserRoleHistory{}, int64(0), nil)
+
+		path := apiPath + "?scope=resource&resource_id=dash_1&resource_type=dashboard&target_user_id=user_1"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNumberOfCalls(t, "HasAnyResourceRole", 1)
+	})
+}
+
+func TestGetUserRoleHistoryAuditor(t *testing.T) {
+	// An auditor holds platform.system.read_audit but not platform.system.get_member
+	isAuditorRoles := func(roles []string) bool {
+		for _, r := range roles {
+			if r == model.RoleSystemAuditor {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("auditor reads system history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "auditor_1", mock.MatchedBy(isAuditorRoles)).Return(true, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.Anything).Return([]*model.UserRoleHistory{}, int64(0), nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles/logs?scope=system&namespace=NS_1", nil, map[string]string{"x-user-id": "auditor_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("auditor filters system history by another user and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "auditor_1", mock.MatchedBy(isAuditorRoles)).Return(true, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.TargetUserID == "user_1"
+		})).Return([]*model.UserRoleHistory{}, int64(0), nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles/logs?scope=system&namespace=NS_1&target_user_id=user_1", nil, map[string]string{"x-user-id": "auditor_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("auditor lists system members and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "auditor_1", mock.MatchedBy(isAuditorRoles)).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "auditor_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles?scope=system&namespace=NS_1", nil, map[string]string{"x-user-id": "auditor_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetUserRoleHistoryECS(t *testing.T) {
+	// API: GET /api/v1/user_roles/logs?format=ecs (with middleware)
+	apiPath := "/api/v1/user_roles/logs"
+
+	t.Run("export assign_owner as ecs event and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+
+		createdAt := time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC)
+		expectedHistory := []*model.UserRoleHistory{
+			{ID: "h_1", Operation: "assign_owner", CallerID: "admin_1", Scope: "resource", Namespace: "NS_1", ResourceID: "dash_1", ResourceType: "dashboard", UserID: "owner_1", UserType: "user", Role: "owner", CreatedAt: createdAt},
+		}
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.Format == model.HistoryFormatECS
+		})).Return(expectedHistory, int64(1), nil)
+
+		path := apiPath + "?scope=resource&resource_id=dash_1&resource_type=dashboard&format=ECS"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Data       []map[string]any `json:"data"`
+			TotalCount int64            `json:"total_count"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, int64(1), resp.TotalCount)
+		if assert.Len(t, resp.Data, 1) {
+			event := resp.Data[0]
+			assert.Equal(t, "2025-03-01T08:30:00Z", event["@timestamp"])
+			assert.Equal(t, map[string]any{"version": model.ECSVersion}, event["ecs"])
+			assert.Equal(t, map[string]any{
+				"id":       "h_1",
+				"kind":     "event",
+				"category": []any{"iam"},
+				"type":     []any{"change"},
+				"action":   "assign_owner",
+				"outcome":  "success",
+				"provider": "rbac",
+				"dataset":  "rbac.user_role_history",
+			}, event["event"])
+			assert.Equal(t, map[string]any{
+				"id":     "admin_1",
+				"target": map[string]any{"id": "owner_1", "roles": []any{"owner"}},
+			}, event["user"])
+			assert.Equal(t, map[string]any{"user": []any{"admin_1", "owner_1"}}, event["related"])
+			assert.Equal(t, map[string]any{
+				"scope":         "resource",
+				"namespace":     "NS_1",
+				"resource_id":   "dash_1",
+				"resource_type": "dashboard",
+				"user_type":     "user",
+			}, event["rbac"])
+			assert.NotContains(t, event, "operation")
+		}
+	})
+
+	t.Run("export delete_user_role as ecs deletion and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		expectedHistory := []*model.UserRoleHistory{
+			{ID: "h_2", Operation: "delete_user_role", CallerID: "admin_1", Scope: "system", Namespace: "NS_1", UserID: "user_1", Role: "viewer", CreatedAt: time.Now()},
+		}
+		mockRepo.On("FindHistory", mock.Anything, mock.Anything).Return(expectedHistory, int64(1), nil)
+
+		path := apiPath + "?scope=system&namespace=NS_1&format=ecs"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"type\":[\"deletion\"]")
+	})
+
+	t.Run("native json stays the default and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		expectedHistory := []*model.UserRoleHistory{
+			{ID: "h_3", Operation: "assign_owner", CallerID: "admin_1", Scope: "system", Namespace: "NS_1", CreatedAt: time.Now()},
+		}
+		mockRepo.On("FindHistory", mock.Anything, mock.Anything).Return(expectedHistory, int64(1), nil)
+
+		path := apiPath + "?scope=system&namespace=NS_1"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"operation\":\"assign_owner\"")
+		assert.NotContains(t, rec.Body.String(), "@timestamp")
+	})
+
+	t.Run("unknown format and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		path := apiPath + "?scope=system&namespace=NS_1&format=cef"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindHistory", mock.Anything, mock.Anything)
+	})
 }
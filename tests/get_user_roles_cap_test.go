@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/handler"
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// seedRoles returns n system viewer roles in NS_1
+func seedRoles(n int) []*model.UserRole {
+	roles := make([]*model.UserRole, 0, n)
+	for i := 1; i <= n; i++ {
+		roles = append(roles, &model.UserRole{UserID: fmt.Sprintf("u_%d", i), Role: "viewer", Namespace: "NS_1", Scope: model.ScopeSystem})
+	}
+	return roles
+}
+
+// limitedTo matches a listing filter asking the repository for at most limit roles
+func limitedTo(limit int64) interface{} {
+	return mock.MatchedBy(func(f model.UserRoleFilter) bool { return f.Limit == limit })
+}
+
+func TestUnpagedRoleCap(t *testing.T) {
+	path := "/api/v1/user_roles?scope=system&namespace=NS_1"
+	seeded := seedRoles(10)
+
+	t.Run("listing more roles than the cap is truncated and flagged and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		// The repository honors the limit, which is the cap plus one
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(4)).Return(seeded[:4], nil)
+
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get(handler.HeaderResultTruncated))
+
+		var roles []*model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		require.Len(t, roles, 3)
+		assert.Equal(t, "u_3", roles[2].UserID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("listing exactly the cap is not flagged and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(4)).Return(seeded[:3], nil)
+
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(handler.HeaderResultTruncated))
+
+		var roles []*model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		assert.Len(t, roles, 3)
+	})
+
+	t.Run("a zero cap lists every role and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 0)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(0)).Return(seeded, nil)
+
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(handler.HeaderResultTruncated))
+
+		var roles []*model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		assert.Len(t, roles, 10)
+	})
+
+	t.Run("my roles beyond the cap are truncated and flagged and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		mine := seedRoles(4)
+		for _, role := range mine {
+			role.UserID = "u_me"
+		}
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(4)).Return(mine, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles/me?scope=system", nil, map[string]string{"x-user-id": "u_me"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get(handler.HeaderResultTruncated))
+
+		var roles []*model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		assert.Len(t, roles, 3)
+	})
+	t.Run("inherited members count toward the cap and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource && f.Limit == 4
+		})).Return([]*model.UserRole{
+			{UserID: "u_1", Role: "viewer", Scope: model.ScopeResource, ResourceID: "d1", ResourceType: "dashboard"},
+			{UserID: "u_2", Role: "viewer", Scope: model.ScopeResource, ResourceID: "d1", ResourceType: "dashboard"},
+		}, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeSystem && f.Limit == 4
+		})).Return(seedRoles(2), nil)
+
+		path := "/api/v1/user_roles?scope=resource&resource_id=d1&resource_type=dashboard&namespace=NS_1&include_inherited=true"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get(handler.HeaderResultTruncated))
+
+		var roles []*model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		require.Len(t, roles, 3)
+		assert.True(t, roles[2].Inherited)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("capable users beyond the cap are truncated and flagged and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(4)).Return(seedRoles(4), nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/resources/capable_users?resource_id=d1&resource_type=dashboard&permission=resource.dashboard.read", nil, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.GetCapableUsersResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Users, 3)
+		assert.True(t, resp.Truncated)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sync beyond the cap returns the last change as cursor and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMaxUnpagedRoles(mockRepo, 3)
+
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		changed := seedRoles(4)
+		for i, role := range changed {
+			role.UpdatedAt = since.Add(time.Duration(i+1) * time.Minute)
+		}
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, limitedTo(4)).Return(changed, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles/sync?modified_since="+since.Format(time.RFC3339), nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.SyncUserRolesResp
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 3)
+		assert.True(t, resp.Truncated)
+		// The fourth change is left for the next sync from the cursor
+		assert.True(t, resp.Cursor.Equal(changed[2].UpdatedAt))
+		mockRepo.AssertExpectations(t)
+	})
+}
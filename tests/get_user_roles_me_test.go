@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestGetUserRolesMe(t *testing.T) {
@@ -170,4 +171,69 @@ func TestGetUserRolesMe(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), "u_correct")
 		assert.NotContains(t, rec.Body.String(), "u_other")
 	})
+
+	t.Run("first sign-in activates pending owner roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithPendingOwners(mockRepo)
+
+		// Activation runs before the listing, so the owner role is already returned as member
+		pending := &model.UserRole{UserID: "invitee", UserType: model.UserTypePending, Role: "owner", Namespace: "NS_1", Scope: "system"}
+		mockRepo.On("FindPendingRoles", mock.Anything, "invitee").Return([]*model.UserRole{pending}, nil).Once()
+		mockRepo.On("ActivatePendingRole", mock.Anything, pending).Return(nil).Once()
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "activate_pending_owner" && h.UserID == "invitee" && h.Scope == "system" && h.Namespace == "NS_1" && h.Role == "owner"
+		})).Return(nil).Once()
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.UserID == "invitee"
+		})).Return([]*model.UserRole{
+			{UserID: "invitee", UserType: model.UserTypeMember, Role: "owner", Namespace: "NS_1", Scope: "system"},
+		}, nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system", nil, map[string]string{"x-user-id": "invitee"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"user_type":"member"`)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sign-in without pending roles writes nothing and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithPendingOwners(mockRepo)
+
+		mockRepo.On("FindPendingRoles", mock.Anything, "u_1").Return([]*model.UserRole{}, nil).Once()
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_1", UserType: model.UserTypeMember, Role: "viewer", Namespace: "NS_1", Scope: "system"},
+		}, nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system", nil, map[string]string{"x-user-id": "u_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "ActivatePendingRole", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("role activated by a concurrent request is skipped and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithPendingOwners(mockRepo)
+
+		pending := &model.UserRole{UserID: "invitee", UserType: model.UserTypePending, Role: "owner", Namespace: "NS_1", Scope: "system"}
+		mockRepo.On("FindPendingRoles", mock.Anything, "invitee").Return([]*model.UserRole{pending}, nil).Once()
+		mockRepo.On("ActivatePendingRole", mock.Anything, pending).Return(mongo.ErrNoDocuments).Once()
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "invitee", UserType: model.UserTypeMember, Role: "owner", Namespace: "NS_1", Scope: "system"},
+		}, nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system", nil, map[string]string{"x-user-id": "invitee"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("activation failure and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithPendingOwners(mockRepo)
+
+		mockRepo.On("FindPendingRoles", mock.Anything, "invitee").Return(nil, errors.New("db error")).Once()
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system", nil, map[string]string{"x-user-id": "invitee"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
 }
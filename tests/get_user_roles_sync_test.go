@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserRolesSync(t *testing.T) {
+	// API: GET /api/v1/user_roles/sync (with middleware)
+	apiPath := "/api/v1/user_roles/sync"
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("sync returns changed and deleted roles with cursor and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		deletedAt := since.Add(3 * time.Hour)
+		changed := []*model.UserRole{
+			{UserID: "u1", Role: "admin", Scope: "system", Namespace: "NS_1", UpdatedAt: since.Add(time.Hour)},
+			{UserID: "u2", Role: "viewer", Scope: "system", Namespace: "NS_1", UpdatedAt: since.Add(-time.Hour), DeletedAt: &deletedAt},
+		}
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.ModifiedSince != nil && f.ModifiedSince.Equal(since) && f.Scope == "system" && f.Namespace == "NS_1"
+		})).Return(changed, nil)
+
+		params := url.Values{}
+		params.Add("modified_since", since.Format(time.RFC3339))
+		params.Add("scope", "system")
+		params.Add("namespace", "ns_1")
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?"+params.Encode(), nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.SyncUserRolesResp
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 2)
+		assert.NotNil(t, resp.Data[1].DeletedAt)
+		// Cursor is the latest change, here the deletion
+		assert.True(t, resp.Cursor.Equal(deletedAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sync without changes keeps cursor at modified_since and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?modified_since="+since.Format(time.RFC3339), nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.SyncUserRolesResp
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotNil(t, resp.Data)
+		assert.Empty(t, resp.Data)
+		assert.True(t, resp.Cursor.Equal(since))
+	})
+
+	t.Run("sync missing modified_since and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system", nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sync invalid modified_since and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?modified_since=yesterday", nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("sync without moderator role and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?modified_since="+since.Format(time.RFC3339), nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("sync internal error and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=resource&modified_since="+since.Format(time.RFC3339), nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
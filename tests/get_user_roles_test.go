@@ -1,15 +1,19 @@
 package tests
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/policy"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetUserRolesList(t *testing.T) {
@@ -21,7 +25,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", Namespace: "NS_1", Scope: "system"},
@@ -44,7 +48,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", ResourceID: "r1", ResourceType: "dashboard", Scope: "resource"},
@@ -68,7 +72,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_TARGET", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_TARGET", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_target", Role: "admin", Namespace: "NS_TARGET"},
@@ -87,7 +91,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "r2", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "r2", "dashboard", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_2", Role: "admin", ResourceID: "r2", ResourceType: "dashboard"},
@@ -109,7 +113,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "d_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d_1", "dashboard", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", ResourceID: "dw_1", ResourceType: "dashboard_widget", Scope: "resource"},
@@ -135,7 +139,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", ResourceID: "lw_1", ResourceType: "library_widget", Scope: "resource"},
@@ -162,7 +166,7 @@ func TestGetUserRolesList(t *testing.T) {
 
 		// Middleware may pass, validation fails in handler
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "admin_1"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -183,7 +187,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Middleware may pass through when no matching config, validation fails in handler
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil).Maybe()
 
@@ -207,7 +211,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Middleware passes through for validation failure cases
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil).Maybe()
 
@@ -222,7 +226,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Middleware passes through for validation failure cases
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil).Maybe()
 
@@ -246,7 +250,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnySystemRole", mock.Anything, "u_no_perm", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_no_perm", mock.Anything).Return(false, nil)
 
 		path := apiPath + "?scope=system&namespace=NS_1"
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "u_no_perm"})
@@ -258,7 +262,7 @@ func TestGetUserRolesList(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied (use 'dashboard' to match config)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "u_no", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_no", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		path := apiPath + "?scope=resource&resource_id=r1&resource_type=dashboard"
 		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "u_no"})
@@ -269,7 +273,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 
 		path := apiPath + "?scope=system&namespace=NS_1"
@@ -281,7 +285,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", Namespace: "NS_1"},
@@ -300,7 +304,7 @@ func TestGetUserRolesList(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
 		expectedRoles := []*model.UserRole{
 			{UserID: "u_1", Role: "viewer", ResourceID: "r1", ResourceType: "dashboard"},
 		}
@@ -311,4 +315,220 @@ func TestGetUserRolesList(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Contains(t, rec.Body.String(), "dashboard")
 	})
+
+	t.Run("list members with include_audit includes who granted and last changed each role and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		createdAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		updatedAt := time.Date(2026, 9, 15, 17, 30, 0, 0, time.UTC)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_1", Role: "viewer", Namespace: "NS_1", Scope: "system", CreatedAt: createdAt, UpdatedAt: updatedAt, CreatedBy: "owner_1", UpdatedBy: "admin_2"},
+		}, nil)
+
+		params := url.Values{}
+		params.Add("scope", "system")
+		params.Add("namespace", "NS_1")
+		params.Add("include_audit", "true")
+		path := apiPath + "?" + params.Encode()
+
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		if assert.Len(t, roles, 1) {
+			assert.Equal(t, "owner_1", roles[0]["created_by"])
+			assert.Equal(t, "admin_2", roles[0]["updated_by"])
+			assert.Equal(t, "2026-03-01T09:00:00Z", roles[0]["created_at"])
+			assert.Equal(t, "2026-09-15T17:30:00Z", roles[0]["updated_at"])
+		}
+	})
+
+	t.Run("list members omits audit fields by default and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_1", Role: "viewer", Namespace: "NS_1", Scope: "system", CreatedAt: time.Now(), UpdatedAt: time.Now(), CreatedBy: "owner_1", UpdatedBy: "admin_2"},
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system&namespace=NS_1", nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		if assert.Len(t, roles, 1) {
+			assert.Equal(t, "u_1", roles[0]["user_id"])
+			for _, field := range []string{"created_at", "updated_at", "created_by", "updated_by"} {
+				assert.NotContains(t, roles[0], field)
+			}
+		}
+	})
+
+	t.Run("non-boolean include_audit and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system&namespace=NS_1&include_audit=yes", nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("expand permissions adds each member's role permissions and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+		engine, err := policy.NewEngine()
+		require.NoError(t, err)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_admin", Role: "admin", Namespace: "NS_1", Scope: "system"},
+			{UserID: "u_viewer", Role: "viewer", Namespace: "NS_1", Scope: "system"},
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system&namespace=NS_1&expand=permissions", nil, map[string]string{"x-user-id": "admin_1"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		require.Len(t, roles, 2)
+		assert.Equal(t, engine.GetRolePermissions("admin", true), roles[0].Permissions)
+		assert.Contains(t, roles[0].Permissions, "platform.system.add_member")
+		assert.Equal(t, []string{"platform.system.read", "system.resource.read"}, roles[1].Permissions)
+	})
+
+	t.Run("expand permissions on resource members uses resource roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+		engine, err := policy.NewEngine()
+		require.NoError(t, err)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_editor", Role: "editor", ResourceID: "r1", ResourceType: "dashboard", Scope: "resource"},
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=resource&resource_id=r1&resource_type=dashboard&expand=permissions", nil, map[string]string{"x-user-id": "admin_1"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []model.UserRole
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		assert.NotEmpty(t, roles[0].Permissions)
+		assert.Equal(t, engine.GetRolePermissions("editor", false), roles[0].Permissions)
+	})
+
+	t.Run("permissions are not expanded by default and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{
+			{UserID: "u_admin", Role: "admin", Namespace: "NS_1", Scope: "system"},
+		}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system&namespace=NS_1", nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "permissions")
+	})
+
+	t.Run("unknown expand value and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath+"?scope=system&namespace=NS_1&expand=history", nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetUserRolesInherited(t *testing.T) {
+	apiPath := "/api/v1/user_roles"
+	direct := func() []*model.UserRole {
+		return []*model.UserRole{{UserID: "u_1", Role: "viewer", Scope: model.ScopeResource, Namespace: "NS_1", ResourceID: "d1", ResourceType: "dashboard"}}
+	}
+	isInheritedQuery := func(f model.UserRoleFilter) bool {
+		return f.Scope == model.ScopeSystem && f.Namespace == "NS_1" && assert.ObjectsAreEqual(model.InheritedSystemRoles, f.Roles)
+	}
+
+	t.Run("include_inherited adds flagged namespace owners and admins and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource && f.ResourceID == "d1"
+		})).Return(direct(), nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(isInheritedQuery)).Return([]*model.UserRole{
+			{UserID: "ns_owner", Role: "owner", Scope: model.ScopeSystem, Namespace: "NS_1"},
+			{UserID: "ns_admin", Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_1"},
+		}, nil)
+
+		path := apiPath + "?scope=resource&resource_id=d1&resource_type=dashboard&namespace=NS_1&include_inherited=true"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		require.Len(t, roles, 3)
+		assert.Equal(t, "u_1", roles[0]["user_id"])
+		assert.NotContains(t, roles[0], "inherited", "direct members are not flagged")
+		for _, role := range roles[1:] {
+			assert.Equal(t, true, role["inherited"])
+			assert.Equal(t, model.ScopeSystem, role["scope"])
+		}
+		assert.Equal(t, "ns_owner", roles[1]["user_id"])
+		assert.Equal(t, "ns_admin", roles[2]["user_id"])
+	})
+
+	t.Run("inherited members are left out by default and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource
+		})).Return(direct(), nil)
+
+		path := apiPath + "?scope=resource&resource_id=d1&resource_type=dashboard&namespace=NS_1"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var roles []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &roles))
+		assert.Len(t, roles, 1)
+		mockRepo.AssertNumberOfCalls(t, "FindUserRoles", 1)
+	})
+
+	t.Run("role filter outside the inherited roles skips the namespace query and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource && f.Role == "viewer"
+		})).Return(direct(), nil)
+
+		path := apiPath + "?scope=resource&resource_id=d1&resource_type=dashboard&namespace=NS_1&role=viewer&include_inherited=true"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNumberOfCalls(t, "FindUserRoles", 1)
+	})
+
+	t.Run("include_inherited without namespace and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		path := apiPath + "?scope=resource&resource_id=d1&resource_type=dashboard&include_inherited=true"
+		rec := PerformRequest(e, http.MethodGet, path, nil, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "include_inherited requires resource scope and a namespace")
+	})
 }
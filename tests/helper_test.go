@@ -2,12 +2,14 @@ package tests
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
 	"strings"
 
 	"rbac7/internal/rbac/handler"
 	"rbac7/internal/rbac/router"
 	"rbac7/internal/rbac/service"
+	"rbac7/internal/rbac/testhook"
 
 	"github.com/labstack/echo/v4"
 )
@@ -27,12 +29,140 @@ func SetupServerWithMiddleware(mockRepo *MockRBACRepository) *echo.Echo {
 	svc := service.NewService(mockRepo, mockRepo)
 	h := handler.NewSystemHandler(svc)
 
-	// Create RBAC middleware
-	policyLoader := svc.Policy.GetLoader()
-	apiConfigs := policyLoader.LoadAPIConfigs(svc.Policy.GetEntityPolicies())
-
 	// Register routes with middleware
-	router.RegisterRoutes(e, h, svc.Policy, mockRepo, apiConfigs)
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithNotifier is SetupServerWithMiddleware with a grant notifier wired into the service
+func SetupServerWithNotifier(mockRepo *MockRBACRepository, notifier service.Notifier) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.Notifier = notifier
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithUserResolver is SetupServerWithMiddleware with a user resolver wired into the service
+func SetupServerWithUserResolver(mockRepo *MockRBACRepository, resolver service.UserResolver) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.UserResolver = resolver
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithMaxPageSize is SetupServerWithMiddleware with the paginated endpoints' size capped at maxPageSize
+func SetupServerWithMaxPageSize(mockRepo *MockRBACRepository, maxPageSize int) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	h := handler.NewSystemHandler(svc)
+	h.MaxPageSize = maxPageSize
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithMaxUnpagedRoles is SetupServerWithMiddleware with unpaginated listings capped at maxRoles
+func SetupServerWithMaxUnpagedRoles(mockRepo *MockRBACRepository, maxRoles int) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.MaxUnpagedRoles = maxRoles
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithFoldResourceIDCase is SetupServerWithMiddleware with resource ID case folding switched on or off
+func SetupServerWithFoldResourceIDCase(mockRepo *MockRBACRepository, fold bool) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	h := handler.NewSystemHandler(svc)
+	h.FoldResourceIDCase = fold
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithNamespacedResources is SetupServerWithMiddleware with resource lookups keyed on namespace
+func SetupServerWithNamespacedResources(mockRepo *MockRBACRepository) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.NamespacedResources = true
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithSuperadmins is SetupServerWithMiddleware with superadmin user IDs that bypass permission checks
+func SetupServerWithSuperadmins(mockRepo *MockRBACRepository, userIDs ...string) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.Policy.SetSuperadmins(userIDs)
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithPendingOwners is SetupServerWithMiddleware with pending owner activation enabled
+func SetupServerWithPendingOwners(mockRepo *MockRBACRepository) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.PendingOwners = true
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithMultipleOwners is SetupServerWithMiddleware with system namespace co-owners allowed
+func SetupServerWithMultipleOwners(mockRepo *MockRBACRepository) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.AllowMultipleOwners = true
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
+
+	return e
+}
+
+// SetupServerWithResolver is SetupServerWithMiddleware with role checks answered by resolver before the mock
+func SetupServerWithResolver(mockRepo *MockRBACRepository, resolver testhook.PermissionResolver) *echo.Echo {
+	e := echo.New()
+	repo := testhook.Wrap(mockRepo, resolver)
+	svc := service.NewService(repo, mockRepo)
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, repo)
+
+	return e
+}
+
+// SetupServerWithDebugDenials is SetupServerWithMiddleware with RBAC debug details in middleware errors
+func SetupServerWithDebugDenials(mockRepo *MockRBACRepository) *echo.Echo {
+	e := echo.New()
+	svc := service.NewService(mockRepo, mockRepo)
+	svc.Policy.SetDebugDenials(true)
+	h := handler.NewSystemHandler(svc)
+
+	router.RegisterRoutes(e, h, svc.Policy, mockRepo)
 
 	return e
 }
@@ -66,3 +196,12 @@ func PerformRequest(e *echo.Echo, method, path string, body interface{}, headers
 	e.ServeHTTP(rec, req)
 	return rec
 }
+
+// ChildResourceIDs returns n distinct widget IDs (w1..wn)
+func ChildResourceIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("w%d", i+1)
+	}
+	return ids
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/handler"
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestHistoryTransaction tests that owner assign/transfer and resource delete write history
+// in the same transaction as the role change, so a failed write leaves no history behind
+func TestHistoryTransaction(t *testing.T) {
+	t.Run("transfer resource owner records history with the transfer and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "transfer_owner" && h.UserID == "caller" && h.NewOwnerID == "u_new" && !h.CreatedAt.IsZero()
+		})).Return(nil).Once()
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer resource owner failure leaves no history and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(errors.New("db error"))
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer system owner failure leaves no history and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}, nil)
+		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "owner_1").Return(errors.New("db error"))
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/owner", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("assign resource owner duplicate leaves no history and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CreateUserRole", mock.Anything, mock.Anything).Return(repository.ErrDuplicate)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(&model.UserRole{UserID: "other"}, nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		payload := map[string]string{"resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete resource failure leaves no history and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.Anything, "owner_1").Return(int64(0), errors.New("db error"))
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		payload := map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/resources/delete", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("history insert failure fails the transfer and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(errors.New("history write failed"))
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("transfer exhausting transaction retries and return 429", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").
+			Return(fmt.Errorf("%w: gave up after 4 attempts: write conflict", repository.ErrTransactionContention))
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, handler.ContentionRetryAfter, rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "too_many_requests")
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer exceeding the transaction timeout and return 503", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").
+			Return(fmt.Errorf("%w: exceeded 10s: context deadline exceeded", repository.ErrTransactionTimeout))
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "service_unavailable")
+	})
+}
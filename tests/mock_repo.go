@@ -3,6 +3,8 @@ package tests
 import (
 	"context"
 	"rbac7/internal/rbac/model"
+	"sync"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -10,6 +12,7 @@ import (
 // MockRBACRepository is a shared mock implementation of repository.RBACRepository for testing.
 type MockRBACRepository struct {
 	mock.Mock
+	txMu sync.Mutex // serializes WithHistory like a transaction on the same document
 }
 
 func (m *MockRBACRepository) GetSystemOwner(ctx context.Context, namespace string) (*model.UserRole, error) {
@@ -20,18 +23,26 @@ func (m *MockRBACRepository) GetSystemOwner(ctx context.Context, namespace strin
 	return args.Get(0).(*model.UserRole), args.Error(1)
 }
 
+func (m *MockRBACRepository) GetResourceOwner(ctx context.Context, namespace, resourceID, resourceType string) (*model.UserRole, error) {
+	args := m.Called(ctx, namespace, resourceID, resourceType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserRole), args.Error(1)
+}
+
 func (m *MockRBACRepository) CreateUserRole(ctx context.Context, role *model.UserRole) error {
 	args := m.Called(ctx, role)
 	return args.Error(0)
 }
 
-func (m *MockRBACRepository) HasSystemRole(ctx context.Context, userID, namespace, role string) (bool, error) {
-	args := m.Called(ctx, userID, namespace, role)
+func (m *MockRBACRepository) HasSystemRole(ctx context.Context, namespace, userID, role string) (bool, error) {
+	args := m.Called(ctx, namespace, userID, role)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRBACRepository) HasAnySystemRole(ctx context.Context, userID, namespace string, roles []string) (bool, error) {
-	args := m.Called(ctx, userID, namespace, roles)
+func (m *MockRBACRepository) HasAnySystemRole(ctx context.Context, namespace, userID string, roles []string) (bool, error) {
+	args := m.Called(ctx, namespace, userID, roles)
 	return args.Bool(0), args.Error(1)
 }
 
@@ -48,6 +59,24 @@ func (m *MockRBACRepository) EnsureIndexes(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockRBACRepository) FindPendingRoles(ctx context.Context, userID string) ([]*model.UserRole, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.UserRole), args.Error(1)
+}
+
+func (m *MockRBACRepository) ActivatePendingRole(ctx context.Context, role *model.UserRole) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRBACRepository) RepositoryHealth(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockRBACRepository) TransferSystemOwner(ctx context.Context, namespace, oldOwnerID, newOwnerID, updatedBy string) error {
 	args := m.Called(ctx, namespace, oldOwnerID, newOwnerID, updatedBy)
 	return args.Error(0)
@@ -58,38 +87,53 @@ func (m *MockRBACRepository) UpsertUserRole(ctx context.Context, role *model.Use
 	return args.Error(0)
 }
 
-func (m *MockRBACRepository) DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) error {
+func (m *MockRBACRepository) DeleteUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy string) (int64, error) {
 	args := m.Called(ctx, namespace, userID, scope, resourceID, resourceType, parentResourceID, deletedBy)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRBACRepository) RestoreUserRole(ctx context.Context, namespace, userID, scope, resourceID, resourceType string) error {
+	args := m.Called(ctx, namespace, userID, scope, resourceID, resourceType)
+	return args.Error(0)
+}
+
+func (m *MockRBACRepository) DemoteSystemOwner(ctx context.Context, role *model.UserRole) error {
+	args := m.Called(ctx, role)
 	return args.Error(0)
 }
 
+func (m *MockRBACRepository) DeleteSystemOwner(ctx context.Context, namespace, userID, deletedBy string) (int64, error) {
+	args := m.Called(ctx, namespace, userID, deletedBy)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockRBACRepository) CountSystemOwners(ctx context.Context, namespace string) (int64, error) {
 	args := m.Called(ctx, namespace)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockRBACRepository) HasResourceRole(ctx context.Context, userID, resourceID, resourceType, role string) (bool, error) {
-	args := m.Called(ctx, userID, resourceID, resourceType, role)
+func (m *MockRBACRepository) HasResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType, role string) (bool, error) {
+	args := m.Called(ctx, namespace, userID, resourceID, resourceType, role)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRBACRepository) HasAnyResourceRole(ctx context.Context, userID, resourceID, resourceType string, roles []string) (bool, error) {
-	args := m.Called(ctx, userID, resourceID, resourceType, roles)
+func (m *MockRBACRepository) HasAnyResourceRole(ctx context.Context, namespace, userID, resourceID, resourceType string, roles []string) (bool, error) {
+	args := m.Called(ctx, namespace, userID, resourceID, resourceType, roles)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRBACRepository) TransferResourceOwner(ctx context.Context, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error {
-	args := m.Called(ctx, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy)
+func (m *MockRBACRepository) TransferResourceOwner(ctx context.Context, namespace, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy string) error {
+	args := m.Called(ctx, namespace, resourceID, resourceType, oldOwnerID, newOwnerID, updatedBy)
 	return args.Error(0)
 }
 
-func (m *MockRBACRepository) CountResourceOwners(ctx context.Context, resourceID, resourceType string) (int64, error) {
-	args := m.Called(ctx, resourceID, resourceType)
+func (m *MockRBACRepository) CountResourceOwners(ctx context.Context, namespace, resourceID, resourceType string) (int64, error) {
+	args := m.Called(ctx, namespace, resourceID, resourceType)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockRBACRepository) CountResourceRoles(ctx context.Context, resourceID, resourceType string) (int64, error) {
-	args := m.Called(ctx, resourceID, resourceType)
+func (m *MockRBACRepository) CountResourceRoles(ctx context.Context, namespace, resourceID, resourceType string) (int64, error) {
+	args := m.Called(ctx, namespace, resourceID, resourceType)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -101,14 +145,14 @@ func (m *MockRBACRepository) BulkUpsertUserRoles(ctx context.Context, roles []*m
 	return args.Get(0).(*model.BatchUpsertResult), args.Error(1)
 }
 
-func (m *MockRBACRepository) SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) error {
+func (m *MockRBACRepository) SoftDeleteResourceUserRoles(ctx context.Context, req *model.SoftDeleteResourceReq, deletedBy string) (int64, error) {
 	args := m.Called(ctx, req, deletedBy)
-	return args.Error(0)
+	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockRBACRepository) DeleteUserRolesByParent(ctx context.Context, userID, parentResourceID, resourceType, deletedBy string) error {
-	args := m.Called(ctx, userID, parentResourceID, resourceType, deletedBy)
-	return args.Error(0)
+func (m *MockRBACRepository) DeleteUserRolesByParent(ctx context.Context, namespace, userID, parentResourceID, resourceType, deletedBy string) (int64, error) {
+	args := m.Called(ctx, namespace, userID, parentResourceID, resourceType, deletedBy)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 // HistoryRepository mock methods
@@ -127,6 +171,36 @@ func (m *MockRBACRepository) CreateHistory(ctx context.Context, history *model.U
 	return nil // Default: succeed silently for fire-and-forget calls
 }
 
+func (m *MockRBACRepository) GetNamespaceResourceRoles(ctx context.Context, namespace string) (*model.NamespaceResourceRoles, error) {
+	args := m.Called(ctx, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NamespaceResourceRoles), args.Error(1)
+}
+
+func (m *MockRBACRepository) SetNamespaceResourceRoles(ctx context.Context, override *model.NamespaceResourceRoles) error {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *MockRBACRepository) DeleteNamespaceResourceRoles(ctx context.Context, namespace string) error {
+	args := m.Called(ctx, namespace)
+	return args.Error(0)
+}
+
+// WithHistory runs write then CreateHistory under a lock, mirroring the transactional ordering
+func (m *MockRBACRepository) WithHistory(ctx context.Context, history *model.UserRoleHistory, write func(ctx context.Context) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+	if err := write(ctx); err != nil {
+		return err
+	}
+	entry := *history
+	entry.CreatedAt = time.Now()
+	return m.CreateHistory(ctx, &entry)
+}
+
 func (m *MockRBACRepository) FindHistory(ctx context.Context, req model.GetUserRoleHistoryReq) ([]*model.UserRoleHistory, int64, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -139,3 +213,45 @@ func (m *MockRBACRepository) EnsureHistoryIndexes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
+
+func (m *MockRBACRepository) EraseUser(ctx context.Context, userID, tombstone, callerID string) (*model.EraseUserResult, error) {
+	args := m.Called(ctx, userID, tombstone, callerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EraseUserResult), args.Error(1)
+}
+
+func (m *MockRBACRepository) ReassignOwnedResources(ctx context.Context, oldOwnerID, newOwnerID, updatedBy string) (int64, error) {
+	args := m.Called(ctx, oldOwnerID, newOwnerID, updatedBy)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRBACRepository) HardDeleteUserRole(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRBACRepository) CountAccessibleResourcesByType(ctx context.Context, userID string) (map[string]int64, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockRBACRepository) RenameNamespace(ctx context.Context, from, to, updatedBy string) (*model.RenameNamespaceResult, error) {
+	args := m.Called(ctx, from, to, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RenameNamespaceResult), args.Error(1)
+}
+
+func (m *MockRBACRepository) RestoreNamespace(ctx context.Context, namespace string, snapshot *model.NamespaceSnapshot, replace bool, updatedBy string) (*model.RestoreNamespaceResult, error) {
+	args := m.Called(ctx, namespace, snapshot, replace, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RestoreNamespaceResult), args.Error(1)
+}
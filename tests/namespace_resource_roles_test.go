@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNamespaceResourceRoles(t *testing.T) {
+	// API: /api/v1/namespaces/:namespace/resource_roles (with middleware)
+	apiPath := "/api/v1/namespaces/NS_1/resource_roles"
+
+	t.Run("get default resource roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(nil, nil)
+
+		rec := PerformRequest(e, http.MethodGet, apiPath, nil, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.NamespaceResourceRolesResp
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Equal(t, "NS_1", resp.Namespace)
+		assert.Equal(t, []string{"admin", "editor", "viewer"}, resp.Roles)
+		assert.False(t, resp.Overridden)
+	})
+
+	t.Run("get overridden resource roles and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").
+			Return(&model.NamespaceResourceRoles{Namespace: "NS_1", Roles: []string{"admin", "viewer"}}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/namespaces/ns_1/resource_roles", nil, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.NamespaceResourceRolesResp
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Equal(t, []string{"admin", "viewer"}, resp.Roles)
+		assert.True(t, resp.Overridden)
+	})
+
+	t.Run("set resource roles success and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("SetNamespaceResourceRoles", mock.Anything, mock.MatchedBy(func(o *model.NamespaceResourceRoles) bool {
+			return o.Namespace == "NS_1" && len(o.Roles) == 2 && o.Roles[0] == "admin" && o.Roles[1] == "viewer" && o.UpdatedBy == "owner_1"
+		})).Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "set_namespace_resource_roles" && h.Scope == "system" && h.Namespace == "NS_1" && h.CallerID == "owner_1"
+		})).Return(nil)
+
+		body := map[string]interface{}{"roles": []string{"Admin", " viewer", "admin"}}
+		rec := PerformRequest(e, http.MethodPut, apiPath, body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("set resource roles outside the global default and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"roles": []string{"viewer", "owner"}}
+		rec := PerformRequest(e, http.MethodPut, apiPath, body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "SetNamespaceResourceRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("set resource roles empty list and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{"roles": []string{}}
+		rec := PerformRequest(e, http.MethodPut, apiPath, body, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("set resource roles without update permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(false, nil)
+
+		body := map[string]interface{}{"roles": []string{"viewer"}}
+		rec := PerformRequest(e, http.MethodPut, apiPath, body, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("reset resource roles success and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("DeleteNamespaceResourceRoles", mock.Anything, "NS_1").Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "reset_namespace_resource_roles" && h.Scope == "system" && h.Namespace == "NS_1" && h.CallerID == "owner_1"
+		})).Return(nil)
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reset resource roles internal error and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("DeleteNamespaceResourceRoles", mock.Anything, "NS_1").Return(errors.New("db error"))
+
+		rec := PerformRequest(e, http.MethodDelete, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestAssignResourceUserRoleNamespaceOverride(t *testing.T) {
+	// API: POST /api/v1/user_roles/resources (with middleware)
+	apiPath := "/api/v1/user_roles/resources"
+	noEditor := &model.NamespaceResourceRoles{Namespace: "NS_1", Roles: []string{"admin", "viewer"}}
+
+	t.Run("assign editor in namespace that forbids editor and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(noEditor, nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard", Namespace: "ns_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("assign editor without namespace uses global default and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.Role == "editor"
+		})).Return(nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetNamespaceResourceRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("assign editor in namespace without override and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_2").Return(nil, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard", Namespace: "NS_2"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("assign viewer in namespace that forbids editor and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(noEditor, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "viewer", ResourceID: "r1", ResourceType: "dashboard", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("batch assign editor in namespace that forbids editor and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(noEditor, nil)
+
+		payload := model.AssignResourceUserRolesReq{UserIDs: []string{"u1", "u2"}, Role: "editor", ResourceID: "r1", ResourceType: "dashboard", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath+"/batch", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "BulkUpsertUserRoles", mock.Anything, mock.Anything)
+	})
+}
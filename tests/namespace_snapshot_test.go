@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNamespaceSnapshot tests GET /api/v1/namespaces/{namespace}/snapshot and POST /api/v1/namespaces/{namespace}/restore
+// Moderators export a namespace's roles and apply them to the same or another namespace (tenant cloning, recovery)
+func TestNamespaceSnapshot(t *testing.T) {
+	headers := map[string]string{"x-user-id": "mod_1"}
+	sourceRoles := func() []*model.UserRole {
+		return []*model.UserRole{
+			{UserID: "u_owner", UserType: "member", Role: "owner", Scope: model.ScopeSystem, Namespace: "NS_SRC"},
+			{UserID: "u_admin", UserType: "member", Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_SRC"},
+			{UserID: "u_viewer", UserType: "member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_SRC"},
+			{UserID: "u_owner", UserType: "member", Role: "owner", Scope: model.ScopeResource, Namespace: "NS_SRC",
+				ResourceID: "lw_1", ResourceType: model.ResourceTypeLibraryWidget},
+		}
+	}
+
+	t.Run("snapshot then restore into a fresh namespace keeps owner and members and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{Namespace: "NS_SRC"}).Return(sourceRoles(), nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_SRC").
+			Return(&model.NamespaceResourceRoles{Namespace: "NS_SRC", Roles: []string{"editor", "viewer"}}, nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/namespaces/ns_src/snapshot", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var snapshot map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+		assert.EqualValues(t, model.NamespaceSnapshotVersion, snapshot["version"])
+		assert.Equal(t, "NS_SRC", snapshot["namespace"])
+		assert.Len(t, snapshot["roles"], 4)
+		assert.NotContains(t, snapshot, "history")
+		mockRepo.AssertNotCalled(t, "FindHistory", mock.Anything, mock.Anything)
+
+		// Restore the exported document as is
+		mockRepo.On("RestoreNamespace", mock.Anything, "NS_NEW", mock.MatchedBy(func(s *model.NamespaceSnapshot) bool {
+			got := map[string]string{}
+			for _, role := range s.Roles {
+				if role.Namespace != "NS_NEW" {
+					return false
+				}
+				got[role.Scope+":"+role.UserID+":"+role.ResourceID] = role.Role
+			}
+			return assert.ObjectsAreEqual(map[string]string{
+				"system:u_owner:":       "owner",
+				"system:u_admin:":       "admin",
+				"system:u_viewer:":      "viewer",
+				"resource:u_owner:lw_1": "owner",
+			}, got) && assert.ObjectsAreEqual([]string{"editor", "viewer"}, s.ResourceRoles)
+		}), false, "mod_1").Return(&model.RestoreNamespaceResult{Namespace: "NS_NEW", RolesRestored: 4, ResourceRolesRestored: true}, nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "restore_namespace" && h.Namespace == "NS_NEW" && h.PreviousNamespace == "NS_SRC"
+		})).Return(nil).Once()
+
+		rec = PerformRequest(e, http.MethodPost, "/api/v1/namespaces/ns_new/restore", map[string]interface{}{"snapshot": snapshot}, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"namespace":"NS_NEW","mode":"merge","roles_restored":4,"roles_removed":0,"resource_roles_restored":true}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("snapshot with history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(sourceRoles(), nil)
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_SRC").Return(nil, nil)
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.Scope == model.ScopeSystem && req.Namespace == "NS_SRC" && req.Page == 1
+		})).Return([]*model.UserRoleHistory{{Operation: "assign_owner", Namespace: "NS_SRC", UserID: "u_owner"}}, int64(1), nil).Once()
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/namespaces/NS_SRC/snapshot?include_history=true", nil, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var snapshot model.NamespaceSnapshot
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+		require.Len(t, snapshot.History, 1)
+		assert.Equal(t, "assign_owner", snapshot.History[0].Operation)
+		assert.Nil(t, snapshot.ResourceRoles)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore replace mode and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreNamespace", mock.Anything, "NS_SRC", mock.Anything, true, "mod_1").
+			Return(&model.RestoreNamespaceResult{Namespace: "NS_SRC", RolesRestored: 4, RolesRemoved: 6}, nil)
+
+		payload := map[string]interface{}{
+			"mode":     "replace",
+			"snapshot": model.NamespaceSnapshot{Version: model.NamespaceSnapshotVersion, Namespace: "NS_SRC", Roles: sourceRoles()},
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/namespaces/NS_SRC/restore", payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"roles_removed":6`)
+		assert.Contains(t, rec.Body.String(), `"mode":"replace"`)
+	})
+
+	t.Run("restore onto a namespace with another owner and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreNamespace", mock.Anything, "NS_TAKEN", mock.Anything, false, "mod_1").Return(nil, repository.ErrDuplicate)
+
+		payload := map[string]interface{}{"snapshot": model.NamespaceSnapshot{Version: model.NamespaceSnapshotVersion, Roles: sourceRoles()}}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/namespaces/NS_TAKEN/restore", payload, headers)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("restore invalid snapshots and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+
+		tests := map[string]interface{}{
+			"unsupported version":            map[string]interface{}{"snapshot": map[string]interface{}{"version": 2, "roles": []interface{}{}}},
+			"unknown mode":                   map[string]interface{}{"mode": "overwrite", "snapshot": map[string]interface{}{"version": 1}},
+			"invalid role":                   map[string]interface{}{"snapshot": map[string]interface{}{"version": 1, "roles": []interface{}{map[string]interface{}{"user_id": "u1", "scope": "system", "role": "moderator"}}}},
+			"resource role without resource": map[string]interface{}{"snapshot": map[string]interface{}{"version": 1, "roles": []interface{}{map[string]interface{}{"user_id": "u1", "scope": "resource", "role": "viewer"}}}},
+		}
+		for name, payload := range tests {
+			rec := PerformRequest(e, http.MethodPost, "/api/v1/namespaces/NS_NEW/restore", payload, headers)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+		}
+		mockRepo.AssertNotCalled(t, "RestoreNamespace", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("snapshot and restore without moderator role and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+		ownerHeaders := map[string]string{"x-user-id": "owner_1"}
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/namespaces/NS_SRC/snapshot", nil, ownerHeaders)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		payload := map[string]interface{}{"snapshot": model.NamespaceSnapshot{Version: model.NamespaceSnapshotVersion}}
+		rec = PerformRequest(e, http.MethodPost, "/api/v1/namespaces/NS_SRC/restore", payload, ownerHeaders)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "RestoreNamespace", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/testhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestPermissionOverride tests that an injected PermissionResolver drives the RBAC middleware without role data
+func TestPermissionOverride(t *testing.T) {
+	apiPath := "/api/v1/user_roles/batch"
+	resolver := testhook.UserRoles{
+		"tooling_admin":  {model.RoleSystemAdmin},
+		"tooling_viewer": {model.RoleSystemViewer},
+	}
+	reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", Namespace: "NS_1"}
+
+	t.Run("hook is enabled in test binaries", func(t *testing.T) {
+		assert.True(t, testhook.Enabled())
+	})
+
+	t.Run("resolver grants admin and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithResolver(mockRepo, resolver)
+
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(&model.BatchUpsertResult{SuccessCount: 1}, nil).Once()
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "tooling_admin"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resolver grants only viewer and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithResolver(mockRepo, resolver)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "tooling_viewer"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "BulkUpsertUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("users unknown to the resolver fall through to the repository and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithResolver(mockRepo, resolver)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_other", mock.Anything).Return(false, nil).Once()
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "u_other"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
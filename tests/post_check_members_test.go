@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostCheckMembers tests POST /api/v1/user_roles/members/check
+// This API returns which of the given users already hold a role, so invitation flows can skip them
+func TestPostCheckMembers(t *testing.T) {
+	apiPath := "/api/v1/user_roles/members/check"
+	headers := map[string]string{"x-user-id": "admin_1"}
+
+	t.Run("system scope returns the existing members and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: caller can list namespace members
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		// Service: one $in query; soft-deleted roles are excluded by the repository, so u_removed is absent
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeSystem && f.Namespace == "NS_1" && f.UserID == "" &&
+				assert.ObjectsAreEqual([]string{"u_member", "u_stranger", "u_removed", "u_admin"}, f.UserIDs)
+		})).Return([]*model.UserRole{
+			{UserID: "u_admin", Role: "admin", Scope: model.ScopeSystem, Namespace: "NS_1"},
+			{UserID: "u_member", Role: "viewer", Scope: model.ScopeSystem, Namespace: "NS_1"},
+		}, nil).Once()
+
+		payload := map[string]interface{}{
+			"scope":     "system",
+			"namespace": "ns_1",
+			"user_ids":  []string{"u_member", "u_stranger", "u_removed", "u_admin", "u_member"},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"members":["u_member","u_admin"]}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("resource scope with no members returns an empty list and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.MatchedBy(func(f model.UserRoleFilter) bool {
+			return f.Scope == model.ScopeResource && f.ResourceID == "d1" && f.ResourceType == "dashboard"
+		})).Return([]*model.UserRole{}, nil).Once()
+
+		payload := map[string]interface{}{"scope": "resource", "resource_id": "d1", "resource_type": "dashboard", "user_ids": []string{"u_stranger"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"members":[]}`, rec.Body.String())
+	})
+
+	t.Run("caller without get_member permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(false, nil)
+
+		payload := map[string]interface{}{"scope": "resource", "resource_id": "d1", "resource_type": "dashboard", "user_ids": []string{"u_member"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty user_ids and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "NS_1", "user_ids": []string{" "}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostEraseUser(t *testing.T) {
+	// API: POST /api/v1/admin/users/{id}/erase (with middleware)
+	apiPath := "/api/v1/admin/users/user_x/erase"
+
+	t.Run("erase user success and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		mockRepo.On("EraseUser", mock.Anything, "user_x", mock.MatchedBy(func(tombstone string) bool {
+			return strings.HasPrefix(tombstone, "erased_") && !strings.Contains(tombstone, "user_x")
+		}), "mod_1").Return(&model.EraseUserResult{Tombstone: "erased_1", RolesDeleted: 3, RolesAnonymized: 1, HistoryAnonymized: 5}, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"roles_deleted\":3")
+		assert.Contains(t, rec.Body.String(), "\"history_anonymized\":5")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("erase user without moderator role returns 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "EraseUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("erase user unauthorized returns 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("erase user transaction failure returns 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("EraseUser", mock.Anything, "user_x", mock.Anything, "mod_1").Return(nil, errors.New("transaction aborted"))
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostMyResourceRoles(t *testing.T) {
+	// API: POST /api/v1/user_roles/resources/my_roles (with middleware)
+	apiPath := "/api/v1/user_roles/resources/my_roles"
+
+	t.Run("get roles for a subset of dashboards and null for the rest and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			UserID:       "user_1",
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1", "dash_2", "dash_3"},
+		}).Return([]*model.UserRole{
+			{UserID: "user_1", Role: model.RoleResourceOwner, ResourceID: "dash_1", ResourceType: "dashboard"},
+			{UserID: "user_1", Role: model.RoleResourceViewer, ResourceID: "dash_3", ResourceType: "dashboard"},
+		}, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1", " dash_2 ", "dash_3", "dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"roles":{"dash_1":"owner","dash_2":null,"dash_3":"viewer"}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get roles in a namespace when resources are namespaced and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithNamespacedResources(mockRepo)
+
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			UserID:       "user_1",
+			Namespace:    "NS_A",
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1", "dash_2"},
+		}).Return([]*model.UserRole{
+			{UserID: "user_1", Role: model.RoleResourceEditor, ResourceID: "dash_2", ResourceType: "dashboard", Namespace: "NS_A"},
+		}, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1", "dash_2"}, "namespace": " ns_a "}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"roles":{"dash_1":null,"dash_2":"editor"}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get roles ignores namespace when resources are not namespaced and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			UserID:       "user_1",
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1"},
+		}).Return([]*model.UserRole{}, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}, "namespace": "NS_A"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get roles when caller has none and return 200 with all null", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1", "dash_2"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_2"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"roles":{"dash_1":null,"dash_2":null}}`, rec.Body.String())
+	})
+
+	t.Run("get roles without resource_ids returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{" "}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("get roles without resource_type returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"resource_ids": []string{"dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("get roles unauthorized returns 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("get roles repository error returns 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "user_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestPostPermissionsCheckBatch tests POST /api/v1/permissions/check/batch
+// This API runs many permission checks of the caller in one round trip, keyed by caller-supplied IDs
+func TestPostPermissionsCheckBatch(t *testing.T) {
+	apiPath := "/api/v1/permissions/check/batch"
+	headers := map[string]string{"x-user-id": "user_1"}
+
+	widget := func(id, widgetID string) map[string]string {
+		return map[string]string{
+			"id": id, "permission": model.PermResourceDashboardWidgetRead, "scope": "resource",
+			"resource_id": widgetID, "resource_type": "dashboard_widget", "parent_resource_id": "d1",
+		}
+	}
+
+	t.Run("mixed checks return a result per id and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "user_1", mock.Anything).Return(true, nil).Once()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil).Once()
+		// w1 has a whitelist the caller is on; w2 has none and inherits the (denied) dashboard read
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(1), nil).Once()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(true, nil).Once()
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w2", "dashboard_widget").Return(int64(0), nil).Once()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "d1", "dashboard", mock.Anything).Return(false, nil).Once()
+
+		payload := map[string]interface{}{"checks": []map[string]string{
+			{"id": "ns", "permission": "platform.system.read", "scope": "system", "namespace": "ns_1"},
+			{"id": "dash", "permission": "resource.dashboard.read", "scope": "resource", "resource_id": "d1", "resource_type": "dashboard"},
+			widget("w1", "w1"),
+			widget("w2", "w2"),
+		}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"results":{"ns":true,"dash":false,"w1":true,"w2":false}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("identical checks are evaluated once and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(1), nil).Once()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "w1", "dashboard_widget", mock.Anything).Return(true, nil).Once()
+
+		payload := map[string]interface{}{"checks": []map[string]string{widget("a", "w1"), widget("b", " w1 ")}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"results":{"a":true,"b":true}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("duplicate id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"checks": []map[string]string{widget("a", "w1"), widget("a", "w2")}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `checks[1]: duplicate id`)
+	})
+
+	t.Run("invalid check names its position and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		invalid := widget("b", "w2")
+		delete(invalid, "parent_resource_id")
+		payload := map[string]interface{}{"checks": []map[string]string{widget("a", "w1"), invalid}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `checks[1]: parent_resource_id is required`)
+		mockRepo.AssertNotCalled(t, "CountResourceRoles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty or oversized batch and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, map[string]interface{}{"checks": []map[string]string{}}, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var checks []map[string]string
+		for _, id := range ChildResourceIDs(model.MaxPermissionChecks + 1) {
+			checks = append(checks, widget(id, "w1"))
+		}
+		rec = PerformRequest(e, http.MethodPost, apiPath, map[string]interface{}{"checks": checks}, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "checks exceeds the maximum")
+	})
+
+	t.Run("missing caller and return 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]interface{}{"checks": []map[string]string{widget("a", "w1")}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("repository error fails the batch and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w1", "dashboard_widget").Return(int64(0), errors.New("db error"))
+
+		payload := map[string]interface{}{"checks": []map[string]string{widget("a", "w1"), widget("b", "w2")}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockRepo.AssertNotCalled(t, "CountResourceRoles", mock.Anything, mock.Anything, "w2", mock.Anything)
+	})
+}
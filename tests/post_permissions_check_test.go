@@ -3,6 +3,7 @@ package tests
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "admin_1", mock.Anything).Return(true, nil)
 
 		payload := map[string]string{
 			"permission": "platform.system.read",
@@ -31,7 +32,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "user_1", "", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "user_1", mock.Anything).Return(false, nil)
 
 		payload := map[string]string{
 			"permission": "platform.system.read",
@@ -46,7 +47,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "admin_1", mock.Anything).Return(true, nil)
 
 		payload := map[string]string{
 			"permission": "platform.system.read",
@@ -62,7 +63,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", mock.Anything).Return(true, nil)
 
 		payload := map[string]string{
 			"permission":    "resource.dashboard.read",
@@ -79,7 +80,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		payload := map[string]string{
 			"permission":    "resource.dashboard.read",
@@ -168,7 +169,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "", mock.Anything).Return(false, errors.New("db error"))
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "admin_1", mock.Anything).Return(false, errors.New("db error"))
 
 		payload := map[string]string{
 			"permission": "platform.system.read",
@@ -183,7 +184,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "viewer_1", "lw_1", "library_widget", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "lw_1", "library_widget", mock.Anything).Return(true, nil)
 
 		payload := map[string]string{
 			"permission":    "resource.library_widget.read",
@@ -200,7 +201,7 @@ func TestPostPermissionsCheck(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "user_1", "lw_1", "library_widget", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "user_1", "lw_1", "library_widget", mock.Anything).Return(false, nil)
 
 		payload := map[string]string{
 			"permission":    "resource.library_widget.read",
@@ -212,4 +213,83 @@ func TestPostPermissionsCheck(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Contains(t, rec.Body.String(), `"allowed":false`)
 	})
+
+	// Dashboard widget checks follow check_permission.json: parent_if_no_roles
+	t.Run("check widget without roles inherits the parent dashboard and return 200 true", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w_1", "dashboard_widget").Return(int64(0), nil)
+		// resource.dashboard_widget.read is mapped to resource.dashboard.read on the parent
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "dash_1", "dashboard", mock.MatchedBy(func(roles []string) bool {
+			return slices.Contains(roles, "viewer")
+		})).Return(true, nil)
+
+		payload := map[string]string{
+			"permission":         "resource.dashboard_widget.read",
+			"scope":              "resource",
+			"resource_id":        "w_1",
+			"resource_type":      "dashboard_widget",
+			"parent_resource_id": "dash_1",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allowed":true`)
+		mockRepo.AssertNotCalled(t, "HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "w_1", "dashboard_widget", mock.Anything)
+	})
+
+	t.Run("check widget with roles requires a direct grant and return 200 false", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w_1", "dashboard_widget").Return(int64(2), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "w_1", "dashboard_widget", mock.Anything).Return(false, nil)
+
+		payload := map[string]string{
+			"permission":         "resource.dashboard_widget.read",
+			"scope":              "resource",
+			"resource_id":        "w_1",
+			"resource_type":      "dashboard_widget",
+			"parent_resource_id": "dash_1",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allowed":false`)
+		// A role on the parent dashboard does not reach a whitelisted widget
+		mockRepo.AssertNotCalled(t, "HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "dash_1", "dashboard", mock.Anything)
+	})
+
+	t.Run("check widget with roles and a direct grant and return 200 true", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("CountResourceRoles", mock.Anything, mock.Anything, "w_1", "dashboard_widget").Return(int64(2), nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "viewer_1", "w_1", "dashboard_widget", mock.Anything).Return(true, nil)
+
+		payload := map[string]string{
+			"permission":         "resource.dashboard_widget.read",
+			"scope":              "resource",
+			"resource_id":        "w_1",
+			"resource_type":      "dashboard_widget",
+			"parent_resource_id": "dash_1",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allowed":true`)
+	})
+
+	t.Run("check widget without parent_resource_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{
+			"permission":    "resource.dashboard_widget.read",
+			"scope":         "resource",
+			"resource_id":   "w_1",
+			"resource_type": "dashboard_widget",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "CountResourceRoles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostPolicyReload(t *testing.T) {
+	// API: POST /api/v1/policy/reload (with middleware)
+	apiPath := "/api/v1/policy/reload"
+
+	t.Run("reload policies and return 200 with a summary", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, map[string]string{"x-user-id": "mod_1"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.PolicySummary
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 4, resp.Entities)
+		assert.Greater(t, resp.Operations, resp.Entities)
+		assert.Positive(t, resp.SystemRoles)
+		assert.Positive(t, resp.ResourceRoles)
+		assert.False(t, resp.LoadedAt.IsZero())
+	})
+
+	t.Run("reload without moderator role returns 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("reload without caller returns 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostReassignOwner(t *testing.T) {
+	// API: POST /api/v1/user_roles/reassign_owner (with middleware)
+	apiPath := "/api/v1/user_roles/reassign_owner"
+
+	t.Run("reassign two dashboards and a library widget and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("ReassignOwnedResources", mock.Anything, "leaver", "successor", "mod_1").Return(int64(3), nil).Once()
+
+		payload := map[string]string{"old_owner_id": " leaver ", "new_owner_id": "successor"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"transferred":3}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reassign a user owning nothing and return 200 with zero", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("ReassignOwnedResources", mock.Anything, "leaver", "successor", "mod_1").Return(int64(0), nil)
+
+		payload := map[string]string{"old_owner_id": "leaver", "new_owner_id": "successor"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"transferred":0}`, rec.Body.String())
+	})
+
+	t.Run("reassign to the same user and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		payload := map[string]string{"old_owner_id": "leaver", "new_owner_id": "leaver"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ReassignOwnedResources", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reassign without new_owner_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		payload := map[string]string{"old_owner_id": "leaver"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "ReassignOwnedResources", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reassign as a non-moderator and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", []string{"moderator"}).Return(false, nil)
+
+		payload := map[string]string{"old_owner_id": "leaver", "new_owner_id": "successor"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "ReassignOwnedResources", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reassign unauthorized and return 401", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"old_owner_id": "leaver", "new_owner_id": "successor"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("reassign internal error and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("ReassignOwnedResources", mock.Anything, "leaver", "successor", "mod_1").Return(int64(0), errors.New("db error"))
+
+		payload := map[string]string{"old_owner_id": "leaver", "new_owner_id": "successor"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostRenameNamespace(t *testing.T) {
+	// API: POST /api/v1/namespaces/rename (with middleware)
+	apiPath := "/api/v1/namespaces/rename"
+	headers := map[string]string{"x-user-id": "mod_1"}
+
+	t.Run("rename namespace success and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("RenameNamespace", mock.Anything, "NS_OLD", "NS_NEW", "mod_1").
+			Return(&model.RenameNamespaceResult{From: "NS_OLD", To: "NS_NEW", RolesMigrated: 4, HistoryMigrated: 9}, nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "rename_namespace" && h.Namespace == "NS_NEW" && h.PreviousNamespace == "NS_OLD"
+		})).Return(nil).Once()
+
+		payload := map[string]string{"from": " ns_old ", "to": "ns_new"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "\"roles_migrated\":4")
+		assert.Contains(t, rec.Body.String(), "\"history_migrated\":9")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rename onto namespace with conflicting owner and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RenameNamespace", mock.Anything, "NS_OLD", "NS_TAKEN", "mod_1").Return(nil, repository.ErrDuplicate)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		payload := map[string]string{"from": "NS_OLD", "to": "NS_TAKEN"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "target namespace has conflicting roles")
+		mockRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rename onto the same namespace and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", mock.Anything).Return(true, nil)
+
+		payload := map[string]string{"from": "NS_OLD", "to": "ns_old"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "to must differ from from")
+		mockRepo.AssertNotCalled(t, "RenameNamespace", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rename namespace without moderator role and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		payload := map[string]string{"from": "NS_OLD", "to": "NS_NEW"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "RenameNamespace", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
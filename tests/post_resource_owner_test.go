@@ -1,11 +1,13 @@
 package tests
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
 
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -24,7 +26,7 @@ func TestPostResourceOwner(t *testing.T) {
 		}
 
 		// Expect Count Check
-		mockRepo.On("CountResourceOwners", mock.Anything, "r1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(0), nil)
 
 		// Expect assignment
 		// UserID == "caller"
@@ -38,6 +40,38 @@ func TestPostResourceOwner(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 
+	t.Run("assign resource owner scopes the lookup and the role to the namespace", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"resource_id": "d1", "resource_type": "dashboard", "namespace": "ns_b"}
+
+		mockRepo.On("CountResourceOwners", mock.Anything, "NS_B", "d1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CreateUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.UserID == "caller" && r.Namespace == "NS_B" && r.ResourceID == "d1"
+		})).Return(nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{
+			"x-user-id": "caller", "authentication": "t",
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("assign resource owner with echo reports the caller as owner and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"resource_id": "d1", "resource_type": "Dashboard", "namespace": "ns_b"}
+
+		mockRepo.On("CountResourceOwners", mock.Anything, "NS_B", "d1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CreateUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath+"?echo=true", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success","request":{"scope":"resource","namespace":"NS_B","user_id":"caller","user_type":"member","role":"owner","resource_id":"d1","resource_type":"dashboard"}}`, rec.Body.String())
+	})
+
 	t.Run("assign resource owner missing resource_id/type and return 400", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
@@ -68,12 +102,51 @@ func TestPostResourceOwner(t *testing.T) {
 		payload := map[string]string{"resource_id": "r1", "resource_type": "dashboard"}
 
 		// Repo returns Count > 0
-		mockRepo.On("CountResourceOwners", mock.Anything, "r1", "dashboard").Return(int64(1), nil)
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(1), nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(&model.UserRole{UserID: "u_123"}, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{
+			"x-user-id": "caller", "authentication": "t",
+		})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		var resp model.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "conflict", resp.Error.Code)
+		assert.Equal(t, "u_123", resp.Error.CurrentOwner)
+	})
+
+	t.Run("assign resource owner losing the insert race returns 409 with the winner", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CreateUserRole", mock.Anything, mock.Anything).Return(repository.ErrDuplicate)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(&model.UserRole{UserID: "u_456"}, nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{
+			"x-user-id": "caller", "authentication": "t",
+		})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"current_owner":"u_456"`)
+	})
+
+	t.Run("assign resource owner conflict without owner lookup still returns 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(1), nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(nil, errors.New("db fail"))
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{
 			"x-user-id": "caller", "authentication": "t",
 		})
 		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "current_owner")
 	})
 
 	t.Run("assign resource owner internal error and return 500", func(t *testing.T) {
@@ -83,7 +156,7 @@ func TestPostResourceOwner(t *testing.T) {
 		payload := map[string]string{"resource_id": "r1", "resource_type": "dashboard"}
 
 		// Repo returns Count -> 0
-		mockRepo.On("CountResourceOwners", mock.Anything, "r1", "dashboard").Return(int64(0), nil)
+		mockRepo.On("CountResourceOwners", mock.Anything, mock.Anything, "r1", "dashboard").Return(int64(0), nil)
 
 		// Repo returns generic error
 		mockRepo.On("CreateUserRole", mock.Anything, mock.Anything).Return(errors.New("db fail"))
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostResourceOwners(t *testing.T) {
+	// API: POST /api/v1/resources/owners (with middleware)
+	apiPath := "/api/v1/resources/owners"
+
+	t.Run("get owners for a subset of dashboards and null for ownerless ones and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: global check (moderator, no namespace)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			Role:         model.RoleResourceOwner,
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1", "dash_2", "dash_3"},
+		}).Return([]*model.UserRole{
+			{UserID: "user_1", Role: model.RoleResourceOwner, ResourceID: "dash_1", ResourceType: "dashboard"},
+			{UserID: "user_3", Role: model.RoleResourceOwner, ResourceID: "dash_3", ResourceType: "dashboard"},
+		}, nil).Once()
+
+		payload := map[string]interface{}{"resource_type": "Dashboard", "resource_ids": []string{"dash_1", " dash_2 ", "dash_3", "dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"owners":{"dash_1":"user_1","dash_2":null,"dash_3":"user_3"}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get owners in a namespace when resources are namespaced and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithNamespacedResources(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			Namespace:    "NS_A",
+			Role:         model.RoleResourceOwner,
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1", "dash_2"},
+		}).Return([]*model.UserRole{
+			{UserID: "user_1", Role: model.RoleResourceOwner, ResourceID: "dash_1", ResourceType: "dashboard", Namespace: "NS_A"},
+		}, nil).Once()
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1", "dash_2"}, "namespace": " ns_a "}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"owners":{"dash_1":"user_1","dash_2":null}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get owners ignores namespace when resources are not namespaced and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, model.UserRoleFilter{
+			Role:         model.RoleResourceOwner,
+			Scope:        model.ScopeResource,
+			ResourceType: "dashboard",
+			ResourceIDs:  []string{"dash_1"},
+		}).Return([]*model.UserRole{}, nil).Once()
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}, "namespace": "NS_A"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get owners when none are owned and return 200 with all null", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return([]*model.UserRole{}, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1", "dash_2"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"owners":{"dash_1":null,"dash_2":null}}`, rec.Body.String())
+	})
+
+	t.Run("get owners without resource_ids returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{" "}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("get owners without moderator role returns 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "owner_1", mock.Anything).Return(false, nil)
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "FindUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("get owners repository error returns 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "mod_1", []string{"moderator"}).Return(true, nil)
+		mockRepo.On("FindUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		payload := map[string]interface{}{"resource_type": "dashboard", "resource_ids": []string{"dash_1"}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "mod_1"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
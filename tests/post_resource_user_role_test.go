@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,10 +24,10 @@ func TestPostResourceUserRole(t *testing.T) {
 		}
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: owner check
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
 
 		// Service: upsert role
 		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
@@ -43,7 +44,7 @@ func TestPostResourceUserRole(t *testing.T) {
 
 		payload := model.ResourceUserRole{UserID: "u1"} // Missing resource info
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -57,7 +58,7 @@ func TestPostResourceUserRole(t *testing.T) {
 			UserID: "u1", Role: "inv", ResourceID: "r1", ResourceType: "dashboard",
 		}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -83,7 +84,7 @@ func TestPostResourceUserRole(t *testing.T) {
 		}
 
 		// RBAC Middleware: permission check fails
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
@@ -97,9 +98,9 @@ func TestPostResourceUserRole(t *testing.T) {
 			UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard",
 		}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
 		// Target is owner
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(true, nil)
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
@@ -113,11 +114,48 @@ func TestPostResourceUserRole(t *testing.T) {
 			UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard",
 		}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
-		mockRepo.On("HasResourceRole", mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
 		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(errors.New("db fail"))
 
 		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("re-assign a resource role the user holds with REJECT_EXISTING_ROLE and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := model.ResourceUserRole{
+			UserID: "u1", Role: "editor", ResourceID: "r1", ResourceType: "dashboard",
+		}
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(repository.ErrAlreadyHasRole)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"already_has_role"`)
+	})
+
+	t.Run("assign resource user role with echo returns normalized request and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := model.ResourceUserRole{
+			UserID: "u1", Role: "Editor", ResourceID: "r1", ResourceType: "dashboard",
+		}
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "r1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		rec := PerformRequest(e, http.MethodPost, apiPath+"?echo=true", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"role":"editor"`)
+		assert.Contains(t, rec.Body.String(), `"resource_type":"dashboard"`)
+		assert.Contains(t, rec.Body.String(), `"scope":"resource"`)
+		assert.Contains(t, rec.Body.String(), `"user_type":"member"`, "the defaulted user type is echoed as stored")
+	})
 }
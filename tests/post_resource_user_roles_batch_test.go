@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"rbac7/internal/rbac/model"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,7 +20,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 
 		// Service: bulk upsert
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
@@ -45,7 +46,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 1 && roles[0].Role == "viewer"
 		})).Return(&model.BatchUpsertResult{SuccessCount: 1, FailedCount: 0}, nil)
@@ -59,7 +60,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 2 && roles[0].Role == "editor"
 		})).Return(&model.BatchUpsertResult{SuccessCount: 2, FailedCount: 0}, nil)
@@ -73,7 +74,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{
 			UserIDs:      []string{},
@@ -89,7 +90,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "owner", ResourceID: "dash_1", ResourceType: "dashboard"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "admin_1"})
@@ -100,7 +101,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "god_mode", ResourceID: "dash_1", ResourceType: "dashboard"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
@@ -112,7 +113,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "u_common", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_common", "dash_1", "dashboard", mock.Anything).Return(false, nil)
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", ResourceID: "dash_1", ResourceType: "dashboard"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "u_common"})
@@ -123,7 +124,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "admin", ResourceID: "", ResourceType: "dashboard"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
@@ -143,7 +144,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "admin", ResourceID: "dash_1", ResourceType: "dashboard"}
@@ -155,7 +156,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(false, errors.New("db disconnect"))
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(false, errors.New("db disconnect"))
 
 		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "admin", ResourceID: "dash_1", ResourceType: "dashboard"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
@@ -166,7 +167,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 3
 		})).Return(&model.BatchUpsertResult{
@@ -195,7 +196,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 2
 		})).Return(&model.BatchUpsertResult{
@@ -232,7 +233,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 
 		// Middleware may pass through when no matching config (widget needs parent_resource_id for config match)
 		// Handler validation will reject missing parent_resource_id
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(&model.BatchUpsertResult{}, nil).Maybe()
 
@@ -251,9 +252,9 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Permission checked on parent dashboard (middleware)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: target users must have parent dashboard read permission
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "u_2", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_2", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(&model.BatchUpsertResult{SuccessCount: 1, FailedCount: 0}, nil)
 
 		reqBody := model.AssignResourceUserRolesReq{
@@ -272,9 +273,9 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// Permission checked on parent dashboard (middleware)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: target user does NOT have parent dashboard read permission
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "u_no_access", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_no_access", "dash_1", "dashboard", mock.Anything).Return(false, nil)
 
 		reqBody := model.AssignResourceUserRolesReq{
 			UserIDs:          []string{"u_no_access"},
@@ -295,13 +296,51 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		assert.Contains(t, result.FailedUsers[0].Reason, "parent dashboard")
 	})
 
+	t.Run("widget viewer batch records only users with parent dashboard permission in history", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_2", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u_no_access", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 1 && roles[0].UserID == "u_2"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 1}, nil)
+
+		recorded := make(chan *model.UserRoleHistory, 1)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { recorded <- args.Get(1).(*model.UserRoleHistory) }).Return(nil)
+
+		reqBody := model.AssignResourceUserRolesReq{
+			UserIDs:          []string{"u_2", "u_no_access"},
+			Role:             "viewer",
+			ResourceID:       "widget_1",
+			ResourceType:     "dashboard_widget",
+			ParentResourceID: "dash_1",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// Batch history is recorded asynchronously
+		select {
+		case h := <-recorded:
+			assert.Equal(t, "assign_user_roles_batch", h.Operation)
+			assert.Equal(t, []string{"u_2"}, h.UserIDs)
+		case <-time.After(time.Second):
+			t.Fatal("history was not recorded")
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
 	// Library Widget Test Cases
 	t.Run("library_widget batch assign viewers success", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check (system scope for library_widget)
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		// Service: namespace has no role override
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(nil, nil)
 
 		// Service: bulk upsert with namespace
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
@@ -331,7 +370,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{
 			UserIDs:      []string{"u_1"},
@@ -350,7 +389,7 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		reqBody := model.AssignResourceUserRolesReq{
 			UserIDs:      []string{"u_1"},
@@ -363,4 +402,70 @@ func TestPostResourceUserRolesBatch(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 		assert.Contains(t, rec.Body.String(), "only viewer role is allowed")
 	})
+
+	t.Run("assign mixed roles rejects only invalid pairs and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 2 && roles[0].UserID == "u_admin" && roles[0].Role == "admin" &&
+				roles[1].UserID == "u_viewer" && roles[1].Role == "viewer" && roles[1].ResourceID == "dash_1"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 2}, nil).Once()
+
+		reqBody := model.AssignResourceUserRolesReq{
+			ResourceID:   "dash_1",
+			ResourceType: "dashboard",
+			Assignments: []model.RoleAssignment{
+				{UserID: "u_admin", Role: "admin"},
+				{UserID: "u_owner", Role: "owner"},
+				{UserID: "u_viewer", Role: "viewer"},
+				{UserID: "u_bogus", Role: "publisher"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result model.BatchUpsertResult
+		json.Unmarshal(rec.Body.Bytes(), &result)
+		assert.Equal(t, 2, result.SuccessCount)
+		assert.Equal(t, 2, result.FailedCount)
+		assert.Equal(t, []model.RoleAssignment{{UserID: "u_admin", Role: "admin"}, {UserID: "u_viewer", Role: "viewer"}}, result.Assigned)
+		assert.Equal(t, []model.FailedUserInfo{
+			{UserID: "u_owner", Reason: "cannot assign resource owner role via this API"},
+			{UserID: "u_bogus", Reason: "invalid role: must be one of [admin, editor, viewer]"},
+		}, result.FailedUsers)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("assign mixed roles fails pairs outside the namespace override and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		// Namespace allows viewers only; the override is read once per distinct role
+		mockRepo.On("GetNamespaceResourceRoles", mock.Anything, "NS_1").Return(&model.NamespaceResourceRoles{Namespace: "NS_1", Roles: []string{"viewer"}}, nil).Twice()
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 2 && roles[0].UserID == "u_1" && roles[1].UserID == "u_3"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 2}, nil).Once()
+
+		reqBody := model.AssignResourceUserRolesReq{
+			ResourceID:   "dash_1",
+			ResourceType: "dashboard",
+			Namespace:    "NS_1",
+			Assignments: []model.RoleAssignment{
+				{UserID: "u_1", Role: "viewer"},
+				{UserID: "u_2", Role: "editor"},
+				{UserID: "u_3", Role: "viewer"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result model.BatchUpsertResult
+		json.Unmarshal(rec.Body.Bytes(), &result)
+		assert.Equal(t, []model.FailedUserInfo{{UserID: "u_2", Reason: "role is not assignable in this namespace"}}, result.FailedUsers)
+		mockRepo.AssertExpectations(t)
+	})
 }
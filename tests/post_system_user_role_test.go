@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,7 +19,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		// Service: check owner
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		// Service: upsert
@@ -35,7 +36,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
 			return r.Role == "viewer" && r.UserID == "u_3"
@@ -50,7 +51,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
 			return r.Role == "viewer" && r.UserID == "u_existing"
@@ -65,7 +66,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 
 		currentOwner := &model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(currentOwner, nil)
@@ -103,7 +104,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnySystemRole", mock.Anything, "u_common", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_common", mock.Anything).Return(false, nil)
 
 		reqBody := model.SystemUserRole{UserID: "u_2", Role: "viewer", Namespace: "NS_1"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "u_common"})
@@ -145,7 +146,7 @@ func TestPostSystemUserRole(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(errors.New("db error"))
 
@@ -154,14 +155,87 @@ func TestPostSystemUserRole(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 
+	t.Run("re-add system user within the removal grace period and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(repository.ErrRecentlyRemoved)
+
+		reqBody := model.SystemUserRole{UserID: "u_2", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "removed recently")
+	})
+
+	t.Run("re-assign a role the user holds in default mode and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil) // no-op upsert
+
+		reqBody := model.SystemUserRole{UserID: "u_2", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("re-assign a role the user holds with REJECT_EXISTING_ROLE and return 409", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(repository.ErrAlreadyHasRole)
+
+		reqBody := model.SystemUserRole{UserID: "u_2", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"already_has_role"`)
+	})
+
 	t.Run("assign system role auth check db error and return 500", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(false, errors.New("db disconnect"))
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(false, errors.New("db disconnect"))
 
 		reqBody := model.SystemUserRole{UserID: "u_2", Role: "admin", Namespace: "NS_1"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("assign system user role with echo returns normalized request and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.Role == "admin" && r.Namespace == "NS_1"
+		})).Return(nil)
+
+		reqBody := model.SystemUserRole{UserID: "u_2", Role: "Admin", Namespace: "ns_1", Scope: "system"}
+		rec := PerformRequest(e, http.MethodPost, apiPath+"?echo=true", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"namespace":"NS_1"`)
+		assert.Contains(t, rec.Body.String(), `"role":"admin"`)
+		assert.Contains(t, rec.Body.String(), `"scope":"system"`)
+	})
+
+	t.Run("assign system user role without echo returns status only and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.Anything).Return(nil)
+
+		reqBody := model.SystemUserRole{UserID: "u_2", Role: "Admin", Namespace: "ns_1", Scope: "system"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success"}`, rec.Body.String())
+	})
 }
@@ -19,7 +19,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		// Service: check owner
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		// Service: bulk upsert
@@ -45,7 +45,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "admin_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 1 && roles[0].Role == "viewer"
@@ -60,7 +60,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 2 && roles[0].Role == "dev_user"
@@ -113,7 +113,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnySystemRole", mock.Anything, "u_common", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_common", mock.Anything).Return(false, nil)
 
 		reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", Namespace: "NS_1"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "u_common"})
@@ -144,7 +144,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 
@@ -157,7 +157,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(false, errors.New("db disconnect"))
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(false, errors.New("db disconnect"))
 
 		reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "admin", Namespace: "NS_1"}
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
@@ -168,7 +168,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 3
@@ -198,7 +198,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 2
@@ -230,7 +230,7 @@ func TestPostUserRolesBatch(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
 		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
 			return len(roles) == 1 && roles[0].UserType == "org"
@@ -240,4 +240,95 @@ func TestPostUserRolesBatch(t *testing.T) {
 		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
+
+	t.Run("assign mixed roles per user and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		// Service: one bulk upsert carrying each user's own role
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 3 && roles[0].UserID == "u_2" && roles[0].Role == "admin" &&
+				roles[1].UserID == "u_3" && roles[1].Role == "viewer" &&
+				roles[2].UserID == "u_4" && roles[2].Role == "viewer"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 3}, nil).Once()
+
+		reqBody := map[string]interface{}{
+			"namespace": "ns_1",
+			"assignments": []map[string]string{
+				{"user_id": "u_2", "role": "admin"},
+				{"user_id": "u_3", "role": "Viewer"},
+				{"user_id": "u_4", "role": "viewer"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"success_count":3,"failed_count":0,"assigned":[
+			{"user_id":"u_2","role":"admin"},{"user_id":"u_3","role":"viewer"},{"user_id":"u_4","role":"viewer"}]}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("assign mixed roles rejects only invalid pairs and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			return len(roles) == 1 && roles[0].UserID == "u_2" && roles[0].Role == "admin"
+		})).Return(&model.BatchUpsertResult{SuccessCount: 1}, nil).Once()
+
+		reqBody := model.AssignSystemUserRolesReq{
+			Namespace: "NS_1",
+			Assignments: []model.RoleAssignment{
+				{UserID: "u_2", Role: "admin"},
+				{UserID: "u_3", Role: "god_mode"},
+				{UserID: "u_4", Role: "owner"},
+			},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result model.BatchUpsertResult
+		json.Unmarshal(rec.Body.Bytes(), &result)
+		assert.Equal(t, 1, result.SuccessCount)
+		assert.Equal(t, 2, result.FailedCount)
+		assert.Equal(t, []model.RoleAssignment{{UserID: "u_2", Role: "admin"}}, result.Assigned)
+		if assert.Len(t, result.FailedUsers, 2) {
+			assert.Equal(t, "u_3", result.FailedUsers[0].UserID)
+			assert.Contains(t, result.FailedUsers[0].Reason, "invalid role")
+			assert.Equal(t, "u_4", result.FailedUsers[1].UserID)
+			assert.Contains(t, result.FailedUsers[1].Reason, "owner")
+		}
+	})
+
+	t.Run("assign mixed roles with only invalid pairs skips the write and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		reqBody := model.AssignSystemUserRolesReq{Namespace: "NS_1", Assignments: []model.RoleAssignment{{UserID: "u_3", Role: "god_mode"}}}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"success_count":0,"failed_count":1,"failed_users":[
+			{"user_id":"u_3","reason":"invalid role: must be one of [admin, viewer, dev_user, auditor]"}]}`, rec.Body.String())
+		mockRepo.AssertNotCalled(t, "BulkUpsertUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("assignments combined with user_ids return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+
+		reqBody := model.AssignSystemUserRolesReq{
+			UserIDs:     []string{"u_2"},
+			Role:        "admin",
+			Namespace:   "NS_1",
+			Assignments: []model.RoleAssignment{{UserID: "u_3", Role: "viewer"}},
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "cannot be combined")
+	})
 }
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"testing"
 
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,9 +24,10 @@ func TestPutResourceOwner(t *testing.T) {
 		}
 
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
 		// Service: transfer
-		mockRepo.On("TransferResourceOwner", mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -36,8 +40,9 @@ func TestPutResourceOwner(t *testing.T) {
 		payload := map[string]string{
 			"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard",
 		}
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
-		mockRepo.On("TransferResourceOwner", mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -49,7 +54,7 @@ func TestPutResourceOwner(t *testing.T) {
 
 		payload := map[string]string{"user_id": "u_new"}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -63,7 +68,7 @@ func TestPutResourceOwner(t *testing.T) {
 			"user_id": "caller", "resource_id": "r1", "resource_type": "dashboard",
 		}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -85,7 +90,7 @@ func TestPutResourceOwner(t *testing.T) {
 		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
@@ -97,20 +102,101 @@ func TestPutResourceOwner(t *testing.T) {
 
 		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(false, nil)
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusForbidden, rec.Code)
 	})
 
+	t.Run("transfer resource owner of a missing resource and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "caller")
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r_gone", "resource_type": "dashboard"}
+
+		// Superadmin bypasses the permission check; there is no owner to demote
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r_gone", "dashboard", "owner").Return(false, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r_gone", "dashboard").Return(nil, nil)
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not_found")
+		mockRepo.AssertNotCalled(t, "TransferResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer resource owner whose owner is removed meanwhile and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		// The demote finds no owner, e.g. after a concurrent transfer
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(repository.ErrOwnerNotFound)
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not_found")
+	})
+
+	t.Run("transfer resource owner by a non-owner demotes the current owner and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "admin_1")
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", "owner").Return(false, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(&model.UserRole{UserID: "u_old", Role: "owner"}, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "u_old", "u_new", "admin_1").Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "transfer_owner" && h.UserID == "u_old" && h.NewOwnerID == "u_new" && h.CallerID == "admin_1"
+		})).Return(nil)
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer resource owner by the owner demotes the caller and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(nil)
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		// The caller is the owner, so no owner lookup is needed
+		mockRepo.AssertNotCalled(t, "GetResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer resource owner to the current owner and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "admin_1")
+
+		payload := map[string]string{"user_id": "u_old", "resource_id": "r1", "resource_type": "dashboard"}
+
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "admin_1", "r1", "dashboard", "owner").Return(false, nil)
+		mockRepo.On("GetResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard").Return(&model.UserRole{UserID: "u_old", Role: "owner"}, nil)
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "admin_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "TransferResourceOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("transfer resource owner internal error and return 500", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
 		payload := map[string]string{"user_id": "u_new", "resource_id": "r1", "resource_type": "dashboard"}
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
-		mockRepo.On("TransferResourceOwner", mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(errors.New("db error"))
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "caller", "r1", "dashboard", "owner").Return(true, nil)
+		mockRepo.On("TransferResourceOwner", mock.Anything, mock.Anything, "r1", "dashboard", "caller", "u_new", "caller").Return(errors.New("db error"))
 
 		rec := PerformRequest(e, http.MethodPut, apiPath, payload, map[string]string{"x-user-id": "caller"})
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,7 +21,7 @@ func TestPutSystemOwner(t *testing.T) {
 
 		ownerRole := &model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}
 		// RBAC Middleware: permission check
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		// Service: get owner and transfer
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(ownerRole, nil)
 		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "owner_1").Return(nil)
@@ -85,7 +86,7 @@ func TestPutSystemOwner(t *testing.T) {
 		e := SetupServerWithMiddleware(mockRepo)
 
 		// RBAC Middleware: permission denied
-		mockRepo.On("HasAnySystemRole", mock.Anything, "fake_owner", "NS_1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "fake_owner", mock.Anything).Return(false, nil)
 
 		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_1"}
 		headers := map[string]string{"x-user-id": "fake_owner"}
@@ -94,12 +95,73 @@ func TestPutSystemOwner(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, rec.Code)
 	})
 
+	t.Run("transfer system owner of a namespace without owner and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_GONE", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_GONE").Return(nil, nil)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_GONE"}
+		headers := map[string]string{"x-user-id": "owner_1"}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, reqBody, headers)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not_found")
+		mockRepo.AssertNotCalled(t, "TransferSystemOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer system owner after the caller lost ownership and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		ownerRole := &model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(ownerRole, nil)
+		// Demote finds no owner: ownership moved between the check and the transaction
+		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "owner_1").Return(repository.ErrOwnerNotFound)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_1"}
+		headers := map[string]string{"x-user-id": "owner_1"}
+
+		rec := PerformRequest(e, http.MethodPut, apiPath, reqBody, headers)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("transfer system owner by a co-owner demotes the caller and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_2", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_2", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_2", "new_owner", "owner_2").Return(nil)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPut, apiPath, reqBody, map[string]string{"x-user-id": "owner_2"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer system owner to the current owner and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "break_glass", model.RoleSystemOwner).Return(false, nil)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "owner_1", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPut, apiPath, reqBody, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "TransferSystemOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("transfer system owner internal error and return 500", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := SetupServerWithMiddleware(mockRepo)
 
 		ownerRole := &model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}
-		mockRepo.On("HasAnySystemRole", mock.Anything, "owner_1", "NS_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
 		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(ownerRole, nil)
 		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "owner_1").Return(errors.New("db error"))
 
@@ -109,4 +171,19 @@ func TestPutSystemOwner(t *testing.T) {
 		rec := PerformRequest(e, http.MethodPut, apiPath, reqBody, headers)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("transfer system owner with echo returns normalized request and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		ownerRole := &model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(ownerRole, nil)
+		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "owner_1").Return(nil)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "ns_1"}
+		rec := PerformRequest(e, http.MethodPut, apiPath+"?echo=true", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success","request":{"scope":"system","namespace":"NS_1","user_id":"new_owner","user_type":"member","role":"owner"}}`, rec.Body.String())
+	})
 }
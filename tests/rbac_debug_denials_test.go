@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRBACDebugDenials tests the debug details RBAC middleware errors carry when RBAC_DEBUG_DENIALS is on
+func TestRBACDebugDenials(t *testing.T) {
+	headers := map[string]string{"x-user-id": "u_common"}
+	debugOf := func(t *testing.T, body []byte) *model.RBACDebug {
+		var resp model.ErrorResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		require.NotNil(t, resp.Error.Debug)
+		return resp.Error.Debug
+	}
+
+	t.Run("denial names the operation and missing permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithDebugDenials(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_common", mock.Anything).Return(false, nil)
+
+		reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", reqBody, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, &model.RBACDebug{
+			Key:        "POST:/api/v1/user_roles/batch",
+			Entity:     "system",
+			Operation:  "assign_user_roles_batch",
+			Permission: "platform.system.add_member",
+			CheckScope: "system",
+		}, debugOf(t, rec.Body.Bytes()))
+	})
+
+	t.Run("missing required param is named and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithDebugDenials(mockRepo)
+
+		reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", reqBody, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		debug := debugOf(t, rec.Body.Bytes())
+		assert.Equal(t, "assign_user_roles_batch", debug.Operation)
+		assert.Equal(t, "namespace", debug.MissingParam)
+	})
+
+	t.Run("unmatched conditions list the candidate operations and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithDebugDenials(mockRepo)
+
+		reqBody := model.AssignResourceUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", ResourceID: "x1", ResourceType: "spreadsheet"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", reqBody, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		debug := debugOf(t, rec.Body.Bytes())
+		assert.Empty(t, debug.Operation)
+		assert.Contains(t, debug.Candidates, "dashboard.assign_user_roles_batch")
+		assert.Contains(t, debug.Candidates, "library_widget.assign_viewers_batch")
+	})
+
+	t.Run("debug details are omitted by default and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "u_common", mock.Anything).Return(false, nil)
+
+		reqBody := model.AssignSystemUserRolesReq{UserIDs: []string{"u_2"}, Role: "viewer", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/batch", reqBody, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "debug")
+		assert.NotContains(t, rec.Body.String(), "platform.system.add_member")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"rbac7/internal/rbac/handler"
@@ -18,8 +19,7 @@ import (
 // setupRBACMiddlewareTest creates a test Echo instance with real policy configs
 func setupRBACMiddlewareTest(mockRepo *MockRBACRepository) *echo.Echo {
 	policyEngine, _ := policy.NewEngine()
-	apiConfigs := policyEngine.GetLoader().LoadAPIConfigs(policyEngine.GetEntityPolicies())
-	rbacMiddleware := handler.NewRBACMiddleware(policyEngine, mockRepo, apiConfigs)
+	rbacMiddleware := handler.NewRBACMiddleware(policyEngine, mockRepo)
 
 	e := echo.New()
 	e.Use(rbacMiddleware.Middleware())
@@ -101,12 +101,12 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		e := setupRBACMiddlewareTest(mockRepo)
 
 		// assign_owner uses check_scope: global, no namespace required
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "caller", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"namespace": "ns1", "user_id": "u1"}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/owner", body, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		mockRepo.AssertCalled(t, "HasAnySystemRole", mock.Anything, "caller", "", mock.Anything)
+		mockRepo.AssertCalled(t, "HasAnySystemRole", mock.Anything, "", "caller", mock.Anything)
 	})
 
 	t.Run("system/transfer_owner matches PUT /user_roles/owner", func(t *testing.T) {
@@ -114,19 +114,19 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		e := setupRBACMiddlewareTest(mockRepo)
 
 		// transfer_owner uses check_scope: system, namespace_required: true
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"namespace": "ns1", "new_owner_id": "u2"}
 		rec := performMiddlewareRequest(e, http.MethodPut, "/api/v1/user_roles/owner", body, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		mockRepo.AssertCalled(t, "HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything)
+		mockRepo.AssertCalled(t, "HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything)
 	})
 
 	t.Run("system/assign_user_role matches POST /user_roles with scope=system", func(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"namespace": "ns1", "user_id": "u1", "role": "admin", "scope": "system"}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles", body, headers)
@@ -137,7 +137,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodDelete, "/api/v1/user_roles?namespace=ns1&user_id=u1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -147,7 +147,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodGet, "/api/v1/user_roles?scope=system&namespace=ns1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -170,7 +170,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "new_owner_id": "u2"}
 		rec := performMiddlewareRequest(e, http.MethodPut, "/api/v1/user_roles/resources/owner", body, headers)
@@ -181,7 +181,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "user_id": "u1", "role": "viewer"}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, headers)
@@ -192,7 +192,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?resource_id=d1&resource_type=dashboard&user_id=u1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -202,7 +202,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodGet, "/api/v1/user_roles?scope=resource&resource_type=dashboard&resource_id=d1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -214,7 +214,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		e := setupRBACMiddlewareTest(mockRepo)
 
 		// Checks parent resource (dashboard)
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{
 			"resource_id":        "w1",
@@ -231,7 +231,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?resource_id=w1&resource_type=dashboard_widget&parent_resource_id=d1&user_id=u1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -242,7 +242,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		body := map[string]interface{}{"namespace": "ns1", "resource_id": "lw1", "resource_type": "library_widget", "role": "viewer", "user_ids": []string{"u1"}}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", body, headers)
@@ -253,7 +253,7 @@ func TestRBACMiddlewareConfigMatching(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(true, nil)
 
 		rec := performMiddlewareRequest(e, http.MethodDelete, "/api/v1/user_roles/resources?namespace=ns1&resource_id=lw1&resource_type=library_widget&user_id=u1", nil, headers)
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -303,6 +303,58 @@ func TestRBACMiddlewareRequiredParams(t *testing.T) {
 	})
 }
 
+// ============================================================================
+// Test: resource_type Casing
+// ============================================================================
+
+func TestRBACMiddlewareResourceTypeCasing(t *testing.T) {
+	headers := map[string]string{"x-user-id": "caller"}
+
+	for _, resourceType := range []string{"Dashboard", "DASHBOARD", " dashboard "} {
+		t.Run("POST /user_roles/resources with resource_type="+resourceType+" matches dashboard/assign_user_role", func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			e := setupRBACMiddlewareTest(mockRepo)
+
+			// The permission check runs against the lowercased type
+			mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+			body := map[string]interface{}{"resource_id": "d1", "resource_type": resourceType, "user_id": "u1", "role": "viewer"}
+			rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, headers)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+
+		t.Run("GET /user_roles with resource_type="+resourceType+" matches dashboard/get_members", func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			e := setupRBACMiddlewareTest(mockRepo)
+
+			mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+			rec := performMiddlewareRequest(e, http.MethodGet, "/api/v1/user_roles?scope=resource&resource_type="+url.QueryEscape(resourceType)+"&resource_id=d1", nil, headers)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("DASHBOARD_WIDGET with role=viewer matches dashboard_widget/assign_viewer", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := setupRBACMiddlewareTest(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(true, nil)
+
+		body := map[string]interface{}{
+			"resource_id":        "w1",
+			"resource_type":      "DASHBOARD_WIDGET",
+			"parent_resource_id": "d1",
+			"user_id":            "u1",
+			"role":               "viewer",
+		}
+		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 // ============================================================================
 // Test: No Matching Config Returns 400
 // ============================================================================
@@ -348,7 +400,7 @@ func TestRBACMiddlewarePermissionDenied(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnySystemRole", mock.Anything, "caller", "NS1", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS1", "caller", mock.Anything).Return(false, nil)
 
 		body := map[string]interface{}{"namespace": "ns1", "user_id": "u1", "role": "admin"}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles", body, headers)
@@ -359,7 +411,7 @@ func TestRBACMiddlewarePermissionDenied(t *testing.T) {
 		mockRepo := new(MockRBACRepository)
 		e := setupRBACMiddlewareTest(mockRepo)
 
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(false, nil)
 
 		body := map[string]interface{}{"resource_id": "d1", "resource_type": "dashboard", "user_id": "u1", "role": "viewer"}
 		rec := performMiddlewareRequest(e, http.MethodPost, "/api/v1/user_roles/resources", body, headers)
@@ -371,7 +423,7 @@ func TestRBACMiddlewarePermissionDenied(t *testing.T) {
 		e := setupRBACMiddlewareTest(mockRepo)
 
 		// No permission on parent dashboard
-		mockRepo.On("HasAnyResourceRole", mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(false, nil)
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "d1", "dashboard", mock.Anything).Return(false, nil)
 
 		body := map[string]interface{}{
 			"resource_id":        "w1",
@@ -385,6 +437,79 @@ func TestRBACMiddlewarePermissionDenied(t *testing.T) {
 	})
 }
 
+// ============================================================================
+// Test: Namespaced Resources
+// ============================================================================
+
+// TestRBACMiddlewareNamespacedResources tests that resource operations pass the request's namespace
+// to the role check, which scopes it when RESOURCE_INDEX_INCLUDE_NAMESPACE keys resources on namespace
+func TestRBACMiddlewareNamespacedResources(t *testing.T) {
+	headers := map[string]string{"x-user-id": "caller"}
+
+	cases := []struct {
+		name         string
+		method       string
+		path         string
+		body         map[string]interface{}
+		resourceID   string
+		resourceType string
+	}{
+		{
+			name:   "dashboard/transfer_owner reads namespace from the body",
+			method: http.MethodPut, path: "/api/v1/user_roles/resources/owner",
+			body:       map[string]interface{}{"namespace": "ns_a", "resource_id": "d1", "resource_type": "dashboard", "user_id": "u2"},
+			resourceID: "d1", resourceType: "dashboard",
+		},
+		{
+			name:   "dashboard/assign_user_roles_batch reads namespace from the body",
+			method: http.MethodPost, path: "/api/v1/user_roles/resources/batch",
+			body:       map[string]interface{}{"namespace": "ns_a", "resource_id": "d1", "resource_type": "dashboard", "user_ids": []string{"u1"}, "role": "viewer"},
+			resourceID: "d1", resourceType: "dashboard",
+		},
+		{
+			name:   "dashboard/delete_user_role reads namespace from the query",
+			method: http.MethodDelete, path: "/api/v1/user_roles/resources?namespace=ns_a&resource_id=d1&resource_type=dashboard&user_id=u1",
+			resourceID: "d1", resourceType: "dashboard",
+		},
+		{
+			name:   "dashboard/get_members reads namespace from the query",
+			method: http.MethodGet, path: "/api/v1/user_roles?scope=resource&namespace=ns_a&resource_type=dashboard&resource_id=d1",
+			resourceID: "d1", resourceType: "dashboard",
+		},
+		{
+			name:   "dashboard_widget/assign_viewer checks the parent dashboard in the namespace",
+			method: http.MethodPost, path: "/api/v1/user_roles/resources",
+			body: map[string]interface{}{
+				"namespace": "ns_a", "resource_id": "w1", "resource_type": "dashboard_widget",
+				"parent_resource_id": "d1", "user_id": "u1", "role": "viewer",
+			},
+			resourceID: "d1", resourceType: "dashboard",
+		},
+		{
+			name:   "dashboard_widget/delete_viewer checks the parent dashboard in the namespace",
+			method: http.MethodDelete, path: "/api/v1/user_roles/resources?namespace=ns_a&resource_id=w1&resource_type=dashboard_widget&parent_resource_id=d1&user_id=u1",
+			resourceID: "d1", resourceType: "dashboard",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			e := setupRBACMiddlewareTest(mockRepo)
+
+			mockRepo.On("HasAnyResourceRole", mock.Anything, "NS_A", "caller", tc.resourceID, tc.resourceType, mock.Anything).Return(true, nil)
+
+			var body interface{}
+			if tc.body != nil {
+				body = tc.body
+			}
+			rec := performMiddlewareRequest(e, tc.method, tc.path, body, headers)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 // ============================================================================
 // Test: Unauthorized Returns 401
 // ============================================================================
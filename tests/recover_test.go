@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rbac7/internal/rbac/handler"
+	"rbac7/internal/rbac/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	newServer := func(buf *bytes.Buffer) *echo.Echo {
+		e := echo.New()
+		e.Use(handler.RecoverMiddleware(slog.New(slog.NewJSONHandler(buf, nil))))
+		v1 := e.Group("/api/v1")
+		v1.Use(handler.RequestIDMiddleware)
+		v1.GET("/panic", func(c echo.Context) error {
+			panic("mongo: secret connection string leaked")
+		})
+		v1.GET("/ok", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+		})
+		return e
+	}
+
+	t.Run("panic returns enveloped 500 and logs the stack", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/panic", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-panic")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		var body model.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "internal_error", body.Error.Code)
+		assert.Equal(t, "req-panic", body.Error.RequestID)
+		assert.NotContains(t, rec.Body.String(), "secret")
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "req-panic", entry["request_id"])
+		assert.Contains(t, entry["panic"], "secret connection string")
+		assert.Contains(t, entry["stack"], "goroutine")
+	})
+
+	t.Run("normal request is untouched", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := newServer(&buf)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ok", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResourceIDCaseFolding(t *testing.T) {
+	assignPath := "/api/v1/user_roles/resources"
+
+	t.Run("case-sensitive mode stores Dash_1 as is and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, false)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "Dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "Dash_1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.ResourceID == "Dash_1"
+		})).Return(nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "viewer", ResourceID: "Dash_1", ResourceType: "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, assignPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("case-sensitive mode checks dash_1 separately from Dash_1 and return 200 false", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, false)
+
+		// u1 holds a role on Dash_1 only
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u1", "Dash_1", "dashboard", mock.Anything).Return(true, nil).Maybe()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u1", "dash_1", "dashboard", mock.Anything).Return(false, nil)
+
+		payload := map[string]string{"permission": "resource.dashboard.read", "scope": "resource", "resource_id": "dash_1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/permissions/check", payload, map[string]string{"x-user-id": "u1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allowed":false`)
+	})
+
+	t.Run("case-folding mode stores Dash_1 as dash_1 and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, true)
+
+		// Middleware and service both see the folded ID
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "dash_1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.ResourceID == "dash_1"
+		})).Return(nil)
+
+		payload := model.AssignResourceUserRoleReq{UserID: "u1", Role: "viewer", ResourceID: "Dash_1", ResourceType: "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, assignPath, payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("case-folding mode checks Dash_1 against dash_1 roles and return 200 true", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, true)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]string{"permission": "resource.dashboard.read", "scope": "resource", "resource_id": "Dash_1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/permissions/check", payload, map[string]string{"x-user-id": "u1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allowed":true`)
+	})
+
+	t.Run("case-folding mode folds each batch check and return 200 true", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, true)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "u1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{"checks": []map[string]string{
+			{"id": "c1", "permission": "resource.dashboard.read", "scope": "resource", "resource_id": "DASH_1", "resource_type": "dashboard"},
+		}}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/permissions/check/batch", payload, map[string]string{"x-user-id": "u1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"results":{"c1":true}}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("case-folding mode deletes DASH_1 as dash_1 and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, true)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "u1", "dash_1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "", "u1", model.ScopeResource, "dash_1", "dashboard", "", "caller").Return(int64(1), nil)
+		mockRepo.On("DeleteUserRolesByParent", mock.Anything, "", "u1", "dash_1", "dashboard_widget", "caller").Return(int64(1), nil)
+
+		rec := PerformRequest(e, http.MethodDelete, assignPath+"?user_id=u1&resource_id=DASH_1&resource_type=dashboard", nil, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("case-folding mode folds widget parent and child IDs and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithFoldResourceIDCase(mockRepo, true)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "caller", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("SoftDeleteResourceUserRoles", mock.Anything, mock.MatchedBy(func(req *model.SoftDeleteResourceReq) bool {
+			return req.ResourceID == "dash_1" && len(req.ChildResourceIDs) == 1 && req.ChildResourceIDs[0] == "w_1"
+		}), "caller").Return(int64(1), nil)
+
+		payload := model.SoftDeleteResourceReq{ResourceID: "Dash_1", ResourceType: "dashboard", ChildResourceIDs: []string{"W_1", "w_1"}}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/resources/delete", payload, map[string]string{"x-user-id": "caller"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TestPostRestoreUserRole tests POST /api/v1/user_roles/restore
+// This API undoes a soft delete, keeping the role's original created_at/created_by
+func TestPostRestoreUserRole(t *testing.T) {
+	apiPath := "/api/v1/user_roles/restore"
+	headers := map[string]string{"x-user-id": "admin_1"}
+
+	t.Run("restore system role and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// RBAC Middleware: caller can remove namespace members
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreUserRole", mock.Anything, "NS_1", "user_x", model.ScopeSystem, "", "").Return(nil).Once()
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "restore_user_role" && h.UserID == "user_x" && h.Namespace == "NS_1"
+		})).Return(nil).Once()
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "ns_1", "user_id": "user_x"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"success"}`, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore dashboard role and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreUserRole", mock.Anything, "", "user_x", model.ScopeResource, "d1", "dashboard").Return(nil).Once()
+
+		payload := map[string]interface{}{"scope": "resource", "resource_id": "d1", "resource_type": "dashboard", "user_id": "user_x"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore widget viewer checks the parent dashboard and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "admin_1", "d1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreUserRole", mock.Anything, "", "user_x", model.ScopeResource, "w1", "dashboard_widget").Return(nil).Once()
+
+		payload := map[string]interface{}{
+			"scope": "resource", "resource_id": "w1", "resource_type": "dashboard_widget", "parent_resource_id": "d1", "user_id": "user_x",
+		}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no soft-deleted role and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreUserRole", mock.Anything, "NS_1", "user_x", model.ScopeSystem, "", "").Return(mongo.ErrNoDocuments).Once()
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "NS_1", "user_id": "user_x"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not_found")
+	})
+
+	t.Run("caller without remove_member permission and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(false, nil)
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "NS_1", "user_id": "user_x"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "RestoreUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing user_id and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "RestoreUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error and return 500", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "admin_1", mock.Anything).Return(true, nil)
+		mockRepo.On("RestoreUserRole", mock.Anything, "NS_1", "user_x", model.ScopeSystem, "", "").Return(errors.New("db error")).Once()
+
+		payload := map[string]interface{}{"scope": "system", "namespace": "NS_1", "user_id": "user_x"}
+		rec := PerformRequest(e, http.MethodPost, apiPath, payload, headers)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
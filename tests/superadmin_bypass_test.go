@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuperadminBypass(t *testing.T) {
+	deletePath := "/api/v1/user_roles?namespace=NS_1&user_id=u_2"
+
+	t.Run("superadmin removes a member without any role and history is flagged and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("DeleteUserRole", mock.Anything, "NS_1", "u_2", "system", "", "", "", "break_glass").Return(int64(1), nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "delete_user_role" && h.CallerID == "break_glass" && h.SuperadminBypass
+		})).Return(nil)
+
+		rec := PerformRequest(e, http.MethodDelete, deletePath, nil, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("superadmin bypass is flagged in fire-and-forget history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.Anything).Return(&model.BatchUpsertResult{SuccessCount: 1}, nil)
+		recorded := make(chan *model.UserRoleHistory, 1)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { recorded <- args.Get(1).(*model.UserRoleHistory) }).Return(nil)
+
+		payload := map[string]interface{}{"user_ids": []string{"u_2"}, "role": "viewer", "resource_id": "dash_1", "resource_type": "dashboard"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", payload, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		select {
+		case h := <-recorded:
+			assert.True(t, h.SuperadminBypass)
+		case <-time.After(time.Second):
+			t.Fatal("history was not recorded")
+		}
+		mockRepo.AssertNotCalled(t, "HasAnyResourceRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("superadmin transfers a system owner they do not hold and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(&model.UserRole{UserID: "owner_1", Role: model.RoleSystemOwner}, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "break_glass", model.RoleSystemOwner).Return(false, nil)
+		mockRepo.On("TransferSystemOwner", mock.Anything, "NS_1", "owner_1", "new_owner", "break_glass").Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.Operation == "transfer_owner" && h.UserID == "owner_1" && h.NewOwnerID == "new_owner" && h.SuperadminBypass
+		})).Return(nil)
+
+		reqBody := model.SystemOwnerUpsertRequest{UserID: "new_owner", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPut, "/api/v1/user_roles/owner", reqBody, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("superadmin filters history by another user and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("FindHistory", mock.Anything, mock.MatchedBy(func(req model.GetUserRoleHistoryReq) bool {
+			return req.TargetUserID == "u_2"
+		})).Return([]*model.UserRoleHistory{}, int64(0), nil)
+
+		rec := PerformRequest(e, http.MethodGet, "/api/v1/user_roles/logs?scope=system&namespace=NS_1&target_user_id=u_2", nil, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "HasAnySystemRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("non-superadmin is still blocked and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "viewer_1", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodDelete, deletePath, nil, map[string]string{"x-user-id": "viewer_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "DeleteUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("superadmin still needs required parameters and return 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithSuperadmins(mockRepo, "break_glass")
+
+		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles?user_id=u_2", nil, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("without configured superadmins nobody bypasses and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "break_glass", mock.Anything).Return(false, nil)
+
+		rec := PerformRequest(e, http.MethodDelete, deletePath, nil, map[string]string{"x-user-id": "break_glass"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
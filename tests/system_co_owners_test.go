@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"rbac7/internal/rbac/model"
+	"rbac7/internal/rbac/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestSystemCoOwners tests namespaces with several owners when AllowMultipleOwners is set:
+// a second owner can be assigned, and only the last owner is protected from downgrade and removal
+func TestSystemCoOwners(t *testing.T) {
+	owners := map[string]string{"x-user-id": "moderator_1"}
+
+	t.Run("assign two owners to one namespace and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "", "moderator_1", mock.Anything).Return(true, nil)
+		mockRepo.On("CreateUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.UserID == "owner_1" && r.Role == model.RoleSystemOwner && r.Namespace == "NS_1"
+		})).Return(nil).Once()
+		mockRepo.On("CreateUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.UserID == "owner_2" && r.Role == model.RoleSystemOwner && r.Namespace == "NS_1"
+		})).Return(nil).Once()
+
+		for _, userID := range []string{"owner_1", "owner_2"} {
+			reqBody := model.SystemOwnerUpsertRequest{UserID: userID, Namespace: "NS_1"}
+			rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/owner", reqBody, owners)
+			assert.Equal(t, http.StatusOK, rec.Code, userID)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("downgrade one of two owners and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		// GetSystemOwner would return only one of the owners, so the target is checked directly
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_2", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("CountSystemOwners", mock.Anything, "NS_1").Return(int64(2), nil)
+		// The upsert never matches an owner role, so the co-owner's owner role is demoted instead
+		mockRepo.On("DemoteSystemOwner", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.UserID == "owner_2" && r.Role == "admin" && r.Namespace == "NS_1"
+		})).Return(nil)
+
+		reqBody := model.SystemUserRole{UserID: "owner_2", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertNotCalled(t, "GetSystemOwner", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("downgrade a co-owner who lost ownership meanwhile and return 404", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_2", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("CountSystemOwners", mock.Anything, "NS_1").Return(int64(2), nil)
+		mockRepo.On("DemoteSystemOwner", mock.Anything, mock.Anything).Return(repository.ErrOwnerNotFound)
+
+		reqBody := model.SystemUserRole{UserID: "owner_2", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("remove one of two owners and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_2", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("CountSystemOwners", mock.Anything, "NS_1").Return(int64(2), nil)
+		mockRepo.On("DeleteSystemOwner", mock.Anything, "NS_1", "owner_2", "owner_1").Return(int64(1), nil)
+
+		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles?namespace=NS_1&user_id=owner_2", nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"deleted_count":1`)
+		mockRepo.AssertNotCalled(t, "DeleteUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("downgrade the last owner and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_1", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("CountSystemOwners", mock.Anything, "NS_1").Return(int64(1), nil)
+
+		reqBody := model.SystemUserRole{UserID: "owner_1", Role: "admin", Namespace: "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", reqBody, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("remove the last owner and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMultipleOwners(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("HasSystemRole", mock.Anything, "NS_1", "owner_1", model.RoleSystemOwner).Return(true, nil)
+		mockRepo.On("CountSystemOwners", mock.Anything, "NS_1").Return(int64(1), nil)
+
+		rec := PerformRequest(e, http.MethodDelete, "/api/v1/user_roles?namespace=NS_1&user_id=owner_1", nil, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "DeleteUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"rbac7/internal/rbac/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTemporaryResourceGrant(t *testing.T) {
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	t.Run("batch grant persists expires_at and reason and records them in history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("BulkUpsertUserRoles", mock.Anything, mock.MatchedBy(func(roles []*model.UserRole) bool {
+			for _, r := range roles {
+				if r.ExpiresAt == nil || !r.ExpiresAt.Equal(expiresAt) || r.Reason != "Q3 project" {
+					return false
+				}
+			}
+			return len(roles) == 2
+		})).Return(&model.BatchUpsertResult{SuccessCount: 2}, nil)
+
+		recorded := make(chan *model.UserRoleHistory, 1)
+		mockRepo.On("CreateHistory", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { recorded <- args.Get(1).(*model.UserRoleHistory) }).Return(nil)
+
+		payload := map[string]interface{}{
+			"user_ids": []string{"contractor_1", "contractor_2"}, "role": "viewer",
+			"resource_id": "dash_1", "resource_type": "dashboard",
+			"expires_at": expiresAt.Format(time.RFC3339), "reason": " Q3 project ",
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// Batch history is recorded asynchronously
+		select {
+		case h := <-recorded:
+			assert.Equal(t, "assign_user_roles_batch", h.Operation)
+			if assert.NotNil(t, h.ExpiresAt) {
+				assert.True(t, h.ExpiresAt.Equal(expiresAt))
+			}
+			assert.Equal(t, "Q3 project", h.Reason)
+		case <-time.After(time.Second):
+			t.Fatal("history was not recorded")
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("single grant persists expires_at and reason in role and history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "contractor_1", "dash_1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.ExpiresAt != nil && r.ExpiresAt.Equal(expiresAt) && r.Reason == "Q3 project"
+		})).Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return h.ExpiresAt != nil && h.ExpiresAt.Equal(expiresAt) && h.Reason == "Q3 project"
+		})).Return(nil)
+
+		payload := map[string]interface{}{
+			"user_id": "contractor_1", "role": "viewer", "resource_id": "dash_1", "resource_type": "dashboard",
+			"expires_at": expiresAt.Format(time.RFC3339), "reason": "Q3 project",
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("grant with past expires_at returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"user_ids": []string{"contractor_1"}, "role": "viewer", "resource_id": "dash_1", "resource_type": "dashboard",
+			"expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339), "reason": "Q3 project",
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources/batch", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "expires_at must be in the future")
+		mockRepo.AssertNotCalled(t, "BulkUpsertUserRoles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ttl_seconds grant expires relative to now and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		before := time.Now()
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+		mockRepo.On("HasResourceRole", mock.Anything, mock.Anything, "contractor_1", "dash_1", "dashboard", model.RoleResourceOwner).Return(false, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return r.ExpiresAt != nil && !r.ExpiresAt.Before(before.Add(time.Hour)) && r.ExpiresAt.Before(time.Now().Add(time.Hour+time.Minute))
+		})).Return(nil)
+
+		payload := map[string]interface{}{
+			"user_id": "contractor_1", "role": "viewer", "resource_id": "dash_1", "resource_type": "dashboard", "ttl_seconds": 3600,
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("expires_at combined with ttl_seconds returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnyResourceRole", mock.Anything, mock.Anything, "owner_1", "dash_1", "dashboard", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"user_id": "contractor_1", "role": "viewer", "resource_id": "dash_1", "resource_type": "dashboard",
+			"expires_at": expiresAt.Format(time.RFC3339), "ttl_seconds": 3600,
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles/resources", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "expires_at and ttl_seconds cannot be combined")
+	})
+}
+
+func TestTemporarySystemGrant(t *testing.T) {
+	t.Run("system grant with ttl_seconds persists expiry and reason in role and history and return 200", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		before := time.Now()
+		expiresWithinADay := func(at *time.Time) bool {
+			return at != nil && !at.Before(before.Add(24*time.Hour)) && at.Before(time.Now().Add(25*time.Hour))
+		}
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+		mockRepo.On("GetSystemOwner", mock.Anything, "NS_1").Return(nil, nil)
+		mockRepo.On("UpsertUserRole", mock.Anything, mock.MatchedBy(func(r *model.UserRole) bool {
+			return expiresWithinADay(r.ExpiresAt) && r.Reason == "audit support"
+		})).Return(nil)
+		mockRepo.On("CreateHistory", mock.Anything, mock.MatchedBy(func(h *model.UserRoleHistory) bool {
+			return expiresWithinADay(h.ExpiresAt) && h.Reason == "audit support"
+		})).Return(nil)
+
+		payload := map[string]interface{}{
+			"user_id": "contractor_1", "role": "viewer", "namespace": "NS_1", "ttl_seconds": 86400, "reason": "audit support",
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("system grant with past expires_at returns 400", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "owner_1", mock.Anything).Return(true, nil)
+
+		payload := map[string]interface{}{
+			"user_id": "contractor_1", "role": "viewer", "namespace": "NS_1", "expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339),
+		}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", payload, map[string]string{"x-user-id": "owner_1"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("caller whose system grant has expired is denied and return 403", func(t *testing.T) {
+		mockRepo := new(MockRBACRepository)
+		e := SetupServerWithMiddleware(mockRepo)
+
+		// The repository's expiry guard no longer matches the caller's admin role
+		mockRepo.On("HasAnySystemRole", mock.Anything, "NS_1", "contractor_1", mock.Anything).Return(false, nil)
+
+		payload := map[string]interface{}{"user_id": "u_2", "role": "viewer", "namespace": "NS_1"}
+		rec := PerformRequest(e, http.MethodPost, "/api/v1/user_roles", payload, map[string]string{"x-user-id": "contractor_1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockRepo.AssertNotCalled(t, "UpsertUserRole", mock.Anything, mock.Anything)
+	})
+}